@@ -1,8 +1,12 @@
 package cfg
 
 import (
+	"log/slog"
+	"sync/atomic"
 	"testing"
 
+	"github.com/BYT0723/go-tools/ds"
+	"github.com/BYT0723/go-tools/logx"
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
@@ -172,3 +176,75 @@ func TestReloadKeyChangeHandler(t *testing.T) {
 		})
 	})
 }
+
+func TestReloadAtomicChangeHandler(t *testing.T) {
+	old := config
+	t.Cleanup(func() { config = old })
+	config = &_config{viper: viper.New()}
+	config.decodeOpts = append(config.decodeOpts, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "cfg"
+	})
+
+	t.Run("ReloadAtomic 替换快照", func(t *testing.T) {
+		type server struct {
+			Name string `cfg:"name"`
+		}
+		var ptr atomic.Pointer[server]
+		config.viper.Set("server.name", "a")
+		h := ReloadAtomic("server", &ptr)
+		h(fsnotify.Event{Name: "test", Op: fsnotify.Write})
+		first := ptr.Load()
+		assert.Equal(t, "a", first.Name)
+
+		config.viper.Set("server.name", "b")
+		h(fsnotify.Event{Name: "test", Op: fsnotify.Write})
+		assert.Equal(t, "b", ptr.Load().Name)
+		assert.Equal(t, "a", first.Name, "旧快照不应被修改")
+	})
+
+	t.Run("ReloadPMap 生成 PMap 快照", func(t *testing.T) {
+		var ptr atomic.Pointer[ds.PMap[string, string]]
+		config.viper.Set("routes", map[string]any{"api": "backend-1", "web": "backend-2"})
+		h := ReloadPMap("routes", &ptr)
+		h(fsnotify.Event{Name: "test", Op: fsnotify.Write})
+		m := ptr.Load()
+		assert.Equal(t, 2, m.Len())
+		v, ok := m.Get("api")
+		assert.True(t, ok)
+		assert.Equal(t, "backend-1", v)
+	})
+
+	t.Run("ReloadLogLevels 更新日志级别", func(t *testing.T) {
+		l, err := logx.NewLogger(logx.WithSlogHandler(slog.DiscardHandler))
+		assert.NoError(t, err)
+		logx.SetDefault(l)
+
+		config.viper.Set("log_levels", map[string]any{"root": "warn", "cfgtest": "debug"})
+		ReloadLogLevels("log_levels")(fsnotify.Event{Name: "test", Op: fsnotify.Write})
+		level, _ := logx.GetLevel("")
+		assert.Equal(t, "warn", level)
+		level, _ = logx.GetLevel("cfgtest")
+		assert.Equal(t, "debug", level)
+
+		// One unknown level rejects the whole map, without panicking.
+		config.viper.Set("log_levels", map[string]any{"root": "error", "cfgtest": "verbose"})
+		assert.ErrorContains(t, ApplyLogLevels("log_levels"), `"verbose"`)
+		assert.NotPanics(t, func() {
+			ReloadLogLevels("log_levels")(fsnotify.Event{Name: "test", Op: fsnotify.Write})
+		})
+		level, _ = logx.GetLevel("")
+		assert.Equal(t, "warn", level)
+		level, _ = logx.GetLevel("cfgtest")
+		assert.Equal(t, "debug", level)
+
+		config.viper.Set("log_levels", "debug")
+		assert.Error(t, ApplyLogLevels("log_levels"))
+	})
+
+	t.Run("matcher返回false时不替换", func(t *testing.T) {
+		var ptr atomic.Pointer[map[string]any]
+		h := ReloadAtomic("", &ptr, func(e fsnotify.Event) bool { return false })
+		h(fsnotify.Event{Name: "test", Op: fsnotify.Write})
+		assert.Nil(t, ptr.Load())
+	})
+}
@@ -1,9 +1,17 @@
 package cfg
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"sync/atomic"
 	"syscall"
 
+	"github.com/BYT0723/go-tools/ds"
+	"github.com/BYT0723/go-tools/logx"
+	"github.com/BYT0723/go-tools/logx/logcore"
 	"github.com/fsnotify/fsnotify"
 )
 
@@ -50,3 +58,101 @@ func ReloadKey(key string, target any, matchers ...ChangeMatcher) ChangeHandler
 		}
 	}
 }
+
+// ReloadAtomic decodes key (the whole config if key is empty) into a new T and
+// stores it in ptr, so readers of ptr never see a partially decoded value.
+func ReloadAtomic[T any](key string, ptr *atomic.Pointer[T], matchers ...ChangeMatcher) ChangeHandler {
+	return func(e fsnotify.Event) {
+		for _, m := range matchers {
+			if !m(e) {
+				return
+			}
+		}
+		v := new(T)
+		if err := unmarshalKey(key, v); err != nil {
+			panic(err)
+		}
+		ptr.Store(v)
+	}
+}
+
+// ReloadPMap decodes key (the whole config if key is empty) into a map and
+// stores it in ptr as an immutable ds.PMap snapshot.
+func ReloadPMap[V any](key string, ptr *atomic.Pointer[ds.PMap[string, V]], matchers ...ChangeMatcher) ChangeHandler {
+	return func(e fsnotify.Event) {
+		for _, m := range matchers {
+			if !m(e) {
+				return
+			}
+		}
+		var raw map[string]V
+		if err := unmarshalKey(key, &raw); err != nil {
+			panic(err)
+		}
+		b := ds.NewPMap[string, V]().Transient()
+		for k, v := range raw {
+			b.Set(k, v)
+		}
+		ptr.Store(b.Map())
+	}
+}
+
+func unmarshalKey(key string, target any) error {
+	if key == "" {
+		return Unmarshal(target)
+	}
+	return UnmarshalKey(key, target)
+}
+
+// ReloadLogLevels applies key with ApplyLogLevels. A config that cannot be
+// applied is reported with logx.Error and leaves every level as it was.
+//
+// Example config:
+//
+//	log_levels:
+//	  root: info
+//	  db: debug
+func ReloadLogLevels(key string, matchers ...ChangeMatcher) ChangeHandler {
+	return func(e fsnotify.Event) {
+		for _, m := range matchers {
+			if !m(e) {
+				return
+			}
+		}
+		if err := ApplyLogLevels(key); err != nil {
+			logx.Error("cfg: reload log levels", logx.Err(err))
+		}
+	}
+}
+
+// ApplyLogLevels decodes key (the whole config if key is empty) as a map from
+// logger name to level and applies it with logx.SetLevel. The empty name, or
+// "root", is the default logger. The whole map is checked first: if it does
+// not decode or a level is unknown, no level is changed.
+func ApplyLogLevels(key string) error {
+	var levels map[string]string
+	if err := unmarshalKey(key, &levels); err != nil {
+		return err
+	}
+	names := slices.Sorted(maps.Keys(levels))
+	for _, name := range names {
+		if !logcore.ValidLevel(levels[name]) {
+			return fmt.Errorf("cfg: unknown level %q for logger %q", levels[name], name)
+		}
+		if name == "" || name == "root" {
+			if _, err := logx.GetLevel(""); err != nil {
+				return err
+			}
+		}
+	}
+
+	var errs []error
+	for _, name := range names {
+		level := levels[name]
+		if name == "root" {
+			name = ""
+		}
+		errs = append(errs, logx.SetLevel(name, level))
+	}
+	return errors.Join(errs...)
+}
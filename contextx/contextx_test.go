@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/BYT0723/go-tools/logx"
 	"github.com/BYT0723/go-tools/logx/noplogger"
 
 	"github.com/stretchr/testify/assert"
@@ -58,6 +59,30 @@ func TestLogger(t *testing.T) {
 	})
 }
 
+func TestFields(t *testing.T) {
+	t.Run("Fields 测试", func(t *testing.T) {
+		assert.Empty(t, Fields(context.Background()))
+
+		ctx := WithTraceID(context.Background(), "t1")
+		ctx = WithRequestID(ctx, "r1")
+		ctx = WithEnv(ctx, "prod")
+		assert.Equal(t, []logx.Field{
+			logx.String("trace_id", "t1"),
+			logx.String("request_id", "r1"),
+			logx.String("env", "prod"),
+		}, Fields(ctx))
+
+		t.Run("已注册为 logx 提取器", func(t *testing.T) {
+			assert.Equal(t, Fields(ctx), logx.ContextFields(ctx))
+		})
+
+		t.Run("与 logx.FromContext 共享 Logger", func(t *testing.T) {
+			logger := noplogger.NopLogger{}
+			assert.Equal(t, logger, logx.FromContext(WithLogger(context.Background(), logger)))
+		})
+	})
+}
+
 func TestTraceID(t *testing.T) {
 	t.Run("TraceID 测试", func(t *testing.T) {
 		t.Run("获取不存在的TraceID返回错误", func(t *testing.T) {
@@ -0,0 +1,34 @@
+package ctxx
+
+import (
+	"context"
+
+	"github.com/BYT0723/go-tools/logx"
+)
+
+func init() {
+	logx.RegisterContextExtractor(Fields)
+}
+
+// Fields returns the trace values carried by ctx as log fields:
+// trace_id, span_id, request_id, service, version and env.
+// It is registered as a logx context extractor.
+func Fields(ctx context.Context) []logx.Field {
+	var fs []logx.Field
+	for _, v := range []struct {
+		key string
+		get func(context.Context) (string, error)
+	}{
+		{"trace_id", TraceID},
+		{"span_id", SpanID},
+		{"request_id", RequestID},
+		{"service", Service},
+		{"version", Version},
+		{"env", Env},
+	} {
+		if s, err := v.get(ctx); err == nil {
+			fs = append(fs, logx.String(v.key, s))
+		}
+	}
+	return fs
+}
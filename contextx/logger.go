@@ -6,17 +6,16 @@ import (
 	"github.com/BYT0723/go-tools/logx"
 )
 
-type loggerKey struct{}
-
-// log.Logger or nil
+// Logger returns the logger stored by WithLogger. It is the same value as
+// logx.FromContext, so the logx *Ctx functions log through it too.
 func Logger(ctx context.Context) (logx.Logger, error) {
-	l, ok := ctx.Value(loggerKey{}).(logx.Logger)
-	if !ok {
+	l := logx.FromContext(ctx)
+	if l == nil {
 		return nil, ErrLoggerNotFound
 	}
 	return l, nil
 }
 
 func WithLogger(ctx context.Context, logger logx.Logger) context.Context {
-	return context.WithValue(ctx, loggerKey{}, logger)
+	return logx.WithContext(ctx, logger)
 }
@@ -2,6 +2,7 @@ package ds
 
 import (
 	"fmt"
+	"iter"
 	"strings"
 )
 
@@ -60,6 +61,17 @@ func (m *ArrayStack[T]) Size() int {
 	return len(m.items)
 }
 
+// All returns an iterator over the elements from top to bottom.
+func (m *ArrayStack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(m.items) - 1; i >= 0; i-- {
+			if !yield(m.items[i]) {
+				return
+			}
+		}
+	}
+}
+
 // String returns a string representation of the stack with elements from top to bottom.
 func (m *ArrayStack[T]) String() string {
 	var (
@@ -243,6 +243,99 @@ func (m *ShardBitMutex) TryLock(i int) bool {
 	return atomic.CompareAndSwapUint64(&(m.shards[shardIndex].val), old, old|mask)
 }
 
+// LockMany acquires the locks at all the given bit positions at once.
+// Since all bits are set with a single CompareAndSwap, callers locking
+// overlapping sets cannot deadlock each other.
+//
+// Parameters:
+//   - indices: The bit indexes to lock (0 ≤ i < 64). Duplicates are allowed.
+//
+// Panics:
+//   - If any index is out of range (i < 0 or i ≥ 64)
+func (m *BitMutex) LockMany(indices ...int) {
+	m.lockMask(bitMask(indices...))
+}
+
+// UnlockMany releases the locks at all the given bit positions.
+//
+// Panics:
+//   - If any index is out of range (i < 0 or i ≥ 64)
+func (m *BitMutex) UnlockMany(indices ...int) {
+	atomic.AndUint64(&m.val, ^bitMask(indices...))
+}
+
+// LockMany acquires the locks at all the given positions in the sharded mutex.
+// The locks of each shard are taken at once, and shards are locked in
+// ascending order, so callers locking overlapping sets cannot deadlock.
+//
+// Parameters:
+//   - indices: The lock indexes (0 ≤ i < 512). Duplicates are allowed.
+//
+// Panics:
+//   - If any index is out of range (i < 0 or i ≥ 512)
+func (m *ShardBitMutex) LockMany(indices ...int) {
+	masks := shardMasks(indices...)
+	for shard, mask := range masks {
+		if mask == 0 {
+			continue
+		}
+		m.shards[shard].lockMask(mask)
+	}
+}
+
+// UnlockMany releases the locks at all the given positions in the sharded mutex.
+//
+// Panics:
+//   - If any index is out of range (i < 0 or i ≥ 512)
+func (m *ShardBitMutex) UnlockMany(indices ...int) {
+	masks := shardMasks(indices...)
+	for shard, mask := range masks {
+		if mask != 0 {
+			atomic.AndUint64(&m.shards[shard].val, ^mask)
+		}
+	}
+}
+
+// lockMask sets all bits of mask with a single CompareAndSwap.
+func (m *BitMutex) lockMask(mask uint64) {
+	spin := 1
+	for {
+		old := atomic.LoadUint64(&m.val)
+		if old&mask == 0 && atomic.CompareAndSwapUint64(&m.val, old, old|mask) {
+			return
+		}
+		for range spin {
+			runtime.Gosched()
+		}
+		if spin < maxSpin {
+			spin *= 2
+		}
+	}
+}
+
+// bitMask returns the mask of the given bit positions of a BitMutex.
+func bitMask(indices ...int) (mask uint64) {
+	for _, i := range indices {
+		if i >= 64 || i < 0 {
+			panic("BitMutex: index out of range")
+		}
+		mask |= uint64(1) << i
+	}
+	return mask
+}
+
+// shardMasks returns the per-shard masks of the given ShardBitMutex indexes.
+func shardMasks(indices ...int) (masks [shardCount]uint64) {
+	for _, i := range indices {
+		if i < 0 || i >= shardCount*bitsPerShard {
+			panic("ShardBitMutex: index out of range")
+		}
+		shardIndex, bitIndex := i2Index(i)
+		masks[shardIndex] |= uint64(1) << bitIndex
+	}
+	return masks
+}
+
 // i2Index maps a global lock index to a shard index and bit index within that shard.
 // This function implements the sharding strategy used by ShardBitMutex to distribute
 // consecutive lock indices across different shards, reducing contention.
@@ -319,3 +319,42 @@ func TestMixedOperations(t *testing.T) {
 		})
 	})
 }
+
+func TestLockMany(t *testing.T) {
+	t.Run("BitMutex LockMany", func(t *testing.T) {
+		var m BitMutex
+		m.LockMany(1, 5, 5, 9)
+		assert.False(t, m.TryLock(5))
+		assert.True(t, m.TryLock(2))
+		m.Unlock(2)
+		m.UnlockMany(1, 5, 9)
+		assert.True(t, m.TryLock(9))
+		m.Unlock(9)
+		assert.Panics(t, func() { m.LockMany(1, 64) })
+	})
+
+	t.Run("ShardBitMutex LockMany 交叉加锁不死锁", func(t *testing.T) {
+		var (
+			m       ShardBitMutex
+			counter int
+			wg      sync.WaitGroup
+		)
+		for g := range 8 {
+			wg.Go(func() {
+				a, b := 3, 200
+				if g%2 == 1 {
+					a, b = b, a
+				}
+				for range 500 {
+					m.LockMany(a, b)
+					counter++
+					m.UnlockMany(a, b)
+				}
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, 4000, counter)
+		assert.True(t, m.TryLock(3))
+		assert.True(t, m.TryLock(200))
+	})
+}
@@ -0,0 +1,310 @@
+package ds
+
+import (
+	"encoding"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*BloomFilter[string])(nil)
+	_ encoding.BinaryUnmarshaler = (*BloomFilter[string])(nil)
+)
+
+const (
+	bloomMagic   = 'B'
+	bloomVersion = 1
+
+	// bloomGrowth is the capacity factor between consecutive stages.
+	bloomGrowth = 2
+	// bloomTightening is the false positive factor between consecutive stages;
+	// the total error converges to fpRate.
+	bloomTightening = 0.85
+	// bloomMaxHashes bounds the number of hash functions of a decoded stage.
+	bloomMaxHashes = 64
+)
+
+type (
+	// BloomFilter is a scalable Bloom filter (Almeida et al.): a space-efficient
+	// set that may report false positives but never false negatives.
+	//
+	// It starts with one stage sized for the initial capacity; when a stage is
+	// full a new stage with twice the capacity and a tighter error rate is added,
+	// so memory grows with the number of elements while the overall false
+	// positive rate stays below fpRate.
+	//
+	// BloomFilter is not safe for concurrent use; see SyncBloomFilter.
+	//
+	// Type parameters:
+	//   - T: The element type
+	BloomFilter[T any] struct {
+		capacity uint64
+		fpRate   float64
+		stages   []*bloomStage
+		hash     Hasher[T]
+	}
+
+	bloomStage struct {
+		bits     []uint64
+		m        uint64 // number of bits
+		k        uint64 // number of hash functions
+		capacity uint64
+		count    uint64
+	}
+)
+
+// NewBloomFilter creates a scalable BloomFilter.
+//
+// Parameters:
+//   - capacity: Expected number of elements of the first stage
+//   - fpRate: Target false positive rate in (0, 1), e.g. 0.01
+//   - hash: Hasher of the elements
+//
+// Example:
+//
+//	seen := NewBloomFilter(1<<20, 0.001, HashString)
+//	if seen.Add(url) {
+//		crawl(url) // first time we see url
+//	}
+func NewBloomFilter[T any](capacity uint64, fpRate float64, hash Hasher[T]) *BloomFilter[T] {
+	if hash == nil {
+		panic("bloom filter: nil hasher")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	return &BloomFilter[T]{
+		capacity: max(1, capacity),
+		fpRate:   fpRate,
+		hash:     hash,
+	}
+}
+
+func newBloomStage(capacity uint64, fpRate float64) *bloomStage {
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = max(64, (m+63)/64*64)
+	return &bloomStage{
+		bits:     make([]uint64, m/64),
+		m:        m,
+		k:        max(1, uint64(math.Ceil(-math.Log2(fpRate)))),
+		capacity: capacity,
+	}
+}
+
+func (s *bloomStage) add(h uint64) {
+	for i := range s.k {
+		b := doubleHash(h, i, s.m)
+		s.bits[b/64] |= 1 << (b % 64)
+	}
+	s.count++
+}
+
+func (s *bloomStage) test(h uint64) bool {
+	for i := range s.k {
+		b := doubleHash(h, i, s.m)
+		if s.bits[b/64]&(1<<(b%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds v to the filter. It reports whether v was added, i.e. false if v
+// was (probably) already present.
+func (f *BloomFilter[T]) Add(v T) bool {
+	h := f.hash(v)
+	if f.test(h) {
+		return false
+	}
+	last := len(f.stages) - 1
+	if last < 0 || f.stages[last].count >= f.stages[last].capacity {
+		f.grow()
+		last++
+	}
+	f.stages[last].add(h)
+	return true
+}
+
+func (f *BloomFilter[T]) grow() {
+	n := len(f.stages)
+	f.stages = append(f.stages, newBloomStage(
+		f.capacity*uint64(math.Pow(bloomGrowth, float64(n))),
+		f.fpRate*(1-bloomTightening)*math.Pow(bloomTightening, float64(n)),
+	))
+}
+
+// Contains reports whether v may be in the filter.
+// false means v was definitely never added.
+func (f *BloomFilter[T]) Contains(v T) bool {
+	return f.test(f.hash(v))
+}
+
+func (f *BloomFilter[T]) test(h uint64) bool {
+	for _, s := range f.stages {
+		if s.test(h) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the approximate number of elements added.
+func (f *BloomFilter[T]) Len() int {
+	var n uint64
+	for _, s := range f.stages {
+		n += s.count
+	}
+	return int(n)
+}
+
+// Merge adds all elements of other to f. Both filters must have been created
+// with the same capacity and fpRate, otherwise ErrIncompatibleSketch is returned.
+func (f *BloomFilter[T]) Merge(other *BloomFilter[T]) error {
+	if f.capacity != other.capacity || f.fpRate != other.fpRate {
+		return ErrIncompatibleSketch
+	}
+	for i := range min(len(f.stages), len(other.stages)) {
+		if f.stages[i].m != other.stages[i].m || f.stages[i].k != other.stages[i].k {
+			return ErrIncompatibleSketch
+		}
+	}
+	for i, o := range other.stages {
+		if i == len(f.stages) {
+			f.stages = append(f.stages, o.clone())
+			continue
+		}
+		s := f.stages[i]
+		for j := range s.bits {
+			s.bits[j] |= o.bits[j]
+		}
+		s.count = min(s.capacity, s.count+o.count)
+	}
+	return nil
+}
+
+func (s *bloomStage) clone() *bloomStage {
+	c := *s
+	c.bits = append([]uint64(nil), s.bits...)
+	return &c
+}
+
+func (f *BloomFilter[T]) clone() *BloomFilter[T] {
+	c := *f
+	c.stages = make([]*bloomStage, len(f.stages))
+	for i, s := range f.stages {
+		c.stages[i] = s.clone()
+	}
+	return &c
+}
+
+// MarshalBinary encodes the filter. The Hasher is not encoded.
+func (f *BloomFilter[T]) MarshalBinary() ([]byte, error) {
+	b := []byte{bloomMagic, bloomVersion}
+	b = binary.BigEndian.AppendUint64(b, f.capacity)
+	b = binary.BigEndian.AppendUint64(b, math.Float64bits(f.fpRate))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(f.stages)))
+	for _, s := range f.stages {
+		b = binary.BigEndian.AppendUint64(b, s.m)
+		b = binary.BigEndian.AppendUint64(b, s.k)
+		b = binary.BigEndian.AppendUint64(b, s.capacity)
+		b = binary.BigEndian.AppendUint64(b, s.count)
+		for _, w := range s.bits {
+			b = binary.BigEndian.AppendUint64(b, w)
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces the filter with the decoded data, keeping its Hasher.
+// Returns ErrInvalidEncoding if the data is truncated or its parameters are
+// out of range; the filter is left unchanged then.
+func (f *BloomFilter[T]) UnmarshalBinary(data []byte) error {
+	r := &sketchReader{b: data}
+	r.header(bloomMagic, bloomVersion)
+	var (
+		capacity = r.u64()
+		fpRate   = math.Float64frombits(r.u64())
+		n        = r.u32()
+		stages   []*bloomStage
+	)
+	if r.err == nil && (capacity == 0 || !(fpRate > 0 && fpRate < 1)) {
+		return ErrInvalidEncoding
+	}
+	for range n {
+		s := &bloomStage{m: r.u64(), k: r.u64(), capacity: r.u64(), count: r.u64()}
+		if r.err != nil {
+			return r.err
+		}
+		if s.m == 0 || s.m%64 != 0 || s.k == 0 || s.k > bloomMaxHashes || s.count > s.capacity {
+			return ErrInvalidEncoding
+		}
+		s.bits = r.u64s(s.m / 64)
+		if r.err != nil {
+			return r.err
+		}
+		stages = append(stages, s)
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	f.capacity, f.fpRate, f.stages = capacity, fpRate, stages
+	return nil
+}
+
+// SyncBloomFilter is a BloomFilter safe for concurrent use.
+type SyncBloomFilter[T any] struct {
+	mutex sync.RWMutex
+	f     *BloomFilter[T]
+}
+
+// NewSyncBloomFilter creates a SyncBloomFilter. See NewBloomFilter for the parameters.
+func NewSyncBloomFilter[T any](capacity uint64, fpRate float64, hash Hasher[T]) *SyncBloomFilter[T] {
+	return &SyncBloomFilter[T]{f: NewBloomFilter(capacity, fpRate, hash)}
+}
+
+// Add adds v to the filter and reports whether it was added.
+func (f *SyncBloomFilter[T]) Add(v T) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.Add(v)
+}
+
+// Contains reports whether v may be in the filter.
+func (f *SyncBloomFilter[T]) Contains(v T) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.f.Contains(v)
+}
+
+// Len returns the approximate number of elements added.
+func (f *SyncBloomFilter[T]) Len() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.f.Len()
+}
+
+// Merge adds all elements of other to f.
+func (f *SyncBloomFilter[T]) Merge(other *SyncBloomFilter[T]) error {
+	other.mutex.RLock()
+	o := other.f.clone()
+	other.mutex.RUnlock()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.Merge(o)
+}
+
+// MarshalBinary encodes the filter.
+func (f *SyncBloomFilter[T]) MarshalBinary() ([]byte, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.f.MarshalBinary()
+}
+
+// UnmarshalBinary replaces the filter with the decoded data.
+func (f *SyncBloomFilter[T]) UnmarshalBinary(data []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.UnmarshalBinary(data)
+}
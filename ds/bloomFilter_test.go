@@ -0,0 +1,110 @@
+package ds
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter(t *testing.T) {
+	t.Run("BloomFilter 测试", func(t *testing.T) {
+		f := NewBloomFilter(1000, 0.01, HashString)
+
+		t.Run("无假阴性且可扩容", func(t *testing.T) {
+			// Add reports false for elements that collide with earlier ones.
+			var collisions int
+			for i := range 10000 {
+				if !f.Add("in-" + strconv.Itoa(i)) {
+					collisions++
+				}
+			}
+			assert.Less(t, collisions, 100)
+			for i := range 10000 {
+				assert.True(t, f.Contains("in-"+strconv.Itoa(i)))
+			}
+			assert.False(t, f.Add("in-1"))
+			assert.Greater(t, len(f.stages), 1)
+		})
+
+		t.Run("假阳性率", func(t *testing.T) {
+			var fp int
+			for i := range 10000 {
+				if f.Contains("out-" + strconv.Itoa(i)) {
+					fp++
+				}
+			}
+			assert.Less(t, float64(fp)/10000, 0.02)
+		})
+
+		t.Run("序列化", func(t *testing.T) {
+			data, err := f.MarshalBinary()
+			assert.Nil(t, err)
+			g := NewBloomFilter(1, 0.5, HashString)
+			assert.Nil(t, g.UnmarshalBinary(data))
+			assert.Equal(t, f.Len(), g.Len())
+			assert.True(t, g.Contains("in-42"))
+			assert.ErrorIs(t, g.UnmarshalBinary(data[:len(data)-1]), ErrInvalidEncoding)
+		})
+
+		t.Run("损坏的头部返回错误", func(t *testing.T) {
+			data, err := f.MarshalBinary()
+			assert.Nil(t, err)
+			// Offsets of the filter header and of the first stage header.
+			const capacity, fpRate, stage = 2, 10, 22
+			for name, corrupt := range map[string]func(b []byte){
+				"容量为0":     func(b []byte) { binary.BigEndian.PutUint64(b[capacity:], 0) },
+				"误判率越界":    func(b []byte) { binary.BigEndian.PutUint64(b[fpRate:], math.Float64bits(1.5)) },
+				"位数为0":     func(b []byte) { binary.BigEndian.PutUint64(b[stage:], 0) },
+				"哈希函数过多":   func(b []byte) { binary.BigEndian.PutUint64(b[stage+8:], 65) },
+				"计数超过容量":   func(b []byte) { binary.BigEndian.PutUint64(b[stage+24:], math.MaxUint64) },
+				"阶段数与数据不符": func(b []byte) { binary.BigEndian.PutUint32(b[stage-4:], math.MaxUint32) },
+			} {
+				t.Run(name, func(t *testing.T) {
+					b := append([]byte(nil), data...)
+					corrupt(b)
+					g := NewBloomFilter(1, 0.5, HashString)
+					assert.ErrorIs(t, g.UnmarshalBinary(b), ErrInvalidEncoding)
+					assert.Equal(t, 0, g.Len())
+
+					sg := NewSyncBloomFilter(1, 0.5, HashString)
+					assert.ErrorIs(t, sg.UnmarshalBinary(b), ErrInvalidEncoding)
+				})
+			}
+		})
+
+		t.Run("合并", func(t *testing.T) {
+			a := NewBloomFilter(100, 0.01, HashString)
+			b := NewBloomFilter(100, 0.01, HashString)
+			for i := range 300 {
+				b.Add(strconv.Itoa(i))
+			}
+			a.Add("a")
+			assert.Nil(t, a.Merge(b))
+			assert.True(t, a.Contains("a"))
+			assert.True(t, a.Contains("299"))
+			assert.ErrorIs(t, a.Merge(NewBloomFilter(10, 0.01, HashString)), ErrIncompatibleSketch)
+		})
+	})
+
+	t.Run("SyncBloomFilter 并发", func(t *testing.T) {
+		var (
+			f  = NewSyncBloomFilter(100, 0.01, HashUint64)
+			wg sync.WaitGroup
+		)
+		for w := range 4 {
+			wg.Go(func() {
+				for i := range 500 {
+					f.Add(uint64(w*500 + i))
+				}
+			})
+		}
+		wg.Wait()
+		for i := range 2000 {
+			assert.True(t, f.Contains(uint64(i)))
+		}
+	})
+}
@@ -1,33 +1,48 @@
 package ds
 
 import (
+	"errors"
 	"sync"
 )
 
+// DefaultTopicBufSize is the subscriber buffer size of topics
+// created implicitly by BroadcastBus.Subscribe.
+const DefaultTopicBufSize = 64
+
 // BroadcastBus is a multi-topic, multi-subscriber pub-sub bus.
 //
-// Each topic is a FastHub (fan-out):
-// - When you publish a message to a topic, all subscribers receive a copy.
-// - If a topic has no subscribers, messages are discarded.
+// Topics are hierarchical, with levels separated by "/" (e.g. "alerts/web/api").
+// Subscriptions use MQTT-style topic filters:
+//   - "+" matches exactly one level: "alerts/+/api" matches "alerts/web/api".
+//   - "#" matches any number of trailing levels: "alerts/#" matches "alerts"
+//     and "alerts/web/api".
+//
+// Each topic filter is a FastHub (fan-out) stored in a trie:
+// - When you publish a message to a topic, every subscriber whose filter matches receives a copy.
+// - If no filter matches the topic, messages are discarded.
+// - A filter is created on first subscribe and removed when its last subscriber leaves.
 //
 // Typical use case: event broadcast, notifications, state updates.
 type BroadcastBus[T any] struct {
-	mutex       sync.Mutex             // protects subscribers map
-	subscribers map[string]*FastHub[T] // topic -> FastHub
-	closeModule                        // handles closed state
+	mutex       sync.Mutex              // protects subscribers trie
+	subscribers *topicTrie[*FastHub[T]] // topic filter -> FastHub
+	closeModule                         // handles closed state
 }
 
-// NewBroadcastBus creates a new BroadcastBus with the given buffer size
-// for each subscriber channel.
+// NewBroadcastBus creates a new BroadcastBus.
 func NewBroadcastBus[T any]() *BroadcastBus[T] {
 	return &BroadcastBus[T]{
-		subscribers: make(map[string]*FastHub[T]),
+		subscribers: newTopicTrie[*FastHub[T]](),
 	}
 }
 
-// AddTopic creates a new topic with its own buffered channel.
-// Returns an error if the topic already exists.
+// AddTopic creates a new topic filter whose subscribers use the given buffer size.
+// Returns an error if the topic already exists or the filter is invalid.
 func (u *BroadcastBus[T]) AddTopic(topic string, bufSize int) error {
+	if err := ValidateTopicFilter(topic); err != nil {
+		return err
+	}
+
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
@@ -35,14 +50,14 @@ func (u *BroadcastBus[T]) AddTopic(topic string, bufSize int) error {
 		return err
 	}
 
-	if _, ok := u.subscribers[topic]; ok {
+	if _, ok := u.subscribers.Get(topic); ok {
 		return ErrTopicAlreadyExists
 	}
-	u.subscribers[topic] = NewFastHub[T](bufSize)
+	u.subscribers.Put(topic, NewFastHub[T](bufSize))
 	return nil
 }
 
-// RemoveTopic closes and removes the topic's channel.
+// RemoveTopic closes and removes the topic filter and all its subscriptions.
 // Messages in the buffer are dropped.
 func (u *BroadcastBus[T]) RemoveTopic(topic string) error {
 	u.mutex.Lock()
@@ -52,19 +67,23 @@ func (u *BroadcastBus[T]) RemoveTopic(topic string) error {
 		return err
 	}
 
-	if ch, ok := u.subscribers[topic]; ok {
-		delete(u.subscribers, topic)
-		return ch.Close()
+	if hub, ok := u.subscribers.Delete(topic); ok {
+		return hub.Close()
 	}
 	return ErrTopicNotFound
 }
 
-// Subscribe subscribes to the given topic, creating the topic if needed.
+// Subscribe subscribes to the given topic filter, creating the topic if needed.
+// Implicitly created topics use DefaultTopicBufSize.
 //
 // Returns a new Subscription which has a buffered channel for receiving messages.
 //
-// If the bus is closed, returns an error.
+// If the bus is closed or the filter is invalid, returns an error.
 func (u *BroadcastBus[T]) Subscribe(topic string) (*Subscription[T], error) {
+	if err := ValidateTopicFilter(topic); err != nil {
+		return nil, err
+	}
+
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
@@ -72,14 +91,15 @@ func (u *BroadcastBus[T]) Subscribe(topic string) (*Subscription[T], error) {
 		return nil, err
 	}
 
-	hub, ok := u.subscribers[topic]
+	hub, ok := u.subscribers.Get(topic)
 	if !ok {
-		return nil, ErrTopicNotFound
+		hub = NewFastHub[T](DefaultTopicBufSize)
+		u.subscribers.Put(topic, hub)
 	}
 	return hub.Subscribe()
 }
 
-// Unsubscribe removes a subscriber from the given topic.
+// Unsubscribe removes a subscriber from the given topic filter.
 // If no subscribers remain for that topic, the topic is closed and removed.
 //
 // Returns an error if the bus is closed or the topic does not exist.
@@ -91,20 +111,32 @@ func (u *BroadcastBus[T]) Unsubscribe(topic string, sub *Subscription[T]) error
 		return err
 	}
 
-	hub, ok := u.subscribers[topic]
+	hub, ok := u.subscribers.Get(topic)
 	if !ok {
 		return ErrTopicNotFound
 	}
 
 	hub.Unsubscribe(sub)
+	if hub.Len() == 0 {
+		u.subscribers.Delete(topic)
+		return hub.Close()
+	}
 	return nil
 }
 
-// Publish broadcasts a message to all subscribers of the given topic.
+// Publish broadcasts a message to all subscribers whose topic filter matches the topic.
+// The topic must be concrete, i.e. it must not contain wildcards.
+//
+// The message is delivered to every matching filter even if some fail; the
+// errors are joined.
 //
-// If the topic has no subscribers, returns ErrTopicNotFound.
+// If no topic filter matches, returns ErrTopicNotFound.
 // If the bus is closed, returns an error.
 func (u *BroadcastBus[T]) Publish(topic string, msg T) error {
+	if err := ValidateTopic(topic); err != nil {
+		return err
+	}
+
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
@@ -112,12 +144,38 @@ func (u *BroadcastBus[T]) Publish(topic string, msg T) error {
 		return err
 	}
 
-	hub, ok := u.subscribers[topic]
-	if !ok {
+	var (
+		matched bool
+		errs    []error
+	)
+	u.subscribers.Match(topic, func(_ string, hub *FastHub[T]) bool {
+		matched = true
+		if err := hub.Publish(msg); err != nil {
+			errs = append(errs, err)
+		}
+		return true
+	})
+	if !matched {
 		return ErrTopicNotFound
 	}
+	return errors.Join(errs...)
+}
+
+// Topics returns all topic filters currently registered on the bus.
+func (u *BroadcastBus[T]) Topics() []string {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.closed {
+		return nil
+	}
 
-	return hub.Publish(msg)
+	topics := make([]string, 0, u.subscribers.Len())
+	u.subscribers.Range(func(topic string, _ *FastHub[T]) bool {
+		topics = append(topics, topic)
+		return true
+	})
+	return topics
 }
 
 // Close closes all topics and marks the bus as closed.
@@ -130,10 +188,13 @@ func (u *BroadcastBus[T]) Close() error {
 		return err
 	}
 
-	for _, hub := range u.subscribers {
-		if err := hub.Close(); err != nil {
-			return err
-		}
+	var err error
+	u.subscribers.Range(func(_ string, hub *FastHub[T]) bool {
+		err = hub.Close()
+		return err == nil
+	})
+	if err != nil {
+		return err
 	}
 
 	u.subscribers = nil
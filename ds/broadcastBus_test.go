@@ -57,11 +57,21 @@ func TestBroadcastBusSubscribe(t *testing.T) {
 			assert.NotNil(t, sub)
 		})
 
-		t.Run("订阅不存在的topic返回错误", func(t *testing.T) {
+		t.Run("订阅不存在的topic自动创建", func(t *testing.T) {
 			bus := NewBroadcastBus[int]()
 			sub, err := bus.Subscribe("nonexistent")
-			assert.Equal(t, ErrTopicNotFound, err)
-			assert.Nil(t, sub)
+			assert.Nil(t, err)
+			assert.NotNil(t, sub)
+			assert.Equal(t, []string{"nonexistent"}, bus.Topics())
+		})
+
+		t.Run("非法的topic filter返回错误", func(t *testing.T) {
+			bus := NewBroadcastBus[int]()
+			for _, filter := range []string{"", "a/#/b", "a/b+", "a#"} {
+				sub, err := bus.Subscribe(filter)
+				assert.Equal(t, ErrInvalidTopicFilter, err, filter)
+				assert.Nil(t, sub)
+			}
 		})
 
 		t.Run("关闭后订阅返回错误", func(t *testing.T) {
@@ -85,6 +95,19 @@ func TestBroadcastBusUnsubscribe(t *testing.T) {
 			assert.Nil(t, err)
 		})
 
+		t.Run("最后一个订阅者取消后topic被移除", func(t *testing.T) {
+			bus := NewBroadcastBus[int]()
+			sub1, _ := bus.Subscribe("alerts/+")
+			sub2, _ := bus.Subscribe("alerts/+")
+
+			assert.Nil(t, bus.Unsubscribe("alerts/+", sub1))
+			assert.Equal(t, []string{"alerts/+"}, bus.Topics())
+
+			assert.Nil(t, bus.Unsubscribe("alerts/+", sub2))
+			assert.Empty(t, bus.Topics())
+			assert.Equal(t, ErrTopicNotFound, bus.Publish("alerts/web", 1))
+		})
+
 		t.Run("取消订阅不存在的topic返回错误", func(t *testing.T) {
 			bus := NewBroadcastBus[int]()
 			bus.AddTopic("topic1", 10)
@@ -110,6 +133,29 @@ func TestBroadcastBusPublish(t *testing.T) {
 			assert.Equal(t, ErrTopicNotFound, err)
 		})
 
+		t.Run("发布到包含通配符的topic返回错误", func(t *testing.T) {
+			bus := NewBroadcastBus[int]()
+			bus.Subscribe("alerts/#")
+			assert.Equal(t, ErrInvalidTopic, bus.Publish("alerts/+", 42))
+			assert.Equal(t, ErrInvalidTopic, bus.Publish("", 42))
+		})
+
+		t.Run("部分 hub 出错时仍投递到其余订阅者", func(t *testing.T) {
+			bus := NewBroadcastBus[int]()
+			all, _ := bus.Subscribe("alerts/#")
+			one, _ := bus.Subscribe("alerts/+/api")
+			exact, _ := bus.Subscribe("alerts/web/api")
+			hub, _ := bus.subscribers.Get("alerts/+/api")
+			hub.Close()
+
+			err := bus.Publish("alerts/web/api", 42)
+			assert.ErrorIs(t, err, ErrHubClosed)
+			assert.Equal(t, 42, <-all.Channel())
+			assert.Equal(t, 42, <-exact.Channel())
+			_, ok := <-one.Channel()
+			assert.False(t, ok)
+		})
+
 		t.Run("关闭后发布返回错误", func(t *testing.T) {
 			bus := NewBroadcastBus[int]()
 			bus.AddTopic("topic1", 10)
@@ -161,6 +207,86 @@ func TestBroadcastBusPublishReceive(t *testing.T) {
 	})
 }
 
+func TestBroadcastBusWildcard(t *testing.T) {
+	t.Run("BroadcastBus 通配符订阅测试", func(t *testing.T) {
+		bus := NewBroadcastBus[string]()
+		defer bus.Close()
+
+		var (
+			exact, _  = bus.Subscribe("alerts/web/api")
+			single, _ = bus.Subscribe("alerts/+/api")
+			multi, _  = bus.Subscribe("alerts/#")
+			other, _  = bus.Subscribe("alerts/ping/+")
+		)
+
+		assert.Nil(t, bus.Publish("alerts/web/api", "down"))
+		assert.Equal(t, "down", <-exact.Channel())
+		assert.Equal(t, "down", <-single.Channel())
+		assert.Equal(t, "down", <-multi.Channel())
+		assert.Len(t, other.Channel(), 0)
+
+		t.Run("#匹配父级topic", func(t *testing.T) {
+			assert.Nil(t, bus.Publish("alerts", "root"))
+			assert.Equal(t, "root", <-multi.Channel())
+			assert.Len(t, single.Channel(), 0)
+		})
+
+		t.Run("+只匹配单层", func(t *testing.T) {
+			assert.Nil(t, bus.Publish("alerts/ping/gw/extra", "deep"))
+			assert.Equal(t, "deep", <-multi.Channel())
+			assert.Len(t, other.Channel(), 0)
+		})
+
+		t.Run("无匹配返回错误", func(t *testing.T) {
+			assert.Equal(t, ErrTopicNotFound, bus.Publish("metrics/cpu", "x"))
+		})
+	})
+}
+
+func TestTopicTrie(t *testing.T) {
+	t.Run("topicTrie 测试", func(t *testing.T) {
+		trie := newTopicTrie[int]()
+		trie.Put("a/b/c", 1)
+		trie.Put("a/+/c", 2)
+		trie.Put("a/#", 3)
+		trie.Put("#", 4)
+		trie.Put("b/+", 5)
+		assert.Equal(t, 5, trie.Len())
+
+		match := func(topic string) []int {
+			var vs []int
+			trie.Match(topic, func(_ string, v int) bool {
+				vs = append(vs, v)
+				return true
+			})
+			return vs
+		}
+		assert.ElementsMatch(t, []int{1, 2, 3, 4}, match("a/b/c"))
+		assert.ElementsMatch(t, []int{2, 3, 4}, match("a/x/c"))
+		assert.ElementsMatch(t, []int{3, 4}, match("a"))
+		assert.ElementsMatch(t, []int{5, 4}, match("b/x"))
+		assert.ElementsMatch(t, []int{4}, match("b/x/y"))
+
+		v, ok := trie.Delete("a/b/c")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+		_, ok = trie.Delete("a/b/c")
+		assert.False(t, ok)
+		_, ok = trie.Get("a/b")
+		assert.False(t, ok)
+		assert.Equal(t, 4, trie.Len())
+		assert.ElementsMatch(t, []int{2, 3, 4}, match("a/b/c"))
+	})
+
+	t.Run("MatchTopic 测试", func(t *testing.T) {
+		assert.True(t, MatchTopic("a/+/c", "a/b/c"))
+		assert.True(t, MatchTopic("a/#", "a"))
+		assert.True(t, MatchTopic("#", "x/y"))
+		assert.False(t, MatchTopic("a/+", "a/b/c"))
+		assert.False(t, MatchTopic("a/b", "a"))
+	})
+}
+
 func TestBroadcastBusClose(t *testing.T) {
 	t.Run("BroadcastBus Close 测试", func(t *testing.T) {
 		t.Run("正常关闭", func(t *testing.T) {
@@ -1,6 +1,7 @@
 package ds
 
 import (
+	"container/list"
 	"context"
 	"sync"
 	"time"
@@ -9,7 +10,8 @@ import (
 type (
 	// Cache is a thread-safe in-memory cache with optional expiration and cleanup.
 	// It stores key-value pairs with configurable expiration times and automatic
-	// cleanup of expired entries.
+	// cleanup of expired entries. A cache created with NewCacheWithCapacity
+	// also evicts its least recently used entries beyond the capacity.
 	//
 	// Type parameters:
 	//   - T: The type of values stored in the cache
@@ -20,12 +22,17 @@ type (
 		entries map[string]*cacheEntry[T]
 		ctx     context.Context
 		cf      context.CancelFunc
+		timer   *WheelTimer // cleanup timer when scheduled on a TimingWheel
+
+		capacity int        // max entries, <= 0 for unbounded
+		lru      *list.List // keys, most recently used first, when capacity > 0
 	}
 
 	// cacheEntry represents a single entry in the cache with its value and expiration time.
 	cacheEntry[T any] struct {
 		value      T
 		expireTime time.Time
+		elem       *list.Element // position in the LRU list, if any
 	}
 )
 
@@ -67,6 +74,49 @@ func NewCache[T any](expire, cleanup time.Duration) *Cache[T] {
 	return c
 }
 
+// NewCacheWithWheel creates a new cache whose cleanup runs on the given TimingWheel
+// instead of a dedicated goroutine, so many caches can share one wheel.
+// A nil w uses DefaultTimingWheel.
+//
+// Parameters:
+//   - expire: Duration after which entries expire. Use 0 for no expiration.
+//   - cleanup: Interval for automatic cleanup of expired entries. Use 0 for no automatic cleanup.
+//   - w: The TimingWheel that schedules the cleanup
+//
+// Returns:
+//   - *Cache[T]: A new cache instance
+func NewCacheWithWheel[T any](expire, cleanup time.Duration, w *TimingWheel) *Cache[T] {
+	c := NewCache[T](expire, 0)
+	c.cleanup = max(0, cleanup)
+	if c.cleanup > 0 {
+		if w == nil {
+			w = DefaultTimingWheel()
+		}
+		c.timer = w.EveryFunc(c.cleanup, c.cleanExpireKey)
+	}
+	return c
+}
+
+// NewCacheWithCapacity creates a new cache holding at most capacity entries.
+// Adding an entry to a full cache evicts the least recently used one; Get
+// and GetOrSet count as a use.
+//
+// Parameters:
+//   - expire: Duration after which entries expire. Use 0 for no expiration.
+//   - cleanup: Interval for automatic cleanup of expired entries. Use 0 for no automatic cleanup.
+//   - capacity: Maximum number of entries. Use 0 for no limit.
+//
+// Returns:
+//   - *Cache[T]: A new cache instance
+func NewCacheWithCapacity[T any](expire, cleanup time.Duration, capacity int) *Cache[T] {
+	c := NewCache[T](expire, cleanup)
+	if capacity > 0 {
+		c.capacity = capacity
+		c.lru = list.New()
+	}
+	return c
+}
+
 // cleanExpireKey removes expired entries from the cache.
 // This method is called automatically during cleanup cycles.
 func (c *Cache[T]) cleanExpireKey() {
@@ -75,8 +125,8 @@ func (c *Cache[T]) cleanExpireKey() {
 
 	now := time.Now()
 	for k, e := range c.entries {
-		if !e.expireTime.IsZero() && e.expireTime.Before(now) {
-			delete(c.entries, k)
+		if e.expired(now) {
+			c.remove(k, e)
 		}
 	}
 }
@@ -94,12 +144,56 @@ func (c *Cache[T]) Get(key string) (value T, loaded bool) {
 	defer c.l.Unlock()
 
 	e, ok := c.entries[key]
-	if !ok || (!e.expireTime.IsZero() && e.expireTime.Before(time.Now())) {
+	if !ok || e.expired(time.Now()) {
 		return value, loaded
 	}
+	c.touch(e)
 	return e.value, true
 }
 
+// GetOrSet returns the value for key, storing the result of create if the key
+// is missing or expired. The expiration of an existing entry is renewed, so
+// entries only read with GetOrSet expire once unused for the default
+// expiration time.
+//
+// Parameters:
+//   - key: The key to look up
+//   - create: Called with the cache locked to create a missing value
+//
+// Returns:
+//   - value: The existing or created value
+//   - loaded: True if the value was already in the cache
+func (c *Cache[T]) GetOrSet(key string, create func() T) (value T, loaded bool) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	now := time.Now()
+	if e, ok := c.entries[key]; ok && !e.expired(now) {
+		if c.expire > 0 {
+			e.expireTime = now.Add(c.expire)
+		}
+		c.touch(e)
+		return e.value, true
+	}
+	value = create()
+	c.set(key, value, c.expire)
+	return value, false
+}
+
+// Len returns the number of entries that have not expired.
+func (c *Cache[T]) Len() int {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	n, now := 0, time.Now()
+	for _, e := range c.entries {
+		if !e.expired(now) {
+			n++
+		}
+	}
+	return n
+}
+
 // Set stores a value in the cache with the default expiration time.
 //
 // Parameters:
@@ -118,7 +212,11 @@ func (c *Cache[T]) Set(key string, value T) {
 func (c *Cache[T]) SetWithExpire(key string, value T, expire time.Duration) {
 	c.l.Lock()
 	defer c.l.Unlock()
+	c.set(key, value, expire)
+}
 
+// set stores value under key. Caller must hold the lock.
+func (c *Cache[T]) set(key string, value T, expire time.Duration) {
 	e, ok := c.entries[key]
 	if ok {
 		e.value = value
@@ -131,9 +229,37 @@ func (c *Cache[T]) SetWithExpire(key string, value T, expire time.Duration) {
 			e.expireTime = time.Now().Add(expire)
 		}
 		c.entries[key] = e
+		if c.lru != nil {
+			e.elem = c.lru.PushFront(key)
+			if c.lru.Len() > c.capacity {
+				k := c.lru.Back().Value.(string)
+				c.remove(k, c.entries[k])
+			}
+			return
+		}
+	}
+	c.touch(e)
+}
+
+// touch marks e as most recently used. Caller must hold the lock.
+func (c *Cache[T]) touch(e *cacheEntry[T]) {
+	if e.elem != nil {
+		c.lru.MoveToFront(e.elem)
 	}
 }
 
+// remove deletes the entry e of key. Caller must hold the lock.
+func (c *Cache[T]) remove(key string, e *cacheEntry[T]) {
+	if e.elem != nil {
+		c.lru.Remove(e.elem)
+	}
+	delete(c.entries, key)
+}
+
+func (e *cacheEntry[T]) expired(now time.Time) bool {
+	return !e.expireTime.IsZero() && e.expireTime.Before(now)
+}
+
 // Delete removes an entry from the cache.
 //
 // Parameters:
@@ -141,7 +267,9 @@ func (c *Cache[T]) SetWithExpire(key string, value T, expire time.Duration) {
 func (c *Cache[T]) Delete(key string) {
 	c.l.Lock()
 	defer c.l.Unlock()
-	delete(c.entries, key)
+	if e, ok := c.entries[key]; ok {
+		c.remove(key, e)
+	}
 }
 
 // Release stops the cleanup goroutine and releases resources.
@@ -158,5 +286,9 @@ func (c *Cache[T]) Release() error {
 		c.ctx = nil
 		c.cf = nil
 	}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
 	return nil
 }
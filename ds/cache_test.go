@@ -104,3 +104,63 @@ func TestCacheRelease(t *testing.T) {
 	err := c.Release()
 	assert.Nil(t, err)
 }
+
+func TestCacheWithWheel(t *testing.T) {
+	t.Run("共享TimingWheel清理过期key", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 16)
+		defer w.Stop()
+
+		c1 := NewCacheWithWheel[int](10*time.Millisecond, 20*time.Millisecond, w)
+		c2 := NewCacheWithWheel[int](10*time.Millisecond, 20*time.Millisecond, w)
+		c1.Set("a", 1)
+		c2.Set("b", 2)
+		assert.Equal(t, 2, w.Len())
+
+		assert.Eventually(t, func() bool {
+			c1.l.Lock()
+			defer c1.l.Unlock()
+			return len(c1.entries) == 0
+		}, time.Second, 5*time.Millisecond)
+
+		c1.Release()
+		c2.Release()
+		assert.Equal(t, 0, w.Len())
+	})
+}
+
+func TestCacheCapacity(t *testing.T) {
+	t.Run("超过容量淘汰最久未用的条目", func(t *testing.T) {
+		c := NewCacheWithCapacity[int](0, 0, 2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Get("a")
+		c.Set("c", 3)
+
+		assert.Equal(t, 2, c.Len())
+		_, ok := c.Get("b")
+		assert.False(t, ok)
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		c.Delete("a")
+		c.Set("d", 4)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("GetOrSet 续期已有条目", func(t *testing.T) {
+		c := NewCache[int](50*time.Millisecond, 0)
+		v, loaded := c.GetOrSet("a", func() int { return 1 })
+		assert.False(t, loaded)
+		assert.Equal(t, 1, v)
+
+		time.Sleep(30 * time.Millisecond)
+		v, loaded = c.GetOrSet("a", func() int { return 2 })
+		assert.True(t, loaded)
+		assert.Equal(t, 1, v)
+
+		time.Sleep(30 * time.Millisecond)
+		_, ok := c.Get("a")
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,54 @@
+package ds
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+var (
+	_ Codec[int] = JSONCodec[int]{}
+	_ Codec[int] = GobCodec[int]{}
+)
+
+// Codec converts values to and from bytes for persistent data structures
+// such as DiskQueue.
+type Codec[T any] interface {
+	// Encode serializes v.
+	Encode(v T) ([]byte, error)
+	// Decode deserializes data produced by Encode.
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec encodes values with encoding/json.
+type JSONCodec[T any] struct{}
+
+// Encode serializes v as JSON.
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode deserializes JSON data.
+func (JSONCodec[T]) Decode(data []byte) (v T, err error) {
+	err = json.Unmarshal(data, &v)
+	return
+}
+
+// GobCodec encodes values with encoding/gob.
+// Each value is encoded as a self-contained gob stream.
+type GobCodec[T any] struct{}
+
+// Encode serializes v as a gob stream.
+func (GobCodec[T]) Encode(v T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode deserializes a gob stream.
+func (GobCodec[T]) Decode(data []byte) (v T, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return
+}
@@ -0,0 +1,243 @@
+package ds
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// ConcurrentSkipList is a sorted map safe for concurrent use.
+	//
+	// Reads (Load, iteration, Floor/Ceil) are lock-free: they traverse atomic
+	// pointers and never block writers. Writes are serialized by a mutex and
+	// publish new nodes bottom-up, so a reader always sees a sorted list.
+	// Iteration is weakly consistent: it reflects some, but not necessarily all,
+	// writes made concurrently with it.
+	//
+	// Type parameters:
+	//   - K: The key type, must be ordered
+	//   - V: The value type
+	ConcurrentSkipList[K cmp.Ordered, V any] struct {
+		mutex sync.Mutex
+		head  *cslNode[K, V]
+		level atomic.Int32
+		len   atomic.Int64
+	}
+
+	cslNode[K cmp.Ordered, V any] struct {
+		key     K
+		value   atomic.Pointer[V]
+		next    []atomic.Pointer[cslNode[K, V]]
+		deleted atomic.Bool
+	}
+)
+
+// NewConcurrentSkipList creates an empty ConcurrentSkipList.
+func NewConcurrentSkipList[K cmp.Ordered, V any]() *ConcurrentSkipList[K, V] {
+	l := &ConcurrentSkipList[K, V]{
+		head: &cslNode[K, V]{next: make([]atomic.Pointer[cslNode[K, V]], skiplistMaxLevel)},
+	}
+	l.level.Store(1)
+	return l
+}
+
+// Len returns the number of entries.
+func (l *ConcurrentSkipList[K, V]) Len() int {
+	return int(l.len.Load())
+}
+
+// ceil returns the first live node with a key >= key, or nil.
+func (l *ConcurrentSkipList[K, V]) ceil(key K) *cslNode[K, V] {
+	x := l.head
+	for i := int(l.level.Load()) - 1; i >= 0; i-- {
+		for n := x.next[i].Load(); n != nil && cmp.Less(n.key, key); n = x.next[i].Load() {
+			x = n
+		}
+	}
+	n := x.next[0].Load()
+	for n != nil && n.deleted.Load() {
+		n = n.next[0].Load()
+	}
+	return n
+}
+
+// Load returns the value stored for key, or false if no value is present.
+func (l *ConcurrentSkipList[K, V]) Load(key K) (value V, ok bool) {
+	if n := l.ceil(key); n != nil && cmp.Compare(n.key, key) == 0 {
+		return *n.value.Load(), true
+	}
+	return value, false
+}
+
+// Ceil returns the entry with the least key greater than or equal to key.
+func (l *ConcurrentSkipList[K, V]) Ceil(key K) (k K, v V, ok bool) {
+	if n := l.ceil(key); n != nil {
+		return n.key, *n.value.Load(), true
+	}
+	return k, v, false
+}
+
+// Floor returns the entry with the greatest key less than or equal to key.
+func (l *ConcurrentSkipList[K, V]) Floor(key K) (k K, v V, ok bool) {
+	x := l.head
+	for i := int(l.level.Load()) - 1; i >= 0; i-- {
+		for n := x.next[i].Load(); n != nil && cmp.Compare(n.key, key) <= 0; n = x.next[i].Load() {
+			if !n.deleted.Load() {
+				x = n
+				continue
+			}
+			// Skip over a node being removed on this level.
+			for n != nil && n.deleted.Load() {
+				n = n.next[i].Load()
+			}
+			if n == nil || cmp.Compare(n.key, key) > 0 {
+				break
+			}
+			x = n
+		}
+	}
+	if x == l.head {
+		return k, v, false
+	}
+	return x.key, *x.value.Load(), true
+}
+
+// Store sets the value for key.
+func (l *ConcurrentSkipList[K, V]) Store(key K, value V) {
+	l.upsert(key, func(V, bool) V { return value })
+}
+
+// LoadOrStore returns the existing value for key if present.
+// Otherwise, it stores and returns the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (l *ConcurrentSkipList[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	if v, ok := l.Load(key); ok {
+		return v, true
+	}
+	actual = value
+	l.upsert(key, func(old V, ok bool) V {
+		if ok {
+			actual, loaded = old, true
+			return old
+		}
+		return value
+	})
+	return actual, loaded
+}
+
+// upsert sets the value for key to fn(old, loaded) under the write lock,
+// where loaded reports whether key was present.
+func (l *ConcurrentSkipList[K, V]) upsert(key K, fn func(old V, loaded bool) V) (old V, loaded bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var (
+		update [skiplistMaxLevel]*cslNode[K, V]
+		level  = int(l.level.Load())
+		x      = l.head
+	)
+	for i := level - 1; i >= 0; i-- {
+		for n := x.next[i].Load(); n != nil && cmp.Less(n.key, key); n = x.next[i].Load() {
+			x = n
+		}
+		update[i] = x
+	}
+	if n := x.next[0].Load(); n != nil && cmp.Compare(n.key, key) == 0 {
+		old = *n.value.Load()
+		v := fn(old, true)
+		n.value.Store(&v)
+		return old, true
+	}
+
+	v := fn(old, false)
+	nl := skiplistRandomLevel()
+	for i := level; i < nl; i++ {
+		update[i] = l.head
+	}
+	n := &cslNode[K, V]{key: key, next: make([]atomic.Pointer[cslNode[K, V]], nl)}
+	n.value.Store(&v)
+	for i := range nl {
+		n.next[i].Store(update[i].next[i].Load())
+	}
+	// Publish bottom-up: once linked on level 0 the node is visible to readers.
+	for i := range nl {
+		update[i].next[i].Store(n)
+	}
+	if nl > level {
+		l.level.Store(int32(nl))
+	}
+	l.len.Add(1)
+	return old, false
+}
+
+// Delete deletes the value for key and reports whether it was present.
+func (l *ConcurrentSkipList[K, V]) Delete(key K) bool {
+	_, ok := l.LoadAndDelete(key)
+	return ok
+}
+
+// LoadAndDelete deletes the value for key, returning the previous value if any.
+// The loaded result reports whether the key was present.
+func (l *ConcurrentSkipList[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var (
+		update [skiplistMaxLevel]*cslNode[K, V]
+		level  = int(l.level.Load())
+		x      = l.head
+	)
+	for i := level - 1; i >= 0; i-- {
+		for n := x.next[i].Load(); n != nil && cmp.Less(n.key, key); n = x.next[i].Load() {
+			x = n
+		}
+		update[i] = x
+	}
+	n := x.next[0].Load()
+	if n == nil || cmp.Compare(n.key, key) != 0 {
+		return value, false
+	}
+
+	// Mark first so readers holding a reference skip it, then unlink top-down.
+	// The node keeps its forward pointers, so a reader standing on it can move on.
+	n.deleted.Store(true)
+	for i := len(n.next) - 1; i >= 0; i-- {
+		update[i].next[i].Store(n.next[i].Load())
+	}
+	for level > 1 && l.head.next[level-1].Load() == nil {
+		level--
+	}
+	l.level.Store(int32(level))
+	l.len.Add(-1)
+	return *n.value.Load(), true
+}
+
+// All returns an iterator over all entries in ascending key order.
+func (l *ConcurrentSkipList[K, V]) All() iter.Seq2[K, V] {
+	return l.seq(func() *cslNode[K, V] { return l.head.next[0].Load() }, nil)
+}
+
+// Range returns an iterator over the entries with lo <= key < hi in ascending key order.
+func (l *ConcurrentSkipList[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return l.seq(func() *cslNode[K, V] { return l.ceil(lo) }, &hi)
+}
+
+// Ascend returns an iterator over the entries with key >= from in ascending key order.
+func (l *ConcurrentSkipList[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return l.seq(func() *cslNode[K, V] { return l.ceil(from) }, nil)
+}
+
+func (l *ConcurrentSkipList[K, V]) seq(start func() *cslNode[K, V], hi *K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := start(); n != nil && (hi == nil || cmp.Less(n.key, *hi)); n = n.next[0].Load() {
+			if n.deleted.Load() {
+				continue
+			}
+			if !yield(n.key, *n.value.Load()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ds
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentSkipList(t *testing.T) {
+	t.Run("ConcurrentSkipList 测试", func(t *testing.T) {
+		l := NewConcurrentSkipList[string, int]()
+		for i, k := range []string{"d", "b", "a", "c"} {
+			l.Store(k, i)
+		}
+		assert.Equal(t, 4, l.Len())
+		assert.Equal(t, []string{"a", "b", "c", "d"}, collectKeys(l.All()))
+
+		t.Run("Load/LoadOrStore", func(t *testing.T) {
+			v, ok := l.Load("b")
+			assert.True(t, ok)
+			assert.Equal(t, 1, v)
+			v, loaded := l.LoadOrStore("b", 9)
+			assert.True(t, loaded)
+			assert.Equal(t, 1, v)
+			v, loaded = l.LoadOrStore("e", 4)
+			assert.False(t, loaded)
+			assert.Equal(t, 4, v)
+		})
+
+		t.Run("Floor/Ceil/Range", func(t *testing.T) {
+			k, _, ok := l.Floor("bb")
+			assert.True(t, ok)
+			assert.Equal(t, "b", k)
+			k, _, ok = l.Ceil("bb")
+			assert.True(t, ok)
+			assert.Equal(t, "c", k)
+			assert.Equal(t, []string{"b", "c"}, collectKeys(l.Range("b", "d")))
+		})
+
+		t.Run("删除", func(t *testing.T) {
+			v, ok := l.LoadAndDelete("c")
+			assert.True(t, ok)
+			assert.Equal(t, 3, v)
+			assert.False(t, l.Delete("c"))
+			k, _, _ := l.Floor("cc")
+			assert.Equal(t, "b", k)
+			assert.Equal(t, 4, l.Len())
+		})
+	})
+
+	t.Run("并发读写", func(t *testing.T) {
+		var (
+			l  = NewConcurrentSkipList[int, int]()
+			wg sync.WaitGroup
+		)
+		for w := range 4 {
+			wg.Go(func() {
+				for i := range 1000 {
+					k := i*4 + w
+					l.Store(k, k)
+					if k%3 == 0 {
+						l.Delete(k)
+					}
+				}
+			})
+		}
+		for range 4 {
+			wg.Go(func() {
+				for range 50 {
+					prev := -1
+					for k, v := range l.All() {
+						assert.Equal(t, k, v)
+						assert.Less(t, prev, k)
+						prev = k
+					}
+				}
+			})
+		}
+		wg.Wait()
+
+		keys := collectKeys(l.All())
+		assert.Equal(t, l.Len(), len(keys))
+		assert.True(t, slices.IsSorted(keys))
+		for _, k := range keys {
+			assert.NotZero(t, k%3)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package ds
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Conformance suites shared by all implementations of Set, Map and Stack.
+// A new implementation only needs an entry in the matching Test function.
+
+type setImpl struct {
+	name string
+	new  func(items ...int) Set[int]
+	// decodeJSON and decodeGob decode an encoded set into a new one.
+	decodeJSON func(data []byte) (Set[int], error)
+	decodeGob  func(data []byte) (Set[int], error)
+}
+
+type mapImpl struct {
+	name       string
+	new        func() Map[string, int]
+	decodeJSON func(data []byte) (Map[string, int], error)
+	decodeGob  func(data []byte) (Map[string, int], error)
+}
+
+type stackImpl struct {
+	name string
+	new  func() Stack[int]
+}
+
+func decodeJSONAs[T any, I any](data []byte) (I, error) {
+	p := new(T)
+	err := json.Unmarshal(data, p)
+	return any(p).(I), err
+}
+
+func decodeGobAs[T any, I any](data []byte) (I, error) {
+	p := new(T)
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(p)
+	return any(p).(I), err
+}
+
+func gobEncode(t *testing.T, v any) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(v))
+	return buf.Bytes()
+}
+
+func TestSetConformance(t *testing.T) {
+	impls := []setImpl{
+		{
+			name: "HashSet",
+			new:  func(items ...int) Set[int] { return NewHashSet(items...) },
+			decodeJSON: func(data []byte) (Set[int], error) {
+				var s HashSet[int]
+				err := json.Unmarshal(data, &s)
+				return s, err
+			},
+			decodeGob: func(data []byte) (Set[int], error) {
+				var s HashSet[int]
+				err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s)
+				return s, err
+			},
+		},
+		{
+			name:       "SyncSet",
+			new:        func(items ...int) Set[int] { return NewSyncSet(items...) },
+			decodeJSON: decodeJSONAs[SyncSet[int], Set[int]],
+			decodeGob:  decodeGobAs[SyncSet[int], Set[int]],
+		},
+	}
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) { testSetConformance(t, impl) })
+	}
+}
+
+func testSetConformance(t *testing.T, impl setImpl) {
+	t.Run("基本操作", func(t *testing.T) {
+		s := impl.new()
+		assert.Equal(t, 0, s.Len())
+		s.Append(1, 2, 2, 3)
+		assert.Equal(t, 3, s.Len())
+		assert.True(t, s.Contains(2))
+		assert.False(t, s.Contains(4))
+		s.Remove(2, 4)
+		assert.Equal(t, 2, s.Len())
+		assert.False(t, s.Contains(2))
+		assert.ElementsMatch(t, []int{1, 3}, s.Values())
+	})
+
+	t.Run("All", func(t *testing.T) {
+		s := impl.new(1, 2, 3)
+		assert.ElementsMatch(t, []int{1, 2, 3}, slices.Collect(s.All()))
+		n := 0
+		for range s.All() {
+			n++
+			break
+		}
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("集合运算", func(t *testing.T) {
+		a, b := impl.new(1, 2, 3), impl.new(2, 3, 4)
+		assert.ElementsMatch(t, []int{1, 2, 3, 4}, a.Union(b).Values())
+		assert.ElementsMatch(t, []int{2, 3}, a.Intersection(b).Values())
+		assert.ElementsMatch(t, []int{1}, a.Difference(b).Values())
+		assert.ElementsMatch(t, []int{1, 4}, a.SymmetricDifference(b).Values())
+	})
+
+	t.Run("Equal/IsSubset", func(t *testing.T) {
+		a := impl.new(1, 2)
+		assert.True(t, a.Equal(impl.new(2, 1)))
+		assert.True(t, a.Equal(NewHashSet(1, 2)), "不同实现之间也可比较")
+		assert.False(t, a.Equal(impl.new(1, 2, 3)))
+		assert.False(t, a.Equal(impl.new(1, 3)))
+		assert.True(t, a.IsSubset(impl.new(1, 2, 3)))
+		assert.True(t, a.IsSubset(a))
+		assert.True(t, impl.new().IsSubset(a))
+		assert.False(t, a.IsSubset(impl.new(1, 3)))
+	})
+
+	t.Run("Clone 相互独立", func(t *testing.T) {
+		a := impl.new(1, 2)
+		c := a.Clone()
+		c.Append(3)
+		a.Remove(1)
+		assert.ElementsMatch(t, []int{2}, a.Values())
+		assert.ElementsMatch(t, []int{1, 2, 3}, c.Values())
+		assert.IsType(t, a, c)
+	})
+
+	t.Run("JSON 往返", func(t *testing.T) {
+		data, err := json.Marshal(impl.new(3, 1, 2))
+		require.NoError(t, err)
+		var values []int
+		require.NoError(t, json.Unmarshal(data, &values))
+		assert.ElementsMatch(t, []int{1, 2, 3}, values)
+
+		s, err := impl.decodeJSON(data)
+		require.NoError(t, err)
+		assert.True(t, s.Equal(impl.new(1, 2, 3)))
+
+		_, err = impl.decodeJSON([]byte(`{"a":1}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("gob 往返", func(t *testing.T) {
+		s, err := impl.decodeGob(gobEncode(t, impl.new(1, 2, 3)))
+		require.NoError(t, err)
+		assert.True(t, s.Equal(impl.new(1, 2, 3)))
+	})
+}
+
+func TestMapConformance(t *testing.T) {
+	impls := []mapImpl{
+		{
+			name:       "SyncMap",
+			new:        func() Map[string, int] { return NewSyncMap[string, int]() },
+			decodeJSON: decodeJSONAs[SyncMap[string, int], Map[string, int]],
+			decodeGob:  decodeGobAs[SyncMap[string, int], Map[string, int]],
+		},
+		{
+			name:       "MutexMap",
+			new:        func() Map[string, int] { return NewMutexMap[string, int]() },
+			decodeJSON: decodeJSONAs[MutexMap[string, int], Map[string, int]],
+			decodeGob:  decodeGobAs[MutexMap[string, int], Map[string, int]],
+		},
+	}
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) { testMapConformance(t, impl) })
+	}
+}
+
+func testMapConformance(t *testing.T, impl mapImpl) {
+	fill := func(m Map[string, int], kv map[string]int) Map[string, int] {
+		for k, v := range kv {
+			m.Store(k, v)
+		}
+		return m
+	}
+
+	t.Run("基本操作", func(t *testing.T) {
+		m := impl.new()
+		assert.Equal(t, 0, m.Len())
+		m.Store("a", 1)
+		v, ok := m.Load("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		old, loaded := m.Swap("a", 2)
+		assert.True(t, loaded)
+		assert.Equal(t, 1, old)
+		_, loaded = m.Swap("b", 3)
+		assert.False(t, loaded)
+
+		v, loaded = m.LoadOrStore("b", 4)
+		assert.True(t, loaded)
+		assert.Equal(t, 3, v)
+		v, loaded = m.LoadOrStore("c", 5)
+		assert.False(t, loaded)
+		assert.Equal(t, 5, v)
+		assert.Equal(t, 3, m.Len())
+
+		assert.False(t, m.CompareAndSwap("a", 1, 10))
+		assert.True(t, m.CompareAndSwap("a", 2, 10))
+		assert.False(t, m.CompareAndDelete("a", 2))
+		assert.True(t, m.CompareAndDelete("a", 10))
+
+		v, loaded = m.LoadAndDelete("b")
+		assert.True(t, loaded)
+		assert.Equal(t, 3, v)
+		m.Delete("c")
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("All/Keys/Values", func(t *testing.T) {
+		kv := map[string]int{"a": 1, "b": 2, "c": 3}
+		m := fill(impl.new(), kv)
+		assert.Equal(t, kv, maps.Collect(m.All()))
+		assert.ElementsMatch(t, []string{"a", "b", "c"}, m.Keys())
+		assert.ElementsMatch(t, []int{1, 2, 3}, m.Values())
+
+		n := 0
+		for range m.All() {
+			n++
+			break
+		}
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		m := fill(impl.new(), map[string]int{"a": 1, "b": 2, "c": 3})
+		odd := m.Filter(func(_ string, v int) bool { return v%2 == 1 })
+		assert.Equal(t, map[string]int{"a": 1, "c": 3}, maps.Collect(odd.All()))
+	})
+
+	t.Run("Clone 相互独立", func(t *testing.T) {
+		m := fill(impl.new(), map[string]int{"a": 1})
+		c := m.Clone()
+		c.Store("b", 2)
+		m.Store("a", 10)
+		assert.Equal(t, map[string]int{"a": 10}, maps.Collect(m.All()))
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(c.All()))
+		assert.IsType(t, m, c)
+	})
+
+	t.Run("MapEqual", func(t *testing.T) {
+		a := fill(impl.new(), map[string]int{"a": 1, "b": 2})
+		assert.True(t, MapEqual(a, fill(impl.new(), map[string]int{"b": 2, "a": 1})))
+		assert.True(t, MapEqual[string, int](a, fill(NewMutexMap[string, int](), map[string]int{"a": 1, "b": 2})))
+		assert.False(t, MapEqual(a, fill(impl.new(), map[string]int{"a": 1, "b": 3})))
+		assert.False(t, MapEqual(a, fill(impl.new(), map[string]int{"a": 1})))
+		assert.True(t, MapEqualFunc(a, fill(impl.new(), map[string]int{"a": 11, "b": 12}), func(x, y int) bool {
+			return x%10 == y%10
+		}))
+	})
+
+	t.Run("MapIsSubset", func(t *testing.T) {
+		a := fill(impl.new(), map[string]int{"a": 1})
+		b := fill(impl.new(), map[string]int{"a": 1, "b": 2})
+		assert.True(t, MapIsSubset(a, b))
+		assert.True(t, MapIsSubset(b, b))
+		assert.False(t, MapIsSubset(b, a))
+		assert.False(t, MapIsSubset(fill(impl.new(), map[string]int{"a": 2}), b))
+		assert.True(t, MapIsSubset(impl.new(), a))
+		assert.True(t, MapIsSubsetFunc(fill(impl.new(), map[string]int{"b": 12}), b, func(x, y int) bool {
+			return x%10 == y%10
+		}))
+	})
+
+	t.Run("JSON 往返", func(t *testing.T) {
+		kv := map[string]int{"a": 1, "b": 2}
+		data, err := json.Marshal(fill(impl.new(), kv))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":1,"b":2}`, string(data))
+
+		m, err := impl.decodeJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, kv, maps.Collect(m.All()))
+
+		_, err = impl.decodeJSON([]byte(`[1,2]`))
+		assert.Error(t, err)
+	})
+
+	t.Run("gob 往返", func(t *testing.T) {
+		kv := map[string]int{"a": 1, "b": 2}
+		m, err := impl.decodeGob(gobEncode(t, fill(impl.new(), kv)))
+		require.NoError(t, err)
+		assert.Equal(t, kv, maps.Collect(m.All()))
+	})
+}
+
+func TestStackConformance(t *testing.T) {
+	impls := []stackImpl{
+		{name: "ArrayStack", new: func() Stack[int] { return NewArrayStack[int]() }},
+		{name: "LinkStack", new: func() Stack[int] { return NewLinkStack[int]() }},
+	}
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) { testStackConformance(t, impl) })
+	}
+}
+
+func testStackConformance(t *testing.T, impl stackImpl) {
+	t.Run("空栈", func(t *testing.T) {
+		s := impl.new()
+		assert.True(t, s.Empty())
+		_, ok := s.Peek()
+		assert.False(t, ok)
+		assert.Equal(t, 0, s.Size())
+	})
+
+	t.Run("后进先出", func(t *testing.T) {
+		s := impl.new()
+		for i := range 5 {
+			s.Push(i)
+		}
+		assert.Equal(t, 5, s.Size())
+		assert.Equal(t, []int{4, 3, 2, 1, 0}, slices.Collect(s.All()))
+
+		for i := 4; i >= 0; i-- {
+			v, ok := s.Peek()
+			assert.True(t, ok)
+			assert.Equal(t, i, v)
+			v, ok = s.Pop()
+			assert.True(t, ok)
+			assert.Equal(t, i, v)
+			assert.Equal(t, i, s.Size())
+		}
+		assert.True(t, s.Empty())
+	})
+}
@@ -0,0 +1,426 @@
+package ds
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*CountMinSketch[string])(nil)
+	_ encoding.BinaryUnmarshaler = (*CountMinSketch[string])(nil)
+	_ encoding.BinaryMarshaler   = (*TopK[string])(nil)
+	_ encoding.BinaryUnmarshaler = (*TopK[string])(nil)
+)
+
+const (
+	cmsMagic   = 'M'
+	cmsVersion = 1
+)
+
+// CountMinSketch estimates the frequency of elements in a stream in sub-linear
+// memory. Estimates never undercount; with probability 1-delta they overcount
+// by at most epsilon times the total count.
+//
+// CountMinSketch is not safe for concurrent use; see SyncCountMinSketch.
+//
+// Type parameters:
+//   - T: The element type
+type CountMinSketch[T any] struct {
+	width  uint64
+	depth  uint64
+	counts []uint64 // depth rows of width counters
+	total  uint64
+	hash   Hasher[T]
+}
+
+// NewCountMinSketch creates a CountMinSketch.
+//
+// Parameters:
+//   - epsilon: Relative error bound in (0, 1), e.g. 0.001
+//   - delta: Probability of exceeding the error bound in (0, 1), e.g. 0.01
+//   - hash: Hasher of the elements
+//
+// Example:
+//
+//	s := NewCountMinSketch(0.001, 0.01, HashString)
+//	s.Add("GET /api/users", 1)
+//	s.Estimate("GET /api/users") // >= 1
+func NewCountMinSketch[T any](epsilon, delta float64, hash Hasher[T]) *CountMinSketch[T] {
+	if hash == nil {
+		panic("count-min sketch: nil hasher")
+	}
+	if epsilon <= 0 || epsilon >= 1 {
+		epsilon = 0.001
+	}
+	if delta <= 0 || delta >= 1 {
+		delta = 0.01
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := uint64(math.Ceil(math.Log(1 / delta)))
+	return &CountMinSketch[T]{
+		width:  width,
+		depth:  depth,
+		counts: make([]uint64, width*depth),
+		hash:   hash,
+	}
+}
+
+// Add adds n occurrences of v and returns its new estimated count.
+func (s *CountMinSketch[T]) Add(v T, n uint64) uint64 {
+	var (
+		h   = s.hash(v)
+		est = uint64(math.MaxUint64)
+	)
+	for i := range s.depth {
+		c := &s.counts[i*s.width+doubleHash(h, i, s.width)]
+		*c += n
+		est = min(est, *c)
+	}
+	s.total += n
+	return est
+}
+
+// Estimate returns the estimated count of v.
+func (s *CountMinSketch[T]) Estimate(v T) uint64 {
+	var (
+		h   = s.hash(v)
+		est = uint64(math.MaxUint64)
+	)
+	for i := range s.depth {
+		est = min(est, s.counts[i*s.width+doubleHash(h, i, s.width)])
+	}
+	return est
+}
+
+// Total returns the sum of all counts added.
+func (s *CountMinSketch[T]) Total() uint64 {
+	return s.total
+}
+
+// Merge adds the counts of other to s. Both must have the same dimensions,
+// otherwise ErrIncompatibleSketch is returned.
+func (s *CountMinSketch[T]) Merge(other *CountMinSketch[T]) error {
+	if s.width != other.width || s.depth != other.depth {
+		return ErrIncompatibleSketch
+	}
+	for i, c := range other.counts {
+		s.counts[i] += c
+	}
+	s.total += other.total
+	return nil
+}
+
+func (s *CountMinSketch[T]) clone() *CountMinSketch[T] {
+	c := *s
+	c.counts = append([]uint64(nil), s.counts...)
+	return &c
+}
+
+// MarshalBinary encodes the sketch. The Hasher is not encoded.
+func (s *CountMinSketch[T]) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 26+8*len(s.counts))
+	b = append(b, cmsMagic, cmsVersion)
+	b = binary.BigEndian.AppendUint64(b, s.width)
+	b = binary.BigEndian.AppendUint64(b, s.depth)
+	b = binary.BigEndian.AppendUint64(b, s.total)
+	for _, c := range s.counts {
+		b = binary.BigEndian.AppendUint64(b, c)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces the sketch with the decoded data, keeping its Hasher.
+func (s *CountMinSketch[T]) UnmarshalBinary(data []byte) error {
+	r := &sketchReader{b: data}
+	r.header(cmsMagic, cmsVersion)
+	var (
+		width = r.u64()
+		depth = r.u64()
+		total = r.u64()
+	)
+	if r.err == nil && (width == 0 || depth == 0 || width > uint64(len(data)) || depth > uint64(len(data))) {
+		return ErrInvalidEncoding
+	}
+	counts := r.u64s(width * depth)
+	if err := r.done(); err != nil {
+		return err
+	}
+	s.width, s.depth, s.total, s.counts = width, depth, total, counts
+	return nil
+}
+
+// SyncCountMinSketch is a CountMinSketch safe for concurrent use.
+type SyncCountMinSketch[T any] struct {
+	mutex sync.RWMutex
+	s     *CountMinSketch[T]
+}
+
+// NewSyncCountMinSketch creates a SyncCountMinSketch. See NewCountMinSketch for the parameters.
+func NewSyncCountMinSketch[T any](epsilon, delta float64, hash Hasher[T]) *SyncCountMinSketch[T] {
+	return &SyncCountMinSketch[T]{s: NewCountMinSketch(epsilon, delta, hash)}
+}
+
+// Add adds n occurrences of v and returns its new estimated count.
+func (s *SyncCountMinSketch[T]) Add(v T, n uint64) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.s.Add(v, n)
+}
+
+// Estimate returns the estimated count of v.
+func (s *SyncCountMinSketch[T]) Estimate(v T) uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.s.Estimate(v)
+}
+
+// Total returns the sum of all counts added.
+func (s *SyncCountMinSketch[T]) Total() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.s.Total()
+}
+
+// Merge adds the counts of other to s.
+func (s *SyncCountMinSketch[T]) Merge(other *SyncCountMinSketch[T]) error {
+	other.mutex.RLock()
+	o := other.s.clone()
+	other.mutex.RUnlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.s.Merge(o)
+}
+
+// MarshalBinary encodes the sketch.
+func (s *SyncCountMinSketch[T]) MarshalBinary() ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.s.MarshalBinary()
+}
+
+// UnmarshalBinary replaces the sketch with the decoded data.
+func (s *SyncCountMinSketch[T]) UnmarshalBinary(data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.s.UnmarshalBinary(data)
+}
+
+type (
+	// TopK tracks the k most frequent elements (heavy hitters) of a stream,
+	// with counts estimated by a CountMinSketch.
+	//
+	// TopK is not safe for concurrent use; see SyncTopK.
+	//
+	// Type parameters:
+	//   - T: The element type, must be comparable
+	TopK[T comparable] struct {
+		k      int
+		sketch *CountMinSketch[T]
+		heap   *PriorityQueue[TopKItem[T]] // min-heap of the current top k
+		items  map[T]*PriorityItem[TopKItem[T]]
+	}
+
+	// TopKItem is an element and its estimated count.
+	TopKItem[T any] struct {
+		Value T
+		Count uint64
+	}
+
+	// topKState is the gob encoding of a TopK.
+	topKState[T any] struct {
+		Sketch []byte
+		Items  []TopKItem[T]
+	}
+)
+
+// NewTopK creates a TopK of k elements over a CountMinSketch with the given
+// epsilon and delta. See NewCountMinSketch for the parameters.
+//
+// Example:
+//
+//	hot := NewTopK(10, 0.001, 0.01, HashString)
+//	hot.Add(path, 1)
+//	for _, it := range hot.List() {
+//		fmt.Println(it.Value, it.Count)
+//	}
+func NewTopK[T comparable](k int, epsilon, delta float64, hash Hasher[T]) *TopK[T] {
+	k = max(1, k)
+	return &TopK[T]{
+		k:      k,
+		sketch: NewCountMinSketch(epsilon, delta, hash),
+		heap:   NewPriorityQueue(func(a, b TopKItem[T]) bool { return a.Count < b.Count }),
+		items:  make(map[T]*PriorityItem[TopKItem[T]], k),
+	}
+}
+
+// Add adds n occurrences of v and returns its new estimated count.
+func (t *TopK[T]) Add(v T, n uint64) uint64 {
+	est := t.sketch.Add(v, n)
+	t.offer(TopKItem[T]{Value: v, Count: est})
+	return est
+}
+
+// offer inserts or updates it if it belongs to the top k.
+func (t *TopK[T]) offer(it TopKItem[T]) {
+	if h, ok := t.items[it.Value]; ok {
+		t.heap.Update(h, it)
+		return
+	}
+	if t.heap.Len() >= t.k {
+		if least, _ := t.heap.Peek(); least.Count >= it.Count {
+			return
+		}
+		least, _ := t.heap.Pop()
+		delete(t.items, least.Value)
+	}
+	t.items[it.Value] = t.heap.Push(it)
+}
+
+// Estimate returns the estimated count of v.
+func (t *TopK[T]) Estimate(v T) uint64 {
+	return t.sketch.Estimate(v)
+}
+
+// List returns the top elements, most frequent first.
+func (t *TopK[T]) List() []TopKItem[T] {
+	items := make([]TopKItem[T], 0, len(t.items))
+	for _, h := range t.items {
+		items = append(items, h.Value())
+	}
+	slices.SortFunc(items, func(a, b TopKItem[T]) int {
+		switch {
+		case a.Count > b.Count:
+			return -1
+		case a.Count < b.Count:
+			return 1
+		}
+		return 0
+	})
+	return items
+}
+
+// Merge adds the counts of other to t and recomputes the top k from the
+// candidates of both. Both must have the same sketch dimensions.
+func (t *TopK[T]) Merge(other *TopK[T]) error {
+	if err := t.sketch.Merge(other.sketch); err != nil {
+		return err
+	}
+	candidates := slices.Collect(maps.Keys(t.items))
+	for v := range other.items {
+		if _, ok := t.items[v]; !ok {
+			candidates = append(candidates, v)
+		}
+	}
+	t.rebuild(candidates)
+	return nil
+}
+
+// rebuild recomputes the top k from candidates with the current sketch.
+func (t *TopK[T]) rebuild(candidates []T) {
+	t.heap = NewPriorityQueue(func(a, b TopKItem[T]) bool { return a.Count < b.Count })
+	clear(t.items)
+	for _, v := range candidates {
+		t.offer(TopKItem[T]{Value: v, Count: t.sketch.Estimate(v)})
+	}
+}
+
+func (t *TopK[T]) clone() *TopK[T] {
+	c := &TopK[T]{k: t.k, sketch: t.sketch.clone(), items: make(map[T]*PriorityItem[TopKItem[T]], t.k)}
+	c.rebuild(slices.Collect(maps.Keys(t.items)))
+	return c
+}
+
+// MarshalBinary encodes the sketch and the current top elements with gob.
+// T must be encodable by encoding/gob.
+func (t *TopK[T]) MarshalBinary() ([]byte, error) {
+	sketch, err := t.sketch.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(topKState[T]{Sketch: sketch, Items: t.List()}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces t with the decoded data, keeping its Hasher and k.
+func (t *TopK[T]) UnmarshalBinary(data []byte) error {
+	var state topKState[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	if err := t.sketch.UnmarshalBinary(state.Sketch); err != nil {
+		return err
+	}
+	candidates := make([]T, len(state.Items))
+	for i, it := range state.Items {
+		candidates[i] = it.Value
+	}
+	t.rebuild(candidates)
+	return nil
+}
+
+// SyncTopK is a TopK safe for concurrent use.
+type SyncTopK[T comparable] struct {
+	mutex sync.RWMutex
+	t     *TopK[T]
+}
+
+// NewSyncTopK creates a SyncTopK. See NewTopK for the parameters.
+func NewSyncTopK[T comparable](k int, epsilon, delta float64, hash Hasher[T]) *SyncTopK[T] {
+	return &SyncTopK[T]{t: NewTopK(k, epsilon, delta, hash)}
+}
+
+// Add adds n occurrences of v and returns its new estimated count.
+func (t *SyncTopK[T]) Add(v T, n uint64) uint64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.t.Add(v, n)
+}
+
+// Estimate returns the estimated count of v.
+func (t *SyncTopK[T]) Estimate(v T) uint64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.t.Estimate(v)
+}
+
+// List returns the top elements, most frequent first.
+func (t *SyncTopK[T]) List() []TopKItem[T] {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.t.List()
+}
+
+// Merge adds the counts of other to t and recomputes the top k.
+func (t *SyncTopK[T]) Merge(other *SyncTopK[T]) error {
+	other.mutex.RLock()
+	o := other.t.clone()
+	other.mutex.RUnlock()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.t.Merge(o)
+}
+
+// MarshalBinary encodes the sketch and the current top elements.
+func (t *SyncTopK[T]) MarshalBinary() ([]byte, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.t.MarshalBinary()
+}
+
+// UnmarshalBinary replaces t with the decoded data.
+func (t *SyncTopK[T]) UnmarshalBinary(data []byte) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.t.UnmarshalBinary(data)
+}
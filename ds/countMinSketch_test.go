@@ -0,0 +1,75 @@
+package ds
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	t.Run("CountMinSketch 测试", func(t *testing.T) {
+		s := NewCountMinSketch(0.001, 0.01, HashString)
+		for i := range 1000 {
+			s.Add(strconv.Itoa(i), uint64(i%10+1))
+		}
+		s.Add("hot", 5000)
+
+		t.Run("估计不低于真实值", func(t *testing.T) {
+			for i := range 1000 {
+				assert.GreaterOrEqual(t, s.Estimate(strconv.Itoa(i)), uint64(i%10+1))
+			}
+			assert.InDelta(t, 5000, s.Estimate("hot"), 0.001*float64(s.Total()))
+		})
+
+		t.Run("合并与序列化", func(t *testing.T) {
+			o := NewCountMinSketch(0.001, 0.01, HashString)
+			o.Add("hot", 1000)
+			assert.Nil(t, s.Merge(o))
+			assert.GreaterOrEqual(t, s.Estimate("hot"), uint64(6000))
+			assert.ErrorIs(t, s.Merge(NewCountMinSketch(0.1, 0.01, HashString)), ErrIncompatibleSketch)
+
+			data, err := s.MarshalBinary()
+			assert.Nil(t, err)
+			c := NewSyncCountMinSketch(0.5, 0.5, HashString)
+			assert.Nil(t, c.UnmarshalBinary(data))
+			assert.Equal(t, s.Estimate("hot"), c.Estimate("hot"))
+			assert.Equal(t, s.Total(), c.Total())
+		})
+	})
+}
+
+func TestTopK(t *testing.T) {
+	t.Run("TopK 测试", func(t *testing.T) {
+		k := NewTopK(3, 0.001, 0.01, HashString)
+		for i := range 100 {
+			k.Add(strconv.Itoa(i), 1)
+		}
+		k.Add("a", 100)
+		k.Add("b", 50)
+		k.Add("c", 70)
+
+		list := k.List()
+		assert.Len(t, list, 3)
+		assert.Equal(t, []string{"a", "c", "b"}, []string{list[0].Value, list[1].Value, list[2].Value})
+
+		t.Run("合并后重新计算", func(t *testing.T) {
+			o := NewTopK(3, 0.001, 0.01, HashString)
+			o.Add("d", 500)
+			o.Add("b", 100)
+			assert.Nil(t, k.Merge(o))
+			list := k.List()
+			assert.Equal(t, "d", list[0].Value)
+			assert.Equal(t, "b", list[1].Value)
+			assert.Equal(t, uint64(150), list[1].Count)
+		})
+
+		t.Run("序列化", func(t *testing.T) {
+			data, err := k.MarshalBinary()
+			assert.Nil(t, err)
+			c := NewSyncTopK(3, 0.001, 0.01, HashString)
+			assert.Nil(t, c.UnmarshalBinary(data))
+			assert.Equal(t, k.List(), c.List())
+		})
+	})
+}
@@ -0,0 +1,304 @@
+package ds
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*CuckooFilter[string])(nil)
+	_ encoding.BinaryUnmarshaler = (*CuckooFilter[string])(nil)
+)
+
+// ErrFilterFull is returned when a CuckooFilter cannot find room for an element.
+var ErrFilterFull = errors.New("filter is full")
+
+const (
+	cuckooMagic   = 'C'
+	cuckooVersion = 1
+
+	cuckooBucketSize = 4
+	cuckooMaxKicks   = 500
+	// cuckooLoadFactor is the occupancy at which inserts start failing in practice.
+	cuckooLoadFactor = 0.95
+)
+
+type (
+	// CuckooFilter is a cuckoo filter (Fan et al.): a set that, unlike a Bloom
+	// filter, supports deletion. It stores a 16-bit fingerprint per element in
+	// one of two candidate buckets, giving a false positive rate of about 0.01%.
+	//
+	// The filter has a fixed capacity; Add returns ErrFilterFull when it runs
+	// out of room. Adding the same element twice stores it twice, and it must
+	// be deleted twice. Only delete elements that were added.
+	//
+	// CuckooFilter is not safe for concurrent use; see SyncCuckooFilter.
+	//
+	// Type parameters:
+	//   - T: The element type
+	CuckooFilter[T any] struct {
+		buckets []uint16 // len = cuckooBucketSize * bucket count, 0 = empty slot
+		mask    uint64   // bucket count - 1
+		count   uint64
+		hash    Hasher[T]
+	}
+
+	// cuckooKick records an eviction so a failed insert can be rolled back.
+	cuckooKick struct {
+		slot uint64
+		fp   uint16
+	}
+)
+
+// NewCuckooFilter creates a CuckooFilter that can hold at least capacity elements.
+//
+// Example:
+//
+//	f := NewCuckooFilter(100000, HashString)
+//	_ = f.Add("session-1")
+//	f.Contains("session-1") // true
+//	f.Delete("session-1")
+func NewCuckooFilter[T any](capacity uint64, hash Hasher[T]) *CuckooFilter[T] {
+	if hash == nil {
+		panic("cuckoo filter: nil hasher")
+	}
+	n := max(1, uint64(float64(capacity)/cuckooBucketSize/cuckooLoadFactor)+1)
+	n = 1 << bits.Len64(n-1) // next power of two
+	return &CuckooFilter[T]{
+		buckets: make([]uint16, n*cuckooBucketSize),
+		mask:    n - 1,
+		hash:    hash,
+	}
+}
+
+// locate returns the fingerprint and the two candidate buckets of a hash.
+func (f *CuckooFilter[T]) locate(h uint64) (fp uint16, i1, i2 uint64) {
+	fp = max(1, uint16(h>>48))
+	i1 = h & f.mask
+	return fp, i1, f.alt(i1, fp)
+}
+
+// alt returns the other candidate bucket of fp stored in bucket i.
+func (f *CuckooFilter[T]) alt(i uint64, fp uint16) uint64 {
+	return (i ^ HashUint64(uint64(fp))) & f.mask
+}
+
+// put stores fp in a free slot of bucket i.
+func (f *CuckooFilter[T]) put(i uint64, fp uint16) bool {
+	b := f.buckets[i*cuckooBucketSize : (i+1)*cuckooBucketSize]
+	for j := range b {
+		if b[j] == 0 {
+			b[j] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether bucket i holds fp.
+func (f *CuckooFilter[T]) has(i uint64, fp uint16) bool {
+	b := f.buckets[i*cuckooBucketSize : (i+1)*cuckooBucketSize]
+	for j := range b {
+		if b[j] == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// drop removes one fp from bucket i.
+func (f *CuckooFilter[T]) drop(i uint64, fp uint16) bool {
+	b := f.buckets[i*cuckooBucketSize : (i+1)*cuckooBucketSize]
+	for j := range b {
+		if b[j] == fp {
+			b[j] = 0
+			return true
+		}
+	}
+	return false
+}
+
+// insert stores fp in bucket i1 or i2, relocating other fingerprints if needed.
+// On failure the filter is left unchanged.
+func (f *CuckooFilter[T]) insert(fp uint16, i1, i2 uint64) error {
+	if f.put(i1, fp) || f.put(i2, fp) {
+		f.count++
+		return nil
+	}
+
+	var (
+		i     = []uint64{i1, i2}[rand.IntN(2)]
+		kicks = make([]cuckooKick, 0, cuckooMaxKicks)
+	)
+	for range cuckooMaxKicks {
+		slot := i*cuckooBucketSize + uint64(rand.IntN(cuckooBucketSize))
+		kicks = append(kicks, cuckooKick{slot: slot, fp: f.buckets[slot]})
+		fp, f.buckets[slot] = f.buckets[slot], fp
+		i = f.alt(i, fp)
+		if f.put(i, fp) {
+			f.count++
+			return nil
+		}
+	}
+	// Undo the evictions in reverse order.
+	for j := len(kicks) - 1; j >= 0; j-- {
+		f.buckets[kicks[j].slot] = kicks[j].fp
+	}
+	return ErrFilterFull
+}
+
+// Add adds v to the filter. Returns ErrFilterFull if there is no room left.
+func (f *CuckooFilter[T]) Add(v T) error {
+	return f.insert(f.locate(f.hash(v)))
+}
+
+// Contains reports whether v may be in the filter.
+// false means v is definitely not in the filter.
+func (f *CuckooFilter[T]) Contains(v T) bool {
+	fp, i1, i2 := f.locate(f.hash(v))
+	return f.has(i1, fp) || f.has(i2, fp)
+}
+
+// Delete removes one occurrence of v and reports whether it was found.
+func (f *CuckooFilter[T]) Delete(v T) bool {
+	fp, i1, i2 := f.locate(f.hash(v))
+	if f.drop(i1, fp) || f.drop(i2, fp) {
+		f.count--
+		return true
+	}
+	return false
+}
+
+// Len returns the number of stored fingerprints.
+func (f *CuckooFilter[T]) Len() int {
+	return int(f.count)
+}
+
+// Cap returns the number of fingerprint slots.
+func (f *CuckooFilter[T]) Cap() int {
+	return len(f.buckets)
+}
+
+// Merge adds all elements of other to f. Both filters must have the same
+// number of buckets, otherwise ErrIncompatibleSketch is returned. If f runs out
+// of room, ErrFilterFull is returned and f holds part of other.
+func (f *CuckooFilter[T]) Merge(other *CuckooFilter[T]) error {
+	if f.mask != other.mask {
+		return ErrIncompatibleSketch
+	}
+	for slot, fp := range other.buckets {
+		if fp == 0 {
+			continue
+		}
+		i := uint64(slot) / cuckooBucketSize
+		if err := f.insert(fp, i, f.alt(i, fp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *CuckooFilter[T]) clone() *CuckooFilter[T] {
+	c := *f
+	c.buckets = append([]uint16(nil), f.buckets...)
+	return &c
+}
+
+// MarshalBinary encodes the filter. The Hasher is not encoded.
+func (f *CuckooFilter[T]) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 18+2*len(f.buckets))
+	b = append(b, cuckooMagic, cuckooVersion)
+	b = binary.BigEndian.AppendUint64(b, f.mask+1)
+	b = binary.BigEndian.AppendUint64(b, f.count)
+	for _, fp := range f.buckets {
+		b = binary.BigEndian.AppendUint16(b, fp)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces the filter with the decoded data, keeping its Hasher.
+func (f *CuckooFilter[T]) UnmarshalBinary(data []byte) error {
+	r := &sketchReader{b: data}
+	r.header(cuckooMagic, cuckooVersion)
+	var (
+		n     = r.u64()
+		count = r.u64()
+	)
+	if r.err == nil && (n == 0 || n&(n-1) != 0 || n > uint64(len(data))) {
+		return ErrInvalidEncoding
+	}
+	buckets := r.u16s(n * cuckooBucketSize)
+	if err := r.done(); err != nil {
+		return err
+	}
+	f.buckets, f.mask, f.count = buckets, n-1, count
+	return nil
+}
+
+// SyncCuckooFilter is a CuckooFilter safe for concurrent use.
+type SyncCuckooFilter[T any] struct {
+	mutex sync.RWMutex
+	f     *CuckooFilter[T]
+}
+
+// NewSyncCuckooFilter creates a SyncCuckooFilter. See NewCuckooFilter for the parameters.
+func NewSyncCuckooFilter[T any](capacity uint64, hash Hasher[T]) *SyncCuckooFilter[T] {
+	return &SyncCuckooFilter[T]{f: NewCuckooFilter(capacity, hash)}
+}
+
+// Add adds v to the filter. Returns ErrFilterFull if there is no room left.
+func (f *SyncCuckooFilter[T]) Add(v T) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.Add(v)
+}
+
+// Contains reports whether v may be in the filter.
+func (f *SyncCuckooFilter[T]) Contains(v T) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.f.Contains(v)
+}
+
+// Delete removes one occurrence of v and reports whether it was found.
+func (f *SyncCuckooFilter[T]) Delete(v T) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.Delete(v)
+}
+
+// Len returns the number of stored fingerprints.
+func (f *SyncCuckooFilter[T]) Len() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.f.Len()
+}
+
+// Merge adds all elements of other to f.
+func (f *SyncCuckooFilter[T]) Merge(other *SyncCuckooFilter[T]) error {
+	other.mutex.RLock()
+	o := other.f.clone()
+	other.mutex.RUnlock()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.Merge(o)
+}
+
+// MarshalBinary encodes the filter.
+func (f *SyncCuckooFilter[T]) MarshalBinary() ([]byte, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.f.MarshalBinary()
+}
+
+// UnmarshalBinary replaces the filter with the decoded data.
+func (f *SyncCuckooFilter[T]) UnmarshalBinary(data []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.f.UnmarshalBinary(data)
+}
@@ -0,0 +1,91 @@
+package ds
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCuckooFilter(t *testing.T) {
+	t.Run("CuckooFilter 测试", func(t *testing.T) {
+		f := NewCuckooFilter(10000, HashString)
+
+		t.Run("添加与查询", func(t *testing.T) {
+			for i := range 10000 {
+				assert.Nil(t, f.Add(strconv.Itoa(i)))
+			}
+			assert.Equal(t, 10000, f.Len())
+			for i := range 10000 {
+				assert.True(t, f.Contains(strconv.Itoa(i)))
+			}
+			var fp int
+			for i := range 10000 {
+				if f.Contains("x" + strconv.Itoa(i)) {
+					fp++
+				}
+			}
+			assert.Less(t, fp, 20)
+		})
+
+		t.Run("删除", func(t *testing.T) {
+			assert.True(t, f.Delete("42"))
+			assert.False(t, f.Contains("42"))
+			assert.Equal(t, 9999, f.Len())
+		})
+
+		t.Run("序列化", func(t *testing.T) {
+			data, err := f.MarshalBinary()
+			assert.Nil(t, err)
+			g := NewCuckooFilter(1, HashString)
+			assert.Nil(t, g.UnmarshalBinary(data))
+			assert.Equal(t, f.Len(), g.Len())
+			assert.True(t, g.Contains("43"))
+		})
+
+		t.Run("合并", func(t *testing.T) {
+			a := NewCuckooFilter(100, HashString)
+			b := NewCuckooFilter(100, HashString)
+			assert.Nil(t, a.Add("a"))
+			assert.Nil(t, b.Add("b"))
+			assert.Nil(t, a.Merge(b))
+			assert.True(t, a.Contains("a"))
+			assert.True(t, a.Contains("b"))
+			assert.ErrorIs(t, a.Merge(NewCuckooFilter(10000, HashString)), ErrIncompatibleSketch)
+		})
+	})
+
+	t.Run("满时失败且不丢失元素", func(t *testing.T) {
+		f := NewCuckooFilter(8, HashUint64)
+		var added []uint64
+		for i := range uint64(100) {
+			if f.Add(i) == nil {
+				added = append(added, i)
+			} else {
+				break
+			}
+		}
+		assert.ErrorIs(t, f.Add(1000), ErrFilterFull)
+		for _, v := range added {
+			assert.True(t, f.Contains(v))
+		}
+		assert.Equal(t, len(added), f.Len())
+	})
+
+	t.Run("SyncCuckooFilter 并发", func(t *testing.T) {
+		var (
+			f  = NewSyncCuckooFilter(4000, HashUint64)
+			wg sync.WaitGroup
+		)
+		for w := range 4 {
+			wg.Go(func() {
+				for i := range 500 {
+					assert.Nil(t, f.Add(uint64(w*500+i)))
+				}
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, 2000, f.Len())
+	})
+}
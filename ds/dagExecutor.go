@@ -0,0 +1,164 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownDependency is returned by DAGExecutor.Run when a task depends on
+// a key that has no task.
+var ErrUnknownDependency = errors.New("dag: unknown dependency")
+
+type (
+	// DAGExecutor runs tasks concurrently in dependency order: a task starts
+	// once all of its dependencies have finished successfully.
+	//
+	// By default the first error cancels the context passed to running tasks,
+	// no further tasks are started, and Run returns that error. With
+	// WithContinueOnError, only the dependents of a failed task are skipped and
+	// Run returns all errors joined.
+	//
+	// Tasks must be added before Run; a DAGExecutor may be run more than once.
+	//
+	// Type parameters:
+	//   - K: The task key type, must be comparable
+	DAGExecutor[K comparable] struct {
+		cfg   dagConfig
+		graph *Graph[K]
+		tasks map[K]func(context.Context) error
+	}
+
+	// DAGOption configures a DAGExecutor.
+	DAGOption func(*dagConfig)
+
+	// dagConfig holds the configuration of a DAGExecutor.
+	dagConfig struct {
+		concurrency     int
+		continueOnError bool
+	}
+)
+
+// WithDAGConcurrency caps the number of tasks running at once. n <= 0 means no limit.
+func WithDAGConcurrency(n int) DAGOption {
+	return func(c *dagConfig) {
+		c.concurrency = n
+	}
+}
+
+// WithContinueOnError keeps running tasks that do not depend on a failed task.
+func WithContinueOnError() DAGOption {
+	return func(c *dagConfig) {
+		c.continueOnError = true
+	}
+}
+
+// NewDAGExecutor creates an empty DAGExecutor.
+//
+// Example:
+//
+//	e := NewDAGExecutor[string](WithDAGConcurrency(4))
+//	e.Add("fetch", fetch)
+//	e.Add("parse", parse, "fetch")
+//	e.Add("index", index, "parse")
+//	e.Add("thumbs", thumbs, "fetch") // runs alongside parse
+//	err := e.Run(ctx)
+func NewDAGExecutor[K comparable](opts ...DAGOption) *DAGExecutor[K] {
+	var cfg dagConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &DAGExecutor[K]{
+		cfg:   cfg,
+		graph: NewGraph[K](),
+		tasks: make(map[K]func(context.Context) error),
+	}
+}
+
+// Add adds task under key, to run after all deps. Adding a key again replaces
+// its task and adds deps to the existing ones.
+func (e *DAGExecutor[K]) Add(key K, task func(context.Context) error, deps ...K) {
+	e.graph.AddNode(key)
+	for _, d := range deps {
+		e.graph.AddEdge(d, key)
+	}
+	e.tasks[key] = task
+}
+
+// Run executes all tasks and waits for the started ones to finish.
+//
+// Returns:
+//   - ErrUnknownDependency or a *CycleError (matching ErrCycle) if the graph is
+//     invalid; no task is run then
+//   - The errors of failed tasks, each prefixed with its key
+//   - ctx.Err() if ctx is done before all tasks are started
+func (e *DAGExecutor[K]) Run(ctx context.Context) error {
+	for _, k := range e.graph.Nodes() {
+		if _, ok := e.tasks[k]; !ok {
+			return fmt.Errorf("%w: %v", ErrUnknownDependency, k)
+		}
+	}
+	if _, err := e.graph.TopoSort(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		key K
+		err error
+	}
+	var (
+		nodes     = e.graph.Nodes()
+		remaining = make(map[K]int, len(nodes))
+		ready     []K
+		done      = make(chan result)
+		running   int
+		errs      []error
+	)
+	for _, k := range nodes {
+		remaining[k] = len(e.graph.Predecessors(k))
+		if remaining[k] == 0 {
+			ready = append(ready, k)
+		}
+	}
+
+	stopped := func() bool { return len(errs) > 0 && !e.cfg.continueOnError }
+	for {
+		for len(ready) > 0 && !stopped() && (e.cfg.concurrency <= 0 || running < e.cfg.concurrency) {
+			if err := ctx.Err(); err != nil {
+				errs, ready = append(errs, err), nil
+				break
+			}
+			k := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				done <- result{key: k, err: e.tasks[k](ctx)}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-done
+		running--
+		if r.err != nil {
+			// In fail-fast mode, later errors are mostly fallout of the cancellation.
+			if !stopped() {
+				errs = append(errs, fmt.Errorf("%v: %w", r.key, r.err))
+			}
+			if !e.cfg.continueOnError {
+				cancel()
+			}
+			continue // dependents of a failed task never become ready
+		}
+		for _, s := range e.graph.Successors(r.key) {
+			if remaining[s]--; remaining[s] == 0 {
+				ready = append(ready, s)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
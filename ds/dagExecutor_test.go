@@ -0,0 +1,133 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDAGExecutor(t *testing.T) {
+	t.Run("DAGExecutor 测试", func(t *testing.T) {
+		t.Run("依赖顺序", func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				order []string
+			)
+			task := func(name string) func(context.Context) error {
+				return func(context.Context) error {
+					mu.Lock()
+					defer mu.Unlock()
+					order = append(order, name)
+					return nil
+				}
+			}
+			e := NewDAGExecutor[string]()
+			e.Add("index", task("index"), "parse")
+			e.Add("parse", task("parse"), "fetch")
+			e.Add("thumbs", task("thumbs"), "fetch")
+			e.Add("fetch", task("fetch"))
+			assert.NoError(t, e.Run(context.Background()))
+
+			index := func(s string) int { return slices.Index(order, s) }
+			assert.Len(t, order, 4)
+			assert.Equal(t, 0, index("fetch"))
+			assert.Less(t, index("parse"), index("index"))
+		})
+
+		t.Run("并发上限", func(t *testing.T) {
+			var running, peak atomic.Int32
+			e := NewDAGExecutor[int](WithDAGConcurrency(3))
+			for i := range 20 {
+				e.Add(i, func(context.Context) error {
+					n := running.Add(1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					running.Add(-1)
+					return nil
+				})
+			}
+			assert.NoError(t, e.Run(context.Background()))
+			assert.Equal(t, int32(3), peak.Load())
+		})
+
+		t.Run("错误传播并取消", func(t *testing.T) {
+			boom := errors.New("boom")
+			var dependentRan atomic.Bool
+			e := NewDAGExecutor[string]()
+			e.Add("fail", func(context.Context) error { return boom })
+			e.Add("slow", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			e.Add("after", func(context.Context) error {
+				dependentRan.Store(true)
+				return nil
+			}, "fail")
+
+			err := e.Run(context.Background())
+			assert.ErrorIs(t, err, boom)
+			assert.NotErrorIs(t, err, context.Canceled)
+			assert.Equal(t, "fail: boom", err.Error())
+			assert.False(t, dependentRan.Load())
+		})
+
+		t.Run("出错后继续独立任务", func(t *testing.T) {
+			var ran sync.Map
+			task := func(name string, err error) func(context.Context) error {
+				return func(context.Context) error {
+					ran.Store(name, true)
+					return err
+				}
+			}
+			e1, e2 := errors.New("e1"), errors.New("e2")
+			e := NewDAGExecutor[string](WithContinueOnError(), WithDAGConcurrency(1))
+			e.Add("a", task("a", e1))
+			e.Add("b", task("b", nil), "a")
+			e.Add("c", task("c", nil))
+			e.Add("d", task("d", e2), "c")
+
+			err := e.Run(context.Background())
+			assert.ErrorIs(t, err, e1)
+			assert.ErrorIs(t, err, e2)
+			_, ok := ran.Load("b")
+			assert.False(t, ok)
+			_, ok = ran.Load("d")
+			assert.True(t, ok)
+		})
+
+		t.Run("无效的图不执行任务", func(t *testing.T) {
+			var ran atomic.Bool
+			noop := func(context.Context) error { ran.Store(true); return nil }
+
+			e := NewDAGExecutor[string]()
+			e.Add("a", noop, "missing")
+			assert.ErrorIs(t, e.Run(context.Background()), ErrUnknownDependency)
+
+			e = NewDAGExecutor[string]()
+			e.Add("a", noop, "b")
+			e.Add("b", noop, "a")
+			assert.ErrorIs(t, e.Run(context.Background()), ErrCycle)
+			assert.False(t, ran.Load())
+		})
+
+		t.Run("上下文已取消", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			e := NewDAGExecutor[int](WithContinueOnError())
+			e.Add(1, func(context.Context) error { return nil })
+			e.Add(2, func(context.Context) error { return nil })
+			assert.ErrorIs(t, e.Run(ctx), context.Canceled)
+		})
+	})
+}
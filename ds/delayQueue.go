@@ -0,0 +1,124 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue is an unbounded queue whose items become available once their
+// deadline passes. Deadlines are tracked by a TimingWheel, so their
+// resolution is the wheel's tick.
+//
+// Released items are taken in the order their deadlines expired.
+//
+// Type parameters:
+//   - T: The element type stored in the queue
+type DelayQueue[T any] struct {
+	mutex   sync.Mutex
+	w       *TimingWheel
+	ready   []T
+	pending int
+	wake    chan struct{} // closed and replaced whenever items are released
+}
+
+// NewDelayQueue creates a DelayQueue scheduled on w.
+// A nil w uses DefaultTimingWheel.
+func NewDelayQueue[T any](w *TimingWheel) *DelayQueue[T] {
+	if w == nil {
+		w = DefaultTimingWheel()
+	}
+	return &DelayQueue[T]{
+		w:    w,
+		wake: make(chan struct{}),
+	}
+}
+
+// Offer adds v to the queue, to be released after delay.
+// The returned cancel function withdraws v if it has not been released yet
+// and reports whether it did so.
+func (q *DelayQueue[T]) Offer(v T, delay time.Duration) (cancel func() bool) {
+	q.mutex.Lock()
+	q.pending++
+	q.mutex.Unlock()
+
+	t := q.w.AfterFunc(delay, func() {
+		q.mutex.Lock()
+		defer q.mutex.Unlock()
+
+		q.pending--
+		q.ready = append(q.ready, v)
+		close(q.wake)
+		q.wake = make(chan struct{})
+	})
+	return func() bool {
+		if !t.Stop() {
+			return false
+		}
+		q.mutex.Lock()
+		q.pending--
+		q.mutex.Unlock()
+		return true
+	}
+}
+
+// OfferAt adds v to the queue, to be released at the given time.
+func (q *DelayQueue[T]) OfferAt(v T, at time.Time) (cancel func() bool) {
+	return q.Offer(v, time.Until(at))
+}
+
+// Poll removes and returns a released item without blocking.
+// Returns false if no item is available.
+func (q *DelayQueue[T]) Poll() (v T, ok bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.pop()
+}
+
+// Take blocks until an item is released or ctx is done.
+// Returns ErrTimingWheelStopped if no item is available and the TimingWheel
+// of the queue is stopped, since no more items are released then.
+func (q *DelayQueue[T]) Take(ctx context.Context) (v T, err error) {
+	for {
+		q.mutex.Lock()
+		v, ok := q.pop()
+		wake := q.wake
+		q.mutex.Unlock()
+		if ok {
+			return v, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return v, ctx.Err()
+		case <-q.w.Done():
+			return v, ErrTimingWheelStopped
+		case <-wake:
+		}
+	}
+}
+
+// Len returns the number of released items waiting to be taken.
+func (q *DelayQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.ready)
+}
+
+// Pending returns the number of items whose deadline has not passed yet.
+func (q *DelayQueue[T]) Pending() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.pending
+}
+
+func (q *DelayQueue[T]) pop() (v T, ok bool) {
+	if len(q.ready) == 0 {
+		return v, false
+	}
+	v = q.ready[0]
+	var zero T
+	q.ready[0] = zero
+	q.ready = q.ready[1:]
+	return v, true
+}
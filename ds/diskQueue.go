@@ -0,0 +1,740 @@
+package ds
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ AckQueue[int] = (*DiskQueue[int])(nil)
+
+// SyncPolicy controls when DiskQueue flushes data and consumer offsets to stable storage.
+type SyncPolicy uint8
+
+const (
+	// SyncAlways fsyncs after every Push and persists the offset after every commit.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval fsyncs and persists the offset periodically in the background.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system; the offset is persisted
+	// only on segment deletion and Close.
+	SyncNever
+)
+
+const (
+	// DefaultSegmentSize is the size at which DiskQueue rotates to a new segment file.
+	DefaultSegmentSize = 64 << 20
+	// DefaultSyncInterval is the flush interval used by SyncInterval.
+	DefaultSyncInterval = time.Second
+
+	segmentExt     = ".seg"
+	offsetFile     = "offset"
+	recordHdrSize  = 8 // uint32 length + uint32 crc
+	offsetFileSize = 28
+)
+
+var (
+	// ErrDiskQueueClosed is returned when operations are attempted on a closed DiskQueue.
+	ErrDiskQueueClosed = errors.New("disk queue is closed")
+
+	// ErrCorruptRecord indicates a record failed its CRC check.
+	// The remainder of the affected segment is skipped.
+	ErrCorruptRecord = errors.New("corrupt record")
+)
+
+type (
+	// DiskQueue is a durable FIFO queue stored in append-only segment files.
+	//
+	// Each record is framed as [length uint32][crc32 uint32][payload] and the
+	// payload is produced by a pluggable Codec. When the active segment reaches
+	// the segment size a new one is started; segments are named after the
+	// sequence number of their first record. A segment is deleted once all its
+	// records are committed. The oldest segment is compacted, rewritten without
+	// its committed records and renamed after the first record left, once
+	// those take at least half the segment size and no less room than the
+	// rest; records committed out of order are kept until the records before
+	// them are committed too.
+	//
+	// Records are consumed either with Pop, which commits at once, or with
+	// Receive and a later Commit. The consumer offset, the position after the
+	// longest committed prefix of records, is persisted to an "offset" file in
+	// the same directory according to the SyncPolicy, so a reopened queue
+	// resumes there: received records that were not committed are handed out
+	// again. A torn record at the tail of the last segment (e.g. after a crash)
+	// is truncated on open.
+	//
+	// DiskQueue is safe for concurrent use.
+	//
+	// Type parameters:
+	//   - T: The element type stored in the queue
+	DiskQueue[T any] struct {
+		mutex    sync.Mutex
+		dir      string
+		codec    Codec[T]
+		conf     diskQueueConfig
+		segments []uint64 // base sequence numbers of existing segments, ascending
+
+		w     *os.File // active (last) segment, append only
+		wSize int64    // bytes in the active segment
+		wSeq  uint64   // sequence number of the next record to write
+
+		r     *os.File // segment being read
+		rSize int64    // size of the read segment, if it is not the active one
+		rSeg  uint64   // base sequence number of the segment being read
+		rPos  int64    // byte offset of the next record in the read segment
+		rSeq  uint64   // sequence number of the next record to read
+
+		committed diskPos              // position after the committed prefix, persisted as the offset
+		received  map[uint64]*diskMark // received records not yet passed by committed, by sequence number
+
+		dirty  bool // unsynced data or offset
+		closed bool
+		cf     context.CancelFunc
+	}
+
+	// DiskQueueOption configures a DiskQueue.
+	DiskQueueOption func(*diskQueueConfig)
+
+	// diskPos is a read position: a segment, a byte offset in it and the
+	// sequence number of the record there.
+	diskPos struct {
+		seg uint64
+		pos int64
+		seq uint64
+	}
+
+	// diskMark tracks a received record, or a skipped run of records.
+	diskMark struct {
+		next diskPos // position after the record
+		done bool    // committed or skipped
+	}
+
+	// diskQueueConfig holds the configuration of a DiskQueue.
+	diskQueueConfig struct {
+		segmentSize  int64
+		syncPolicy   SyncPolicy
+		syncInterval time.Duration
+	}
+)
+
+// WithSegmentSize sets the size in bytes at which a new segment file is started.
+func WithSegmentSize(size int64) DiskQueueOption {
+	return func(c *diskQueueConfig) {
+		if size > 0 {
+			c.segmentSize = size
+		}
+	}
+}
+
+// WithSyncPolicy sets when data and offsets are flushed to disk.
+// interval is only used by SyncInterval; <= 0 uses DefaultSyncInterval.
+func WithSyncPolicy(policy SyncPolicy, interval time.Duration) DiskQueueOption {
+	return func(c *diskQueueConfig) {
+		c.syncPolicy = policy
+		if interval > 0 {
+			c.syncInterval = interval
+		}
+	}
+}
+
+// OpenDiskQueue opens (or creates) a DiskQueue in dir.
+// A nil codec defaults to JSONCodec.
+//
+// Example:
+//
+//	q, err := OpenDiskQueue[Job]("/var/lib/app/jobs", nil, WithSyncPolicy(SyncInterval, time.Second))
+//	if err != nil {
+//		return err
+//	}
+//	defer q.Close()
+func OpenDiskQueue[T any](dir string, codec Codec[T], opts ...DiskQueueOption) (*DiskQueue[T], error) {
+	if codec == nil {
+		codec = JSONCodec[T]{}
+	}
+	q := &DiskQueue[T]{
+		dir:      dir,
+		codec:    codec,
+		received: make(map[uint64]*diskMark),
+		conf: diskQueueConfig{
+			segmentSize:  DefaultSegmentSize,
+			syncPolicy:   SyncInterval,
+			syncInterval: DefaultSyncInterval,
+		},
+	}
+	for _, opt := range opts {
+		opt(&q.conf)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := q.load(); err != nil {
+		q.closeFiles()
+		return nil, err
+	}
+
+	if q.conf.syncPolicy == SyncInterval {
+		var ctx context.Context
+		ctx, q.cf = context.WithCancel(context.Background())
+		go q.syncLoop(ctx)
+	}
+	return q, nil
+}
+
+// load discovers segments, recovers the active segment and restores the consumer offset.
+func (q *DiskQueue[T]) load() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.segments = append(q.segments, base)
+	}
+	slices.Sort(q.segments)
+	if len(q.segments) == 0 {
+		q.segments = []uint64{0}
+	}
+
+	// Recover the active segment: count valid records and truncate a torn tail.
+	last := q.segments[len(q.segments)-1]
+	q.w, err = os.OpenFile(q.segmentPath(last), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	count, size, err := scanSegment(q.w)
+	if err != nil {
+		return err
+	}
+	if err := q.w.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := q.w.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	q.wSize, q.wSeq = size, last+count
+
+	// Restore the consumer offset, falling back to the oldest segment.
+	c := diskPos{seg: q.segments[0], seq: q.segments[0]}
+	if seg, pos, seq, ok := q.readOffset(); ok && slices.Contains(q.segments, seg) && seq <= q.wSeq {
+		c = diskPos{seg: seg, pos: pos, seq: seq}
+	}
+	if c.seg == last && c.pos > q.wSize {
+		c.pos, c.seq = q.wSize, q.wSeq
+	}
+	q.committed = c
+	q.rSeg, q.rPos, q.rSeq = c.seg, c.pos, c.seq
+	return q.openReader()
+}
+
+// openReader opens the read segment and records its size.
+func (q *DiskQueue[T]) openReader() error {
+	r, err := os.Open(q.segmentPath(q.rSeg))
+	if err != nil {
+		return err
+	}
+	fi, err := r.Stat()
+	if err != nil {
+		r.Close()
+		return err
+	}
+	q.r, q.rSize = r, fi.Size()
+	return nil
+}
+
+// scanSegment counts the valid records of a segment and returns the size of its valid prefix.
+func scanSegment(f *os.File) (count uint64, size int64, err error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	var hdr [recordHdrSize]byte
+	for {
+		if _, err := f.ReadAt(hdr[:], size); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, size, nil
+			}
+			return 0, 0, err
+		}
+		n := binary.BigEndian.Uint32(hdr[0:4])
+		if size+recordHdrSize+int64(n) > fi.Size() {
+			return count, size, nil
+		}
+		data := make([]byte, n)
+		if _, err := f.ReadAt(data, size+recordHdrSize); err != nil {
+			return 0, 0, err
+		}
+		if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:8]) {
+			return count, size, nil
+		}
+		size += recordHdrSize + int64(n)
+		count++
+	}
+}
+
+// Push appends v to the queue.
+func (q *DiskQueue[T]) Push(v T) error {
+	data, err := q.codec.Encode(v)
+	if err != nil {
+		return err
+	}
+	rec := make([]byte, recordHdrSize+len(data))
+	binary.BigEndian.PutUint32(rec[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(rec[4:8], crc32.ChecksumIEEE(data))
+	copy(rec[recordHdrSize:], data)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return ErrDiskQueueClosed
+	}
+	if q.wSize > 0 && q.wSize+int64(len(rec)) > q.conf.segmentSize {
+		if err := q.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := q.w.Write(rec); err != nil {
+		return err
+	}
+	q.wSize += int64(len(rec))
+	q.wSeq++
+
+	if q.conf.syncPolicy == SyncAlways {
+		return q.w.Sync()
+	}
+	q.dirty = true
+	return nil
+}
+
+// Pop removes and returns the oldest unread element, committing it at once.
+// Returns false if the queue is empty.
+// Returns ErrCorruptRecord if the next record is damaged; the remainder of its
+// segment is skipped so the following Pop continues with the next segment.
+// Returns the Codec error if the record cannot be decoded; the record is
+// skipped.
+func (q *DiskQueue[T]) Pop() (v T, ok bool, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return v, false, ErrDiskQueueClosed
+	}
+	v, seq, ok, err := q.receive()
+	if !ok || err != nil {
+		return v, ok, err
+	}
+	return v, true, q.commitSeq(seq)
+}
+
+// Receive returns the oldest unread element and its token without removing
+// it: the element is removed by Commit(token). If the queue is reopened
+// before, the element is read again. Errors are those of Pop.
+func (q *DiskQueue[T]) Receive() (v T, token uint64, ok bool, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return v, 0, false, ErrDiskQueueClosed
+	}
+	return q.receive()
+}
+
+// Commit removes the element received with token. The consumer offset moves
+// past it once all the elements received before it are committed too.
+// Committing an unknown or committed token does nothing.
+func (q *DiskQueue[T]) Commit(token uint64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return ErrDiskQueueClosed
+	}
+	return q.commitSeq(token)
+}
+
+// receive reads the next record, returning its sequence number.
+func (q *DiskQueue[T]) receive() (v T, seq uint64, ok bool, err error) {
+	for q.rSeq < q.wSeq {
+		limit := q.rSize
+		if q.rSeg == q.activeSegment() {
+			limit = q.wSize
+		} else if q.rPos >= limit {
+			if err = q.nextSegment(); err != nil {
+				return v, 0, false, err
+			}
+			continue
+		}
+
+		var hdr [recordHdrSize]byte
+		if _, err = q.r.ReadAt(hdr[:], q.rPos); err != nil {
+			return v, 0, false, err
+		}
+
+		var (
+			n    = binary.BigEndian.Uint32(hdr[0:4])
+			sum  = binary.BigEndian.Uint32(hdr[4:8])
+			data []byte
+		)
+		if q.rPos+recordHdrSize+int64(n) <= limit {
+			data = make([]byte, n)
+			_, err = q.r.ReadAt(data, q.rPos+recordHdrSize)
+		}
+		if data == nil || err != nil || crc32.ChecksumIEEE(data) != sum {
+			seq = q.rSeq
+			if err = q.skipSegment(); err != nil {
+				return v, 0, false, err
+			}
+			if err = q.skip(seq); err != nil {
+				return v, 0, false, err
+			}
+			return v, 0, false, ErrCorruptRecord
+		}
+
+		seq = q.rSeq
+		q.rPos += recordHdrSize + int64(n)
+		q.rSeq++
+		q.received[seq] = &diskMark{next: diskPos{seg: q.rSeg, pos: q.rPos, seq: q.rSeq}}
+
+		if v, err = q.codec.Decode(data); err != nil {
+			return v, 0, false, errors.Join(err, q.skip(seq))
+		}
+		return v, seq, true, nil
+	}
+	return v, 0, false, nil
+}
+
+// skip marks the records from seq to the read position as done.
+func (q *DiskQueue[T]) skip(seq uint64) error {
+	q.received[seq] = &diskMark{next: diskPos{seg: q.rSeg, pos: q.rPos, seq: q.rSeq}, done: true}
+	return q.advance()
+}
+
+// commitSeq marks the received record seq as done.
+func (q *DiskQueue[T]) commitSeq(seq uint64) error {
+	m, ok := q.received[seq]
+	if !ok || m.done {
+		return nil
+	}
+	m.done = true
+	return q.advance()
+}
+
+// advance moves the committed position over the done records and deletes the
+// segments it left.
+func (q *DiskQueue[T]) advance() error {
+	moved := false
+	for m, ok := q.received[q.committed.seq]; ok && m.done; m, ok = q.received[q.committed.seq] {
+		delete(q.received, q.committed.seq)
+		q.committed = m.next
+		moved = true
+	}
+	if !moved {
+		return nil
+	}
+
+	i, _ := slices.BinarySearch(q.segments, q.committed.seg)
+	if i == 0 {
+		if err := q.commit(); err != nil {
+			return err
+		}
+		return q.maybeCompact()
+	}
+	// Persist the offset before deleting so a crash never points at a missing segment.
+	if err := q.writeOffset(); err != nil {
+		return err
+	}
+	var errs []error
+	for _, base := range q.segments[:i] {
+		errs = append(errs, os.Remove(q.segmentPath(base)))
+	}
+	q.segments = slices.Delete(q.segments, 0, i)
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return q.maybeCompact()
+}
+
+// maybeCompact compacts the oldest segment if its committed records take at
+// least half the segment size and no less room than the rest of it.
+func (q *DiskQueue[T]) maybeCompact() error {
+	c := q.committed
+	if c.pos*2 < q.conf.segmentSize {
+		return nil
+	}
+	size := q.wSize
+	active := c.seg == q.activeSegment()
+	if !active {
+		fi, err := os.Stat(q.segmentPath(c.seg))
+		if err != nil {
+			return err
+		}
+		size = fi.Size()
+	}
+	// A sealed segment with nothing left would be renamed after the next
+	// segment; it is deleted once the reader moves on instead.
+	if live := size - c.pos; c.pos < live || (live == 0 && !active) {
+		return nil
+	}
+	return q.compact(size)
+}
+
+// compact rewrites the oldest segment, of the given size, without the
+// committed records at its head. The copy is named after the committed
+// sequence number, so the sequence numbers of the records are unchanged.
+func (q *DiskQueue[T]) compact(size int64) error {
+	var (
+		c      = q.committed
+		active = c.seg == q.activeSegment()
+		path   = q.segmentPath(c.seq)
+		tmp    = path + ".tmp"
+	)
+	src, err := os.Open(q.segmentPath(c.seg))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_RDWR|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, io.NewSectionReader(src, c.pos, size-c.pos)); err == nil {
+		err = dst.Sync()
+	}
+	if err != nil {
+		dst.Close()
+		return errors.Join(err, os.Remove(tmp))
+	}
+
+	// Point the offset at the copy before it appears: a crash in between
+	// falls back to the oldest segment and hands the committed records out
+	// again, rather than reading the copied records twice.
+	q.committed = diskPos{seg: c.seq, seq: c.seq}
+	if err := q.writeOffset(); err != nil {
+		q.committed = c
+		dst.Close()
+		return errors.Join(err, os.Remove(tmp))
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		q.committed = c
+		dst.Close()
+		return errors.Join(err, q.writeOffset(), os.Remove(tmp))
+	}
+
+	q.segments[0] = c.seq
+	if active {
+		q.w.Close()
+		q.w, q.wSize = dst, size-c.pos
+	} else if err := dst.Close(); err != nil {
+		return err
+	}
+	for _, m := range q.received {
+		if m.next.seg == c.seg {
+			m.next.seg, m.next.pos = c.seq, m.next.pos-c.pos
+		}
+	}
+	if q.rSeg == c.seg {
+		q.r.Close()
+		q.r, q.rSeg, q.rPos = nil, c.seq, q.rPos-c.pos
+		if err := q.openReader(); err != nil {
+			return err
+		}
+	}
+	return os.Remove(q.segmentPath(c.seg))
+}
+
+// Len returns the number of unread elements. Received but uncommitted
+// elements are not counted.
+func (q *DiskQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return int(q.wSeq - q.rSeq)
+}
+
+// Sync flushes the active segment and persists the consumer offset.
+func (q *DiskQueue[T]) Sync() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return ErrDiskQueueClosed
+	}
+	return q.sync()
+}
+
+// Close syncs and closes the queue.
+// This method is idempotent and can be called multiple times.
+func (q *DiskQueue[T]) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	if q.cf != nil {
+		q.cf()
+	}
+	err := q.sync()
+	return errors.Join(err, q.closeFiles())
+}
+
+func (q *DiskQueue[T]) closeFiles() error {
+	var errs []error
+	if q.w != nil {
+		errs = append(errs, q.w.Close())
+	}
+	if q.r != nil {
+		errs = append(errs, q.r.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (q *DiskQueue[T]) syncLoop(ctx context.Context) {
+	t := time.NewTicker(q.conf.syncInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			q.mutex.Lock()
+			if !q.closed && q.dirty {
+				_ = q.sync()
+			}
+			q.mutex.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (q *DiskQueue[T]) sync() error {
+	if err := q.w.Sync(); err != nil {
+		return err
+	}
+	if err := q.writeOffset(); err != nil {
+		return err
+	}
+	q.dirty = false
+	return nil
+}
+
+// commit persists the consumer offset according to the sync policy.
+func (q *DiskQueue[T]) commit() error {
+	if q.conf.syncPolicy == SyncAlways {
+		return q.writeOffset()
+	}
+	q.dirty = true
+	return nil
+}
+
+// rotate closes the active segment and starts a new one at the next sequence number.
+func (q *DiskQueue[T]) rotate() error {
+	if err := q.w.Sync(); err != nil {
+		return err
+	}
+	if err := q.w.Close(); err != nil {
+		return err
+	}
+	w, err := os.OpenFile(q.segmentPath(q.wSeq), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if q.rSeg == q.activeSegment() {
+		// The read segment is sealed now, so its size is final.
+		q.rSize = q.wSize
+	}
+	q.w, q.wSize = w, 0
+	q.segments = append(q.segments, q.wSeq)
+	return nil
+}
+
+// nextSegment moves the reader to the following segment. The consumed one is
+// deleted by advance once its records are committed.
+func (q *DiskQueue[T]) nextSegment() error {
+	i, _ := slices.BinarySearch(q.segments, q.rSeg)
+	next := q.segments[i+1]
+
+	q.r.Close()
+	q.r, q.rSeg, q.rPos, q.rSeq = nil, next, 0, next
+	return q.openReader()
+}
+
+// skipSegment discards the unread remainder of the current read segment.
+func (q *DiskQueue[T]) skipSegment() error {
+	if q.rSeg != q.activeSegment() {
+		return q.nextSegment()
+	}
+	q.rPos, q.rSeq = q.wSize, q.wSeq
+	return nil
+}
+
+func (q *DiskQueue[T]) activeSegment() uint64 {
+	return q.segments[len(q.segments)-1]
+}
+
+func (q *DiskQueue[T]) segmentPath(base uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", base, segmentExt))
+}
+
+// writeOffset atomically replaces the offset file with the committed position.
+func (q *DiskQueue[T]) writeOffset() error {
+	var buf [offsetFileSize]byte
+	binary.BigEndian.PutUint64(buf[0:8], q.committed.seg)
+	binary.BigEndian.PutUint64(buf[8:16], uint64(q.committed.pos))
+	binary.BigEndian.PutUint64(buf[16:24], q.committed.seq)
+	binary.BigEndian.PutUint32(buf[24:28], crc32.ChecksumIEEE(buf[:24]))
+
+	var (
+		path = filepath.Join(q.dir, offsetFile)
+		tmp  = path + ".tmp"
+	)
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf[:]); err != nil {
+		f.Close()
+		return err
+	}
+	if q.conf.syncPolicy != SyncNever {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readOffset loads the persisted read position, if present and intact.
+func (q *DiskQueue[T]) readOffset() (seg uint64, pos int64, seq uint64, ok bool) {
+	buf, err := os.ReadFile(filepath.Join(q.dir, offsetFile))
+	if err != nil || len(buf) != offsetFileSize ||
+		crc32.ChecksumIEEE(buf[:24]) != binary.BigEndian.Uint32(buf[24:28]) {
+		return 0, 0, 0, false
+	}
+	return binary.BigEndian.Uint64(buf[0:8]),
+		int64(binary.BigEndian.Uint64(buf[8:16])),
+		binary.BigEndian.Uint64(buf[16:24]),
+		true
+}
@@ -0,0 +1,412 @@
+package ds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diskJob struct {
+	ID   int
+	Name string
+}
+
+func TestDiskQueuePushPop(t *testing.T) {
+	t.Run("DiskQueue Push/Pop 测试", func(t *testing.T) {
+		q, err := OpenDiskQueue[diskJob](t.TempDir(), nil, WithSyncPolicy(SyncNever, 0))
+		require.Nil(t, err)
+		defer q.Close()
+
+		_, ok, err := q.Pop()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		for i := range 10 {
+			assert.Nil(t, q.Push(diskJob{ID: i, Name: "job"}))
+		}
+		assert.Equal(t, 10, q.Len())
+
+		for i := range 10 {
+			v, ok, err := q.Pop()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, diskJob{ID: i, Name: "job"}, v)
+		}
+		assert.Equal(t, 0, q.Len())
+	})
+}
+
+func TestDiskQueueReopen(t *testing.T) {
+	for name, policy := range map[string]SyncPolicy{
+		"always":   SyncAlways,
+		"interval": SyncInterval,
+		"never":    SyncNever,
+	} {
+		t.Run("DiskQueue 重新打开后恢复偏移 "+name, func(t *testing.T) {
+			dir := t.TempDir()
+			q, err := OpenDiskQueue(dir, GobCodec[int]{}, WithSyncPolicy(policy, 0), WithSegmentSize(64))
+			require.Nil(t, err)
+			for i := range 20 {
+				require.Nil(t, q.Push(i))
+			}
+			for i := range 7 {
+				v, _, _ := q.Pop()
+				assert.Equal(t, i, v)
+			}
+			require.Nil(t, q.Close())
+			assert.Equal(t, ErrDiskQueueClosed, q.Push(1))
+
+			q, err = OpenDiskQueue(dir, GobCodec[int]{}, WithSyncPolicy(policy, 0), WithSegmentSize(64))
+			require.Nil(t, err)
+			defer q.Close()
+
+			assert.Equal(t, 13, q.Len())
+			for i := 7; i < 20; i++ {
+				v, ok, err := q.Pop()
+				assert.Nil(t, err)
+				assert.True(t, ok)
+				assert.Equal(t, i, v)
+			}
+		})
+	}
+}
+
+func TestDiskQueueReceiveCommit(t *testing.T) {
+	t.Run("DiskQueue 未提交的记录重新打开后再次投递", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue(dir, GobCodec[int]{}, WithSyncPolicy(SyncAlways, 0))
+		require.Nil(t, err)
+		for i := range 4 {
+			require.Nil(t, q.Push(i))
+		}
+
+		tokens := make([]uint64, 3)
+		for i := range tokens {
+			v, token, ok, err := q.Receive()
+			require.Nil(t, err)
+			require.True(t, ok)
+			assert.Equal(t, i, v)
+			tokens[i] = token
+		}
+		assert.Equal(t, 1, q.Len())
+
+		// Committing out of order only moves the offset past the committed prefix.
+		require.Nil(t, q.Commit(tokens[1]))
+		require.Nil(t, q.Commit(tokens[0]))
+		require.Nil(t, q.Commit(tokens[0]))
+		require.Nil(t, q.Close())
+
+		q, err = OpenDiskQueue(dir, GobCodec[int]{})
+		require.Nil(t, err)
+		defer q.Close()
+		assert.Equal(t, 2, q.Len())
+		for want := 2; want < 4; want++ {
+			v, ok, err := q.Pop()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, want, v)
+		}
+	})
+
+	t.Run("DiskQueue 分段在提交后删除", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue[string](dir, nil, WithSegmentSize(40), WithSyncPolicy(SyncNever, 0))
+		require.Nil(t, err)
+		defer q.Close()
+		for range 4 {
+			require.Nil(t, q.Push("0123456789"))
+		}
+
+		var tokens []uint64
+		for range 3 {
+			_, token, ok, err := q.Receive()
+			require.Nil(t, err)
+			require.True(t, ok)
+			tokens = append(tokens, token)
+		}
+		segs, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+		assert.Len(t, segs, 2)
+
+		for _, token := range tokens {
+			require.Nil(t, q.Commit(token))
+		}
+		segs, _ = filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+		assert.Len(t, segs, 1)
+	})
+
+	t.Run("DiskQueue 解码失败时跳过记录", func(t *testing.T) {
+		dir := t.TempDir()
+		sq, err := OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+		require.Nil(t, sq.Push("x"))
+		require.Nil(t, sq.Close())
+
+		q, err := OpenDiskQueue[int](dir, nil)
+		require.Nil(t, err)
+		defer q.Close()
+		require.Nil(t, q.Push(1))
+
+		_, _, err = q.Pop()
+		assert.NotNil(t, err)
+		v, ok, err := q.Pop()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+	})
+}
+
+func TestDiskQueueSegments(t *testing.T) {
+	t.Run("DiskQueue 分段轮转与删除测试", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue[string](dir, nil, WithSegmentSize(40), WithSyncPolicy(SyncNever, 0))
+		require.Nil(t, err)
+		defer q.Close()
+
+		for range 10 {
+			require.Nil(t, q.Push("0123456789"))
+		}
+		segs, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+		assert.Len(t, segs, 5)
+
+		for range 6 {
+			_, ok, err := q.Pop()
+			require.Nil(t, err)
+			require.True(t, ok)
+		}
+		// Segments 0 and 2 are deleted; 4 was compacted into 5, which is
+		// deleted once the reader moves past it.
+		segs, _ = filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+		assert.Equal(t, []string{
+			filepath.Join(dir, "00000000000000000005"+segmentExt),
+			filepath.Join(dir, "00000000000000000006"+segmentExt),
+			filepath.Join(dir, "00000000000000000008"+segmentExt),
+		}, segs)
+		assert.Equal(t, 4, q.Len())
+	})
+
+	t.Run("DiskQueue 压缩部分提交的分段", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue[string](dir, nil, WithSegmentSize(100), WithSyncPolicy(SyncNever, 0))
+		require.Nil(t, err)
+		for range 8 {
+			require.Nil(t, q.Push("0123456789"))
+		}
+
+		// The sealed segment 0 holds records 0-4; committing 0-2 rewrites it
+		// without them.
+		var tokens []uint64
+		for range 4 {
+			_, token, ok, err := q.Receive()
+			require.Nil(t, err)
+			require.True(t, ok)
+			tokens = append(tokens, token)
+		}
+		for _, token := range tokens[:3] {
+			require.Nil(t, q.Commit(token))
+		}
+		segs, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+		assert.Equal(t, []string{
+			filepath.Join(dir, "00000000000000000003"+segmentExt),
+			filepath.Join(dir, "00000000000000000005"+segmentExt),
+		}, segs)
+		fi, err := os.Stat(segs[0])
+		require.Nil(t, err)
+		assert.Equal(t, int64(40), fi.Size())
+
+		// The received record 3 and the unread record 4 survive a reopen.
+		require.Nil(t, q.Close())
+		q, err = OpenDiskQueue[string](dir, nil, WithSegmentSize(100), WithSyncPolicy(SyncNever, 0))
+		require.Nil(t, err)
+		defer q.Close()
+		assert.Equal(t, 5, q.Len())
+		for range 5 {
+			v, ok, err := q.Pop()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "0123456789", v)
+		}
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("DiskQueue 压缩活动分段后继续写入", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue(dir, GobCodec[int]{}, WithSegmentSize(1<<10), WithSyncPolicy(SyncAlways, 0))
+		require.Nil(t, err)
+
+		next := 0
+		for i := range 200 {
+			require.Nil(t, q.Push(i))
+			if i >= 3 {
+				v, ok, err := q.Pop()
+				require.Nil(t, err)
+				require.True(t, ok)
+				require.Equal(t, next, v)
+				next++
+			}
+		}
+		// The records pushed far exceed the segment size, yet the queue never
+		// rotated: the active segment is compacted in place.
+		segs, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+		require.Len(t, segs, 1)
+		fi, err := os.Stat(segs[0])
+		require.Nil(t, err)
+		assert.Less(t, fi.Size(), int64(1<<10))
+		require.Nil(t, q.Close())
+
+		q, err = OpenDiskQueue(dir, GobCodec[int]{}, WithSegmentSize(1<<10), WithSyncPolicy(SyncAlways, 0))
+		require.Nil(t, err)
+		defer q.Close()
+		assert.Equal(t, 3, q.Len())
+		for ; next < 200; next++ {
+			v, ok, err := q.Pop()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, next, v)
+		}
+	})
+}
+
+func TestDiskQueueRecovery(t *testing.T) {
+	t.Run("DiskQueue 截断损坏的尾部记录", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue[int](dir, nil, WithSyncPolicy(SyncAlways, 0))
+		require.Nil(t, err)
+		q.Push(1)
+		q.Push(2)
+		q.Close()
+
+		// Simulate a torn write at the tail.
+		path := filepath.Join(dir, "00000000000000000000"+segmentExt)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		require.Nil(t, err)
+		f.Write([]byte{0, 0, 0, 9, 1, 2})
+		f.Close()
+
+		q, err = OpenDiskQueue[int](dir, nil)
+		require.Nil(t, err)
+		defer q.Close()
+		assert.Equal(t, 2, q.Len())
+		assert.Nil(t, q.Push(3))
+
+		for want := 1; want <= 3; want++ {
+			v, ok, err := q.Pop()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, want, v)
+		}
+	})
+
+	t.Run("DiskQueue CRC校验失败返回错误", func(t *testing.T) {
+		dir := t.TempDir()
+		q, err := OpenDiskQueue[int](dir, nil, WithSegmentSize(16), WithSyncPolicy(SyncAlways, 0))
+		require.Nil(t, err)
+		defer q.Close()
+		for i := range 4 {
+			q.Push(i * 1000)
+		}
+
+		// Flip a payload byte in the first (inactive) segment.
+		path := filepath.Join(dir, "00000000000000000000"+segmentExt)
+		data, _ := os.ReadFile(path)
+		data[recordHdrSize] ^= 0xff
+		require.Nil(t, os.WriteFile(path, data, 0o644))
+
+		_, _, err = q.Pop()
+		assert.Equal(t, ErrCorruptRecord, err)
+
+		v, ok, err := q.Pop()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1000, v)
+	})
+}
+
+func TestWorkQueueDiskTopic(t *testing.T) {
+	t.Run("WorkQueue 使用DiskQueue作为topic存储", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+
+		wq := NewWorkQueue[string]()
+		require.Nil(t, wq.AddTopicQueue("jobs", store))
+		require.Nil(t, wq.Publish("jobs", "a"))
+		require.Nil(t, wq.Publish("jobs", "b"))
+
+		d, err := wq.Consume(context.Background(), "jobs")
+		require.Nil(t, err)
+		assert.Equal(t, "a", d.Value)
+		assert.Nil(t, d.Ack())
+		wq.Close()
+		require.Nil(t, store.Close())
+
+		store, err = OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+		defer store.Close()
+
+		wq = NewWorkQueue[string]()
+		require.Nil(t, wq.AddTopicQueue("jobs", store))
+		stats, _ := wq.Stats("jobs")
+		assert.Equal(t, 1, stats.Ready)
+
+		d, err = wq.Consume(context.Background(), "jobs")
+		require.Nil(t, err)
+		assert.Equal(t, "b", d.Value)
+	})
+
+	t.Run("WorkQueue 未Ack的消息重启后再次投递", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+
+		wq := NewWorkQueue[string]()
+		require.Nil(t, wq.AddTopicQueue("jobs", store))
+		require.Nil(t, wq.Publish("jobs", "a"))
+		require.Nil(t, wq.Publish("jobs", "b"))
+
+		_, err = wq.Consume(context.Background(), "jobs")
+		require.Nil(t, err)
+		d, err := wq.Consume(context.Background(), "jobs")
+		require.Nil(t, err)
+		assert.Nil(t, d.Ack())
+		wq.Close()
+		require.Nil(t, store.Close())
+
+		store, err = OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+		defer store.Close()
+
+		wq = NewWorkQueue[string]()
+		require.Nil(t, wq.AddTopicQueue("jobs", store))
+		d, err = wq.Consume(context.Background(), "jobs")
+		require.Nil(t, err)
+		assert.Equal(t, "a", d.Value)
+		assert.Nil(t, d.Ack())
+	})
+
+	t.Run("WorkQueue 死信消息从存储中提交", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+
+		wq := NewWorkQueue[string](WithMaxRetries(0), WithDeadLetterTopic("dead"))
+		require.Nil(t, wq.AddTopicQueue("jobs", store))
+		require.Nil(t, wq.Publish("jobs", "a"))
+
+		d, err := wq.Consume(context.Background(), "jobs")
+		require.Nil(t, err)
+		require.Nil(t, d.Nack())
+		stats, _ := wq.Stats("dead")
+		assert.Equal(t, 1, stats.Ready)
+		wq.Close()
+		require.Nil(t, store.Close())
+
+		store, err = OpenDiskQueue[string](dir, nil)
+		require.Nil(t, err)
+		defer store.Close()
+		assert.Equal(t, 0, store.Len())
+	})
+}
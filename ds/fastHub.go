@@ -96,6 +96,13 @@ func (b *FastHub[T]) Unsubscribe(s *Subscription[T]) {
 	}
 }
 
+// Len returns the number of active subscribers.
+func (b *FastHub[T]) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subscribers)
+}
+
 // Close closes the bus and all active subscriber channels.
 // After closing, Publish and Subscribe will return ErrHubClosed.
 func (b *FastHub[T]) Close() error {
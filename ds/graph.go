@@ -0,0 +1,337 @@
+package ds
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ErrCycle is matched by the CycleError returned when a graph has a cycle.
+var ErrCycle = errors.New("graph: cycle detected")
+
+// CycleError reports a cycle. Path starts and ends with the same node.
+type CycleError[K comparable] struct {
+	Path []K
+}
+
+func (e *CycleError[K]) Error() string {
+	parts := make([]string, len(e.Path))
+	for i, k := range e.Path {
+		parts[i] = fmt.Sprint(k)
+	}
+	return ErrCycle.Error() + ": " + strings.Join(parts, " -> ")
+}
+
+// Unwrap makes errors.Is(err, ErrCycle) report true.
+func (e *CycleError[K]) Unwrap() error {
+	return ErrCycle
+}
+
+type (
+	// Graph is a directed graph with optionally weighted edges.
+	//
+	// Nodes and edges are kept in insertion order, and all algorithms visit
+	// them in that order, so results are deterministic.
+	//
+	// Graph is not safe for concurrent use.
+	//
+	// Type parameters:
+	//   - K: The node type, must be comparable
+	Graph[K comparable] struct {
+		keys  []K
+		index map[K]int
+		out   [][]graphEdge
+		in    [][]int
+	}
+
+	graphEdge struct {
+		to     int
+		weight float64
+	}
+)
+
+// NewGraph creates an empty directed graph.
+//
+// Example:
+//
+//	g := NewGraph[string]()
+//	g.AddEdge("config", "db")   // config before db
+//	g.AddEdge("db", "http")
+//	order, err := g.TopoSort() // [config db http]
+func NewGraph[K comparable]() *Graph[K] {
+	return &Graph[K]{index: make(map[K]int)}
+}
+
+// AddNode adds k if it is not present yet.
+func (g *Graph[K]) AddNode(k K) {
+	g.node(k)
+}
+
+func (g *Graph[K]) node(k K) int {
+	if i, ok := g.index[k]; ok {
+		return i
+	}
+	i := len(g.keys)
+	g.index[k] = i
+	g.keys = append(g.keys, k)
+	g.out = append(g.out, nil)
+	g.in = append(g.in, nil)
+	return i
+}
+
+// AddEdge adds an edge from -> to with weight 1, adding missing nodes.
+func (g *Graph[K]) AddEdge(from, to K) {
+	g.AddWeightedEdge(from, to, 1)
+}
+
+// AddWeightedEdge adds an edge from -> to, or updates the weight of an existing one.
+//
+// Panics:
+//   - If weight is negative or NaN
+func (g *Graph[K]) AddWeightedEdge(from, to K, weight float64) {
+	if weight < 0 || math.IsNaN(weight) {
+		panic("Graph: negative edge weight")
+	}
+	f, t := g.node(from), g.node(to)
+	for i, e := range g.out[f] {
+		if e.to == t {
+			g.out[f][i].weight = weight
+			return
+		}
+	}
+	g.out[f] = append(g.out[f], graphEdge{to: t, weight: weight})
+	g.in[t] = append(g.in[t], f)
+}
+
+// HasNode reports whether k is in the graph.
+func (g *Graph[K]) HasNode(k K) bool {
+	_, ok := g.index[k]
+	return ok
+}
+
+// HasEdge reports whether the edge from -> to exists.
+func (g *Graph[K]) HasEdge(from, to K) bool {
+	f, ok := g.index[from]
+	if !ok {
+		return false
+	}
+	t, ok := g.index[to]
+	if !ok {
+		return false
+	}
+	for _, e := range g.out[f] {
+		if e.to == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of nodes.
+func (g *Graph[K]) Len() int {
+	return len(g.keys)
+}
+
+// Nodes returns all nodes in insertion order.
+func (g *Graph[K]) Nodes() []K {
+	return append([]K(nil), g.keys...)
+}
+
+// Successors returns the targets of the edges leaving k.
+func (g *Graph[K]) Successors(k K) []K {
+	i, ok := g.index[k]
+	if !ok {
+		return nil
+	}
+	res := make([]K, len(g.out[i]))
+	for n, e := range g.out[i] {
+		res[n] = g.keys[e.to]
+	}
+	return res
+}
+
+// Predecessors returns the sources of the edges entering k.
+func (g *Graph[K]) Predecessors(k K) []K {
+	i, ok := g.index[k]
+	if !ok {
+		return nil
+	}
+	res := make([]K, len(g.in[i]))
+	for n, f := range g.in[i] {
+		res[n] = g.keys[f]
+	}
+	return res
+}
+
+// TopoSort returns the nodes ordered so that every edge points forward.
+// Among independent nodes, insertion order is kept. If the graph has a cycle,
+// a *CycleError is returned.
+func (g *Graph[K]) TopoSort() ([]K, error) {
+	indegree := make([]int, len(g.keys))
+	for i := range g.keys {
+		indegree[i] = len(g.in[i])
+	}
+
+	queue := make([]int, 0, len(g.keys))
+	for i, d := range indegree {
+		if d == 0 {
+			queue = append(queue, i)
+		}
+	}
+	order := make([]K, 0, len(g.keys))
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		order = append(order, g.keys[i])
+		for _, e := range g.out[i] {
+			if indegree[e.to]--; indegree[e.to] == 0 {
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	if len(order) < len(g.keys) {
+		cycle, _ := g.FindCycle()
+		return nil, &CycleError[K]{Path: cycle}
+	}
+	return order, nil
+}
+
+// FindCycle returns a cycle as a path that starts and ends with the same node,
+// or false if the graph is acyclic.
+func (g *Graph[K]) FindCycle() ([]K, bool) {
+	const (
+		white = iota // not visited
+		grey         // on the current DFS path
+		black        // finished
+	)
+	color := make([]uint8, len(g.keys))
+	parent := make([]int, len(g.keys))
+
+	// Iterative DFS: each frame is a node and the next edge to explore.
+	type frame struct{ node, edge int }
+	for root := range g.keys {
+		if color[root] != white {
+			continue
+		}
+		stack := []frame{{node: root}}
+		color[root] = grey
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.edge == len(g.out[top.node]) {
+				color[top.node] = black
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			next := g.out[top.node][top.edge].to
+			top.edge++
+			switch color[next] {
+			case white:
+				color[next], parent[next] = grey, top.node
+				stack = append(stack, frame{node: next})
+			case grey:
+				// Back edge top.node -> next closes a cycle.
+				path := []K{g.keys[next]}
+				for i := top.node; i != next; i = parent[i] {
+					path = append(path, g.keys[i])
+				}
+				path = append(path, g.keys[next])
+				slices.Reverse(path[1 : len(path)-1])
+				return path, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ShortestPath returns the path with the lowest total weight from -> to
+// (Dijkstra), and that weight. Returns false if to is unreachable.
+func (g *Graph[K]) ShortestPath(from, to K) (path []K, dist float64, ok bool) {
+	f, ok1 := g.index[from]
+	t, ok2 := g.index[to]
+	if !ok1 || !ok2 {
+		return nil, 0, false
+	}
+
+	type item struct {
+		node int
+		dist float64
+	}
+	dists := make([]float64, len(g.keys))
+	for i := range dists {
+		dists[i] = math.Inf(1)
+	}
+	prev := make([]int, len(g.keys))
+	dists[f], prev[f] = 0, -1
+
+	pq := NewPriorityQueue(func(a, b item) bool { return a.dist < b.dist })
+	pq.Push(item{node: f})
+	for pq.Len() > 0 {
+		cur, _ := pq.Pop()
+		if cur.dist > dists[cur.node] {
+			continue // stale entry
+		}
+		if cur.node == t {
+			break
+		}
+		for _, e := range g.out[cur.node] {
+			if d := cur.dist + e.weight; d < dists[e.to] {
+				dists[e.to], prev[e.to] = d, cur.node
+				pq.Push(item{node: e.to, dist: d})
+			}
+		}
+	}
+
+	if math.IsInf(dists[t], 1) {
+		return nil, 0, false
+	}
+	for i := t; i != -1; i = prev[i] {
+		path = append(path, g.keys[i])
+	}
+	slices.Reverse(path)
+	return path, dists[t], true
+}
+
+// Components returns the weakly connected components: groups of nodes linked
+// by edges in either direction. Components and their nodes are in insertion order.
+func (g *Graph[K]) Components() [][]K {
+	comp := make([]int, len(g.keys))
+	for i := range comp {
+		comp[i] = -1
+	}
+
+	var res [][]K
+	for root := range g.keys {
+		if comp[root] != -1 {
+			continue
+		}
+		id := len(res)
+		comp[root] = id
+		members := []int{root}
+		for n := 0; n < len(members); n++ {
+			i := members[n]
+			visit := func(j int) {
+				if comp[j] == -1 {
+					comp[j] = id
+					members = append(members, j)
+				}
+			}
+			for _, e := range g.out[i] {
+				visit(e.to)
+			}
+			for _, j := range g.in[i] {
+				visit(j)
+			}
+		}
+
+		slices.Sort(members)
+		keys := make([]K, len(members))
+		for n, i := range members {
+			keys[n] = g.keys[i]
+		}
+		res = append(res, keys)
+	}
+	return res
+}
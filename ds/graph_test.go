@@ -0,0 +1,111 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	t.Run("Graph 测试", func(t *testing.T) {
+		t.Run("节点与边", func(t *testing.T) {
+			g := NewGraph[string]()
+			g.AddEdge("a", "b")
+			g.AddEdge("a", "c")
+			g.AddEdge("a", "b")
+			g.AddNode("d")
+			assert.Equal(t, 4, g.Len())
+			assert.Equal(t, []string{"a", "b", "c", "d"}, g.Nodes())
+			assert.True(t, g.HasEdge("a", "b"))
+			assert.False(t, g.HasEdge("b", "a"))
+			assert.False(t, g.HasEdge("x", "a"))
+			assert.True(t, g.HasNode("d"))
+			assert.Equal(t, []string{"b", "c"}, g.Successors("a"))
+			assert.Equal(t, []string{"a"}, g.Predecessors("b"))
+			assert.Nil(t, g.Successors("x"))
+			assert.Panics(t, func() { g.AddWeightedEdge("a", "b", -1) })
+		})
+
+		t.Run("拓扑排序", func(t *testing.T) {
+			g := NewGraph[string]()
+			g.AddEdge("config", "db")
+			g.AddEdge("config", "cache")
+			g.AddEdge("db", "http")
+			g.AddEdge("cache", "http")
+			g.AddNode("metrics")
+			order, err := g.TopoSort()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"config", "metrics", "db", "cache", "http"}, order)
+		})
+
+		t.Run("环检测报告路径", func(t *testing.T) {
+			g := NewGraph[string]()
+			g.AddEdge("start", "a")
+			g.AddEdge("a", "b")
+			g.AddEdge("b", "c")
+			g.AddEdge("c", "a")
+			cycle, ok := g.FindCycle()
+			assert.True(t, ok)
+			assert.Equal(t, []string{"a", "b", "c", "a"}, cycle)
+
+			_, err := g.TopoSort()
+			assert.True(t, errors.Is(err, ErrCycle))
+			var ce *CycleError[string]
+			assert.True(t, errors.As(err, &ce))
+			assert.Equal(t, []string{"a", "b", "c", "a"}, ce.Path)
+			assert.Equal(t, "graph: cycle detected: a -> b -> c -> a", err.Error())
+
+			self := NewGraph[int]()
+			self.AddEdge(1, 1)
+			selfCycle, ok := self.FindCycle()
+			assert.True(t, ok)
+			assert.Equal(t, []int{1, 1}, selfCycle)
+
+			dag := NewGraph[int]()
+			dag.AddEdge(1, 2)
+			dag.AddEdge(1, 3)
+			dag.AddEdge(2, 3)
+			_, ok = dag.FindCycle()
+			assert.False(t, ok)
+		})
+
+		t.Run("最短路径", func(t *testing.T) {
+			g := NewGraph[string]()
+			g.AddWeightedEdge("a", "b", 4)
+			g.AddWeightedEdge("a", "c", 1)
+			g.AddWeightedEdge("c", "b", 1)
+			g.AddWeightedEdge("b", "d", 1)
+			g.AddWeightedEdge("c", "d", 5)
+			g.AddNode("e")
+
+			path, dist, ok := g.ShortestPath("a", "d")
+			assert.True(t, ok)
+			assert.Equal(t, []string{"a", "c", "b", "d"}, path)
+			assert.Equal(t, 3.0, dist)
+
+			path, dist, ok = g.ShortestPath("a", "a")
+			assert.True(t, ok)
+			assert.Equal(t, []string{"a"}, path)
+			assert.Zero(t, dist)
+
+			_, _, ok = g.ShortestPath("d", "a")
+			assert.False(t, ok)
+			_, _, ok = g.ShortestPath("a", "e")
+			assert.False(t, ok)
+			_, _, ok = g.ShortestPath("a", "x")
+			assert.False(t, ok)
+		})
+
+		t.Run("连通分量", func(t *testing.T) {
+			g := NewGraph[int]()
+			g.AddEdge(1, 2)
+			g.AddEdge(3, 2)
+			g.AddEdge(4, 5)
+			g.AddNode(6)
+			g.AddEdge(5, 1)
+			g.AddEdge(7, 8)
+			assert.Equal(t, [][]int{{1, 2, 3, 4, 5}, {6}, {7, 8}}, g.Components())
+		})
+	})
+}
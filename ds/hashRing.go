@@ -0,0 +1,502 @@
+package ds
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	_ ConsistentHash[string] = (*HashRing[string])(nil)
+	_ ConsistentHash[string] = (*RendezvousHash[string])(nil)
+	_ ConsistentHash[string] = (*JumpHash[string])(nil)
+)
+
+// ErrNoNodes is returned when a key is looked up on a ring without members.
+var ErrNoNodes = errors.New("consistent hash: no nodes")
+
+const (
+	// DefaultVirtualNodes is the number of ring points per unit of weight.
+	DefaultVirtualNodes = 160
+	// DefaultLoadFactor is the load bound commonly used with bounded loads:
+	// no node gets more than 25% above its fair share.
+	DefaultLoadFactor = 1.25
+)
+
+// ConsistentHash maps keys to member nodes so that changing the membership
+// remaps only a small fraction of the keys.
+//
+// Node identity is hashed from fmt.Sprint(node), so distinct nodes must print
+// differently (e.g. "10.0.0.1:6379").
+//
+// Type parameters:
+//   - N: The node type, must be comparable
+//
+// Implementations:
+//   - HashRing: Ring with virtual nodes, weights and optional bounded loads
+//   - RendezvousHash: Highest random weight hashing, O(n) lookups without a ring
+//   - JumpHash: Jump consistent hash, O(ln n) lookups and no memory per key space
+type ConsistentHash[N comparable] interface {
+	// Add adds node with the given weight, or updates its weight if present.
+	// Weights <= 0 are treated as 1.
+	Add(node N, weight int)
+	// Remove removes node. Non-existent nodes are ignored.
+	Remove(node N)
+	// Get returns the node responsible for key, or false if there are no nodes.
+	Get(key string) (N, bool)
+	// Nodes returns the member nodes in no particular order.
+	Nodes() []N
+	// Len returns the number of member nodes.
+	Len() int
+}
+
+type (
+	// HashRing is a consistent hashing ring. Each node is placed on the ring
+	// at weight*virtualNodes points, and a key belongs to the first point
+	// clockwise from its hash. Adding or removing a node only moves the keys
+	// of that node's points.
+	//
+	// With WithBoundedLoad, the ring implements consistent hashing with bounded
+	// loads (Mirrokni et al.): Acquire skips nodes whose in-flight load exceeds
+	// loadFactor times their fair share, and Release gives the load back.
+	//
+	// HashRing is safe for concurrent use.
+	//
+	// Type parameters:
+	//   - N: The node type, must be comparable
+	HashRing[N comparable] struct {
+		mu          sync.RWMutex
+		cfg         hashRingConfig
+		points      []ringPoint[N]
+		weights     map[N]int
+		totalWeight int
+		loads       map[N]*atomic.Int64
+		totalLoad   atomic.Int64
+	}
+
+	ringPoint[N comparable] struct {
+		hash uint64
+		node N
+	}
+
+	// HashRingOption configures a HashRing.
+	HashRingOption func(*hashRingConfig)
+
+	// hashRingConfig holds the configuration of a HashRing.
+	hashRingConfig struct {
+		virtualNodes int
+		loadFactor   float64
+		hash         Hasher[string]
+	}
+)
+
+// WithVirtualNodes sets the number of ring points per unit of weight.
+// More points spread keys more evenly at the cost of memory and Add/Remove time.
+func WithVirtualNodes(n int) HashRingOption {
+	return func(c *hashRingConfig) {
+		if n > 0 {
+			c.virtualNodes = n
+		}
+	}
+}
+
+// WithBoundedLoad enables bounded loads: a node accepts at most
+// ceil(factor * (totalLoad+1) * weight/totalWeight) in-flight keys through
+// Acquire. factor must be > 1; values <= 1 use DefaultLoadFactor.
+func WithBoundedLoad(factor float64) HashRingOption {
+	return func(c *hashRingConfig) {
+		if factor <= 1 {
+			factor = DefaultLoadFactor
+		}
+		c.loadFactor = factor
+	}
+}
+
+// WithRingHash sets the hash function of keys and ring points. Defaults to HashString.
+func WithRingHash(hash Hasher[string]) HashRingOption {
+	return func(c *hashRingConfig) {
+		if hash != nil {
+			c.hash = hash
+		}
+	}
+}
+
+// NewHashRing creates an empty HashRing.
+//
+// Example:
+//
+//	ring := NewHashRing[string](WithBoundedLoad(1.25))
+//	ring.Add("cache-a:6379", 1)
+//	ring.Add("cache-b:6379", 2) // twice the share of cache-a
+//
+//	node, _ := ring.Acquire(userID)
+//	defer ring.Release(node)
+func NewHashRing[N comparable](opts ...HashRingOption) *HashRing[N] {
+	cfg := hashRingConfig{virtualNodes: DefaultVirtualNodes, hash: HashString}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &HashRing[N]{
+		cfg:     cfg,
+		weights: make(map[N]int),
+		loads:   make(map[N]*atomic.Int64),
+	}
+}
+
+// Add adds node with the given weight, or updates its weight if present.
+func (r *HashRing[N]) Add(node N, weight int) {
+	weight = max(weight, 1)
+	name := fmt.Sprint(node)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w, ok := r.weights[node]; ok {
+		if w == weight {
+			return
+		}
+		r.removePoints(node)
+		r.totalWeight -= w
+	} else {
+		r.loads[node] = new(atomic.Int64)
+	}
+	r.weights[node] = weight
+	r.totalWeight += weight
+
+	for i := range weight * r.cfg.virtualNodes {
+		r.points = append(r.points, ringPoint[N]{
+			hash: r.cfg.hash(name + "#" + strconv.Itoa(i)),
+			node: node,
+		})
+	}
+	slices.SortStableFunc(r.points, func(a, b ringPoint[N]) int {
+		switch {
+		case a.hash < b.hash:
+			return -1
+		case a.hash > b.hash:
+			return 1
+		}
+		return 0
+	})
+}
+
+// Remove removes node. Its in-flight load is forgotten.
+func (r *HashRing[N]) Remove(node N) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.weights[node]
+	if !ok {
+		return
+	}
+	r.removePoints(node)
+	delete(r.weights, node)
+	r.totalWeight -= w
+	r.totalLoad.Add(-r.loads[node].Load())
+	delete(r.loads, node)
+}
+
+func (r *HashRing[N]) removePoints(node N) {
+	r.points = slices.DeleteFunc(r.points, func(p ringPoint[N]) bool { return p.node == node })
+}
+
+// Get returns the node responsible for key. With bounded loads, nodes at
+// capacity are skipped, but no load is taken; see Acquire.
+func (r *HashRing[N]) Get(key string) (node N, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.points) == 0 {
+		return node, false
+	}
+	i := r.search(key)
+	if r.cfg.loadFactor == 0 {
+		return r.points[i].node, true
+	}
+	for n := range len(r.points) {
+		p := r.points[(i+n)%len(r.points)]
+		if r.loads[p.node].Load() < r.capacity(p.node) {
+			return p.node, true
+		}
+	}
+	return r.points[i].node, true
+}
+
+// Acquire returns the node responsible for key and counts one unit of
+// in-flight load against it. Without bounded loads it behaves like Get and
+// still tracks load. Every successful Acquire must be paired with Release.
+func (r *HashRing[N]) Acquire(key string) (node N, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.points) == 0 {
+		return node, false
+	}
+	i := r.search(key)
+	if r.cfg.loadFactor == 0 {
+		node = r.points[i].node
+		r.loads[node].Add(1)
+		r.totalLoad.Add(1)
+		return node, true
+	}
+	for n := range len(r.points) {
+		p := r.points[(i+n)%len(r.points)]
+		load := r.loads[p.node]
+		for {
+			l := load.Load()
+			if l >= r.capacity(p.node) {
+				break
+			}
+			if load.CompareAndSwap(l, l+1) {
+				r.totalLoad.Add(1)
+				return p.node, true
+			}
+		}
+	}
+	// Only reachable with a racing capacity change; fall back to the owner.
+	node = r.points[i].node
+	r.loads[node].Add(1)
+	r.totalLoad.Add(1)
+	return node, true
+}
+
+// Release gives back one unit of load taken by Acquire.
+func (r *HashRing[N]) Release(node N) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	load, ok := r.loads[node]
+	if !ok {
+		return
+	}
+	for l := load.Load(); l > 0; l = load.Load() {
+		if load.CompareAndSwap(l, l-1) {
+			r.totalLoad.Add(-1)
+			return
+		}
+	}
+}
+
+// Load returns the in-flight load of node.
+func (r *HashRing[N]) Load(node N) int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if load, ok := r.loads[node]; ok {
+		return load.Load()
+	}
+	return 0
+}
+
+// capacity returns the maximum in-flight load of node. Callers hold r.mu.
+func (r *HashRing[N]) capacity(node N) int64 {
+	share := float64(r.totalLoad.Load()+1) * float64(r.weights[node]) / float64(r.totalWeight)
+	return int64(math.Ceil(r.cfg.loadFactor * share))
+}
+
+// search returns the index of the first point clockwise from the hash of key.
+// Callers hold r.mu and ensure the ring is not empty.
+func (r *HashRing[N]) search(key string) int {
+	h := r.cfg.hash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return i
+}
+
+// Nodes returns the member nodes in no particular order.
+func (r *HashRing[N]) Nodes() []N {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	nodes := make([]N, 0, len(r.weights))
+	for n := range r.weights {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// Len returns the number of member nodes.
+func (r *HashRing[N]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.weights)
+}
+
+type (
+	// RendezvousHash implements highest random weight (HRW) hashing: each key
+	// goes to the node with the highest weighted score of hash(key, node).
+	// Removing a node only moves that node's keys, and no ring has to be
+	// stored, but lookups are O(n) in the number of nodes.
+	//
+	// RendezvousHash is safe for concurrent use.
+	//
+	// Type parameters:
+	//   - N: The node type, must be comparable
+	RendezvousHash[N comparable] struct {
+		mu    sync.RWMutex
+		hash  Hasher[string]
+		nodes []rendezvousNode[N]
+	}
+
+	rendezvousNode[N comparable] struct {
+		node   N
+		hash   uint64
+		weight float64
+	}
+)
+
+// NewRendezvousHash creates an empty RendezvousHash. A nil hash uses HashString.
+func NewRendezvousHash[N comparable](hash Hasher[string]) *RendezvousHash[N] {
+	if hash == nil {
+		hash = HashString
+	}
+	return &RendezvousHash[N]{hash: hash}
+}
+
+// Add adds node with the given weight, or updates its weight if present.
+func (r *RendezvousHash[N]) Add(node N, weight int) {
+	n := rendezvousNode[N]{node: node, hash: r.hash(fmt.Sprint(node)), weight: float64(max(weight, 1))}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.index(node); i >= 0 {
+		r.nodes[i] = n
+		return
+	}
+	r.nodes = append(r.nodes, n)
+}
+
+// Remove removes node.
+func (r *RendezvousHash[N]) Remove(node N) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.index(node); i >= 0 {
+		r.nodes = slices.Delete(r.nodes, i, i+1)
+	}
+}
+
+func (r *RendezvousHash[N]) index(node N) int {
+	return slices.IndexFunc(r.nodes, func(n rendezvousNode[N]) bool { return n.node == node })
+}
+
+// Get returns the node with the highest score for key.
+func (r *RendezvousHash[N]) Get(key string) (node N, ok bool) {
+	h := r.hash(key)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	best := math.Inf(-1)
+	for _, n := range r.nodes {
+		// Weighted HRW (Schindelhauer & Schomaker): -w/ln(u) with u uniform in (0, 1).
+		u := (float64(HashUint64(h^n.hash)>>11) + 0.5) / (1 << 53)
+		if score := -n.weight / math.Log(u); score > best {
+			best, node, ok = score, n.node, true
+		}
+	}
+	return node, ok
+}
+
+// Nodes returns the member nodes in insertion order.
+func (r *RendezvousHash[N]) Nodes() []N {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	nodes := make([]N, len(r.nodes))
+	for i, n := range r.nodes {
+		nodes[i] = n.node
+	}
+	return nodes
+}
+
+// Len returns the number of member nodes.
+func (r *RendezvousHash[N]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.nodes)
+}
+
+// JumpHash implements jump consistent hashing (Lamping & Veach), which maps
+// keys onto numbered buckets in O(ln n) time without storing a ring.
+//
+// Buckets are the nodes in insertion order, and weights are ignored. Adding a
+// node moves only the keys it takes over. Removing the last node is equally
+// cheap; removing any other node moves the last node into its bucket, so the
+// keys of both nodes move.
+//
+// JumpHash is safe for concurrent use.
+//
+// Type parameters:
+//   - N: The node type, must be comparable
+type JumpHash[N comparable] struct {
+	mu    sync.RWMutex
+	hash  Hasher[string]
+	nodes []N
+}
+
+// NewJumpHash creates an empty JumpHash. A nil hash uses HashString.
+func NewJumpHash[N comparable](hash Hasher[string]) *JumpHash[N] {
+	if hash == nil {
+		hash = HashString
+	}
+	return &JumpHash[N]{hash: hash}
+}
+
+// Add appends node as the last bucket. weight is ignored.
+func (j *JumpHash[N]) Add(node N, _ int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !slices.Contains(j.nodes, node) {
+		j.nodes = append(j.nodes, node)
+	}
+}
+
+// Remove removes node, moving the last node into its bucket.
+func (j *JumpHash[N]) Remove(node N) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	i := slices.Index(j.nodes, node)
+	if i < 0 {
+		return
+	}
+	last := len(j.nodes) - 1
+	j.nodes[i] = j.nodes[last]
+	var zero N
+	j.nodes[last] = zero
+	j.nodes = j.nodes[:last]
+}
+
+// Get returns the node of the bucket key jumps to.
+func (j *JumpHash[N]) Get(key string) (node N, ok bool) {
+	h := j.hash(key)
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if len(j.nodes) == 0 {
+		return node, false
+	}
+	return j.nodes[jumpHash(h, len(j.nodes))], true
+}
+
+// Nodes returns the member nodes in bucket order.
+func (j *JumpHash[N]) Nodes() []N {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return slices.Clone(j.nodes)
+}
+
+// Len returns the number of member nodes.
+func (j *JumpHash[N]) Len() int {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return len(j.nodes)
+}
+
+// jumpHash returns the bucket in [0, buckets) of key.
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
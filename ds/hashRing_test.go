@@ -0,0 +1,209 @@
+package ds
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ringKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func assignments(h ConsistentHash[string], keys []string) map[string]string {
+	m := make(map[string]string, len(keys))
+	for _, k := range keys {
+		m[k], _ = h.Get(k)
+	}
+	return m
+}
+
+func TestConsistentHash(t *testing.T) {
+	impls := map[string]func() ConsistentHash[string]{
+		"HashRing":       func() ConsistentHash[string] { return NewHashRing[string]() },
+		"RendezvousHash": func() ConsistentHash[string] { return NewRendezvousHash[string](nil) },
+		"JumpHash":       func() ConsistentHash[string] { return NewJumpHash[string](nil) },
+	}
+	keys := ringKeys(20000)
+	nodes := []string{"a", "b", "c", "d", "e"}
+
+	for name, newHash := range impls {
+		t.Run(name, func(t *testing.T) {
+			t.Run("空环", func(t *testing.T) {
+				_, ok := newHash().Get("x")
+				assert.False(t, ok)
+			})
+
+			t.Run("成员管理", func(t *testing.T) {
+				h := newHash()
+				for _, n := range nodes {
+					h.Add(n, 1)
+				}
+				h.Add("a", 1)
+				assert.Equal(t, 5, h.Len())
+				assert.ElementsMatch(t, nodes, h.Nodes())
+				h.Remove("c")
+				h.Remove("x")
+				assert.ElementsMatch(t, []string{"a", "b", "d", "e"}, h.Nodes())
+			})
+
+			t.Run("分布均匀且稳定", func(t *testing.T) {
+				h := newHash()
+				for _, n := range nodes {
+					h.Add(n, 1)
+				}
+				before := assignments(h, keys)
+				assert.Equal(t, before, assignments(h, keys))
+
+				counts := map[string]int{}
+				for _, n := range before {
+					counts[n]++
+				}
+				fair := len(keys) / len(nodes)
+				for _, n := range nodes {
+					assert.InDelta(t, fair, counts[n], float64(fair)*0.25, "node %s", n)
+				}
+			})
+
+			t.Run("增加节点只迁移必要的 key", func(t *testing.T) {
+				h := newHash()
+				for _, n := range nodes {
+					h.Add(n, 1)
+				}
+				before := assignments(h, keys)
+				h.Add("f", 1)
+				after := assignments(h, keys)
+				moved := 0
+				for k, n := range after {
+					if n != before[k] {
+						moved++
+						assert.Equal(t, "f", n, "key 只能迁移到新节点")
+					}
+				}
+				// Ideal is 1/6 of the keys.
+				assert.InDelta(t, len(keys)/6, moved, float64(len(keys))*0.05)
+			})
+
+			t.Run("删除节点只迁移该节点的 key", func(t *testing.T) {
+				h := newHash()
+				for _, n := range nodes {
+					h.Add(n, 1)
+				}
+				before := assignments(h, keys)
+				// Removing the last node keeps JumpHash's remapping minimal too.
+				h.Remove("e")
+				after := assignments(h, keys)
+				for k, n := range after {
+					if before[k] != "e" {
+						assert.Equal(t, before[k], n)
+					} else {
+						assert.NotEqual(t, "e", n)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestHashRingWeights(t *testing.T) {
+	for name, h := range map[string]ConsistentHash[string]{
+		"HashRing":       NewHashRing[string](),
+		"RendezvousHash": NewRendezvousHash[string](nil),
+	} {
+		t.Run(name+" 权重", func(t *testing.T) {
+			h.Add("small", 1)
+			h.Add("big", 3)
+			counts := map[string]int{}
+			for _, n := range assignments(h, ringKeys(20000)) {
+				counts[n]++
+			}
+			assert.InDelta(t, 15000, counts["big"], 1000)
+
+			h.Add("big", 1)
+			counts = map[string]int{}
+			for _, n := range assignments(h, ringKeys(20000)) {
+				counts[n]++
+			}
+			assert.InDelta(t, 10000, counts["big"], 1500)
+		})
+	}
+}
+
+func TestHashRingBoundedLoad(t *testing.T) {
+	t.Run("HashRing 有界负载", func(t *testing.T) {
+		ring := NewHashRing[string](WithBoundedLoad(1.25))
+		for _, n := range []string{"a", "b", "c", "d"} {
+			ring.Add(n, 1)
+		}
+
+		// Every acquisition uses the same key, so without bounds one node would take everything.
+		const total = 1000
+		for range total {
+			_, ok := ring.Acquire("hot")
+			assert.True(t, ok)
+		}
+		limit := int64(math.Ceil(1.25 * total / 4))
+		var sum int64
+		for _, n := range ring.Nodes() {
+			assert.LessOrEqual(t, ring.Load(n), limit, "node %s", n)
+			sum += ring.Load(n)
+		}
+		assert.Equal(t, int64(total), sum)
+
+		for _, n := range ring.Nodes() {
+			for ring.Load(n) > 0 {
+				ring.Release(n)
+			}
+		}
+		ring.Release("a") // extra releases are ignored
+		assert.Equal(t, int64(0), ring.Load("a"))
+		owner, _ := ring.Get("hot")
+		node, _ := ring.Acquire("hot")
+		assert.Equal(t, owner, node, "空载时回到原节点")
+		ring.Release(node)
+	})
+
+	t.Run("并发 Acquire/Release", func(t *testing.T) {
+		ring := NewHashRing[int](WithBoundedLoad(0))
+		for i := range 8 {
+			ring.Add(i, 1)
+		}
+		var wg sync.WaitGroup
+		for g := range 8 {
+			wg.Go(func() {
+				for i := range 1000 {
+					n, _ := ring.Acquire(strconv.Itoa(g*1000 + i))
+					ring.Release(n)
+				}
+			})
+		}
+		wg.Wait()
+		for i := range 8 {
+			assert.Equal(t, int64(0), ring.Load(i))
+		}
+	})
+}
+
+func TestJumpHashRemoveMiddle(t *testing.T) {
+	t.Run("JumpHash 删除中间节点", func(t *testing.T) {
+		h := NewJumpHash[string](nil)
+		for _, n := range []string{"a", "b", "c", "d"} {
+			h.Add(n, 1)
+		}
+		before := assignments(h, ringKeys(10000))
+		h.Remove("b")
+		assert.Equal(t, []string{"a", "d", "c"}, h.Nodes())
+		for k, n := range assignments(h, ringKeys(10000)) {
+			if before[k] != "b" && before[k] != "d" {
+				assert.Equal(t, before[k], n)
+			}
+		}
+	})
+}
@@ -1,7 +1,10 @@
 package ds
 
 import (
+	"encoding/json"
 	"fmt"
+	"iter"
+	"maps"
 )
 
 // HashSet is a simple set implementation using Go's built-in map.
@@ -11,7 +14,11 @@ import (
 //   - T: The element type, must be comparable
 type HashSet[T comparable] map[T]struct{}
 
-var _ Set[int] = HashSet[int](nil)
+var (
+	_ Set[int]         = HashSet[int](nil)
+	_ json.Marshaler   = HashSet[int](nil)
+	_ json.Unmarshaler = (*HashSet[int])(nil)
+)
 
 // NewHashSet creates a new HashSet with optional initial elements.
 //
@@ -48,6 +55,11 @@ func (s HashSet[T]) Values() (values []T) {
 	return
 }
 
+// All returns an iterator over all elements in the set, in no particular order.
+func (s HashSet[T]) All() iter.Seq[T] {
+	return maps.Keys(s)
+}
+
 // Remove removes one or more elements from the set.
 // Non-existent elements are ignored.
 func (s HashSet[T]) Remove(values ...T) bool {
@@ -122,3 +134,48 @@ func (s HashSet[T]) SymmetricDifference(s1 Set[T]) Set[T] {
 	}
 	return result
 }
+
+// Clone returns a copy of the set.
+func (s HashSet[T]) Clone() Set[T] {
+	return maps.Clone(s)
+}
+
+// Equal reports whether both sets contain the same elements.
+func (s HashSet[T]) Equal(s1 Set[T]) bool {
+	return setEqual(s, s1)
+}
+
+// IsSubset reports whether every element of this set is in the other.
+func (s HashSet[T]) IsSubset(s1 Set[T]) bool {
+	return setIsSubset(s, s1)
+}
+
+// MarshalJSON encodes the set as a JSON array, in no particular order.
+func (s HashSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Values())
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of a JSON array.
+func (s *HashSet[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = NewHashSet(values...)
+	return nil
+}
+
+// GobEncode encodes the set as a gob stream of its elements.
+func (s HashSet[T]) GobEncode() ([]byte, error) {
+	return GobCodec[[]T]{}.Encode(s.Values())
+}
+
+// GobDecode replaces the contents of the set with the decoded elements.
+func (s *HashSet[T]) GobDecode(data []byte) error {
+	values, err := GobCodec[[]T]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+	*s = NewHashSet(values...)
+	return nil
+}
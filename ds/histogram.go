@@ -0,0 +1,188 @@
+package ds
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// histogramSubBits is the number of significant bits kept per value; the
+	// relative error of a recorded value is below 2^-histogramSubBits (< 1%).
+	histogramSubBits = 7
+	histogramSubSize = 1 << histogramSubBits
+	// histogramBuckets covers every non-negative int64.
+	histogramBuckets = (64 - histogramSubBits) << histogramSubBits
+)
+
+// Histogram records the distribution of non-negative int64 values, e.g.
+// latencies in nanoseconds, and answers quantile queries such as p50 and p99.
+//
+// Values are counted in log-linear buckets, as in an HDR histogram: values
+// below 128 are exact, larger values keep 7 significant bits, so quantiles have
+// a relative error below 1% and memory use is fixed regardless of the number
+// of observations.
+//
+// Histogram is lock-free and safe for concurrent use. Reads during concurrent
+// writes see an approximate, not an atomic, snapshot.
+type Histogram struct {
+	counts [histogramBuckets]atomic.Int64
+	count  atomic.Int64
+	sum    atomic.Int64
+	min    atomic.Int64
+	max    atomic.Int64
+}
+
+// NewHistogram creates an empty Histogram.
+//
+// Example:
+//
+//	h := NewHistogram()
+//	h.ObserveDuration(time.Since(start))
+//	p99 := time.Duration(h.Quantile(0.99))
+func NewHistogram() *Histogram {
+	h := &Histogram{}
+	h.min.Store(math.MaxInt64)
+	return h
+}
+
+// histogramIndex returns the bucket of v.
+func histogramIndex(v int64) int {
+	u := uint64(v)
+	if u < histogramSubSize {
+		return int(u)
+	}
+	shift := bits.Len64(u) - histogramSubBits - 1
+	return (shift+1)<<histogramSubBits + int(u>>shift) - histogramSubSize
+}
+
+// histogramBounds returns the lowest value and the width of bucket i.
+func histogramBounds(i int) (lo, width int64) {
+	if i < histogramSubSize {
+		return int64(i), 1
+	}
+	shift := i>>histogramSubBits - 1
+	mantissa := int64(i&(histogramSubSize-1)) + histogramSubSize
+	return mantissa << shift, 1 << shift
+}
+
+// Observe records v. Negative values are recorded as 0.
+func (h *Histogram) Observe(v int64) {
+	v = max(0, v)
+	h.counts[histogramIndex(v)].Add(1)
+	h.count.Add(1)
+	h.sum.Add(v)
+	for old := h.min.Load(); v < old && !h.min.CompareAndSwap(old, v); old = h.min.Load() {
+	}
+	for old := h.max.Load(); v > old && !h.max.CompareAndSwap(old, v); old = h.max.Load() {
+	}
+}
+
+// ObserveDuration records d in nanoseconds.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(int64(d))
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() int64 {
+	return h.count.Load()
+}
+
+// Sum returns the sum of all observations.
+func (h *Histogram) Sum() int64 {
+	return h.sum.Load()
+}
+
+// Mean returns the mean of all observations, 0 if there are none.
+func (h *Histogram) Mean() float64 {
+	n := h.count.Load()
+	if n == 0 {
+		return 0
+	}
+	return float64(h.sum.Load()) / float64(n)
+}
+
+// Min returns the smallest observation, 0 if there are none.
+func (h *Histogram) Min() int64 {
+	if h.count.Load() == 0 {
+		return 0
+	}
+	return h.min.Load()
+}
+
+// Max returns the largest observation, 0 if there are none.
+func (h *Histogram) Max() int64 {
+	return h.max.Load()
+}
+
+// Quantile returns the value below which a fraction q of the observations fall.
+// q is clamped to [0, 1]; Quantile returns 0 if there are no observations.
+func (h *Histogram) Quantile(q float64) int64 {
+	return h.Quantiles(q)[0]
+}
+
+// Quantiles returns the values for several quantiles in a single pass.
+// qs must be in ascending order.
+//
+// Example:
+//
+//	ps := h.Quantiles(0.5, 0.9, 0.99) // p50, p90, p99
+func (h *Histogram) Quantiles(qs ...float64) []int64 {
+	var (
+		res   = make([]int64, len(qs))
+		total = h.count.Load()
+	)
+	if total == 0 || len(qs) == 0 {
+		return res
+	}
+	lowest, highest := h.min.Load(), h.max.Load()
+
+	var (
+		seen int64
+		j    int
+	)
+	for i := range h.counts {
+		seen += h.counts[i].Load()
+		for ; j < len(qs); j++ {
+			rank := quantileRank(qs[j], total)
+			if seen < rank {
+				break
+			}
+			switch rank {
+			case 1:
+				res[j] = lowest
+			case total:
+				res[j] = highest
+			default:
+				lo, width := histogramBounds(i)
+				res[j] = min(max(lo+(width-1)/2, lowest), highest)
+			}
+		}
+		if j == len(qs) {
+			return res
+		}
+	}
+	// Observations raced with the scan; the remaining ranks fall at the top.
+	for ; j < len(qs); j++ {
+		res[j] = highest
+	}
+	return res
+}
+
+// quantileRank returns the 1-based rank of quantile q among total observations.
+func quantileRank(q float64, total int64) int64 {
+	q = min(max(q, 0), 1)
+	return max(1, int64(math.Ceil(q*float64(total))))
+}
+
+// Reset discards all observations.
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.count.Store(0)
+	h.sum.Store(0)
+	h.min.Store(math.MaxInt64)
+	h.max.Store(0)
+}
@@ -0,0 +1,87 @@
+package ds
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	t.Run("Histogram 测试", func(t *testing.T) {
+		t.Run("空直方图", func(t *testing.T) {
+			h := NewHistogram()
+			assert.Equal(t, int64(0), h.Count())
+			assert.Equal(t, int64(0), h.Min())
+			assert.Equal(t, int64(0), h.Quantile(0.99))
+			assert.Equal(t, 0.0, h.Mean())
+		})
+
+		t.Run("小值精确", func(t *testing.T) {
+			h := NewHistogram()
+			for v := range int64(100) {
+				h.Observe(v + 1)
+			}
+			assert.Equal(t, int64(100), h.Count())
+			assert.Equal(t, int64(1), h.Min())
+			assert.Equal(t, int64(100), h.Max())
+			assert.Equal(t, 50.5, h.Mean())
+			assert.Equal(t, []int64{1, 50, 99, 100}, h.Quantiles(0, 0.5, 0.99, 1))
+		})
+
+		t.Run("大值相对误差小于1%", func(t *testing.T) {
+			h := NewHistogram()
+			for i := range int64(10000) {
+				h.ObserveDuration(time.Duration(i+1) * time.Microsecond)
+			}
+			for _, q := range []float64{0.5, 0.9, 0.99, 0.999} {
+				want := q * float64(10000*time.Microsecond)
+				assert.InEpsilon(t, want, float64(h.Quantile(q)), 0.01)
+			}
+			assert.Equal(t, int64(10000*time.Microsecond), h.Quantile(1))
+		})
+
+		t.Run("负值记为0", func(t *testing.T) {
+			h := NewHistogram()
+			h.Observe(-5)
+			assert.Equal(t, int64(0), h.Max())
+			assert.Equal(t, int64(0), h.Quantile(0.5))
+		})
+
+		t.Run("Reset", func(t *testing.T) {
+			h := NewHistogram()
+			h.Observe(42)
+			h.Reset()
+			assert.Equal(t, int64(0), h.Count())
+			assert.Equal(t, int64(0), h.Quantile(0.5))
+		})
+
+		t.Run("并发记录", func(t *testing.T) {
+			h := NewHistogram()
+			var wg sync.WaitGroup
+			for range 8 {
+				wg.Go(func() {
+					for v := range int64(1000) {
+						h.Observe(v)
+					}
+				})
+			}
+			wg.Wait()
+			assert.Equal(t, int64(8000), h.Count())
+			assert.Equal(t, int64(999), h.Max())
+		})
+	})
+}
+
+func TestHistogramIndex(t *testing.T) {
+	t.Run("桶边界连续", func(t *testing.T) {
+		for i := range histogramBuckets - 1 {
+			lo, width := histogramBounds(i)
+			next, _ := histogramBounds(i + 1)
+			assert.Equal(t, lo+width, next)
+			assert.Equal(t, i, histogramIndex(lo))
+			assert.Equal(t, i, histogramIndex(lo+width-1))
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package ds
+
+import (
+	"encoding"
+	"math"
+	"math/bits"
+	"sync"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*HyperLogLog[string])(nil)
+	_ encoding.BinaryUnmarshaler = (*HyperLogLog[string])(nil)
+)
+
+const (
+	hllMagic   = 'H'
+	hllVersion = 1
+
+	// MinHyperLogLogPrecision and MaxHyperLogLogPrecision bound the precision of a HyperLogLog.
+	MinHyperLogLogPrecision = 4
+	MaxHyperLogLogPrecision = 18
+	// DefaultHyperLogLogPrecision uses 16 KiB of registers for a standard error of about 0.8%.
+	DefaultHyperLogLogPrecision = 14
+)
+
+// HyperLogLog estimates the number of distinct elements (cardinality) of a
+// stream in fixed memory: 2^precision one-byte registers, with a standard
+// error of about 1.04/sqrt(2^precision).
+//
+// HyperLogLog is not safe for concurrent use; see SyncHyperLogLog.
+//
+// Type parameters:
+//   - T: The element type
+type HyperLogLog[T any] struct {
+	p         uint8
+	registers []uint8
+	hash      Hasher[T]
+}
+
+// NewHyperLogLog creates a HyperLogLog.
+//
+// Parameters:
+//   - precision: Number of index bits, clamped to [MinHyperLogLogPrecision, MaxHyperLogLogPrecision].
+//     Use 0 for DefaultHyperLogLogPrecision.
+//   - hash: Hasher of the elements
+//
+// Example:
+//
+//	visitors := NewHyperLogLog(0, HashString)
+//	visitors.Add(userID)
+//	fmt.Println(visitors.Count()) // ≈ number of distinct users
+func NewHyperLogLog[T any](precision uint8, hash Hasher[T]) *HyperLogLog[T] {
+	if hash == nil {
+		panic("hyperloglog: nil hasher")
+	}
+	if precision == 0 {
+		precision = DefaultHyperLogLogPrecision
+	}
+	precision = min(max(precision, MinHyperLogLogPrecision), MaxHyperLogLogPrecision)
+	return &HyperLogLog[T]{
+		p:         precision,
+		registers: make([]uint8, 1<<precision),
+		hash:      hash,
+	}
+}
+
+// Add adds v to the set.
+func (h *HyperLogLog[T]) Add(v T) {
+	x := h.hash(v)
+	idx := x >> (64 - h.p)
+	// Rank of the first set bit in the remaining bits; the sentinel bit bounds it.
+	rank := uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1))) + 1
+	h.registers[idx] = max(h.registers[idx], rank)
+}
+
+// Count returns the estimated number of distinct elements added.
+func (h *HyperLogLog[T]) Count() uint64 {
+	var (
+		m     = float64(len(h.registers))
+		sum   float64
+		zeros int
+	)
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	estimate := alpha * m * m / sum
+
+	// Small range correction: linear counting while registers are still empty.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Precision returns the number of index bits.
+func (h *HyperLogLog[T]) Precision() uint8 {
+	return h.p
+}
+
+// Merge adds all elements of other to h, so that h estimates the cardinality of
+// the union. Both must have the same precision, otherwise ErrIncompatibleSketch is returned.
+func (h *HyperLogLog[T]) Merge(other *HyperLogLog[T]) error {
+	if h.p != other.p {
+		return ErrIncompatibleSketch
+	}
+	for i, r := range other.registers {
+		h.registers[i] = max(h.registers[i], r)
+	}
+	return nil
+}
+
+func (h *HyperLogLog[T]) clone() *HyperLogLog[T] {
+	c := *h
+	c.registers = append([]uint8(nil), h.registers...)
+	return &c
+}
+
+// MarshalBinary encodes the registers. The Hasher is not encoded.
+func (h *HyperLogLog[T]) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 3+len(h.registers))
+	b = append(b, hllMagic, hllVersion, h.p)
+	return append(b, h.registers...), nil
+}
+
+// UnmarshalBinary replaces the registers with the decoded data, keeping the Hasher.
+func (h *HyperLogLog[T]) UnmarshalBinary(data []byte) error {
+	r := &sketchReader{b: data}
+	r.header(hllMagic, hllVersion)
+	p := r.u8()
+	if r.err == nil && (p < MinHyperLogLogPrecision || p > MaxHyperLogLogPrecision) {
+		return ErrInvalidEncoding
+	}
+	registers := r.bytes(1 << p)
+	if err := r.done(); err != nil {
+		return err
+	}
+	h.p, h.registers = p, append([]uint8(nil), registers...)
+	return nil
+}
+
+// SyncHyperLogLog is a HyperLogLog safe for concurrent use.
+type SyncHyperLogLog[T any] struct {
+	mutex sync.RWMutex
+	h     *HyperLogLog[T]
+}
+
+// NewSyncHyperLogLog creates a SyncHyperLogLog. See NewHyperLogLog for the parameters.
+func NewSyncHyperLogLog[T any](precision uint8, hash Hasher[T]) *SyncHyperLogLog[T] {
+	return &SyncHyperLogLog[T]{h: NewHyperLogLog(precision, hash)}
+}
+
+// Add adds v to the set.
+func (h *SyncHyperLogLog[T]) Add(v T) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.h.Add(v)
+}
+
+// Count returns the estimated number of distinct elements added.
+func (h *SyncHyperLogLog[T]) Count() uint64 {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.h.Count()
+}
+
+// Merge adds all elements of other to h.
+func (h *SyncHyperLogLog[T]) Merge(other *SyncHyperLogLog[T]) error {
+	other.mutex.RLock()
+	o := other.h.clone()
+	other.mutex.RUnlock()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.h.Merge(o)
+}
+
+// MarshalBinary encodes the registers.
+func (h *SyncHyperLogLog[T]) MarshalBinary() ([]byte, error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.h.MarshalBinary()
+}
+
+// UnmarshalBinary replaces the registers with the decoded data.
+func (h *SyncHyperLogLog[T]) UnmarshalBinary(data []byte) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.h.UnmarshalBinary(data)
+}
@@ -0,0 +1,42 @@
+package ds
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	t.Run("HyperLogLog 测试", func(t *testing.T) {
+		t.Run("基数估计", func(t *testing.T) {
+			for _, n := range []int{10, 1000, 100000} {
+				h := NewHyperLogLog(0, HashString)
+				for i := range n {
+					h.Add(strconv.Itoa(i))
+					h.Add(strconv.Itoa(i)) // duplicates do not count
+				}
+				assert.InEpsilon(t, n, h.Count(), 0.03)
+			}
+		})
+
+		t.Run("合并与序列化", func(t *testing.T) {
+			a := NewHyperLogLog(12, HashUint64)
+			b := NewHyperLogLog(12, HashUint64)
+			for i := range uint64(5000) {
+				a.Add(i)
+				b.Add(i + 2500)
+			}
+			assert.Nil(t, a.Merge(b))
+			assert.InEpsilon(t, 7500, a.Count(), 0.05)
+			assert.ErrorIs(t, a.Merge(NewHyperLogLog(10, HashUint64)), ErrIncompatibleSketch)
+
+			data, err := a.MarshalBinary()
+			assert.Nil(t, err)
+			c := NewSyncHyperLogLog(4, HashUint64)
+			assert.Nil(t, c.UnmarshalBinary(data))
+			assert.Equal(t, a.Count(), c.Count())
+			assert.ErrorIs(t, c.UnmarshalBinary(data[:10]), ErrInvalidEncoding)
+		})
+	})
+}
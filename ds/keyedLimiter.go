@@ -0,0 +1,114 @@
+package ds
+
+import (
+	"context"
+	"time"
+)
+
+// KeyedLimiter keeps an independent RateLimiter per key, e.g. per client IP
+// or API token. Limiters are created on first use by a factory and kept in a
+// Cache: they are evicted after they have been idle for the idle duration
+// and, with NewKeyedLimiterWithCapacity, when more than capacity keys are
+// tracked, least recently used first.
+//
+// Type parameters:
+//   - K: The key type, stored in the Cache under the string its id function returns
+type KeyedLimiter[K comparable] struct {
+	factory func(K) RateLimiter
+	id      func(K) string
+	cache   *Cache[RateLimiter]
+}
+
+// NewKeyedLimiter creates a KeyedLimiter.
+//
+// Parameters:
+//   - factory: Creates the limiter for a new key
+//   - idle: Duration after which an unused limiter is evicted. Use 0 to never evict.
+//
+// Call Release when the limiter is no longer needed to stop the eviction goroutine.
+//
+// Example:
+//
+//	// 5 requests per second per client, burst 10; forget clients idle for 10 minutes
+//	l := NewKeyedLimiter(func(string) RateLimiter { return NewTokenBucket(5, 10) }, 10*time.Minute)
+//	defer l.Release()
+//	if !l.Allow(clientIP) {
+//		return ErrTooManyRequests
+//	}
+func NewKeyedLimiter[K ~string](factory func(K) RateLimiter, idle time.Duration) *KeyedLimiter[K] {
+	return NewKeyedLimiterWithCapacity(factory, idle, 0)
+}
+
+// NewKeyedLimiterWithCapacity creates a KeyedLimiter tracking at most
+// capacity keys. Use 0 for no limit.
+func NewKeyedLimiterWithCapacity[K ~string](factory func(K) RateLimiter, idle time.Duration, capacity int) *KeyedLimiter[K] {
+	return NewKeyedLimiterWithID(factory, func(key K) string { return string(key) }, idle, capacity)
+}
+
+// NewKeyedLimiterWithID creates a KeyedLimiter for keys of any comparable
+// type. id converts a key to the string it is tracked under and must return
+// the same string for equal keys and different strings for different keys.
+// Use 0 capacity for no limit.
+//
+// Example:
+//
+//	// 100 requests per minute per tenant and route
+//	type route struct{ Tenant, Path string }
+//	l := NewKeyedLimiterWithID(
+//		func(route) RateLimiter { return NewSlidingWindowLimiter(100, time.Minute) },
+//		func(r route) string { return r.Tenant + "\x00" + r.Path },
+//		time.Hour, 10000,
+//	)
+func NewKeyedLimiterWithID[K comparable](factory func(K) RateLimiter, id func(K) string, idle time.Duration, capacity int) *KeyedLimiter[K] {
+	if factory == nil {
+		panic("keyed limiter: nil factory")
+	}
+	if id == nil {
+		panic("keyed limiter: nil id")
+	}
+	idle = max(0, idle)
+	return &KeyedLimiter[K]{
+		factory: factory,
+		id:      id,
+		cache:   NewCacheWithCapacity[RateLimiter](idle, idle, capacity),
+	}
+}
+
+// Get returns the limiter for key, creating it if needed.
+func (l *KeyedLimiter[K]) Get(key K) RateLimiter {
+	v, _ := l.cache.GetOrSet(l.id(key), func() RateLimiter { return l.factory(key) })
+	return v
+}
+
+// Allow reports whether an event for key may happen now.
+func (l *KeyedLimiter[K]) Allow(key K) bool {
+	return l.Get(key).Allow()
+}
+
+// Reserve reserves capacity for one event for key.
+func (l *KeyedLimiter[K]) Reserve(key K) *Reservation {
+	return l.Get(key).Reserve()
+}
+
+// Wait blocks until an event for key may happen or ctx is done.
+func (l *KeyedLimiter[K]) Wait(ctx context.Context, key K) error {
+	return l.Get(key).Wait(ctx)
+}
+
+// Delete removes the limiter for key.
+func (l *KeyedLimiter[K]) Delete(key K) {
+	l.cache.Delete(l.id(key))
+}
+
+// Len returns the number of tracked keys.
+func (l *KeyedLimiter[K]) Len() int {
+	return l.cache.Len()
+}
+
+// Release stops the eviction goroutine and releases resources.
+//
+// Returns:
+//   - error: Always returns nil
+func (l *KeyedLimiter[K]) Release() error {
+	return l.cache.Release()
+}
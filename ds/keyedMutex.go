@@ -0,0 +1,150 @@
+package ds
+
+import (
+	"context"
+	"hash/maphash"
+	"slices"
+)
+
+// DefaultKeyedMutexStripes is the number of stripes used when NewKeyedMutex gets stripes <= 0.
+const DefaultKeyedMutexStripes = 256
+
+// KeyedMutex is a reader/writer lock per key, for keys that are not known in
+// advance such as user IDs or file paths.
+//
+// Keys are hashed onto a fixed number of stripes backed by RWBitMutex slots,
+// so memory does not grow with the number of keys. Distinct keys may share a
+// stripe and then exclude each other; more stripes make that rarer.
+//
+// Type parameters:
+//   - K: The key type, must be comparable
+type KeyedMutex[K comparable] struct {
+	seed    maphash.Seed
+	stripes int
+	words   []RWBitMutex
+}
+
+// NewKeyedMutex creates a KeyedMutex with the given number of stripes.
+//
+// Example:
+//
+//	var accounts = NewKeyedMutex[string](0)
+//
+//	func transfer(ctx context.Context, from, to string) error {
+//		accounts.LockMany(from, to) // deadlock-free regardless of argument order
+//		defer accounts.UnlockMany(from, to)
+//		// ...
+//	}
+func NewKeyedMutex[K comparable](stripes int) *KeyedMutex[K] {
+	if stripes <= 0 {
+		stripes = DefaultKeyedMutexStripes
+	}
+	return &KeyedMutex[K]{
+		seed:    maphash.MakeSeed(),
+		stripes: stripes,
+		words:   make([]RWBitMutex, (stripes+rwSlots-1)/rwSlots),
+	}
+}
+
+// stripe returns the stripe index of key.
+func (m *KeyedMutex[K]) stripe(key K) int {
+	return int(maphash.Comparable(m.seed, key) % uint64(m.stripes))
+}
+
+// slot returns the RWBitMutex and slot of a stripe.
+func (m *KeyedMutex[K]) slot(stripe int) (*RWBitMutex, int) {
+	return &m.words[stripe/rwSlots], stripe % rwSlots
+}
+
+// Lock acquires the write lock of key.
+func (m *KeyedMutex[K]) Lock(key K) {
+	w, i := m.slot(m.stripe(key))
+	w.Lock(i)
+}
+
+// LockCtx acquires the write lock of key, giving up when ctx is done.
+func (m *KeyedMutex[K]) LockCtx(ctx context.Context, key K) error {
+	w, i := m.slot(m.stripe(key))
+	return w.LockCtx(ctx, i)
+}
+
+// TryLock acquires the write lock of key without blocking.
+func (m *KeyedMutex[K]) TryLock(key K) bool {
+	w, i := m.slot(m.stripe(key))
+	return w.TryLock(i)
+}
+
+// Unlock releases the write lock of key.
+func (m *KeyedMutex[K]) Unlock(key K) {
+	w, i := m.slot(m.stripe(key))
+	w.Unlock(i)
+}
+
+// RLock acquires a read lock of key.
+func (m *KeyedMutex[K]) RLock(key K) {
+	w, i := m.slot(m.stripe(key))
+	w.RLock(i)
+}
+
+// RLockCtx acquires a read lock of key, giving up when ctx is done.
+func (m *KeyedMutex[K]) RLockCtx(ctx context.Context, key K) error {
+	w, i := m.slot(m.stripe(key))
+	return w.RLockCtx(ctx, i)
+}
+
+// TryRLock acquires a read lock of key without blocking.
+func (m *KeyedMutex[K]) TryRLock(key K) bool {
+	w, i := m.slot(m.stripe(key))
+	return w.TryRLock(i)
+}
+
+// RUnlock releases a read lock of key.
+func (m *KeyedMutex[K]) RUnlock(key K) {
+	w, i := m.slot(m.stripe(key))
+	w.RUnlock(i)
+}
+
+// LockMany acquires the write locks of all keys. Stripes are locked once each,
+// in ascending order, so callers locking overlapping key sets cannot deadlock.
+func (m *KeyedMutex[K]) LockMany(keys ...K) {
+	for _, s := range m.stripesOf(keys) {
+		w, i := m.slot(s)
+		w.Lock(i)
+	}
+}
+
+// LockManyCtx acquires the write locks of all keys like LockMany, but gives up
+// when ctx is done, releasing the locks acquired so far.
+func (m *KeyedMutex[K]) LockManyCtx(ctx context.Context, keys ...K) error {
+	stripes := m.stripesOf(keys)
+	for n, s := range stripes {
+		w, i := m.slot(s)
+		if err := w.LockCtx(ctx, i); err != nil {
+			m.unlockStripes(stripes[:n])
+			return err
+		}
+	}
+	return nil
+}
+
+// UnlockMany releases the write locks of all keys.
+func (m *KeyedMutex[K]) UnlockMany(keys ...K) {
+	m.unlockStripes(m.stripesOf(keys))
+}
+
+func (m *KeyedMutex[K]) unlockStripes(stripes []int) {
+	for _, s := range stripes {
+		w, i := m.slot(s)
+		w.Unlock(i)
+	}
+}
+
+// stripesOf returns the sorted, distinct stripes of keys.
+func (m *KeyedMutex[K]) stripesOf(keys []K) []int {
+	stripes := make([]int, len(keys))
+	for n, k := range keys {
+		stripes[n] = m.stripe(k)
+	}
+	slices.Sort(stripes)
+	return slices.Compact(stripes)
+}
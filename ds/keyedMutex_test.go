@@ -0,0 +1,71 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	t.Run("KeyedMutex 测试", func(t *testing.T) {
+		m := NewKeyedMutex[string](1024)
+
+		t.Run("按key加锁", func(t *testing.T) {
+			m.Lock("a")
+			assert.False(t, m.TryLock("a"))
+			assert.False(t, m.TryRLock("a"))
+			m.Unlock("a")
+			m.RLock("a")
+			assert.True(t, m.TryRLock("a"))
+			m.RUnlock("a")
+			m.RUnlock("a")
+		})
+
+		t.Run("LockCtx 可放弃等待", func(t *testing.T) {
+			m.Lock("busy")
+			defer m.Unlock("busy")
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			assert.ErrorIs(t, m.LockCtx(ctx, "busy"), context.DeadlineExceeded)
+			assert.ErrorIs(t, m.RLockCtx(ctx, "busy"), context.DeadlineExceeded)
+			assert.ErrorIs(t, m.LockManyCtx(ctx, "x", "busy"), context.DeadlineExceeded)
+			// locks taken before giving up are released
+			assert.True(t, m.TryLock("x"))
+			m.Unlock("x")
+		})
+
+		t.Run("LockMany 交叉顺序不死锁", func(t *testing.T) {
+			var (
+				balance = map[string]int{"alice": 1000, "bob": 1000}
+				wg      sync.WaitGroup
+			)
+			for g := range 8 {
+				wg.Go(func() {
+					from, to := "alice", "bob"
+					if g%2 == 1 {
+						from, to = to, from
+					}
+					for range 200 {
+						m.LockMany(from, to)
+						balance[from]--
+						balance[to]++
+						m.UnlockMany(from, to)
+					}
+				})
+			}
+			wg.Wait()
+			assert.Equal(t, 2000, balance["alice"]+balance["bob"])
+		})
+	})
+
+	t.Run("单条带时所有key互斥", func(t *testing.T) {
+		m := NewKeyedMutex[int](1)
+		m.Lock(1)
+		assert.False(t, m.TryLock(2))
+		m.LockMany() // no keys is a no-op
+		m.Unlock(1)
+	})
+}
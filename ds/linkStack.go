@@ -1,5 +1,7 @@
 package ds
 
+import "iter"
+
 var _ Stack[int] = (*LinkStack[int])(nil)
 
 type (
@@ -30,6 +32,7 @@ func NewLinkStack[T any]() *LinkStack[T] {
 func (m *LinkStack[T]) Pop() (value T, exist bool) {
 	value, exist = m.Peek()
 	m.top = m.top.next
+	m.size--
 	return
 }
 
@@ -59,3 +62,14 @@ func (m *LinkStack[T]) Empty() bool {
 func (m *LinkStack[T]) Size() int {
 	return m.size
 }
+
+// All returns an iterator over the elements from top to bottom.
+func (m *LinkStack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := m.top; e != nil; e = e.next {
+			if !yield(e.value) {
+				return
+			}
+		}
+	}
+}
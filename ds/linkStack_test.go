@@ -104,6 +104,17 @@ func TestLinkStackSize(t *testing.T) {
 			s.Push(2)
 			assert.Equal(t, 2, s.Size())
 		})
+
+		t.Run("Pop 后大小减少", func(t *testing.T) {
+			s := NewLinkStack[int]()
+			s.Push(1)
+			s.Push(2)
+			s.Pop()
+			assert.Equal(t, 1, s.Size())
+			s.Pop()
+			assert.Equal(t, 0, s.Size())
+			assert.True(t, s.Empty())
+		})
 	})
 }
 
@@ -1,5 +1,7 @@
 package ds
 
+import "iter"
+
 // Map is a generic interface for thread-safe key-value storage.
 // It provides operations similar to Go's sync.Map but with a more comprehensive API.
 //
@@ -63,4 +65,48 @@ type Map[K comparable, V any] interface {
 
 	// Filter returns a new Map containing only entries that satisfy the filter function.
 	Filter(filter func(K, V) bool) Map[K, V]
+
+	// Len returns the number of entries in the map.
+	Len() int
+
+	// All returns an iterator over all entries in the map, in no particular order.
+	All() iter.Seq2[K, V]
+
+	// Clone returns a shallow copy of the map with the same implementation.
+	Clone() Map[K, V]
+}
+
+// MapEqual reports whether two maps contain the same key/value pairs.
+// Values are compared using ==.
+//
+// Unlike Set.Equal and Set.IsSubset, the Map comparisons are functions rather
+// than methods: comparing values needs V to be comparable or an eq function,
+// which the Map interface cannot require of every V.
+func MapEqual[K, V comparable](a, b Map[K, V]) bool {
+	return MapEqualFunc(a, b, func(x, y V) bool { return x == y })
+}
+
+// MapEqualFunc is like MapEqual, but compares values using eq.
+func MapEqualFunc[K comparable, V any](a, b Map[K, V], eq func(V, V) bool) bool {
+	return a.Len() == b.Len() && MapIsSubsetFunc(a, b, eq)
+}
+
+// MapIsSubset reports whether every key/value pair of a is in b.
+// Values are compared using ==.
+func MapIsSubset[K, V comparable](a, b Map[K, V]) bool {
+	return MapIsSubsetFunc(a, b, func(x, y V) bool { return x == y })
+}
+
+// MapIsSubsetFunc is like MapIsSubset, but compares values using eq.
+func MapIsSubsetFunc[K comparable, V any](a, b Map[K, V], eq func(V, V) bool) bool {
+	if a.Len() > b.Len() {
+		return false
+	}
+	for k, v := range a.All() {
+		w, ok := b.Load(k)
+		if !ok || !eq(v, w) {
+			return false
+		}
+	}
+	return true
 }
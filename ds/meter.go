@@ -0,0 +1,141 @@
+package ds
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// MeterTickInterval is the interval at which the moving averages of a Meter are updated.
+const MeterTickInterval = 5 * time.Second
+
+// Alphas of the 1, 5 and 15 minute exponentially weighted moving averages
+// sampled every MeterTickInterval, as used by Unix load averages.
+var (
+	alpha1m  = 1 - math.Exp(-MeterTickInterval.Seconds()/time.Minute.Seconds())
+	alpha5m  = 1 - math.Exp(-MeterTickInterval.Seconds()/(5*time.Minute).Seconds())
+	alpha15m = 1 - math.Exp(-MeterTickInterval.Seconds()/(15*time.Minute).Seconds())
+)
+
+type (
+	// Meter measures the rate of events with 1, 5 and 15 minute exponentially
+	// weighted moving averages (EWMA) and a mean rate over its lifetime.
+	//
+	// The averages are updated lazily every MeterTickInterval by whichever call
+	// comes first, so a Meter needs no background goroutine.
+	// Meter is lock-free and safe for concurrent use.
+	Meter struct {
+		count     atomic.Int64
+		uncounted atomic.Int64 // events since the last tick
+		lastTick  atomic.Int64 // nanoseconds since start of the last tick
+		rates     [3]ewma
+		start     time.Time
+		now       func() time.Time
+	}
+
+	// ewma is an exponentially weighted moving average of a per-second rate.
+	ewma struct {
+		alpha float64
+		rate  atomic.Uint64 // float64 bits
+		init  atomic.Bool
+	}
+)
+
+// NewMeter creates a Meter.
+//
+// Example:
+//
+//	m := NewMeter()
+//	m.Mark(1) // on every request
+//	fmt.Printf("%.2f req/s over the last minute\n", m.Rate1())
+func NewMeter() *Meter {
+	m := &Meter{start: time.Now(), now: time.Now}
+	m.rates[0].alpha = alpha1m
+	m.rates[1].alpha = alpha5m
+	m.rates[2].alpha = alpha15m
+	return m
+}
+
+// Mark records n events.
+func (m *Meter) Mark(n int64) {
+	m.tickIfNeeded()
+	m.count.Add(n)
+	m.uncounted.Add(n)
+}
+
+// Count returns the total number of events.
+func (m *Meter) Count() int64 {
+	return m.count.Load()
+}
+
+// Rate1 returns the one-minute moving average rate of events per second.
+func (m *Meter) Rate1() float64 {
+	m.tickIfNeeded()
+	return m.rates[0].value()
+}
+
+// Rate5 returns the five-minute moving average rate of events per second.
+func (m *Meter) Rate5() float64 {
+	m.tickIfNeeded()
+	return m.rates[1].value()
+}
+
+// Rate15 returns the fifteen-minute moving average rate of events per second.
+func (m *Meter) Rate15() float64 {
+	m.tickIfNeeded()
+	return m.rates[2].value()
+}
+
+// RateMean returns the mean rate of events per second since the Meter was created.
+func (m *Meter) RateMean() float64 {
+	elapsed := m.now().Sub(m.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(m.count.Load()) / elapsed
+}
+
+// tickIfNeeded updates the moving averages for every tick interval that has
+// passed. Only the goroutine that advances lastTick performs the update.
+func (m *Meter) tickIfNeeded() {
+	var (
+		now  = int64(m.now().Sub(m.start))
+		last = m.lastTick.Load()
+		age  = now - last
+	)
+	if age < int64(MeterTickInterval) {
+		return
+	}
+	ticks := age / int64(MeterTickInterval)
+	if !m.lastTick.CompareAndSwap(last, last+ticks*int64(MeterTickInterval)) {
+		return
+	}
+
+	// The first tick absorbs the uncounted events; the rest only decay.
+	instant := float64(m.uncounted.Swap(0)) / MeterTickInterval.Seconds()
+	for i := range m.rates {
+		m.rates[i].update(instant, ticks-1)
+	}
+}
+
+// update applies one tick with the given instant rate, followed by idle idle ticks.
+func (e *ewma) update(instant float64, idle int64) {
+	decay := math.Pow(1-e.alpha, float64(idle))
+	for {
+		old := e.rate.Load()
+		rate := instant
+		if e.init.Load() {
+			rate = math.Float64frombits(old)
+			rate += e.alpha * (instant - rate)
+		}
+		rate *= decay
+		if e.rate.CompareAndSwap(old, math.Float64bits(rate)) {
+			e.init.Store(true)
+			return
+		}
+	}
+}
+
+func (e *ewma) value() float64 {
+	return math.Float64frombits(e.rate.Load())
+}
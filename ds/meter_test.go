@@ -0,0 +1,47 @@
+package ds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter(t *testing.T) {
+	t.Run("Meter 测试", func(t *testing.T) {
+		clock := newFakeClock()
+		m := NewMeter()
+		m.start, m.now = clock.Now(), clock.Now
+
+		t.Run("首个tick前速率为0", func(t *testing.T) {
+			m.Mark(50)
+			assert.Equal(t, int64(50), m.Count())
+			assert.Equal(t, 0.0, m.Rate1())
+		})
+
+		t.Run("首个tick使用瞬时速率", func(t *testing.T) {
+			clock.Add(MeterTickInterval)
+			assert.InDelta(t, 10, m.Rate1(), 1e-9)
+			assert.InDelta(t, 10, m.Rate5(), 1e-9)
+			assert.InDelta(t, 10, m.Rate15(), 1e-9)
+			assert.InDelta(t, 10, m.RateMean(), 1e-9)
+		})
+
+		t.Run("空闲时指数衰减", func(t *testing.T) {
+			clock.Add(time.Minute)
+			// 12 idle ticks: 10 * (1-alpha)^12 = 10 * e^-1 for the one-minute average
+			assert.InDelta(t, 10/2.718281828, m.Rate1(), 1e-6)
+			assert.Less(t, m.Rate1(), m.Rate5())
+			assert.Less(t, m.Rate5(), m.Rate15())
+		})
+
+		t.Run("稳定速率收敛", func(t *testing.T) {
+			for range 720 {
+				m.Mark(20)
+				clock.Add(MeterTickInterval)
+			}
+			assert.InDelta(t, 4, m.Rate1(), 0.01)
+			assert.InDelta(t, 4, m.Rate15(), 0.1)
+		})
+	})
+}
@@ -1,12 +1,18 @@
 package ds
 
 import (
+	"encoding/json"
+	"iter"
 	"maps"
 	"slices"
 	"sync"
 )
 
-var _ Map[int, int] = (*MutexMap[int, int])(nil)
+var (
+	_ Map[int, int]    = (*MutexMap[int, int])(nil)
+	_ json.Marshaler   = (*MutexMap[int, int])(nil)
+	_ json.Unmarshaler = (*MutexMap[int, int])(nil)
+)
 
 // MutexMap is a thread-safe map implementation using a mutex for synchronization.
 // It provides a simpler alternative to SyncMap with mutex-based concurrency control.
@@ -178,3 +184,64 @@ func (m *MutexMap[K, V]) Filter(filter func(K, V) bool) Map[K, V] {
 	}
 	return result
 }
+
+// All returns an iterator over a snapshot of the entries, in no particular order.
+// Unlike Range, the map may be modified during iteration.
+func (m *MutexMap[K, V]) All() iter.Seq2[K, V] {
+	return maps.All(m.snapshot())
+}
+
+// Clone returns a copy of the map.
+func (m *MutexMap[K, V]) Clone() Map[K, V] {
+	return &MutexMap[K, V]{entries: m.snapshot()}
+}
+
+// MarshalJSON encodes the map as a JSON object.
+func (m *MutexMap[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.snapshot())
+}
+
+// UnmarshalJSON replaces the contents of the map with the entries of a JSON object.
+func (m *MutexMap[K, V]) UnmarshalJSON(data []byte) error {
+	entries := make(map[K]V)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	m.reset(entries)
+	return nil
+}
+
+// GobEncode encodes the map as a gob stream.
+func (m *MutexMap[K, V]) GobEncode() ([]byte, error) {
+	return GobCodec[map[K]V]{}.Encode(m.snapshot())
+}
+
+// GobDecode replaces the contents of the map with the decoded entries.
+func (m *MutexMap[K, V]) GobDecode(data []byte) error {
+	entries, err := GobCodec[map[K]V]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = make(map[K]V)
+	}
+	m.reset(entries)
+	return nil
+}
+
+// snapshot returns a copy of the entries.
+func (m *MutexMap[K, V]) snapshot() map[K]V {
+	m.l.Lock()
+	defer m.l.Unlock()
+	entries := maps.Clone(m.entries)
+	if entries == nil {
+		entries = make(map[K]V)
+	}
+	return entries
+}
+
+func (m *MutexMap[K, V]) reset(entries map[K]V) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.entries = entries
+}
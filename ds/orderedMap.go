@@ -0,0 +1,288 @@
+package ds
+
+import (
+	"cmp"
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// skiplistMaxLevel bounds the height of skiplist towers; with p = 1/4 it
+// comfortably indexes 4^32 entries.
+const skiplistMaxLevel = 32
+
+// skiplistRandomLevel returns a tower height with P(level > n) = 4^-n.
+func skiplistRandomLevel() int {
+	return min(skiplistMaxLevel, 1+bits.TrailingZeros64(rand.Uint64()|1<<63)/2)
+}
+
+type (
+	// OrderedMap is a map that keeps its keys sorted, backed by an indexable skiplist.
+	// Besides point lookups it supports range scans, floor/ceil searches and
+	// rank queries, all in O(log n) expected time.
+	//
+	// OrderedMap is not safe for concurrent use; see ConcurrentSkipList for a
+	// concurrent alternative.
+	//
+	// Type parameters:
+	//   - K: The key type, must be ordered
+	//   - V: The value type
+	OrderedMap[K cmp.Ordered, V any] struct {
+		head  *omNode[K, V]
+		level int
+		len   int
+	}
+
+	omNode[K cmp.Ordered, V any] struct {
+		key   K
+		value V
+		next  []omLink[K, V]
+	}
+
+	// omLink is a forward pointer and the number of level 0 steps it skips.
+	omLink[K cmp.Ordered, V any] struct {
+		node *omNode[K, V]
+		span int
+	}
+)
+
+// NewOrderedMap creates an empty OrderedMap.
+//
+// Example:
+//
+//	m := NewOrderedMap[int, string]()
+//	m.Store(3, "c")
+//	m.Store(1, "a")
+//	for k, v := range m.All() {
+//		fmt.Println(k, v) // 1 a, 3 c
+//	}
+func NewOrderedMap[K cmp.Ordered, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		head:  &omNode[K, V]{next: make([]omLink[K, V], skiplistMaxLevel)},
+		level: 1,
+	}
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[K, V]) Len() int {
+	return m.len
+}
+
+// search returns, per level, the last node with a key less than key and its
+// 1-based position (the head is position 0).
+func (m *OrderedMap[K, V]) search(key K, update *[skiplistMaxLevel]*omNode[K, V], rank *[skiplistMaxLevel]int) {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		if i < m.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i].node != nil && cmp.Less(x.next[i].node.key, key) {
+			rank[i] += x.next[i].span
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+}
+
+// Store sets the value for key.
+func (m *OrderedMap[K, V]) Store(key K, value V) {
+	var (
+		update [skiplistMaxLevel]*omNode[K, V]
+		rank   [skiplistMaxLevel]int
+	)
+	m.search(key, &update, &rank)
+	if n := update[0].next[0].node; n != nil && cmp.Compare(n.key, key) == 0 {
+		n.value = value
+		return
+	}
+
+	level := skiplistRandomLevel()
+	for i := m.level; i < level; i++ {
+		update[i] = m.head
+		m.head.next[i].span = m.len
+	}
+	m.level = max(m.level, level)
+
+	n := &omNode[K, V]{key: key, value: value, next: make([]omLink[K, V], level)}
+	for i := range level {
+		prev := &update[i].next[i]
+		n.next[i] = omLink[K, V]{node: prev.node, span: prev.span - (rank[0] - rank[i])}
+		*prev = omLink[K, V]{node: n, span: rank[0] - rank[i] + 1}
+	}
+	for i := level; i < m.level; i++ {
+		update[i].next[i].span++
+	}
+	m.len++
+}
+
+// Load returns the value stored for key, or false if no value is present.
+func (m *OrderedMap[K, V]) Load(key K) (value V, ok bool) {
+	if n := m.ceil(key); n != nil && cmp.Compare(n.key, key) == 0 {
+		return n.value, true
+	}
+	return value, false
+}
+
+// Delete deletes the value for key and reports whether it was present.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	var (
+		update [skiplistMaxLevel]*omNode[K, V]
+		rank   [skiplistMaxLevel]int
+	)
+	m.search(key, &update, &rank)
+	n := update[0].next[0].node
+	if n == nil || cmp.Compare(n.key, key) != 0 {
+		return false
+	}
+
+	for i := range m.level {
+		prev := &update[i].next[i]
+		if prev.node == n {
+			prev.span += n.next[i].span - 1
+			prev.node = n.next[i].node
+		} else {
+			prev.span--
+		}
+	}
+	for m.level > 1 && m.head.next[m.level-1].node == nil {
+		m.level--
+	}
+	m.len--
+	return true
+}
+
+// ceil returns the first node with a key >= key, or nil.
+func (m *OrderedMap[K, V]) ceil(key K) *omNode[K, V] {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && cmp.Less(x.next[i].node.key, key) {
+			x = x.next[i].node
+		}
+	}
+	return x.next[0].node
+}
+
+// floor returns the last node with a key <= key, or nil.
+func (m *OrderedMap[K, V]) floor(key K) *omNode[K, V] {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && cmp.Compare(x.next[i].node.key, key) <= 0 {
+			x = x.next[i].node
+		}
+	}
+	if x == m.head {
+		return nil
+	}
+	return x
+}
+
+// Floor returns the entry with the greatest key less than or equal to key.
+func (m *OrderedMap[K, V]) Floor(key K) (k K, v V, ok bool) {
+	return m.entry(m.floor(key))
+}
+
+// Ceil returns the entry with the least key greater than or equal to key.
+func (m *OrderedMap[K, V]) Ceil(key K) (k K, v V, ok bool) {
+	return m.entry(m.ceil(key))
+}
+
+// Min returns the entry with the smallest key.
+func (m *OrderedMap[K, V]) Min() (k K, v V, ok bool) {
+	return m.entry(m.head.next[0].node)
+}
+
+// Max returns the entry with the largest key.
+func (m *OrderedMap[K, V]) Max() (k K, v V, ok bool) {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil {
+			x = x.next[i].node
+		}
+	}
+	if x == m.head {
+		return k, v, false
+	}
+	return m.entry(x)
+}
+
+// Rank returns the number of keys less than key, i.e. the 0-based index key
+// has or would have in sorted order.
+func (m *OrderedMap[K, V]) Rank(key K) int {
+	var (
+		x    = m.head
+		rank int
+	)
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && cmp.Less(x.next[i].node.key, key) {
+			rank += x.next[i].span
+			x = x.next[i].node
+		}
+	}
+	return rank
+}
+
+// At returns the entry at the 0-based index i in sorted order.
+func (m *OrderedMap[K, V]) At(i int) (k K, v V, ok bool) {
+	if i < 0 || i >= m.len {
+		return k, v, false
+	}
+	var (
+		x         = m.head
+		target    = i + 1
+		traversed int
+	)
+	for l := m.level - 1; l >= 0; l-- {
+		for x.next[l].node != nil && traversed+x.next[l].span <= target {
+			traversed += x.next[l].span
+			x = x.next[l].node
+		}
+		if traversed == target {
+			break
+		}
+	}
+	return m.entry(x)
+}
+
+// All returns an iterator over all entries in ascending key order.
+// The map must not be modified during iteration.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return m.from(m.head.next[0].node, nil)
+}
+
+// Range returns an iterator over the entries with lo <= key < hi in ascending key order.
+// The map must not be modified during iteration.
+func (m *OrderedMap[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return m.from(m.ceil(lo), &hi)
+}
+
+// Ascend returns an iterator over the entries with key >= from in ascending key order.
+// The map must not be modified during iteration.
+func (m *OrderedMap[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return m.from(m.ceil(from), nil)
+}
+
+// Keys returns all keys in ascending order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.len)
+	for k := range m.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (m *OrderedMap[K, V]) from(start *omNode[K, V], hi *K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := start; n != nil && (hi == nil || cmp.Less(n.key, *hi)); n = n.next[0].node {
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) entry(n *omNode[K, V]) (k K, v V, ok bool) {
+	if n == nil {
+		return k, v, false
+	}
+	return n.key, n.value, true
+}
@@ -0,0 +1,121 @@
+package ds
+
+import (
+	"iter"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedMap(t *testing.T) {
+	t.Run("OrderedMap 测试", func(t *testing.T) {
+		m := NewOrderedMap[int, string]()
+		for _, k := range []int{5, 1, 9, 3, 7} {
+			m.Store(k, string(rune('a'+k)))
+		}
+
+		t.Run("有序遍历", func(t *testing.T) {
+			assert.Equal(t, 5, m.Len())
+			assert.Equal(t, []int{1, 3, 5, 7, 9}, m.Keys())
+		})
+
+		t.Run("Load 与覆盖写入", func(t *testing.T) {
+			m.Store(3, "x")
+			v, ok := m.Load(3)
+			assert.True(t, ok)
+			assert.Equal(t, "x", v)
+			assert.Equal(t, 5, m.Len())
+			_, ok = m.Load(4)
+			assert.False(t, ok)
+		})
+
+		t.Run("Floor/Ceil", func(t *testing.T) {
+			k, _, ok := m.Floor(6)
+			assert.True(t, ok)
+			assert.Equal(t, 5, k)
+			k, _, _ = m.Floor(7)
+			assert.Equal(t, 7, k)
+			_, _, ok = m.Floor(0)
+			assert.False(t, ok)
+
+			k, _, ok = m.Ceil(6)
+			assert.True(t, ok)
+			assert.Equal(t, 7, k)
+			_, _, ok = m.Ceil(10)
+			assert.False(t, ok)
+		})
+
+		t.Run("Min/Max", func(t *testing.T) {
+			k, _, _ := m.Min()
+			assert.Equal(t, 1, k)
+			k, _, _ = m.Max()
+			assert.Equal(t, 9, k)
+		})
+
+		t.Run("Rank/At", func(t *testing.T) {
+			assert.Equal(t, 0, m.Rank(1))
+			assert.Equal(t, 2, m.Rank(5))
+			assert.Equal(t, 3, m.Rank(6))
+			assert.Equal(t, 5, m.Rank(100))
+			k, _, ok := m.At(3)
+			assert.True(t, ok)
+			assert.Equal(t, 7, k)
+			_, _, ok = m.At(5)
+			assert.False(t, ok)
+		})
+
+		t.Run("范围扫描", func(t *testing.T) {
+			assert.Equal(t, []int{3, 5, 7}, collectKeys(m.Range(2, 9)))
+			assert.Equal(t, []int{7, 9}, collectKeys(m.Ascend(6)))
+			for k := range m.All() {
+				if k == 3 {
+					break
+				}
+			}
+		})
+
+		t.Run("删除", func(t *testing.T) {
+			assert.True(t, m.Delete(5))
+			assert.False(t, m.Delete(5))
+			assert.Equal(t, []int{1, 3, 7, 9}, m.Keys())
+			assert.Equal(t, 2, m.Rank(7))
+		})
+	})
+
+	t.Run("与排序切片一致", func(t *testing.T) {
+		var (
+			m   = NewOrderedMap[int, int]()
+			ref = map[int]int{}
+		)
+		for range 5000 {
+			k := rand.IntN(1000)
+			if rand.IntN(3) == 0 {
+				assert.Equal(t, m.Delete(k), ref[k] != 0)
+				delete(ref, k)
+			} else {
+				m.Store(k, k+1)
+				ref[k] = k + 1
+			}
+		}
+		keys := slices.Sorted(maps.Keys(ref))
+		assert.Equal(t, len(keys), m.Len())
+		assert.Equal(t, keys, m.Keys())
+		for i, k := range keys {
+			assert.Equal(t, i, m.Rank(k))
+			got, _, _ := m.At(i)
+			assert.Equal(t, k, got)
+		}
+	})
+}
+
+// collectKeys collects the keys of seq.
+func collectKeys[K, V any](seq iter.Seq2[K, V]) []K {
+	var keys []K
+	for k := range seq {
+		keys = append(keys, k)
+	}
+	return keys
+}
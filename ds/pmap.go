@@ -0,0 +1,358 @@
+package ds
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+)
+
+const (
+	persistentBits  = 5
+	persistentWidth = 1 << persistentBits
+	persistentMask  = persistentWidth - 1
+)
+
+// pmapSeed is shared by all PMaps so that versions of a map agree on hashes.
+var pmapSeed = maphash.MakeSeed()
+
+type (
+	// PMap is an immutable (persistent) hash map implemented as a hash array
+	// mapped trie (HAMT) with 32-way branching.
+	//
+	// Set and Delete return a new map in O(log32 n) and leave the receiver
+	// unchanged; the two versions share all untouched nodes. That makes PMap a
+	// good fit for snapshots that are swapped atomically and read without locks,
+	// e.g. configuration or routing tables held in an atomic.Pointer. Use
+	// Transient for bulk edits.
+	//
+	// The zero value is not usable; create maps with NewPMap. A PMap is safe for
+	// concurrent reads.
+	//
+	// Type parameters:
+	//   - K: The key type, must be comparable
+	//   - V: The value type
+	PMap[K comparable, V any] struct {
+		root *pmapNode[K, V]
+		len  int
+	}
+
+	// PMapBuilder is a mutable (transient) view of a PMap for bulk edits.
+	// It edits nodes it has copied in place instead of copying them again.
+	// A PMapBuilder is not safe for concurrent use.
+	PMapBuilder[K comparable, V any] struct {
+		root *pmapNode[K, V]
+		len  int
+		edit *pmapEdit
+	}
+
+	// pmapEdit identifies the builder that owns a node; nodes owned by the
+	// current builder may be modified in place.
+	pmapEdit struct{ _ byte }
+
+	// pmapNode is a bitmap-indexed branch, or a collision list once all 64
+	// hash bits are consumed.
+	pmapNode[K comparable, V any] struct {
+		bitmap    uint32
+		entries   []pmapEntry[K, V]
+		collision bool
+		edit      *pmapEdit
+	}
+
+	// pmapEntry is either a key/value leaf or, if child is set, a subtree.
+	pmapEntry[K comparable, V any] struct {
+		hash  uint64
+		key   K
+		value V
+		child *pmapNode[K, V]
+	}
+)
+
+// NewPMap creates an empty PMap.
+//
+// Example:
+//
+//	var routes atomic.Pointer[PMap[string, string]]
+//	routes.Store(NewPMap[string, string]())
+//
+//	// writer: copy-on-write update, readers never block
+//	old := routes.Load()
+//	routes.CompareAndSwap(old, old.Set("/api", "backend-1"))
+//
+//	// reader
+//	backend, ok := routes.Load().Get("/api")
+func NewPMap[K comparable, V any]() *PMap[K, V] {
+	return &PMap[K, V]{}
+}
+
+func pmapHash[K comparable](key K) uint64 {
+	return maphash.Comparable(pmapSeed, key)
+}
+
+// Len returns the number of entries.
+func (m *PMap[K, V]) Len() int {
+	return m.len
+}
+
+// Get returns the value stored for key, or false if no value is present.
+func (m *PMap[K, V]) Get(key K) (value V, ok bool) {
+	return m.root.get(0, pmapHash(key), key)
+}
+
+// Set returns a map with key set to value.
+func (m *PMap[K, V]) Set(key K, value V) *PMap[K, V] {
+	root, added := m.root.set(0, pmapHash(key), key, value, nil)
+	n := m.len
+	if added {
+		n++
+	}
+	return &PMap[K, V]{root: root, len: n}
+}
+
+// Delete returns a map without key. If key is not present, m itself is returned.
+func (m *PMap[K, V]) Delete(key K) *PMap[K, V] {
+	root, removed := m.root.delete(0, pmapHash(key), key, nil)
+	if !removed {
+		return m
+	}
+	return &PMap[K, V]{root: root, len: m.len - 1}
+}
+
+// All returns an iterator over all entries, in no particular but stable order.
+func (m *PMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.root.all(yield)
+	}
+}
+
+// Transient returns a builder initialized with the entries of m.
+// m is not affected by edits to the builder.
+//
+// Example:
+//
+//	b := m.Transient()
+//	for k, v := range updates {
+//		b.Set(k, v)
+//	}
+//	m = b.Map()
+func (m *PMap[K, V]) Transient() *PMapBuilder[K, V] {
+	return &PMapBuilder[K, V]{root: m.root, len: m.len, edit: &pmapEdit{}}
+}
+
+// Len returns the number of entries.
+func (b *PMapBuilder[K, V]) Len() int {
+	return b.len
+}
+
+// Get returns the value stored for key, or false if no value is present.
+func (b *PMapBuilder[K, V]) Get(key K) (value V, ok bool) {
+	return b.root.get(0, pmapHash(key), key)
+}
+
+// Set sets the value for key.
+func (b *PMapBuilder[K, V]) Set(key K, value V) {
+	var added bool
+	b.root, added = b.root.set(0, pmapHash(key), key, value, b.edit)
+	if added {
+		b.len++
+	}
+}
+
+// Delete deletes the value for key.
+func (b *PMapBuilder[K, V]) Delete(key K) {
+	var removed bool
+	b.root, removed = b.root.delete(0, pmapHash(key), key, b.edit)
+	if removed {
+		b.len--
+	}
+}
+
+// Map returns an immutable PMap with the builder's entries. The builder stays
+// usable; further edits do not affect the returned map.
+func (b *PMapBuilder[K, V]) Map() *PMap[K, V] {
+	b.edit = &pmapEdit{}
+	return &PMap[K, V]{root: b.root, len: b.len}
+}
+
+// editable returns n itself if it is owned by edit, otherwise a copy owned by edit.
+func (n *pmapNode[K, V]) editable(edit *pmapEdit) *pmapNode[K, V] {
+	if edit != nil && n.edit == edit {
+		return n
+	}
+	c := *n
+	c.entries = append(make([]pmapEntry[K, V], 0, len(n.entries)+1), n.entries...)
+	c.edit = edit
+	return &c
+}
+
+// index returns the bit of hash at shift and its position in entries.
+func (n *pmapNode[K, V]) index(shift uint, hash uint64) (bit uint32, pos int) {
+	bit = 1 << ((hash >> shift) & persistentMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *pmapNode[K, V]) get(shift uint, hash uint64, key K) (value V, ok bool) {
+	for n != nil {
+		if n.collision {
+			for _, e := range n.entries {
+				if e.key == key {
+					return e.value, true
+				}
+			}
+			return value, false
+		}
+		bit, pos := n.index(shift, hash)
+		if n.bitmap&bit == 0 {
+			return value, false
+		}
+		e := &n.entries[pos]
+		if e.child == nil {
+			if e.hash == hash && e.key == key {
+				return e.value, true
+			}
+			return value, false
+		}
+		n, shift = e.child, shift+persistentBits
+	}
+	return value, false
+}
+
+func (n *pmapNode[K, V]) set(shift uint, hash uint64, key K, value V, edit *pmapEdit) (*pmapNode[K, V], bool) {
+	leaf := pmapEntry[K, V]{hash: hash, key: key, value: value}
+	if n == nil {
+		bit := uint32(1) << ((hash >> shift) & persistentMask)
+		return &pmapNode[K, V]{bitmap: bit, entries: []pmapEntry[K, V]{leaf}, edit: edit}, true
+	}
+
+	if n.collision {
+		n = n.editable(edit)
+		for i := range n.entries {
+			if n.entries[i].key == key {
+				n.entries[i].value = value
+				return n, false
+			}
+		}
+		n.entries = append(n.entries, leaf)
+		return n, true
+	}
+
+	bit, pos := n.index(shift, hash)
+	if n.bitmap&bit == 0 {
+		n = n.editable(edit)
+		n.bitmap |= bit
+		n.entries = append(n.entries, pmapEntry[K, V]{})
+		copy(n.entries[pos+1:], n.entries[pos:])
+		n.entries[pos] = leaf
+		return n, true
+	}
+
+	e := n.entries[pos]
+	switch {
+	case e.child != nil:
+		child, added := e.child.set(shift+persistentBits, hash, key, value, edit)
+		n = n.editable(edit)
+		n.entries[pos].child = child
+		return n, added
+	case e.hash == hash && e.key == key:
+		n = n.editable(edit)
+		n.entries[pos].value = value
+		return n, false
+	default:
+		n = n.editable(edit)
+		n.entries[pos] = pmapEntry[K, V]{child: pmapMerge(shift+persistentBits, e, leaf, edit)}
+		return n, true
+	}
+}
+
+// pmapMerge builds the subtree holding the two leaves a and b.
+func pmapMerge[K comparable, V any](shift uint, a, b pmapEntry[K, V], edit *pmapEdit) *pmapNode[K, V] {
+	if shift >= 64 {
+		return &pmapNode[K, V]{entries: []pmapEntry[K, V]{a, b}, collision: true, edit: edit}
+	}
+	ia, ib := (a.hash>>shift)&persistentMask, (b.hash>>shift)&persistentMask
+	if ia == ib {
+		return &pmapNode[K, V]{
+			bitmap:  1 << ia,
+			entries: []pmapEntry[K, V]{{child: pmapMerge(shift+persistentBits, a, b, edit)}},
+			edit:    edit,
+		}
+	}
+	if ia > ib {
+		a, b = b, a
+	}
+	return &pmapNode[K, V]{bitmap: 1<<ia | 1<<ib, entries: []pmapEntry[K, V]{a, b}, edit: edit}
+}
+
+func (n *pmapNode[K, V]) delete(shift uint, hash uint64, key K, edit *pmapEdit) (*pmapNode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	if n.collision {
+		for i := range n.entries {
+			if n.entries[i].key == key {
+				if len(n.entries) == 1 {
+					return nil, true
+				}
+				n = n.editable(edit)
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				return n, true
+			}
+		}
+		return n, false
+	}
+
+	bit, pos := n.index(shift, hash)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	e := n.entries[pos]
+	if e.child != nil {
+		child, removed := e.child.delete(shift+persistentBits, hash, key, edit)
+		if !removed {
+			return n, false
+		}
+		n = n.editable(edit)
+		switch {
+		case child == nil:
+			n.removeAt(bit, pos)
+		case len(child.entries) == 1 && child.entries[0].child == nil:
+			// Pull a lone leaf up into this node.
+			n.entries[pos] = child.entries[0]
+		default:
+			n.entries[pos].child = child
+		}
+	} else {
+		if e.hash != hash || e.key != key {
+			return n, false
+		}
+		n = n.editable(edit)
+		n.removeAt(bit, pos)
+	}
+	if len(n.entries) == 0 {
+		return nil, true
+	}
+	return n, true
+}
+
+func (n *pmapNode[K, V]) removeAt(bit uint32, pos int) {
+	n.bitmap &^= bit
+	n.entries = append(n.entries[:pos], n.entries[pos+1:]...)
+}
+
+func (n *pmapNode[K, V]) all(yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	for i := range n.entries {
+		e := &n.entries[i]
+		if e.child != nil {
+			if !e.child.all(yield) {
+				return false
+			}
+		} else if !yield(e.key, e.value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package ds
+
+import (
+	"maps"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPMap(t *testing.T) {
+	t.Run("PMap 测试", func(t *testing.T) {
+		t.Run("旧版本不受修改影响", func(t *testing.T) {
+			m1 := NewPMap[string, int]().Set("a", 1).Set("b", 2)
+			m2 := m1.Set("a", 10).Set("c", 3)
+			m3 := m2.Delete("b")
+
+			assert.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(m1.All()))
+			assert.Equal(t, map[string]int{"a": 10, "b": 2, "c": 3}, maps.Collect(m2.All()))
+			assert.Equal(t, map[string]int{"a": 10, "c": 3}, maps.Collect(m3.All()))
+			assert.Equal(t, 2, m1.Len())
+			assert.Equal(t, 3, m2.Len())
+			assert.Equal(t, 2, m3.Len())
+		})
+
+		t.Run("删除不存在的键返回原 map", func(t *testing.T) {
+			m := NewPMap[int, int]().Set(1, 1)
+			assert.Same(t, m, m.Delete(2))
+			_, ok := NewPMap[int, int]().Get(1)
+			assert.False(t, ok)
+		})
+
+		t.Run("与内置 map 随机对比", func(t *testing.T) {
+			r := rand.New(rand.NewPCG(1, 2))
+			m := NewPMap[int, int]()
+			ref := map[int]int{}
+			type version struct {
+				m   *PMap[int, int]
+				ref map[int]int
+			}
+			var versions []version
+			for i := range 20000 {
+				k := r.IntN(3000)
+				if r.IntN(3) == 0 {
+					m = m.Delete(k)
+					delete(ref, k)
+				} else {
+					m = m.Set(k, i)
+					ref[k] = i
+				}
+				if i%2000 == 0 {
+					versions = append(versions, version{m, maps.Clone(ref)})
+				}
+			}
+			assert.Equal(t, len(ref), m.Len())
+			assert.Equal(t, ref, maps.Collect(m.All()))
+			for _, v := range versions {
+				assert.Equal(t, v.ref, maps.Collect(v.m.All()))
+				assert.Equal(t, len(v.ref), v.m.Len())
+			}
+		})
+
+		t.Run("提前结束遍历", func(t *testing.T) {
+			m := NewPMap[int, int]()
+			for i := range 100 {
+				m = m.Set(i, i)
+			}
+			n := 0
+			for range m.All() {
+				n++
+				if n == 10 {
+					break
+				}
+			}
+			assert.Equal(t, 10, n)
+		})
+	})
+}
+
+func TestPMapBuilder(t *testing.T) {
+	t.Run("PMapBuilder 测试", func(t *testing.T) {
+		base := NewPMap[int, string]().Set(1, "a").Set(2, "b")
+		b := base.Transient()
+		for i := range 1000 {
+			b.Set(i, "x")
+		}
+		b.Delete(500)
+		b.Delete(5000)
+		m := b.Map()
+
+		assert.Equal(t, 999, m.Len())
+		assert.Equal(t, 999, b.Len())
+		v, _ := m.Get(1)
+		assert.Equal(t, "x", v)
+		_, ok := m.Get(500)
+		assert.False(t, ok)
+		assert.Equal(t, map[int]string{1: "a", 2: "b"}, maps.Collect(base.All()), "原 map 不应被修改")
+
+		// Edits after Map must not leak into the returned map.
+		b.Set(1, "y")
+		b.Delete(2)
+		v, _ = m.Get(1)
+		assert.Equal(t, "x", v)
+		_, ok = m.Get(2)
+		assert.True(t, ok)
+		v, _ = b.Get(1)
+		assert.Equal(t, "y", v)
+	})
+}
+
+func TestPMapCollision(t *testing.T) {
+	t.Run("哈希完全冲突", func(t *testing.T) {
+		const hash = 0xdeadbeef
+		var root *pmapNode[string, int]
+		for i, k := range []string{"a", "b", "c"} {
+			var added bool
+			root, added = root.set(0, hash, k, i, nil)
+			assert.True(t, added)
+		}
+		for i, k := range []string{"a", "b", "c"} {
+			v, ok := root.get(0, hash, k)
+			assert.True(t, ok)
+			assert.Equal(t, i, v)
+		}
+
+		after, removed := root.delete(0, hash, "b", nil)
+		assert.True(t, removed)
+		_, ok := after.get(0, hash, "b")
+		assert.False(t, ok)
+		_, ok = root.get(0, hash, "b")
+		assert.True(t, ok, "旧版本不应被修改")
+
+		after, _ = after.delete(0, hash, "a", nil)
+		v, ok := after.get(0, hash, "c")
+		assert.True(t, ok)
+		assert.Equal(t, 2, v)
+		after, _ = after.delete(0, hash, "c", nil)
+		assert.Nil(t, after)
+	})
+}
@@ -0,0 +1,130 @@
+package ds
+
+import "container/heap"
+
+type (
+	// PriorityQueue is a binary heap ordered by a custom less function.
+	// Push returns a handle that can later be used to update the element's
+	// priority or remove it in O(log n).
+	//
+	// PriorityQueue is not safe for concurrent use.
+	//
+	// Type parameters:
+	//   - T: The element type stored in the queue
+	PriorityQueue[T any] struct {
+		h *pqHeap[T]
+	}
+
+	// PriorityItem is a handle to an element of a PriorityQueue.
+	PriorityItem[T any] struct {
+		value T
+		index int // position in the heap, -1 once removed
+		h     *pqHeap[T]
+	}
+
+	// pqHeap implements heap.Interface.
+	pqHeap[T any] struct {
+		items []*PriorityItem[T]
+		less  func(a, b T) bool
+	}
+)
+
+// NewPriorityQueue creates an empty PriorityQueue. Pop returns the element
+// for which less reports true against every other element, i.e. the minimum.
+//
+// Example:
+//
+//	q := NewPriorityQueue(func(a, b Task) bool { return a.Deadline.Before(b.Deadline) })
+//	item := q.Push(task)
+//	q.Update(item, rescheduled)
+//	next, _ := q.Pop()
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	if less == nil {
+		panic("priority queue: nil less function")
+	}
+	return &PriorityQueue[T]{h: &pqHeap[T]{less: less}}
+}
+
+// Push adds v to the queue and returns its handle.
+func (q *PriorityQueue[T]) Push(v T) *PriorityItem[T] {
+	it := &PriorityItem[T]{value: v, h: q.h}
+	heap.Push(q.h, it)
+	return it
+}
+
+// Pop removes and returns the minimum element.
+// Returns false if the queue is empty.
+func (q *PriorityQueue[T]) Pop() (v T, ok bool) {
+	if len(q.h.items) == 0 {
+		return v, false
+	}
+	return heap.Pop(q.h).(*PriorityItem[T]).value, true
+}
+
+// Peek returns the minimum element without removing it.
+// Returns false if the queue is empty.
+func (q *PriorityQueue[T]) Peek() (v T, ok bool) {
+	if len(q.h.items) == 0 {
+		return v, false
+	}
+	return q.h.items[0].value, true
+}
+
+// Update replaces the element of it with v and restores the heap order.
+// Returns false if it is not in the queue.
+func (q *PriorityQueue[T]) Update(it *PriorityItem[T], v T) bool {
+	if !q.contains(it) {
+		return false
+	}
+	it.value = v
+	heap.Fix(q.h, it.index)
+	return true
+}
+
+// Remove removes the element of it from the queue.
+// Returns false if it is not in the queue.
+func (q *PriorityQueue[T]) Remove(it *PriorityItem[T]) bool {
+	if !q.contains(it) {
+		return false
+	}
+	heap.Remove(q.h, it.index)
+	return true
+}
+
+// Len returns the number of elements in the queue.
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.h.items)
+}
+
+func (q *PriorityQueue[T]) contains(it *PriorityItem[T]) bool {
+	return it != nil && it.h == q.h && it.index >= 0
+}
+
+// Value returns the element held by the handle.
+func (it *PriorityItem[T]) Value() T {
+	return it.value
+}
+
+func (h *pqHeap[T]) Len() int           { return len(h.items) }
+func (h *pqHeap[T]) Less(i, j int) bool { return h.less(h.items[i].value, h.items[j].value) }
+
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *pqHeap[T]) Push(x any) {
+	it := x.(*PriorityItem[T])
+	it.index = len(h.items)
+	h.items = append(h.items, it)
+}
+
+func (h *pqHeap[T]) Pop() any {
+	n := len(h.items) - 1
+	it := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	it.index = -1
+	return it
+}
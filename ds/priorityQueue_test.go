@@ -0,0 +1,54 @@
+package ds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	t.Run("PriorityQueue 测试", func(t *testing.T) {
+		q := NewPriorityQueue(func(a, b int) bool { return a < b })
+
+		_, ok := q.Pop()
+		assert.False(t, ok)
+
+		items := map[int]*PriorityItem[int]{}
+		for _, v := range []int{5, 2, 8, 1, 9} {
+			items[v] = q.Push(v)
+		}
+		assert.Equal(t, 5, q.Len())
+
+		v, ok := q.Peek()
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		t.Run("通过句柄更新", func(t *testing.T) {
+			assert.True(t, q.Update(items[9], 0))
+			assert.Equal(t, 0, items[9].Value())
+			v, _ := q.Peek()
+			assert.Equal(t, 0, v)
+		})
+
+		t.Run("通过句柄删除", func(t *testing.T) {
+			assert.True(t, q.Remove(items[2]))
+			assert.False(t, q.Remove(items[2]))
+			assert.False(t, q.Update(items[2], 3))
+		})
+
+		t.Run("按优先级出队", func(t *testing.T) {
+			var got []int
+			for q.Len() > 0 {
+				v, _ := q.Pop()
+				got = append(got, v)
+			}
+			assert.Equal(t, []int{0, 1, 5, 8}, got)
+		})
+
+		t.Run("其他队列的句柄无效", func(t *testing.T) {
+			other := NewPriorityQueue(func(a, b int) bool { return a < b })
+			it := other.Push(1)
+			assert.False(t, q.Remove(it))
+		})
+	})
+}
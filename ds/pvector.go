@@ -0,0 +1,332 @@
+package ds
+
+import "iter"
+
+type (
+	// PVector is an immutable (persistent) vector implemented as a 32-way trie
+	// with a tail buffer.
+	//
+	// Get is O(log32 n); Set, Append and Pop return a new vector in O(log32 n)
+	// and leave the receiver unchanged, sharing all untouched nodes with it.
+	// Appends and pops at the end usually only touch the tail. Use Transient
+	// for bulk edits.
+	//
+	// The zero value is not usable; create vectors with NewPVector. A PVector
+	// is safe for concurrent reads.
+	//
+	// Type parameters:
+	//   - T: The element type
+	PVector[T any] struct {
+		len   int
+		shift uint
+		root  *pvectorNode[T]
+		tail  []T
+	}
+
+	// PVectorBuilder is a mutable (transient) view of a PVector for bulk edits.
+	// It edits nodes it has copied in place instead of copying them again.
+	// A PVectorBuilder is not safe for concurrent use.
+	PVectorBuilder[T any] struct {
+		v         PVector[T]
+		edit      *pmapEdit
+		tailOwned bool
+	}
+
+	// pvectorNode is a branch (children) or a leaf (values) of the trie.
+	pvectorNode[T any] struct {
+		children []*pvectorNode[T]
+		values   []T
+		edit     *pmapEdit
+	}
+)
+
+// NewPVector creates a PVector holding values.
+//
+// Example:
+//
+//	v := NewPVector(1, 2, 3)
+//	w := v.Append(4).Set(0, 10)
+//	fmt.Println(v.Len(), w.Len()) // 3 4
+func NewPVector[T any](values ...T) *PVector[T] {
+	b := (&PVector[T]{shift: persistentBits, root: &pvectorNode[T]{}}).Transient()
+	for _, v := range values {
+		b.Append(v)
+	}
+	return b.Vector()
+}
+
+// Len returns the number of elements.
+func (v *PVector[T]) Len() int {
+	return v.len
+}
+
+// tailOffset returns the index of the first element in the tail.
+func (v *PVector[T]) tailOffset() int {
+	if v.len < persistentWidth {
+		return 0
+	}
+	return (v.len - 1) &^ persistentMask
+}
+
+// leaf returns the values of the leaf holding index i.
+func (v *PVector[T]) leaf(i int) []T {
+	if i >= v.tailOffset() {
+		return v.tail
+	}
+	n := v.root
+	for level := v.shift; level > 0; level -= persistentBits {
+		n = n.children[(i>>level)&persistentMask]
+	}
+	return n.values
+}
+
+// Get returns the element at index i.
+//
+// Panics:
+//   - If i is out of range
+func (v *PVector[T]) Get(i int) T {
+	if i < 0 || i >= v.len {
+		panic("PVector: index out of range")
+	}
+	return v.leaf(i)[i&persistentMask]
+}
+
+// Set returns a vector with the element at index i replaced by value.
+// Setting index Len() appends.
+//
+// Panics:
+//   - If i is out of range (i < 0 or i > Len())
+func (v *PVector[T]) Set(i int, value T) *PVector[T] {
+	c := *v
+	c.set(i, value, nil, false)
+	return &c
+}
+
+// Append returns a vector with value added at the end.
+func (v *PVector[T]) Append(value T) *PVector[T] {
+	c := *v
+	c.append(value, nil, false)
+	return &c
+}
+
+// Pop returns a vector without the last element.
+//
+// Panics:
+//   - If the vector is empty
+func (v *PVector[T]) Pop() *PVector[T] {
+	c := *v
+	c.pop(nil, false)
+	return &c
+}
+
+// All returns an iterator over indices and elements in order.
+func (v *PVector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < v.len; i += persistentWidth {
+			for j, x := range v.leaf(i) {
+				if !yield(i+j, x) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Transient returns a builder initialized with the elements of v.
+// v is not affected by edits to the builder.
+//
+// Example:
+//
+//	b := v.Transient()
+//	for _, x := range batch {
+//		b.Append(x)
+//	}
+//	v = b.Vector()
+func (v *PVector[T]) Transient() *PVectorBuilder[T] {
+	return &PVectorBuilder[T]{v: *v, edit: &pmapEdit{}}
+}
+
+// Len returns the number of elements.
+func (b *PVectorBuilder[T]) Len() int {
+	return b.v.len
+}
+
+// Get returns the element at index i.
+func (b *PVectorBuilder[T]) Get(i int) T {
+	return b.v.Get(i)
+}
+
+// Set replaces the element at index i. Setting index Len() appends.
+func (b *PVectorBuilder[T]) Set(i int, value T) {
+	b.tailOwned = b.v.set(i, value, b.edit, b.tailOwned)
+}
+
+// Append adds value at the end.
+func (b *PVectorBuilder[T]) Append(value T) {
+	b.tailOwned = b.v.append(value, b.edit, b.tailOwned)
+}
+
+// Pop removes the last element.
+func (b *PVectorBuilder[T]) Pop() {
+	b.tailOwned = b.v.pop(b.edit, b.tailOwned)
+}
+
+// Vector returns an immutable PVector with the builder's elements. The builder
+// stays usable; further edits do not affect the returned vector.
+func (b *PVectorBuilder[T]) Vector() *PVector[T] {
+	b.edit, b.tailOwned = &pmapEdit{}, false
+	v := b.v
+	return &v
+}
+
+// editable returns n itself if it is owned by edit, otherwise a copy owned by edit.
+func (n *pvectorNode[T]) editable(edit *pmapEdit) *pvectorNode[T] {
+	if edit != nil && n.edit == edit {
+		return n
+	}
+	return &pvectorNode[T]{
+		children: append([]*pvectorNode[T](nil), n.children...),
+		values:   append([]T(nil), n.values...),
+		edit:     edit,
+	}
+}
+
+// ownTail makes the tail safe to modify in place unless it already is.
+func (v *PVector[T]) ownTail(owned bool) {
+	if !owned {
+		v.tail = append(make([]T, 0, persistentWidth), v.tail...)
+	}
+}
+
+// The methods below modify v in place and copy only nodes not owned by edit.
+// They report whether the tail is owned by the caller afterwards.
+
+func (v *PVector[T]) set(i int, value T, edit *pmapEdit, owned bool) bool {
+	if i == v.len {
+		return v.append(value, edit, owned)
+	}
+	if i < 0 || i > v.len {
+		panic("PVector: index out of range")
+	}
+	if i >= v.tailOffset() {
+		v.ownTail(owned)
+		v.tail[i&persistentMask] = value
+		return edit != nil
+	}
+	v.root = v.root.editable(edit)
+	n := v.root
+	for level := v.shift; level > 0; level -= persistentBits {
+		k := (i >> level) & persistentMask
+		n.children[k] = n.children[k].editable(edit)
+		n = n.children[k]
+	}
+	n.values[i&persistentMask] = value
+	return owned
+}
+
+func (v *PVector[T]) append(value T, edit *pmapEdit, owned bool) bool {
+	if v.len-v.tailOffset() < persistentWidth {
+		v.ownTail(owned)
+		v.tail = append(v.tail, value)
+		v.len++
+		return edit != nil
+	}
+
+	// The tail is full: push it into the trie as a leaf.
+	leaf := &pvectorNode[T]{values: v.tail, edit: edit}
+	if v.len>>persistentBits > 1<<v.shift {
+		// The trie is full: grow a level.
+		v.root = &pvectorNode[T]{
+			children: []*pvectorNode[T]{v.root, pvectorPath(v.shift, leaf, edit)},
+			edit:     edit,
+		}
+		v.shift += persistentBits
+	} else {
+		v.root = v.pushTail(v.shift, v.root, leaf, edit)
+	}
+	v.tail = append(make([]T, 0, persistentWidth), value)
+	v.len++
+	return edit != nil
+}
+
+// pushTail inserts leaf as the last leaf below n, which is at level.
+func (v *PVector[T]) pushTail(level uint, n, leaf *pvectorNode[T], edit *pmapEdit) *pvectorNode[T] {
+	n = n.editable(edit)
+	k := ((v.len - 1) >> level) & persistentMask
+	var child *pvectorNode[T]
+	switch {
+	case level == persistentBits:
+		child = leaf
+	case k < len(n.children):
+		child = v.pushTail(level-persistentBits, n.children[k], leaf, edit)
+	default:
+		child = pvectorPath(level-persistentBits, leaf, edit)
+	}
+	if k < len(n.children) {
+		n.children[k] = child
+	} else {
+		n.children = append(n.children, child)
+	}
+	return n
+}
+
+// pvectorPath wraps leaf in branches down from level.
+func pvectorPath[T any](level uint, leaf *pvectorNode[T], edit *pmapEdit) *pvectorNode[T] {
+	for ; level > 0; level -= persistentBits {
+		leaf = &pvectorNode[T]{children: []*pvectorNode[T]{leaf}, edit: edit}
+	}
+	return leaf
+}
+
+func (v *PVector[T]) pop(edit *pmapEdit, owned bool) bool {
+	switch {
+	case v.len == 0:
+		panic("PVector: pop from empty vector")
+	case v.len == 1:
+		*v = PVector[T]{shift: persistentBits, root: &pvectorNode[T]{}}
+		return false
+	case v.len-v.tailOffset() > 1:
+		// Shrinking is safe on a shared tail: unowned tails are copied before writes.
+		v.tail = v.tail[:len(v.tail)-1]
+		v.len--
+		return owned
+	}
+
+	// The tail becomes empty: pull the last leaf out of the trie.
+	v.tail = v.leaf(v.len - 2)
+	root := v.popTail(v.shift, v.root, edit)
+	if root == nil {
+		root = &pvectorNode[T]{edit: edit}
+	}
+	if v.shift > persistentBits && len(root.children) == 1 {
+		root = root.children[0]
+		v.shift -= persistentBits
+	}
+	v.root = root
+	v.len--
+	return false
+}
+
+// popTail removes the last leaf below n, which is at level. It returns nil if n becomes empty.
+func (v *PVector[T]) popTail(level uint, n *pvectorNode[T], edit *pmapEdit) *pvectorNode[T] {
+	k := ((v.len - 2) >> level) & persistentMask
+	if level > persistentBits {
+		child := v.popTail(level-persistentBits, n.children[k], edit)
+		if child == nil && k == 0 {
+			return nil
+		}
+		n = n.editable(edit)
+		if child == nil {
+			n.children = n.children[:k]
+		} else {
+			n.children[k] = child
+		}
+		return n
+	}
+	if k == 0 {
+		return nil
+	}
+	n = n.editable(edit)
+	n.children = n.children[:k]
+	return n
+}
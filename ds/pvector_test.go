@@ -0,0 +1,125 @@
+package ds
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectPVector[T any](v *PVector[T]) []T {
+	s := make([]T, 0, v.Len())
+	for _, x := range v.All() {
+		s = append(s, x)
+	}
+	return s
+}
+
+func TestPVector(t *testing.T) {
+	t.Run("PVector 测试", func(t *testing.T) {
+		t.Run("旧版本不受修改影响", func(t *testing.T) {
+			v1 := NewPVector(1, 2, 3)
+			v2 := v1.Append(4).Set(0, 10)
+			v3 := v2.Pop()
+
+			assert.Equal(t, []int{1, 2, 3}, collectPVector(v1))
+			assert.Equal(t, []int{10, 2, 3, 4}, collectPVector(v2))
+			assert.Equal(t, []int{10, 2, 3}, collectPVector(v3))
+			assert.Equal(t, 4, v2.Get(3))
+		})
+
+		t.Run("Set 到 Len 时追加", func(t *testing.T) {
+			v := NewPVector[int]().Set(0, 1)
+			assert.Equal(t, []int{1}, collectPVector(v))
+		})
+
+		t.Run("越界 panic", func(t *testing.T) {
+			v := NewPVector(1)
+			assert.Panics(t, func() { v.Get(1) })
+			assert.Panics(t, func() { v.Set(2, 0) })
+			assert.Panics(t, func() { NewPVector[int]().Pop() })
+		})
+
+		t.Run("跨越多层的追加与弹出", func(t *testing.T) {
+			const n = 40000 // > 32^3, three trie levels
+			v := NewPVector[int]()
+			versions := map[int]*PVector[int]{}
+			for i := range n {
+				v = v.Append(i)
+				if i%997 == 0 {
+					versions[i+1] = v
+				}
+			}
+			assert.Equal(t, n, v.Len())
+			for i := range n {
+				if v.Get(i) != i {
+					t.Fatalf("Get(%d) = %d", i, v.Get(i))
+				}
+			}
+			for l, old := range versions {
+				assert.Equal(t, l, old.Len())
+				assert.Equal(t, l-1, old.Get(l-1))
+			}
+
+			for v.Len() > 0 {
+				v = v.Pop()
+				if l := v.Len(); l > 0 && v.Get(l-1) != l-1 {
+					t.Fatalf("after Pop, Get(%d) = %d", l-1, v.Get(l-1))
+				}
+			}
+			assert.Equal(t, 0, v.Len())
+			assert.Equal(t, []int{5}, collectPVector(v.Append(5)))
+		})
+
+		t.Run("与切片随机对比", func(t *testing.T) {
+			r := rand.New(rand.NewPCG(3, 4))
+			v := NewPVector[int]()
+			var ref []int
+			for i := range 20000 {
+				switch op := r.IntN(10); {
+				case op < 5 || len(ref) == 0:
+					v = v.Append(i)
+					ref = append(ref, i)
+				case op < 8:
+					k := r.IntN(len(ref))
+					v = v.Set(k, i)
+					ref[k] = i
+				default:
+					v = v.Pop()
+					ref = ref[:len(ref)-1]
+				}
+			}
+			assert.Equal(t, ref, collectPVector(v))
+		})
+	})
+}
+
+func TestPVectorBuilder(t *testing.T) {
+	t.Run("PVectorBuilder 测试", func(t *testing.T) {
+		base := NewPVector(1, 2, 3)
+		b := base.Transient()
+		for i := range 2000 {
+			b.Append(i)
+		}
+		b.Set(0, 100)
+		b.Set(1000, -1)
+		b.Pop()
+		v := b.Vector()
+
+		assert.Equal(t, []int{1, 2, 3}, collectPVector(base), "原 vector 不应被修改")
+		assert.Equal(t, 2002, v.Len())
+		assert.Equal(t, 100, v.Get(0))
+		assert.Equal(t, -1, v.Get(1000))
+		assert.Equal(t, 1998, v.Get(2001))
+
+		// Edits after Vector must not leak into the returned vector.
+		b.Set(0, 7)
+		b.Set(2001, 7)
+		b.Append(7)
+		assert.Equal(t, 100, v.Get(0))
+		assert.Equal(t, 1998, v.Get(2001))
+		assert.Equal(t, 2002, v.Len())
+		assert.Equal(t, 7, b.Get(0))
+		assert.Equal(t, 2003, b.Len())
+	})
+}
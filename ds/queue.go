@@ -0,0 +1,36 @@
+package ds
+
+// Queue is a generic interface for First-In-First-Out (FIFO) queues.
+// Implementations may be backed by storage that can fail, so Push and Pop
+// report errors.
+//
+// Type parameters:
+//   - T: The element type stored in the queue
+//
+// Implementations:
+//   - DiskQueue: Durable queue stored in append-only segment files
+type Queue[T any] interface {
+	// Push appends an element to the tail of the queue.
+	Push(T) error
+	// Pop removes and returns the element at the head of the queue.
+	// Returns false if the queue is empty.
+	Pop() (T, bool, error)
+	// Len returns the number of elements in the queue.
+	Len() int
+}
+
+// AckQueue is a Queue whose elements can be read before they are removed:
+// Receive returns the next element with a token and Commit(token) removes it.
+// A durable AckQueue hands out the received but uncommitted elements again
+// after it is reopened.
+//
+// Implementations:
+//   - DiskQueue
+type AckQueue[T any] interface {
+	Queue[T]
+	// Receive returns the next unread element and its token without removing it.
+	// Returns false if there is no unread element.
+	Receive() (v T, token uint64, ok bool, err error)
+	// Commit removes the element received with token.
+	Commit(token uint64) error
+}
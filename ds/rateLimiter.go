@@ -0,0 +1,356 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	_ RateLimiter = (*TokenBucket)(nil)
+	_ RateLimiter = (*SlidingWindowLimiter)(nil)
+	_ RateLimiter = (*GCRALimiter)(nil)
+)
+
+var (
+	// ErrRateLimitExceeded is returned by Wait when a reservation is impossible
+	// (e.g. a zero limit) or would not be ready before the context deadline.
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+)
+
+type (
+	// RateLimiter controls how frequently events may happen.
+	//
+	// Implementations:
+	//   - TokenBucket: Refills tokens at a constant rate up to a burst size
+	//   - SlidingWindowLimiter: Allows at most N events in any rolling window
+	//   - GCRALimiter: Generic Cell Rate Algorithm, a memory-light token bucket
+	RateLimiter interface {
+		// Allow reports whether an event may happen now, consuming capacity if so.
+		Allow() bool
+
+		// Reserve reserves capacity for one event and reports how long the caller
+		// must wait before acting. The reservation should be cancelled if the
+		// caller decides not to act.
+		Reserve() *Reservation
+
+		// Wait blocks until an event may happen or ctx is done.
+		// Returns ErrRateLimitExceeded if the wait would exceed the ctx deadline.
+		Wait(ctx context.Context) error
+	}
+
+	// Reservation holds capacity reserved by RateLimiter.Reserve.
+	Reservation struct {
+		ok     bool
+		delay  time.Duration
+		at     time.Time        // when the reserved event may happen
+		now    func() time.Time // clock of the limiter
+		cancel func()
+		once   sync.Once
+	}
+)
+
+// OK reports whether the reservation can ever be honoured.
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay returns how long the caller must wait before acting on the reservation.
+func (r *Reservation) Delay() time.Duration {
+	return r.delay
+}
+
+// Cancel returns the reserved capacity to the limiter, as far as possible.
+// Once the reservation time has come the capacity counts as used and Cancel
+// does nothing. It is safe to call Cancel more than once.
+func (r *Reservation) Cancel() {
+	if !r.ok || r.cancel == nil {
+		return
+	}
+	r.once.Do(func() {
+		if r.now().Before(r.at) {
+			r.cancel()
+		}
+	})
+}
+
+// newReservation returns an honoured reservation made at now for delay.
+func newReservation(clock func() time.Time, now time.Time, delay time.Duration, cancel func()) *Reservation {
+	return &Reservation{ok: true, delay: delay, at: now.Add(delay), now: clock, cancel: cancel}
+}
+
+// waitReservation implements RateLimiter.Wait on top of Reserve.
+func waitReservation(ctx context.Context, reserve func() *Reservation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r := reserve()
+	if !r.ok {
+		return ErrRateLimitExceeded
+	}
+	if r.delay <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < r.delay {
+		r.Cancel()
+		return ErrRateLimitExceeded
+	}
+
+	t := time.NewTimer(r.delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// TokenBucket is a RateLimiter that refills rate tokens per second up to burst.
+// Each event consumes one token. TokenBucket is safe for concurrent use.
+type TokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket creates a full TokenBucket.
+//
+// Parameters:
+//   - rate: Tokens added per second
+//   - burst: Maximum number of tokens, i.e. the largest burst of events
+//
+// Example:
+//
+//	// 10 requests per second, bursts of up to 20
+//	l := NewTokenBucket(10, 20)
+//	if !l.Allow() {
+//		return ErrTooManyRequests
+//	}
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   max(0, rate),
+		burst:  float64(max(0, burst)),
+		tokens: float64(max(0, burst)),
+		now:    time.Now,
+	}
+}
+
+// advance refills tokens for the time elapsed since the last update.
+func (l *TokenBucket) advance(now time.Time) {
+	if !l.last.IsZero() {
+		if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+			l.tokens = min(l.burst, l.tokens+elapsed*l.rate)
+		}
+	}
+	l.last = now
+}
+
+// Allow reports whether a token is available now, consuming it if so.
+func (l *TokenBucket) Allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.advance(l.now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Reserve takes a token, possibly going into debt, and returns the time until it is available.
+func (l *TokenBucket) Reserve() *Reservation {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.advance(now)
+	if l.burst < 1 || (l.rate <= 0 && l.tokens < 1) {
+		return &Reservation{}
+	}
+	l.tokens--
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	return newReservation(l.now, now, delay, func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		l.advance(l.now())
+		l.tokens = min(l.burst, l.tokens+1)
+	})
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *TokenBucket) Wait(ctx context.Context) error {
+	return waitReservation(ctx, l.Reserve)
+}
+
+// Tokens returns the number of tokens currently available.
+func (l *TokenBucket) Tokens() float64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.advance(l.now())
+	return l.tokens
+}
+
+// SlidingWindowLimiter is a RateLimiter that allows at most limit events in any
+// rolling window, tracked with an exact log of event times.
+// Memory grows with limit. SlidingWindowLimiter is safe for concurrent use.
+type SlidingWindowLimiter struct {
+	mutex  sync.Mutex
+	limit  int
+	window time.Duration
+	log    []time.Time // granted event times, ascending; may include future reservations
+	now    func() time.Time
+}
+
+// NewSlidingWindowLimiter creates a SlidingWindowLimiter allowing limit events per window.
+func NewSlidingWindowLimiter(limit int, window time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{
+		limit:  max(0, limit),
+		window: window,
+		log:    make([]time.Time, 0, max(0, limit)),
+		now:    time.Now,
+	}
+}
+
+// expire drops events that left the window.
+func (l *SlidingWindowLimiter) expire(now time.Time) {
+	cut := now.Add(-l.window)
+	i := 0
+	for i < len(l.log) && !l.log[i].After(cut) {
+		i++
+	}
+	if i > 0 {
+		l.log = append(l.log[:0], l.log[i:]...)
+	}
+}
+
+// Allow reports whether fewer than limit events happened in the last window,
+// recording the event if so.
+func (l *SlidingWindowLimiter) Allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.expire(now)
+	if len(l.log) >= l.limit {
+		return false
+	}
+	l.log = append(l.log, now)
+	return true
+}
+
+// Reserve records an event at the earliest time the window allows it.
+func (l *SlidingWindowLimiter) Reserve() *Reservation {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.limit == 0 {
+		return &Reservation{}
+	}
+
+	now := l.now()
+	l.expire(now)
+	at := now
+	if n := len(l.log); n >= l.limit {
+		at = l.log[n-l.limit].Add(l.window)
+	}
+	l.log = append(l.log, at)
+
+	return newReservation(l.now, now, at.Sub(now), func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		if i := slices.IndexFunc(l.log, at.Equal); i >= 0 {
+			l.log = slices.Delete(l.log, i, i+1)
+		}
+	})
+}
+
+// Wait blocks until the window allows an event or ctx is done.
+func (l *SlidingWindowLimiter) Wait(ctx context.Context) error {
+	return waitReservation(ctx, l.Reserve)
+}
+
+// GCRALimiter is a RateLimiter implementing the Generic Cell Rate Algorithm.
+// It behaves like a token bucket but stores a single timestamp: the theoretical
+// arrival time (TAT) of the next event. GCRALimiter is safe for concurrent use.
+type GCRALimiter struct {
+	mutex     sync.Mutex
+	interval  time.Duration // emission interval between events
+	tolerance time.Duration // how far TAT may run ahead of now (burst)
+	tat       time.Time
+	now       func() time.Time
+}
+
+// NewGCRALimiter creates a GCRALimiter allowing rate events per second with bursts of up to burst.
+// Like TokenBucket, a burst below 1 allows no events.
+func NewGCRALimiter(rate float64, burst int) *GCRALimiter {
+	l := &GCRALimiter{interval: math.MaxInt64, now: time.Now}
+	if rate > 0 && burst >= 1 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+		l.tolerance = time.Duration(burst-1) * l.interval
+	}
+	return l
+}
+
+// Allow reports whether an event conforms now, updating the TAT if so.
+func (l *GCRALimiter) Allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.interval == math.MaxInt64 {
+		return false
+	}
+
+	now := l.now()
+	tat := l.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	if tat.Sub(now) > l.tolerance {
+		return false
+	}
+	l.tat = tat.Add(l.interval)
+	return true
+}
+
+// Reserve schedules an event at the earliest conforming time.
+func (l *GCRALimiter) Reserve() *Reservation {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.interval == math.MaxInt64 {
+		return &Reservation{}
+	}
+
+	now := l.now()
+	tat := l.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	delay := max(0, tat.Sub(now)-l.tolerance)
+	l.tat = tat.Add(l.interval)
+
+	return newReservation(l.now, now, delay, func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		l.tat = l.tat.Add(-l.interval)
+	})
+}
+
+// Wait blocks until an event conforms or ctx is done.
+func (l *GCRALimiter) Wait(ctx context.Context) error {
+	return waitReservation(ctx, l.Reserve)
+}
@@ -0,0 +1,220 @@
+package ds
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock for deterministic limiter tests.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time      { return c.t }
+func (c *fakeClock) Add(d time.Duration) { c.t = c.t.Add(d) }
+func newFakeClock() *fakeClock           { return &fakeClock{t: time.Unix(1700000000, 0)} }
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("TokenBucket 测试", func(t *testing.T) {
+		clock := newFakeClock()
+		l := NewTokenBucket(2, 3)
+		l.now = clock.Now
+
+		t.Run("突发容量用尽后拒绝", func(t *testing.T) {
+			for range 3 {
+				assert.True(t, l.Allow())
+			}
+			assert.False(t, l.Allow())
+		})
+
+		t.Run("按速率补充令牌", func(t *testing.T) {
+			clock.Add(500 * time.Millisecond)
+			assert.True(t, l.Allow())
+			assert.False(t, l.Allow())
+
+			clock.Add(10 * time.Second)
+			assert.InDelta(t, 3, l.Tokens(), 1e-9)
+		})
+
+		t.Run("Reserve返回等待时间, Cancel归还令牌", func(t *testing.T) {
+			for range 3 {
+				l.Allow()
+			}
+			r := l.Reserve()
+			assert.True(t, r.OK())
+			assert.Equal(t, 500*time.Millisecond, r.Delay())
+			r.Cancel()
+			r.Cancel()
+			assert.InDelta(t, 0, l.Tokens(), 1e-9)
+		})
+
+		t.Run("零突发容量不可预留", func(t *testing.T) {
+			assert.False(t, NewTokenBucket(1, 0).Reserve().OK())
+		})
+
+		t.Run("预留时间已到后Cancel不归还令牌", func(t *testing.T) {
+			clock.Add(10 * time.Second)
+			for range 3 {
+				l.Allow()
+			}
+			r := l.Reserve()
+			clock.Add(r.Delay())
+			r.Cancel()
+			assert.InDelta(t, 0, l.Tokens(), 1e-9)
+
+			r = l.Reserve()
+			r.Cancel()
+			assert.InDelta(t, 0, l.Tokens(), 1e-9)
+		})
+	})
+}
+
+func TestRateLimiterZeroBurst(t *testing.T) {
+	limiters := map[string]RateLimiter{
+		"TokenBucket": NewTokenBucket(10, 0),
+		"GCRALimiter": NewGCRALimiter(10, 0),
+	}
+	for name, l := range limiters {
+		t.Run(name+" 零突发容量拒绝所有请求", func(t *testing.T) {
+			assert.False(t, l.Allow())
+			assert.False(t, l.Reserve().OK())
+			assert.Equal(t, ErrRateLimitExceeded, l.Wait(context.Background()))
+		})
+	}
+}
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	t.Run("SlidingWindowLimiter 测试", func(t *testing.T) {
+		clock := newFakeClock()
+		l := NewSlidingWindowLimiter(2, time.Second)
+		l.now = clock.Now
+
+		assert.True(t, l.Allow())
+		clock.Add(400 * time.Millisecond)
+		assert.True(t, l.Allow())
+		assert.False(t, l.Allow())
+
+		r := l.Reserve()
+		assert.True(t, r.OK())
+		assert.Equal(t, 600*time.Millisecond, r.Delay())
+		r.Cancel()
+
+		clock.Add(601 * time.Millisecond)
+		assert.True(t, l.Allow())
+		assert.False(t, l.Allow())
+
+		clock.Add(400 * time.Millisecond)
+		assert.True(t, l.Allow())
+	})
+}
+
+func TestGCRALimiter(t *testing.T) {
+	t.Run("GCRALimiter 测试", func(t *testing.T) {
+		clock := newFakeClock()
+		l := NewGCRALimiter(10, 2)
+		l.now = clock.Now
+
+		assert.True(t, l.Allow())
+		assert.True(t, l.Allow())
+		assert.False(t, l.Allow())
+
+		clock.Add(100 * time.Millisecond)
+		assert.True(t, l.Allow())
+		assert.False(t, l.Allow())
+
+		r := l.Reserve()
+		assert.True(t, r.OK())
+		assert.Equal(t, 100*time.Millisecond, r.Delay())
+		r.Cancel()
+
+		assert.False(t, NewGCRALimiter(0, 1).Allow())
+		assert.False(t, NewGCRALimiter(0, 1).Reserve().OK())
+	})
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiters := map[string]func() RateLimiter{
+		"TokenBucket":          func() RateLimiter { return NewTokenBucket(100, 1) },
+		"SlidingWindowLimiter": func() RateLimiter { return NewSlidingWindowLimiter(1, 10*time.Millisecond) },
+		"GCRALimiter":          func() RateLimiter { return NewGCRALimiter(100, 1) },
+	}
+	for name, newLimiter := range limiters {
+		t.Run(name+" Wait 测试", func(t *testing.T) {
+			l := newLimiter()
+			ctx := context.Background()
+
+			start := time.Now()
+			for range 3 {
+				assert.Nil(t, l.Wait(ctx))
+			}
+			assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+
+			t.Run("超过deadline返回错误", func(t *testing.T) {
+				l.Reserve()
+				ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+				defer cancel()
+				assert.Equal(t, ErrRateLimitExceeded, l.Wait(ctx))
+			})
+		})
+	}
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	t.Run("KeyedLimiter 测试", func(t *testing.T) {
+		l := NewKeyedLimiter(func(string) RateLimiter { return NewTokenBucket(1, 1) }, 20*time.Millisecond)
+		defer l.Release()
+
+		assert.True(t, l.Allow("a"))
+		assert.False(t, l.Allow("a"))
+		assert.True(t, l.Allow("b"))
+		assert.Equal(t, 2, l.Len())
+		assert.Same(t, l.Get("a"), l.Get("a"))
+
+		t.Run("空闲key被淘汰", func(t *testing.T) {
+			assert.Eventually(t, func() bool { return l.Len() == 0 }, time.Second, 5*time.Millisecond)
+			assert.True(t, l.Allow("a"))
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			l.Delete("a")
+			assert.Equal(t, 0, l.Len())
+		})
+	})
+
+	t.Run("KeyedLimiter 超过容量淘汰最久未用的key", func(t *testing.T) {
+		l := NewKeyedLimiterWithCapacity(func(string) RateLimiter { return NewTokenBucket(1, 1) }, 0, 2)
+		defer l.Release()
+
+		a := l.Get("a")
+		l.Get("b")
+		l.Get("a")
+		l.Get("c")
+		assert.Equal(t, 2, l.Len())
+		assert.Same(t, a, l.Get("a"))
+		assert.True(t, l.Allow("b"))
+	})
+
+	t.Run("KeyedLimiter 使用非字符串key", func(t *testing.T) {
+		type route struct {
+			Tenant string
+			Port   int
+		}
+		l := NewKeyedLimiterWithID(
+			func(route) RateLimiter { return NewTokenBucket(1, 1) },
+			func(r route) string { return r.Tenant + ":" + strconv.Itoa(r.Port) },
+			0, 0,
+		)
+		defer l.Release()
+
+		assert.True(t, l.Allow(route{"a", 80}))
+		assert.False(t, l.Allow(route{"a", 80}))
+		assert.True(t, l.Allow(route{"a", 443}))
+		assert.Equal(t, 2, l.Len())
+
+		l.Delete(route{"a", 80})
+		assert.Equal(t, 1, l.Len())
+		assert.True(t, l.Allow(route{"a", 80}))
+	})
+}
@@ -3,10 +3,12 @@ package ds
 import (
 	"iter"
 	"sync"
-	"sync/atomic"
 )
 
-// RingBuffer Non-concurrency-safe Ring
+// RingBuffer is a fixed-size ring that keeps the most recent values: pushing
+// into a full buffer overwrites the oldest value. It is safe for concurrent
+// use but has no way to consume values; see MPMCQueue and SPSCQueue for
+// bounded FIFO queues.
 type RingBuffer[T any] struct {
 	m    sync.Mutex
 	data []T
@@ -41,7 +43,7 @@ func (r *RingBuffer[T]) Push(values ...T) {
 	defer r.m.Unlock()
 	for _, value := range values {
 		r.data[r.next] = value
-		atomic.StoreInt64(&r.next, (r.next+1)%r.size)
+		r.next = (r.next + 1) % r.size
 
 		if !r.full && r.next == 0 {
 			r.full = true
@@ -50,8 +52,15 @@ func (r *RingBuffer[T]) Push(values ...T) {
 }
 
 // Iterator returns an iterator that yields all values in the ring buffer
-// in chronological order (oldest to newest).
+// in chronological order (oldest to newest). It is equivalent to All.
 func (r *RingBuffer[T]) Iterator() iter.Seq[T] {
+	return r.All()
+}
+
+// All returns an iterator that yields all values in the ring buffer
+// in chronological order (oldest to newest). The buffer is locked during
+// iteration, so the loop body must not push to it.
+func (r *RingBuffer[T]) All() iter.Seq[T] {
 	return func(yield func(T) bool) {
 		r.m.Lock()
 		defer r.m.Unlock()
@@ -96,6 +105,8 @@ func (r *RingBuffer[T]) Values() (result []T) {
 
 // Len returns the number of elements currently stored in the ring buffer.
 func (r *RingBuffer[T]) Len() int {
+	r.m.Lock()
+	defer r.m.Unlock()
 	if !r.full {
 		return int(r.next)
 	}
@@ -0,0 +1,285 @@
+package ds
+
+import (
+	"context"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// MPMCQueue is a bounded lock-free FIFO queue for multiple producers and
+	// multiple consumers, after Dmitry Vyukov's bounded MPMC queue.
+	//
+	// Every slot carries a sequence number that tells producers and consumers
+	// whose turn it is, so an operation costs a single CAS on the shared
+	// position counter. The counters are padded onto separate cache lines, like
+	// NFSBitMutex, so producers and consumers do not falsely share. Blocked
+	// Push and Pop calls sleep until the other side makes room or adds an element.
+	//
+	// Type parameters:
+	//   - T: The element type stored in the queue
+	MPMCQueue[T any] struct {
+		_        [64]byte
+		enq      atomic.Uint64
+		_        [56]byte
+		deq      atomic.Uint64
+		_        [56]byte
+		mask     uint64
+		slots    []mpmcSlot[T]
+		notEmpty queueSignal // signalled after a push
+		notFull  queueSignal // signalled after a pop
+	}
+
+	mpmcSlot[T any] struct {
+		seq atomic.Uint64
+		val T
+	}
+
+	// SPSCQueue is a bounded lock-free FIFO queue for exactly one producer and
+	// one consumer goroutine. It needs no CAS: each side owns one counter and
+	// caches the other's to avoid touching the shared cache line on every call.
+	//
+	// Using an SPSCQueue from more than one producer or consumer is a data race.
+	//
+	// Type parameters:
+	//   - T: The element type stored in the queue
+	SPSCQueue[T any] struct {
+		_          [64]byte
+		head       atomic.Uint64 // next position to pop, written by the consumer
+		cachedTail uint64        // consumer's copy of tail
+		_          [48]byte
+		tail       atomic.Uint64 // next position to push, written by the producer
+		cachedHead uint64        // producer's copy of head
+		_          [48]byte
+		mask       uint64
+		buf        []T
+		notEmpty   queueSignal // signalled after a push
+		notFull    queueSignal // signalled after a pop
+	}
+)
+
+// queueCapacity rounds capacity up to a power of two, at least 2.
+func queueCapacity(capacity int) uint64 {
+	return 1 << bits.Len64(uint64(max(2, capacity))-1)
+}
+
+// queueSignal wakes goroutines blocked on a queue. Waiters register before
+// re-checking the queue and then wait on the channel, so a signal sent after
+// the queue changed is never lost; signal is a single atomic load when
+// nobody waits.
+type queueSignal struct {
+	waiters atomic.Int64
+	mutex   sync.Mutex
+	ch      chan struct{} // closed and cleared by signal
+}
+
+// signal wakes all current waiters.
+func (s *queueSignal) signal() {
+	if s.waiters.Load() == 0 {
+		return
+	}
+	s.mutex.Lock()
+	if s.ch != nil {
+		close(s.ch)
+		s.ch = nil
+	}
+	s.mutex.Unlock()
+}
+
+// wait retries try until it succeeds or ctx is done, sleeping on s in between.
+func (s *queueSignal) wait(ctx context.Context, try func() bool) error {
+	for {
+		if try() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.waiters.Add(1)
+		s.mutex.Lock()
+		if s.ch == nil {
+			s.ch = make(chan struct{})
+		}
+		ch := s.ch
+		s.mutex.Unlock()
+
+		if try() {
+			s.waiters.Add(-1)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			s.waiters.Add(-1)
+			return ctx.Err()
+		case <-ch:
+			s.waiters.Add(-1)
+		}
+	}
+}
+
+// NewMPMCQueue creates an MPMCQueue holding up to capacity elements,
+// rounded up to a power of two.
+//
+// Example:
+//
+//	q := NewMPMCQueue[Job](1024)
+//	if !q.TryPush(job) {
+//		// queue full
+//	}
+//	job, err := q.Pop(ctx) // blocks until a job is available
+func NewMPMCQueue[T any](capacity int) *MPMCQueue[T] {
+	n := queueCapacity(capacity)
+	q := &MPMCQueue[T]{
+		mask:  n - 1,
+		slots: make([]mpmcSlot[T], n),
+	}
+	for i := range q.slots {
+		q.slots[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// TryPush adds v to the queue without blocking. Returns false if the queue is full.
+func (q *MPMCQueue[T]) TryPush(v T) bool {
+	pos := q.enq.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		switch diff := int64(slot.seq.Load() - pos); {
+		case diff == 0:
+			if q.enq.CompareAndSwap(pos, pos+1) {
+				slot.val = v
+				slot.seq.Store(pos + 1)
+				q.notEmpty.signal()
+				return true
+			}
+			pos = q.enq.Load()
+		case diff < 0:
+			return false
+		default:
+			pos = q.enq.Load()
+		}
+	}
+}
+
+// TryPop removes and returns the oldest element without blocking.
+// Returns false if the queue is empty.
+func (q *MPMCQueue[T]) TryPop() (v T, ok bool) {
+	pos := q.deq.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		switch diff := int64(slot.seq.Load() - (pos + 1)); {
+		case diff == 0:
+			if q.deq.CompareAndSwap(pos, pos+1) {
+				var zero T
+				v, slot.val = slot.val, zero
+				slot.seq.Store(pos + q.mask + 1)
+				q.notFull.signal()
+				return v, true
+			}
+			pos = q.deq.Load()
+		case diff < 0:
+			return v, false
+		default:
+			pos = q.deq.Load()
+		}
+	}
+}
+
+// Push adds v to the queue, waiting for room until ctx is done.
+func (q *MPMCQueue[T]) Push(ctx context.Context, v T) error {
+	return q.notFull.wait(ctx, func() bool { return q.TryPush(v) })
+}
+
+// Pop removes and returns the oldest element, waiting until one is available or ctx is done.
+func (q *MPMCQueue[T]) Pop(ctx context.Context) (v T, err error) {
+	err = q.notEmpty.wait(ctx, func() (ok bool) {
+		v, ok = q.TryPop()
+		return ok
+	})
+	return v, err
+}
+
+// Len returns the number of elements in the queue. It is a snapshot that may
+// be stale by the time it is used.
+func (q *MPMCQueue[T]) Len() int {
+	deq := q.deq.Load()
+	return int(min(q.mask+1, q.enq.Load()-deq))
+}
+
+// Cap returns the capacity of the queue.
+func (q *MPMCQueue[T]) Cap() int {
+	return int(q.mask + 1)
+}
+
+// NewSPSCQueue creates an SPSCQueue holding up to capacity elements,
+// rounded up to a power of two.
+func NewSPSCQueue[T any](capacity int) *SPSCQueue[T] {
+	n := queueCapacity(capacity)
+	return &SPSCQueue[T]{
+		mask: n - 1,
+		buf:  make([]T, n),
+	}
+}
+
+// TryPush adds v to the queue without blocking. Returns false if the queue is full.
+// Must only be called from the producer goroutine.
+func (q *SPSCQueue[T]) TryPush(v T) bool {
+	tail := q.tail.Load()
+	if tail-q.cachedHead > q.mask {
+		q.cachedHead = q.head.Load()
+		if tail-q.cachedHead > q.mask {
+			return false
+		}
+	}
+	q.buf[tail&q.mask] = v
+	q.tail.Store(tail + 1)
+	q.notEmpty.signal()
+	return true
+}
+
+// TryPop removes and returns the oldest element without blocking.
+// Returns false if the queue is empty. Must only be called from the consumer goroutine.
+func (q *SPSCQueue[T]) TryPop() (v T, ok bool) {
+	head := q.head.Load()
+	if head == q.cachedTail {
+		q.cachedTail = q.tail.Load()
+		if head == q.cachedTail {
+			return v, false
+		}
+	}
+	var zero T
+	v, q.buf[head&q.mask] = q.buf[head&q.mask], zero
+	q.head.Store(head + 1)
+	q.notFull.signal()
+	return v, true
+}
+
+// Push adds v to the queue, waiting for room until ctx is done.
+// Must only be called from the producer goroutine.
+func (q *SPSCQueue[T]) Push(ctx context.Context, v T) error {
+	return q.notFull.wait(ctx, func() bool { return q.TryPush(v) })
+}
+
+// Pop removes and returns the oldest element, waiting until one is available or ctx is done.
+// Must only be called from the consumer goroutine.
+func (q *SPSCQueue[T]) Pop(ctx context.Context) (v T, err error) {
+	err = q.notEmpty.wait(ctx, func() (ok bool) {
+		v, ok = q.TryPop()
+		return ok
+	})
+	return v, err
+}
+
+// Len returns the number of elements in the queue. It is a snapshot that may
+// be stale by the time it is used.
+func (q *SPSCQueue[T]) Len() int {
+	head := q.head.Load()
+	return int(min(q.mask+1, q.tail.Load()-head))
+}
+
+// Cap returns the capacity of the queue.
+func (q *SPSCQueue[T]) Cap() int {
+	return int(q.mask + 1)
+}
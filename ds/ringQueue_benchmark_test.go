@@ -0,0 +1,107 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+const queueBenchmarkSize = 1024
+
+// benchmarkQueue moves b.N items from producers to consumers through push/pop.
+func benchmarkQueue(b *testing.B, producers, consumers int, push func(int), pop func()) {
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for p := range producers {
+		n := b.N / producers
+		if p == 0 {
+			n += b.N % producers
+		}
+		wg.Go(func() {
+			for i := range n {
+				push(i)
+			}
+		})
+	}
+	for c := range consumers {
+		n := b.N / consumers
+		if c == 0 {
+			n += b.N % consumers
+		}
+		wg.Go(func() {
+			for range n {
+				pop()
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkQueueSPSC(b *testing.B) {
+	b.Run("SPSCQueue", func(b *testing.B) {
+		q := NewSPSCQueue[int](queueBenchmarkSize)
+		ctx := context.Background()
+		benchmarkQueue(b, 1, 1,
+			func(i int) { _ = q.Push(ctx, i) },
+			func() { _, _ = q.Pop(ctx) })
+	})
+	b.Run("MPMCQueue", func(b *testing.B) {
+		q := NewMPMCQueue[int](queueBenchmarkSize)
+		ctx := context.Background()
+		benchmarkQueue(b, 1, 1,
+			func(i int) { _ = q.Push(ctx, i) },
+			func() { _, _ = q.Pop(ctx) })
+	})
+	b.Run("Channel", func(b *testing.B) {
+		ch := make(chan int, queueBenchmarkSize)
+		benchmarkQueue(b, 1, 1,
+			func(i int) { ch <- i },
+			func() { <-ch })
+	})
+}
+
+func BenchmarkQueueMPMC(b *testing.B) {
+	b.Run("MPMCQueue", func(b *testing.B) {
+		q := NewMPMCQueue[int](queueBenchmarkSize)
+		ctx := context.Background()
+		benchmarkQueue(b, benchmarkGoroutines, benchmarkGoroutines,
+			func(i int) { _ = q.Push(ctx, i) },
+			func() { _, _ = q.Pop(ctx) })
+	})
+	b.Run("Channel", func(b *testing.B) {
+		ch := make(chan int, queueBenchmarkSize)
+		benchmarkQueue(b, benchmarkGoroutines, benchmarkGoroutines,
+			func(i int) { ch <- i },
+			func() { <-ch })
+	})
+}
+
+func BenchmarkQueueTryPushPop(b *testing.B) {
+	b.Run("MPMCQueue", func(b *testing.B) {
+		q := NewMPMCQueue[int](queueBenchmarkSize)
+		for i := 0; i < b.N; i++ {
+			q.TryPush(i)
+			q.TryPop()
+		}
+	})
+	b.Run("SPSCQueue", func(b *testing.B) {
+		q := NewSPSCQueue[int](queueBenchmarkSize)
+		for i := 0; i < b.N; i++ {
+			q.TryPush(i)
+			q.TryPop()
+		}
+	})
+	b.Run("Channel", func(b *testing.B) {
+		ch := make(chan int, queueBenchmarkSize)
+		for i := 0; i < b.N; i++ {
+			select {
+			case ch <- i:
+			default:
+			}
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMPMCQueue(t *testing.T) {
+	t.Run("MPMCQueue 测试", func(t *testing.T) {
+		q := NewMPMCQueue[int](3)
+		assert.Equal(t, 4, q.Cap())
+
+		t.Run("先进先出且有界", func(t *testing.T) {
+			for i := range 4 {
+				assert.True(t, q.TryPush(i))
+			}
+			assert.False(t, q.TryPush(4))
+			assert.Equal(t, 4, q.Len())
+			for i := range 4 {
+				v, ok := q.TryPop()
+				assert.True(t, ok)
+				assert.Equal(t, i, v)
+			}
+			_, ok := q.TryPop()
+			assert.False(t, ok)
+		})
+
+		t.Run("阻塞操作遵循ctx", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := q.Pop(ctx)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+			go func() {
+				time.Sleep(5 * time.Millisecond)
+				q.TryPush(42)
+			}()
+			v, err := q.Pop(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 42, v)
+		})
+
+		t.Run("阻塞的Push在出队后被唤醒", func(t *testing.T) {
+			for i := range 4 {
+				assert.True(t, q.TryPush(i))
+			}
+			done := make(chan error, 1)
+			go func() { done <- q.Push(context.Background(), 4) }()
+			time.Sleep(5 * time.Millisecond)
+			assert.Equal(t, 1, int(q.notFull.waiters.Load()))
+
+			_, ok := q.TryPop()
+			assert.True(t, ok)
+			select {
+			case err := <-done:
+				assert.Nil(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("Push was not woken up")
+			}
+			assert.Equal(t, 0, int(q.notFull.waiters.Load()))
+			for range 4 {
+				q.TryPop()
+			}
+		})
+	})
+
+	t.Run("多生产者多消费者", func(t *testing.T) {
+		const producers, perProducer = 4, 10000
+		var (
+			q    = NewMPMCQueue[int](64)
+			ctx  = context.Background()
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			seen = make(map[int]bool, producers*perProducer)
+		)
+		for p := range producers {
+			wg.Go(func() {
+				for i := range perProducer {
+					assert.Nil(t, q.Push(ctx, p*perProducer+i))
+				}
+			})
+		}
+		var cg sync.WaitGroup
+		for range producers {
+			cg.Go(func() {
+				for range perProducer {
+					v, err := q.Pop(ctx)
+					assert.Nil(t, err)
+					mu.Lock()
+					seen[v] = true
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+		cg.Wait()
+		assert.Len(t, seen, producers*perProducer)
+		assert.Equal(t, 0, q.Len())
+	})
+}
+
+func TestSPSCQueue(t *testing.T) {
+	t.Run("SPSCQueue 测试", func(t *testing.T) {
+		q := NewSPSCQueue[int](2)
+		assert.True(t, q.TryPush(1))
+		assert.True(t, q.TryPush(2))
+		assert.False(t, q.TryPush(3))
+		assert.Equal(t, 2, q.Len())
+		v, ok := q.TryPop()
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("单生产者单消费者保持顺序", func(t *testing.T) {
+		const n = 100000
+		var (
+			q   = NewSPSCQueue[int](128)
+			ctx = context.Background()
+			wg  sync.WaitGroup
+		)
+		wg.Go(func() {
+			for i := range n {
+				assert.Nil(t, q.Push(ctx, i))
+			}
+		})
+		for i := range n {
+			v, err := q.Pop(ctx)
+			assert.Nil(t, err)
+			if v != i {
+				assert.Equal(t, i, v)
+				break
+			}
+		}
+		wg.Wait()
+	})
+}
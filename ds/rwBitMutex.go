@@ -0,0 +1,189 @@
+package ds
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// rwSlots is the number of reader/writer locks in an RWBitMutex.
+	rwSlots = 32
+	// maxBackoffWait is the longest retryBackoff sleeps between attempts.
+	maxBackoffWait = time.Millisecond
+)
+
+// RWBitMutex is a bit-based reader/writer mutex holding 32 independent
+// reader/writer locks in a single uint64, 2 bits per slot:
+//
+//   - pending (bit 2i): a writer has claimed the slot and waits for readers to leave
+//   - locked (bit 2i+1): the writer holds the slot
+//
+// New readers are turned away while either bit is set, so writers are not
+// starved by a steady stream of readers. Active readers are counted per slot
+// next to the bit word.
+type RWBitMutex struct {
+	state   atomic.Uint64
+	readers [rwSlots]atomic.Int32
+}
+
+func rwPending(i int) uint64 { return 1 << (2 * i) }
+func rwLocked(i int) uint64  { return 2 << (2 * i) }
+func rwMask(i int) uint64    { return 3 << (2 * i) }
+
+func rwCheck(i int) {
+	if i < 0 || i >= rwSlots {
+		panic("RWBitMutex: index out of range")
+	}
+}
+
+// tryClaim sets the pending bit of slot i if no writer holds or claims it.
+func (m *RWBitMutex) tryClaim(i int) bool {
+	old := m.state.Load()
+	return old&rwMask(i) == 0 && m.state.CompareAndSwap(old, old|rwPending(i))
+}
+
+// tryRLock registers a reader of slot i unless a writer holds or claims it.
+func (m *RWBitMutex) tryRLock(i int) bool {
+	if m.state.Load()&rwMask(i) != 0 {
+		return false
+	}
+	m.readers[i].Add(1)
+	// A writer may have claimed the slot between the check and the increment;
+	// it will see our count, but we must back off so that it can proceed.
+	if m.state.Load()&rwMask(i) != 0 {
+		m.readers[i].Add(-1)
+		return false
+	}
+	return true
+}
+
+// spinUntil spins with exponential backoff until f returns true.
+func spinUntil(f func() bool) {
+	for spin := 1; !f(); {
+		for range spin {
+			runtime.Gosched()
+		}
+		if spin < maxSpin {
+			spin *= 2
+		}
+	}
+}
+
+// Lock acquires slot i for writing, waiting for active readers to leave.
+//
+// Panics:
+//   - If i is out of range (i < 0 or i ≥ 32)
+func (m *RWBitMutex) Lock(i int) {
+	rwCheck(i)
+	spinUntil(func() bool { return m.tryClaim(i) })
+	spinUntil(func() bool { return m.readers[i].Load() == 0 })
+	m.state.Or(rwLocked(i))
+}
+
+// LockCtx acquires slot i for writing like Lock, but gives up when ctx is done.
+func (m *RWBitMutex) LockCtx(ctx context.Context, i int) error {
+	rwCheck(i)
+	if err := retryBackoff(ctx, func() bool { return m.tryClaim(i) }); err != nil {
+		return err
+	}
+	if err := retryBackoff(ctx, func() bool { return m.readers[i].Load() == 0 }); err != nil {
+		m.state.And(^rwPending(i))
+		return err
+	}
+	m.state.Or(rwLocked(i))
+	return nil
+}
+
+// TryLock acquires slot i for writing without blocking.
+// Returns false if a writer or any reader holds the slot.
+func (m *RWBitMutex) TryLock(i int) bool {
+	rwCheck(i)
+	if m.readers[i].Load() != 0 || !m.tryClaim(i) {
+		return false
+	}
+	if m.readers[i].Load() != 0 {
+		m.state.And(^rwPending(i))
+		return false
+	}
+	m.state.Or(rwLocked(i))
+	return true
+}
+
+// Unlock releases the write lock of slot i.
+func (m *RWBitMutex) Unlock(i int) {
+	rwCheck(i)
+	m.state.And(^rwMask(i))
+}
+
+// RLock acquires slot i for reading. Any number of readers may hold a slot at once.
+func (m *RWBitMutex) RLock(i int) {
+	rwCheck(i)
+	spinUntil(func() bool { return m.tryRLock(i) })
+}
+
+// RLockCtx acquires slot i for reading like RLock, but gives up when ctx is done.
+func (m *RWBitMutex) RLockCtx(ctx context.Context, i int) error {
+	rwCheck(i)
+	return retryBackoff(ctx, func() bool { return m.tryRLock(i) })
+}
+
+// TryRLock acquires slot i for reading without blocking.
+// Returns false if a writer holds or waits for the slot.
+func (m *RWBitMutex) TryRLock(i int) bool {
+	rwCheck(i)
+	return m.tryRLock(i)
+}
+
+// RUnlock releases a read lock of slot i.
+func (m *RWBitMutex) RUnlock(i int) {
+	rwCheck(i)
+	m.readers[i].Add(-1)
+}
+
+// LockMany acquires the given slots for writing in ascending order, so callers
+// locking overlapping sets cannot deadlock. Duplicates are ignored.
+func (m *RWBitMutex) LockMany(indices ...int) {
+	for _, i := range sortedUnique(indices) {
+		m.Lock(i)
+	}
+}
+
+// UnlockMany releases the write locks of the given slots.
+func (m *RWBitMutex) UnlockMany(indices ...int) {
+	for _, i := range sortedUnique(indices) {
+		m.Unlock(i)
+	}
+}
+
+// sortedUnique returns a sorted copy of indices without duplicates.
+func sortedUnique(indices []int) []int {
+	return slices.Compact(slices.Sorted(slices.Values(indices)))
+}
+
+// retryBackoff retries try with backoff until it succeeds or ctx is done:
+// it first yields the processor, then sleeps for up to maxBackoffWait.
+func retryBackoff(ctx context.Context, try func() bool) error {
+	for spin := 1; ; spin = min(spin*2, 1<<20) {
+		if try() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if spin <= maxSpin {
+			runtime.Gosched()
+			continue
+		}
+
+		t := time.NewTimer(min(time.Duration(spin)*time.Nanosecond, maxBackoffWait))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
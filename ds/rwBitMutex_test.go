@@ -0,0 +1,101 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRWBitMutex(t *testing.T) {
+	t.Run("RWBitMutex 测试", func(t *testing.T) {
+		t.Run("读锁共享写锁独占", func(t *testing.T) {
+			var m RWBitMutex
+			m.RLock(3)
+			assert.True(t, m.TryRLock(3))
+			assert.False(t, m.TryLock(3))
+			assert.True(t, m.TryLock(4))
+			m.RUnlock(3)
+			m.RUnlock(3)
+
+			assert.True(t, m.TryLock(3))
+			assert.False(t, m.TryRLock(3))
+			m.Unlock(3)
+			m.Unlock(4)
+			assert.True(t, m.TryRLock(3))
+			m.RUnlock(3)
+		})
+
+		t.Run("等待中的写者阻止新读者", func(t *testing.T) {
+			var m RWBitMutex
+			m.RLock(0)
+			locked := make(chan struct{})
+			go func() {
+				m.Lock(0)
+				close(locked)
+			}()
+			assert.Eventually(t, func() bool {
+				if m.TryRLock(0) {
+					m.RUnlock(0)
+					return false
+				}
+				return true
+			}, time.Second, time.Millisecond)
+			m.RUnlock(0)
+			<-locked
+			m.Unlock(0)
+		})
+
+		t.Run("LockCtx 超时放弃", func(t *testing.T) {
+			var m RWBitMutex
+			m.RLock(7)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			assert.ErrorIs(t, m.LockCtx(ctx, 7), context.DeadlineExceeded)
+			// the claim is withdrawn, readers can enter again
+			assert.True(t, m.TryRLock(7))
+			m.RUnlock(7)
+			m.RUnlock(7)
+
+			m.Lock(7)
+			ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel2()
+			assert.ErrorIs(t, m.RLockCtx(ctx2, 7), context.DeadlineExceeded)
+			m.Unlock(7)
+		})
+
+		t.Run("越界 panic", func(t *testing.T) {
+			var m RWBitMutex
+			assert.Panics(t, func() { m.Lock(32) })
+			assert.Panics(t, func() { m.RLock(-1) })
+		})
+
+		t.Run("并发读写", func(t *testing.T) {
+			var (
+				m     RWBitMutex
+				value int
+				wg    sync.WaitGroup
+			)
+			for range 4 {
+				wg.Go(func() {
+					for range 500 {
+						m.LockMany(1, 2)
+						value++
+						m.UnlockMany(2, 1)
+					}
+				})
+				wg.Go(func() {
+					for range 500 {
+						m.RLock(1)
+						_ = value
+						m.RUnlock(1)
+					}
+				})
+			}
+			wg.Wait()
+			assert.Equal(t, 2000, value)
+		})
+	})
+}
@@ -1,6 +1,9 @@
 package ds
 
-import "fmt"
+import (
+	"fmt"
+	"iter"
+)
 
 // Set is a generic interface for collections of unique elements.
 // It provides standard set operations like union, intersection, difference, etc.
@@ -21,6 +24,14 @@ type Set[T comparable] interface {
 	Contains(T) bool
 	// Values returns a slice containing all elements in the set.
 	Values() []T
+	// All returns an iterator over all elements in the set, in no particular order.
+	All() iter.Seq[T]
+	// Clone returns a shallow copy of the set with the same implementation.
+	Clone() Set[T]
+	// Equal reports whether both sets contain the same elements.
+	Equal(Set[T]) bool
+	// IsSubset reports whether every element of this set is in the other.
+	IsSubset(Set[T]) bool
 	// Union returns a new set containing all elements from both sets.
 	Union(Set[T]) Set[T]
 	// Intersection returns a new set containing elements present in both sets.
@@ -30,3 +41,21 @@ type Set[T comparable] interface {
 	// SymmetricDifference returns a new set containing elements present in either set but not both.
 	SymmetricDifference(Set[T]) Set[T]
 }
+
+// setEqual reports whether a and b contain the same elements.
+func setEqual[T comparable](a, b Set[T]) bool {
+	return a.Len() == b.Len() && setIsSubset(a, b)
+}
+
+// setIsSubset reports whether every element of a is in b.
+func setIsSubset[T comparable](a, b Set[T]) bool {
+	if a.Len() > b.Len() {
+		return false
+	}
+	for v := range a.All() {
+		if !b.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
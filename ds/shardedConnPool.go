@@ -0,0 +1,179 @@
+package ds
+
+import (
+	"io"
+	"sync"
+)
+
+type (
+	// ShardedConnPool keeps one connection pool per node of a ConsistentHash
+	// and hands out connections to the node responsible for a key.
+	//
+	// If the ConsistentHash is a HashRing, its in-flight load is tracked with
+	// Acquire and Release, so bounded loads account for borrowed connections.
+	//
+	// ShardedConnPool is safe for concurrent use.
+	//
+	// Type parameters:
+	//   - N: The node type, must be comparable
+	//   - T: The connection type, must implement io.Closer
+	ShardedConnPool[N comparable, T io.Closer] struct {
+		mu        sync.RWMutex
+		picker    ConsistentHash[N]
+		factory   func(N) (T, error)
+		residence int
+		opts      []ConnPoolOption[T]
+		pools     map[N]*connPool[T]
+		closed    bool
+	}
+
+	// loadTracker is implemented by ConsistentHash implementations that
+	// account for in-flight load, such as HashRing.
+	loadTracker[N comparable] interface {
+		Acquire(key string) (N, bool)
+		Release(node N)
+	}
+)
+
+// NewShardedConnPool creates a ShardedConnPool over picker. Nodes already in
+// picker get a pool immediately.
+//
+// Parameters:
+//   - picker: Chooses the node of a key
+//   - factory: Required function that creates a connection to a node
+//   - residence: Maximum number of idle connections kept per node
+//   - opts: Options applied to every node's pool
+//
+// Panics:
+//   - If factory is nil
+//
+// Example:
+//
+//	pool := NewShardedConnPool(NewHashRing[string](WithBoundedLoad(0)),
+//		func(addr string) (net.Conn, error) { return net.Dial("tcp", addr) }, 8)
+//	pool.Add("10.0.0.1:6379", 1)
+//	pool.Add("10.0.0.2:6379", 1)
+//
+//	node, conn, err := pool.Get(key)
+//	if err != nil {
+//		return err
+//	}
+//	defer pool.Put(node, conn)
+func NewShardedConnPool[N comparable, T io.Closer](
+	picker ConsistentHash[N],
+	factory func(N) (T, error),
+	residence int,
+	opts ...ConnPoolOption[T],
+) *ShardedConnPool[N, T] {
+	if factory == nil {
+		panic("sharded conn pool: nil factory")
+	}
+	p := &ShardedConnPool[N, T]{
+		picker:    picker,
+		factory:   factory,
+		residence: residence,
+		opts:      opts,
+		pools:     make(map[N]*connPool[T]),
+	}
+	for _, node := range picker.Nodes() {
+		p.pools[node] = p.newPool(node)
+	}
+	return p
+}
+
+func (p *ShardedConnPool[N, T]) newPool(node N) *connPool[T] {
+	return NewConnPool(func() (T, error) { return p.factory(node) }, p.residence, p.opts...)
+}
+
+// Add adds node with the given weight and creates its pool.
+// If the pool is closed, returns ErrConnPoolClosed.
+func (p *ShardedConnPool[N, T]) Add(node N, weight int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrConnPoolClosed
+	}
+	p.picker.Add(node, weight)
+	if _, ok := p.pools[node]; !ok {
+		p.pools[node] = p.newPool(node)
+	}
+	return nil
+}
+
+// Remove removes node and closes its pool. Connections of node that are
+// still borrowed are closed when they are put back.
+func (p *ShardedConnPool[N, T]) Remove(node N) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.picker.Remove(node)
+	if pool, ok := p.pools[node]; ok {
+		pool.Close()
+		delete(p.pools, node)
+	}
+}
+
+// Get returns the node responsible for key and a connection to it.
+//
+// Returns:
+//   - node: The node the connection belongs to; pass it to Put
+//   - conn: The connection
+//   - err: ErrNoNodes if there are no nodes, ErrConnPoolClosed if the pool is
+//     closed, or the error of the factory
+func (p *ShardedConnPool[N, T]) Get(key string) (node N, conn T, err error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return node, conn, ErrConnPoolClosed
+	}
+
+	tracker, tracked := p.picker.(loadTracker[N])
+	var ok bool
+	if tracked {
+		node, ok = tracker.Acquire(key)
+	} else {
+		node, ok = p.picker.Get(key)
+	}
+	if !ok {
+		return node, conn, ErrNoNodes
+	}
+
+	pool, ok := p.pools[node]
+	if !ok {
+		// The node was added to the picker directly, bypassing Add.
+		err = ErrNoNodes
+	} else {
+		conn, err = pool.Get()
+	}
+	if err != nil && tracked {
+		tracker.Release(node)
+	}
+	return node, conn, err
+}
+
+// Put returns a connection obtained from Get to the pool of node.
+// If node has been removed or the pool is closed, the connection is closed.
+func (p *ShardedConnPool[N, T]) Put(node N, conn T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if tracker, ok := p.picker.(loadTracker[N]); ok {
+		tracker.Release(node)
+	}
+	pool, ok := p.pools[node]
+	if !ok || p.closed {
+		return conn.Close()
+	}
+	return pool.Put(conn)
+}
+
+// Close closes the pools of all nodes. It is idempotent.
+func (p *ShardedConnPool[N, T]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for _, pool := range p.pools {
+		pool.Close()
+	}
+}
@@ -0,0 +1,96 @@
+package ds
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nodeConn struct {
+	mockConn
+	node string
+}
+
+func TestShardedConnPool(t *testing.T) {
+	newPool := func(picker ConsistentHash[string]) (*ShardedConnPool[string, *nodeConn], *atomic.Int32) {
+		var created atomic.Int32
+		p := NewShardedConnPool(picker, func(node string) (*nodeConn, error) {
+			if node == "bad" {
+				return nil, errors.New("dial failed")
+			}
+			created.Add(1)
+			return &nodeConn{node: node}, nil
+		}, 2)
+		return p, &created
+	}
+
+	t.Run("ShardedConnPool 测试", func(t *testing.T) {
+		t.Run("无节点", func(t *testing.T) {
+			p, _ := newPool(NewHashRing[string]())
+			_, _, err := p.Get("k")
+			assert.ErrorIs(t, err, ErrNoNodes)
+		})
+
+		t.Run("按节点复用连接", func(t *testing.T) {
+			ring := NewHashRing[string]()
+			ring.Add("a", 1) // nodes already in the picker get a pool
+			p, created := newPool(ring)
+			assert.NoError(t, p.Add("b", 1))
+
+			node, conn, err := p.Get("user-1")
+			assert.NoError(t, err)
+			assert.Equal(t, node, conn.node)
+			want, _ := ring.Get("user-1")
+			assert.Equal(t, want, node)
+			assert.Equal(t, int64(1), ring.Load(node))
+
+			assert.NoError(t, p.Put(node, conn))
+			assert.Equal(t, int64(0), ring.Load(node))
+			_, again, _ := p.Get("user-1")
+			assert.Same(t, conn, again)
+			assert.Equal(t, int32(1), created.Load())
+		})
+
+		t.Run("删除节点后归还的连接被关闭", func(t *testing.T) {
+			p, _ := newPool(NewRendezvousHash[string](nil))
+			assert.NoError(t, p.Add("a", 1))
+			node, conn, err := p.Get("k")
+			assert.NoError(t, err)
+			p.Remove(node)
+			assert.NoError(t, p.Put(node, conn))
+			assert.True(t, conn.isClosed())
+			_, _, err = p.Get("k")
+			assert.ErrorIs(t, err, ErrNoNodes)
+		})
+
+		t.Run("factory 失败时释放负载", func(t *testing.T) {
+			ring := NewHashRing[string]()
+			p, _ := newPool(ring)
+			assert.NoError(t, p.Add("bad", 1))
+			_, _, err := p.Get("k")
+			assert.Error(t, err)
+			assert.Equal(t, int64(0), ring.Load("bad"))
+		})
+
+		t.Run("Close", func(t *testing.T) {
+			p, _ := newPool(NewJumpHash[string](nil))
+			assert.NoError(t, p.Add("a", 1))
+			node, conn, _ := p.Get("k")
+			p.Close()
+			p.Close()
+			_, _, err := p.Get("k")
+			assert.ErrorIs(t, err, ErrConnPoolClosed)
+			assert.ErrorIs(t, p.Add("b", 1), ErrConnPoolClosed)
+			assert.NoError(t, p.Put(node, conn))
+			assert.True(t, conn.isClosed())
+		})
+
+		t.Run("factory为nil panic", func(t *testing.T) {
+			assert.Panics(t, func() {
+				NewShardedConnPool[string, *nodeConn](NewHashRing[string](), nil, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,134 @@
+package ds
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrIncompatibleSketch is returned when merging or decoding probabilistic
+	// structures created with different parameters.
+	ErrIncompatibleSketch = errors.New("incompatible sketch parameters")
+	// ErrInvalidEncoding is returned when decoding malformed binary data.
+	ErrInvalidEncoding = errors.New("invalid binary encoding")
+)
+
+// Hasher maps an element to a 64-bit hash for the probabilistic structures
+// (BloomFilter, CuckooFilter, HyperLogLog, CountMinSketch, TopK).
+//
+// The hash must be deterministic across processes: structures are only
+// comparable, mergeable and decodable when built with the same Hasher.
+// HashString, HashBytes and HashUint64 are ready-made Hashers.
+type Hasher[T any] func(T) uint64
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// HashString hashes s with FNV-1a followed by a 64-bit finalizer.
+func HashString(s string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return HashUint64(h)
+}
+
+// HashBytes hashes b with FNV-1a followed by a 64-bit finalizer.
+func HashBytes(b []byte) uint64 {
+	h := uint64(fnvOffset64)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return HashUint64(h)
+}
+
+// HashUint64 mixes v with the MurmurHash3 finalizer, so that every input bit
+// affects every output bit.
+func HashUint64(v uint64) uint64 {
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return v
+}
+
+// doubleHash derives the i-th of several independent indexes in [0, n) from a
+// single 64-bit hash (Kirsch-Mitzenmacher).
+func doubleHash(h uint64, i, n uint64) uint64 {
+	h1, h2 := h, h>>32|h<<32|1
+	return (h1 + i*h2) % n
+}
+
+// sketchReader decodes the big-endian binary format of the probabilistic
+// structures. The first read past the end sets err and returns zeros.
+type sketchReader struct {
+	b   []byte
+	err error
+}
+
+// bytes returns the next n bytes, or nil if fewer are left.
+func (r *sketchReader) bytes(n uint64) []byte {
+	if r.err != nil || uint64(len(r.b)) < n {
+		r.err = ErrInvalidEncoding
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *sketchReader) next(n int) []byte {
+	if b := r.bytes(uint64(n)); b != nil {
+		return b
+	}
+	return make([]byte, n)
+}
+
+func (r *sketchReader) u8() uint8   { return r.next(1)[0] }
+func (r *sketchReader) u16() uint16 { return binary.BigEndian.Uint16(r.next(2)) }
+func (r *sketchReader) u32() uint32 { return binary.BigEndian.Uint32(r.next(4)) }
+func (r *sketchReader) u64() uint64 { return binary.BigEndian.Uint64(r.next(8)) }
+
+func (r *sketchReader) u64s(n uint64) []uint64 {
+	if r.err != nil || n > uint64(len(r.b))/8 {
+		r.err = ErrInvalidEncoding
+		return nil
+	}
+	vs := make([]uint64, n)
+	for i := range vs {
+		vs[i] = r.u64()
+	}
+	return vs
+}
+
+func (r *sketchReader) u16s(n uint64) []uint16 {
+	if r.err != nil || n > uint64(len(r.b))/2 {
+		r.err = ErrInvalidEncoding
+		return nil
+	}
+	vs := make([]uint16, n)
+	for i := range vs {
+		vs[i] = r.u16()
+	}
+	return vs
+}
+
+// header checks the magic byte and version of an encoding.
+func (r *sketchReader) header(magic, version byte) {
+	if r.u8() != magic || r.u8() != version {
+		r.err = ErrInvalidEncoding
+	}
+}
+
+// done reports the first error, or ErrInvalidEncoding if bytes are left over.
+func (r *sketchReader) done() error {
+	if r.err == nil && len(r.b) > 0 {
+		return ErrInvalidEncoding
+	}
+	return r.err
+}
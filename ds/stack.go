@@ -1,5 +1,7 @@
 package ds
 
+import "iter"
+
 // Stack is a generic interface for Last-In-First-Out (LIFO) data structures.
 // It provides basic stack operations like push, pop, and peek.
 //
@@ -18,4 +20,6 @@ type Stack[T any] interface {
 	Empty() bool
 	// Size returns the number of elements in the stack.
 	Size() int
+	// All returns an iterator over the elements from top to bottom.
+	All() iter.Seq[T]
 }
@@ -1,8 +1,16 @@
 package ds
 
-import "sync"
-
-var _ Map[int, int] = (*SyncMap[int, int])(nil)
+import (
+	"encoding/json"
+	"iter"
+	"sync"
+)
+
+var (
+	_ Map[int, int]    = (*SyncMap[int, int])(nil)
+	_ json.Marshaler   = (*SyncMap[int, int])(nil)
+	_ json.Unmarshaler = (*SyncMap[int, int])(nil)
+)
 
 // SyncMap Generic wrapper for sync.Map
 type SyncMap[K comparable, V any] struct {
@@ -156,3 +164,75 @@ func (m *SyncMap[K, V]) Filter(filter func(K, V) bool) Map[K, V] {
 	})
 	return result
 }
+
+// Len returns the number of entries in the map. It is O(n): sync.Map keeps no count.
+func (m *SyncMap[K, V]) Len() int {
+	n := 0
+	m.entries.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// All returns an iterator over all entries, in no particular order.
+// It has the same consistency guarantees as Range.
+func (m *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+// Clone returns a copy of the map.
+func (m *SyncMap[K, V]) Clone() Map[K, V] {
+	result := NewSyncMap[K, V]()
+	for k, v := range m.All() {
+		result.Store(k, v)
+	}
+	return result
+}
+
+// MarshalJSON encodes the map as a JSON object.
+func (m *SyncMap[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.snapshot())
+}
+
+// UnmarshalJSON replaces the contents of the map with the entries of a JSON object.
+// It is not atomic with respect to concurrent readers.
+func (m *SyncMap[K, V]) UnmarshalJSON(data []byte) error {
+	var entries map[K]V
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	m.reset(entries)
+	return nil
+}
+
+// GobEncode encodes the map as a gob stream.
+func (m *SyncMap[K, V]) GobEncode() ([]byte, error) {
+	return GobCodec[map[K]V]{}.Encode(m.snapshot())
+}
+
+// GobDecode replaces the contents of the map with the decoded entries.
+// It is not atomic with respect to concurrent readers.
+func (m *SyncMap[K, V]) GobDecode(data []byte) error {
+	entries, err := GobCodec[map[K]V]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+	m.reset(entries)
+	return nil
+}
+
+func (m *SyncMap[K, V]) snapshot() map[K]V {
+	entries := make(map[K]V)
+	for k, v := range m.All() {
+		entries[k] = v
+	}
+	return entries
+}
+
+func (m *SyncMap[K, V]) reset(entries map[K]V) {
+	m.entries.Clear()
+	for k, v := range entries {
+		m.entries.Store(k, v)
+	}
+}
@@ -1,7 +1,9 @@
 package ds
 
 import (
+	"encoding/json"
 	"fmt"
+	"iter"
 	"maps"
 	"slices"
 	"sync"
@@ -15,8 +17,11 @@ type (
 	}
 )
 
-// Ensure SyncSet implements Set[int] interface (assumed to exist).
-var _ Set[int] = (*SyncSet[int])(nil)
+var (
+	_ Set[int]         = (*SyncSet[int])(nil)
+	_ json.Marshaler   = (*SyncSet[int])(nil)
+	_ json.Unmarshaler = (*SyncSet[int])(nil)
+)
 
 // NewSyncSet creates a new SyncSet and optionally adds initial items.
 func NewSyncSet[T comparable](items ...T) *SyncSet[T] {
@@ -36,8 +41,6 @@ func (s *SyncSet[T]) Len() int {
 
 // String returns the string representation of the set's elements.
 func (s *SyncSet[T]) String() string {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
 	return fmt.Sprint(s.Values())
 }
 
@@ -78,6 +81,18 @@ func (s *SyncSet[T]) Values() []T {
 	return slices.Collect(maps.Keys(s.entries))
 }
 
+// All returns an iterator over a snapshot of the elements, in no particular order.
+// The set may be modified during iteration.
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range s.Values() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
 // Union returns a new set containing all elements from the current set and another set.
 func (s *SyncSet[T]) Union(s1 Set[T]) Set[T] {
 	result := NewSyncSet[T](s.Values()...)
@@ -122,3 +137,64 @@ func (s *SyncSet[T]) SymmetricDifference(s1 Set[T]) Set[T] {
 	}
 	return result
 }
+
+// Clone returns a copy of the set.
+func (s *SyncSet[T]) Clone() Set[T] {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	entries := maps.Clone(s.entries)
+	if entries == nil {
+		entries = make(map[T]struct{})
+	}
+	return &SyncSet[T]{entries: entries}
+}
+
+// Equal reports whether both sets contain the same elements.
+func (s *SyncSet[T]) Equal(s1 Set[T]) bool {
+	return setEqual[T](s, s1)
+}
+
+// IsSubset reports whether every element of this set is in the other.
+func (s *SyncSet[T]) IsSubset(s1 Set[T]) bool {
+	return setIsSubset[T](s, s1)
+}
+
+// MarshalJSON encodes the set as a JSON array, in no particular order.
+func (s *SyncSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Values())
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of a JSON array.
+func (s *SyncSet[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	s.reset(values)
+	return nil
+}
+
+// GobEncode encodes the set as a gob stream of its elements.
+func (s *SyncSet[T]) GobEncode() ([]byte, error) {
+	return GobCodec[[]T]{}.Encode(s.Values())
+}
+
+// GobDecode replaces the contents of the set with the decoded elements.
+func (s *SyncSet[T]) GobDecode(data []byte) error {
+	values, err := GobCodec[[]T]{}.Decode(data)
+	if err != nil {
+		return err
+	}
+	s.reset(values)
+	return nil
+}
+
+func (s *SyncSet[T]) reset(values []T) {
+	entries := make(map[T]struct{}, len(values))
+	for _, v := range values {
+		entries[v] = struct{}{}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = entries
+}
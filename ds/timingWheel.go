@@ -0,0 +1,332 @@
+package ds
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWheelTick is the tick duration of the default TimingWheel.
+	DefaultWheelTick = 10 * time.Millisecond
+	// DefaultWheelSize is the number of buckets per level of a TimingWheel.
+	DefaultWheelSize = 64
+)
+
+// ErrTimingWheelStopped indicates the TimingWheel was stopped.
+var ErrTimingWheelStopped = errors.New("timing wheel stopped")
+
+var (
+	defaultWheel      *TimingWheel
+	defaultWheelMutex sync.Mutex
+)
+
+type (
+	// TimingWheel is a hierarchical timing wheel that multiplexes many timers
+	// onto a single time.Ticker.
+	//
+	// Level 0 has wheelSize buckets of one tick each; every further level has
+	// wheelSize buckets that each span a whole lower level. A timer is placed in
+	// the lowest level that can hold its deadline and cascades down as the wheel
+	// turns. Inserting and cancelling a timer are O(1); the resolution is one tick.
+	//
+	// Timer callbacks run on the wheel goroutine, in deadline order per tick.
+	// They must not block; start a goroutine for long-running work.
+	TimingWheel struct {
+		mutex  sync.Mutex
+		tick   time.Duration
+		size   uint64
+		start  time.Time
+		now    uint64         // ticks elapsed since start
+		levels [][]*list.List // levels[l][bucket] -> *WheelTimer
+		count  int
+		ctx    context.Context // done when the wheel is stopped
+		cf     context.CancelFunc
+	}
+
+	// WheelTimer is a timer scheduled on a TimingWheel.
+	WheelTimer struct {
+		w       *TimingWheel
+		expire  uint64 // absolute deadline in ticks
+		period  uint64 // reschedule interval in ticks, 0 for one-shot
+		f       func()
+		bucket  *list.List
+		elem    *list.Element
+		stopped bool
+	}
+
+	// WheelTicker delivers ticks on a channel at intervals, like time.Ticker,
+	// but is driven by a TimingWheel.
+	WheelTicker struct {
+		C <-chan time.Time // The channel on which the ticks are delivered.
+
+		c     chan time.Time
+		w     *TimingWheel
+		mutex sync.Mutex
+		timer *WheelTimer
+	}
+)
+
+// DefaultTimingWheel returns a process-wide TimingWheel with DefaultWheelTick
+// resolution, started on first use and after StopDefaultTimingWheel.
+func DefaultTimingWheel() *TimingWheel {
+	defaultWheelMutex.Lock()
+	defer defaultWheelMutex.Unlock()
+
+	if defaultWheel == nil {
+		defaultWheel = NewTimingWheel(DefaultWheelTick, DefaultWheelSize)
+	}
+	return defaultWheel
+}
+
+// StopDefaultTimingWheel stops the wheel returned by DefaultTimingWheel, if
+// it was started. Timers, caches and delay queues scheduled on it stop
+// firing; the next call to DefaultTimingWheel starts a new wheel.
+func StopDefaultTimingWheel() {
+	defaultWheelMutex.Lock()
+	defer defaultWheelMutex.Unlock()
+
+	if defaultWheel != nil {
+		defaultWheel.Stop()
+		defaultWheel = nil
+	}
+}
+
+// NewTimingWheel creates and starts a TimingWheel.
+//
+// Parameters:
+//   - tick: The resolution of the wheel. Use 0 for DefaultWheelTick.
+//   - wheelSize: The number of buckets per level. Use 0 for DefaultWheelSize.
+//
+// Call Stop when the wheel is no longer needed to release its goroutine.
+//
+// Example:
+//
+//	w := NewTimingWheel(10*time.Millisecond, 64)
+//	defer w.Stop()
+//	t := w.AfterFunc(time.Second, func() { fmt.Println("fired") })
+//	t.Stop() // cancel
+func NewTimingWheel(tick time.Duration, wheelSize int) *TimingWheel {
+	if tick <= 0 {
+		tick = DefaultWheelTick
+	}
+	if wheelSize <= 1 {
+		wheelSize = DefaultWheelSize
+	}
+	w := &TimingWheel{
+		tick:  tick,
+		size:  uint64(wheelSize),
+		start: time.Now(),
+	}
+	w.levels = [][]*list.List{w.newLevel()}
+
+	w.ctx, w.cf = context.WithCancel(context.Background())
+	go w.run(w.ctx)
+	return w
+}
+
+// Tick returns the resolution of the wheel.
+func (w *TimingWheel) Tick() time.Duration {
+	return w.tick
+}
+
+// Len returns the number of pending timers.
+func (w *TimingWheel) Len() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.count
+}
+
+// AfterFunc schedules f to run once after d. The deadline is rounded up to the next tick.
+func (w *TimingWheel) AfterFunc(d time.Duration, f func()) *WheelTimer {
+	return w.schedule(d, 0, f)
+}
+
+// EveryFunc schedules f to run every interval until the returned timer is stopped.
+// The interval is rounded up to a whole number of ticks.
+func (w *TimingWheel) EveryFunc(interval time.Duration, f func()) *WheelTimer {
+	return w.schedule(interval, w.ticks(interval), f)
+}
+
+// NewTicker returns a WheelTicker that sends the current time on its channel
+// every interval. Like time.Ticker, ticks are dropped if the receiver falls behind.
+func (w *TimingWheel) NewTicker(interval time.Duration) *WheelTicker {
+	c := make(chan time.Time, 1)
+	t := &WheelTicker{C: c, c: c, w: w}
+	t.timer = w.EveryFunc(interval, t.send)
+	return t
+}
+
+// Stop stops the wheel goroutine. Pending timers never fire.
+func (w *TimingWheel) Stop() {
+	w.cf()
+}
+
+// Done returns a channel that is closed when the wheel is stopped.
+func (w *TimingWheel) Done() <-chan struct{} {
+	return w.ctx.Done()
+}
+
+func (w *TimingWheel) schedule(d time.Duration, period uint64, f func()) *WheelTimer {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	t := &WheelTimer{w: w, period: period, f: f}
+	t.expire = w.elapsed() + w.ticks(d)
+	w.add(t)
+	return t
+}
+
+// ticks converts d to a number of ticks, rounded up, at least 1.
+func (w *TimingWheel) ticks(d time.Duration) uint64 {
+	if d <= w.tick {
+		return 1
+	}
+	return uint64((d + w.tick - 1) / w.tick)
+}
+
+// elapsed returns the current time in ticks. Between two turns of the wheel the
+// clock may be ahead of w.now; deadlines are based on the real clock so that a
+// busy wheel does not delay newly scheduled timers.
+func (w *TimingWheel) elapsed() uint64 {
+	return max(w.now, uint64(time.Since(w.start)/w.tick))
+}
+
+// add places t in the lowest level whose span covers its deadline.
+// Caller must hold the mutex.
+func (w *TimingWheel) add(t *WheelTimer) {
+	var (
+		span  uint64 = 1
+		level int
+	)
+	for ; ; level++ {
+		if level == len(w.levels) {
+			w.levels = append(w.levels, w.newLevel())
+		}
+		if t.expire/span-w.now/span < w.size {
+			break
+		}
+		span *= w.size
+	}
+	t.bucket = w.levels[level][(t.expire/span)%w.size]
+	t.elem = t.bucket.PushBack(t)
+	w.count++
+}
+
+func (w *TimingWheel) remove(t *WheelTimer) {
+	t.bucket.Remove(t.elem)
+	t.bucket, t.elem = nil, nil
+	w.count--
+}
+
+func (w *TimingWheel) newLevel() []*list.List {
+	buckets := make([]*list.List, w.size)
+	for i := range buckets {
+		buckets[i] = list.New()
+	}
+	return buckets
+}
+
+func (w *TimingWheel) run(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.advance(uint64(time.Since(w.start) / w.tick))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// advance turns the wheel up to the given tick, firing all due timers.
+func (w *TimingWheel) advance(target uint64) {
+	for {
+		w.mutex.Lock()
+		if w.now >= target {
+			w.mutex.Unlock()
+			return
+		}
+		w.now++
+
+		// Cascade higher levels whose bucket boundary was crossed.
+		span := w.size
+		for level := 1; level < len(w.levels) && w.now%span == 0; level++ {
+			bucket := w.levels[level][(w.now/span)%w.size]
+			for e := bucket.Front(); e != nil; {
+				next := e.Next()
+				t := e.Value.(*WheelTimer)
+				w.remove(t)
+				w.add(t)
+				e = next
+			}
+			span *= w.size
+		}
+
+		var (
+			bucket = w.levels[0][w.now%w.size]
+			fired  = make([]func(), 0, bucket.Len())
+		)
+		for e := bucket.Front(); e != nil; {
+			next := e.Next()
+			t := e.Value.(*WheelTimer)
+			w.remove(t)
+			fired = append(fired, t.f)
+			if t.period > 0 {
+				t.expire = w.now + t.period
+				w.add(t)
+			}
+			e = next
+		}
+		w.mutex.Unlock()
+
+		for _, f := range fired {
+			f()
+		}
+	}
+}
+
+// Stop cancels the timer. It returns true if the call stops the timer,
+// false if the timer already fired (one-shot) or was stopped.
+func (t *WheelTimer) Stop() bool {
+	w := t.w
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	if t.elem == nil {
+		return false
+	}
+	w.remove(t)
+	return true
+}
+
+// Reset stops the ticker and resets its period to interval.
+func (t *WheelTicker) Reset(interval time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.timer.Stop()
+	t.timer = t.w.EveryFunc(interval, t.send)
+}
+
+// Stop turns off the ticker. No more ticks will be sent; the channel is not closed.
+func (t *WheelTicker) Stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.timer.Stop()
+}
+
+func (t *WheelTicker) send() {
+	select {
+	case t.c <- time.Now():
+	default:
+	}
+}
@@ -0,0 +1,207 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimingWheelAfterFunc(t *testing.T) {
+	t.Run("TimingWheel AfterFunc 测试", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 8)
+		defer w.Stop()
+
+		t.Run("按截止时间顺序触发", func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				order []int
+				wg    sync.WaitGroup
+			)
+			// 8 buckets of 1ms per level: 30ms and 100ms need higher levels and cascade.
+			for i, d := range []time.Duration{100, 5, 30, 1} {
+				wg.Add(1)
+				w.AfterFunc(d*time.Millisecond, func() {
+					defer wg.Done()
+					mu.Lock()
+					order = append(order, i)
+					mu.Unlock()
+				})
+			}
+			start := time.Now()
+			wg.Wait()
+			assert.Equal(t, []int{3, 1, 2, 0}, order)
+			assert.GreaterOrEqual(t, time.Since(start), 95*time.Millisecond)
+			assert.Equal(t, 0, w.Len())
+		})
+
+		t.Run("Stop取消定时器", func(t *testing.T) {
+			var fired atomic.Bool
+			timer := w.AfterFunc(20*time.Millisecond, func() { fired.Store(true) })
+			assert.Equal(t, 1, w.Len())
+			assert.True(t, timer.Stop())
+			assert.False(t, timer.Stop())
+			assert.Equal(t, 0, w.Len())
+
+			time.Sleep(40 * time.Millisecond)
+			assert.False(t, fired.Load())
+		})
+
+		t.Run("已触发的定时器Stop返回false", func(t *testing.T) {
+			done := make(chan struct{})
+			timer := w.AfterFunc(time.Millisecond, func() { close(done) })
+			<-done
+			assert.False(t, timer.Stop())
+		})
+	})
+}
+
+func TestTimingWheelEvery(t *testing.T) {
+	t.Run("TimingWheel 周期定时器测试", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 4)
+		defer w.Stop()
+
+		var n atomic.Int32
+		timer := w.EveryFunc(5*time.Millisecond, func() { n.Add(1) })
+		time.Sleep(60 * time.Millisecond)
+		timer.Stop()
+		got := n.Load()
+		assert.GreaterOrEqual(t, got, int32(5))
+
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, got, n.Load())
+	})
+
+	t.Run("WheelTicker 测试", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 16)
+		defer w.Stop()
+
+		ticker := w.NewTicker(5 * time.Millisecond)
+		for range 3 {
+			select {
+			case <-ticker.C:
+			case <-time.After(time.Second):
+				t.Fatal("ticker did not fire")
+			}
+		}
+		ticker.Reset(time.Hour)
+		time.Sleep(15 * time.Millisecond)
+		for len(ticker.C) > 0 {
+			<-ticker.C
+		}
+		select {
+		case <-ticker.C:
+			t.Fatal("ticker fired after Reset")
+		case <-time.After(20 * time.Millisecond):
+		}
+		ticker.Stop()
+	})
+}
+
+func TestTimingWheelMany(t *testing.T) {
+	t.Run("TimingWheel 大量定时器测试", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 64)
+		defer w.Stop()
+
+		var (
+			n      = 100000
+			fired  atomic.Int32
+			timers = make([]*WheelTimer, n)
+		)
+		for i := range n {
+			timers[i] = w.AfterFunc(time.Duration(i%50)*time.Millisecond, func() { fired.Add(1) })
+		}
+		// Cancel every other timer.
+		var stopped int32
+		for i := 0; i < n; i += 2 {
+			if timers[i].Stop() {
+				stopped++
+			}
+		}
+		assert.Eventually(t, func() bool { return w.Len() == 0 }, 2*time.Second, 5*time.Millisecond)
+		assert.Equal(t, int32(n)-stopped, fired.Load())
+	})
+}
+
+func TestDelayQueue(t *testing.T) {
+	t.Run("DelayQueue 测试", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 16)
+		defer w.Stop()
+		q := NewDelayQueue[string](w)
+
+		q.Offer("late", 30*time.Millisecond)
+		q.OfferAt("early", time.Now().Add(10*time.Millisecond))
+		cancel := q.Offer("cancelled", 20*time.Millisecond)
+		assert.Equal(t, 3, q.Pending())
+		assert.True(t, cancel())
+		assert.Equal(t, 2, q.Pending())
+
+		_, ok := q.Poll()
+		assert.False(t, ok)
+
+		ctx, cf := context.WithTimeout(context.Background(), time.Second)
+		defer cf()
+		v, err := q.Take(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "early", v)
+		v, err = q.Take(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "late", v)
+		assert.Equal(t, 0, q.Pending())
+		assert.False(t, cancel())
+
+		ctx2, cf2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cf2()
+		_, err = q.Take(ctx2)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("TimingWheel Stop 唤醒阻塞的Take", func(t *testing.T) {
+		w := NewTimingWheel(time.Millisecond, 16)
+		q := NewDelayQueue[string](w)
+		q.Offer("never", time.Hour)
+
+		errc := make(chan error, 1)
+		go func() {
+			_, err := q.Take(context.Background())
+			errc <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		w.Stop()
+		w.Stop()
+
+		select {
+		case err := <-errc:
+			assert.Equal(t, ErrTimingWheelStopped, err)
+		case <-time.After(time.Second):
+			t.Fatal("Take did not return after Stop")
+		}
+	})
+}
+
+func TestDefaultTimingWheel(t *testing.T) {
+	t.Run("DefaultTimingWheel 停止后重新启动", func(t *testing.T) {
+		w := DefaultTimingWheel()
+		assert.Same(t, w, DefaultTimingWheel())
+
+		StopDefaultTimingWheel()
+		select {
+		case <-w.Done():
+		default:
+			t.Fatal("default wheel not stopped")
+		}
+
+		w2 := DefaultTimingWheel()
+		assert.NotSame(t, w, w2)
+		done := make(chan struct{})
+		w2.AfterFunc(time.Millisecond, func() { close(done) })
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("timer did not fire")
+		}
+	})
+}
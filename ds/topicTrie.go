@@ -0,0 +1,226 @@
+package ds
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// TopicSeparator separates the levels of a hierarchical topic, e.g. "alerts/web/api".
+	TopicSeparator = "/"
+	// TopicWildcardOne matches exactly one topic level, e.g. "alerts/+/api".
+	TopicWildcardOne = "+"
+	// TopicWildcardMulti matches any number of trailing topic levels (including zero),
+	// e.g. "alerts/#". It must be the last level of a filter.
+	TopicWildcardMulti = "#"
+)
+
+var (
+	// ErrInvalidTopic indicates the topic name is empty or contains wildcards.
+	ErrInvalidTopic = errors.New("invalid topic")
+
+	// ErrInvalidTopicFilter indicates the topic filter is empty or uses wildcards incorrectly.
+	ErrInvalidTopicFilter = errors.New("invalid topic filter")
+)
+
+type (
+	// topicTrie stores values by MQTT-style topic filters and resolves
+	// which filters match a concrete topic.
+	//
+	// Filters are split on TopicSeparator; each level is a trie node.
+	// A "+" level matches any single level and a trailing "#" level
+	// matches the remainder of the topic. Not concurrency-safe.
+	topicTrie[V any] struct {
+		root *topicNode[V]
+		size int
+	}
+
+	// topicNode is a single level of a topicTrie.
+	topicNode[V any] struct {
+		children map[string]*topicNode[V]
+		value    V
+		has      bool
+	}
+)
+
+// newTopicTrie creates an empty topicTrie.
+func newTopicTrie[V any]() *topicTrie[V] {
+	return &topicTrie[V]{root: &topicNode[V]{}}
+}
+
+// ValidateTopic reports whether topic is a valid publish topic:
+// non-empty and free of wildcard levels.
+func ValidateTopic(topic string) error {
+	if topic == "" {
+		return ErrInvalidTopic
+	}
+	for level := range strings.SplitSeq(topic, TopicSeparator) {
+		if strings.ContainsAny(level, TopicWildcardOne+TopicWildcardMulti) {
+			return ErrInvalidTopic
+		}
+	}
+	return nil
+}
+
+// ValidateTopicFilter reports whether filter is a valid subscription filter.
+// Wildcards must occupy a whole level, and "#" may only appear as the last level.
+func ValidateTopicFilter(filter string) error {
+	if filter == "" {
+		return ErrInvalidTopicFilter
+	}
+	levels := strings.Split(filter, TopicSeparator)
+	for i, level := range levels {
+		switch level {
+		case TopicWildcardOne:
+		case TopicWildcardMulti:
+			if i != len(levels)-1 {
+				return ErrInvalidTopicFilter
+			}
+		default:
+			if strings.ContainsAny(level, TopicWildcardOne+TopicWildcardMulti) {
+				return ErrInvalidTopicFilter
+			}
+		}
+	}
+	return nil
+}
+
+// MatchTopic reports whether the concrete topic matches the filter.
+func MatchTopic(filter, topic string) bool {
+	var (
+		fs = strings.Split(filter, TopicSeparator)
+		ts = strings.Split(topic, TopicSeparator)
+	)
+	for i, f := range fs {
+		if f == TopicWildcardMulti {
+			return true
+		}
+		if i >= len(ts) || (f != TopicWildcardOne && f != ts[i]) {
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
+
+// Len returns the number of filters stored in the trie.
+func (t *topicTrie[V]) Len() int {
+	return t.size
+}
+
+// Get returns the value stored at exactly the given filter.
+func (t *topicTrie[V]) Get(filter string) (value V, ok bool) {
+	n := t.root
+	for level := range strings.SplitSeq(filter, TopicSeparator) {
+		if n = n.children[level]; n == nil {
+			return value, false
+		}
+	}
+	return n.value, n.has
+}
+
+// Put stores value at the given filter, replacing any previous value.
+func (t *topicTrie[V]) Put(filter string, value V) {
+	n := t.root
+	for level := range strings.SplitSeq(filter, TopicSeparator) {
+		child, ok := n.children[level]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[string]*topicNode[V])
+			}
+			child = &topicNode[V]{}
+			n.children[level] = child
+		}
+		n = child
+	}
+	if !n.has {
+		t.size++
+	}
+	n.value, n.has = value, true
+}
+
+// Delete removes the value stored at the given filter and prunes empty nodes.
+// Returns the removed value and whether it existed.
+func (t *topicTrie[V]) Delete(filter string) (value V, ok bool) {
+	var (
+		levels = strings.Split(filter, TopicSeparator)
+		path   = make([]*topicNode[V], 0, len(levels)+1)
+		n      = t.root
+	)
+	path = append(path, n)
+	for _, level := range levels {
+		if n = n.children[level]; n == nil {
+			return value, false
+		}
+		path = append(path, n)
+	}
+	if !n.has {
+		return value, false
+	}
+
+	value = n.value
+	var zero V
+	n.value, n.has = zero, false
+	t.size--
+
+	// Prune now-empty nodes from the leaf upwards.
+	for i := len(levels) - 1; i >= 0; i-- {
+		child := path[i+1]
+		if child.has || len(child.children) > 0 {
+			break
+		}
+		delete(path[i].children, levels[i])
+	}
+	return value, true
+}
+
+// Match calls fn for the value of every filter that matches the concrete topic.
+// Iteration stops early if fn returns false.
+func (t *topicTrie[V]) Match(topic string, fn func(filter string, value V) bool) {
+	t.root.match(strings.Split(topic, TopicSeparator), nil, fn)
+}
+
+func (n *topicNode[V]) match(levels, prefix []string, fn func(string, V) bool) bool {
+	// A trailing "#" also matches the parent level itself ("a/#" matches "a").
+	if multi, ok := n.children[TopicWildcardMulti]; ok && multi.has {
+		if !fn(strings.Join(append(prefix, TopicWildcardMulti), TopicSeparator), multi.value) {
+			return false
+		}
+	}
+	if len(levels) == 0 {
+		if n.has && len(prefix) > 0 {
+			return fn(strings.Join(prefix, TopicSeparator), n.value)
+		}
+		return true
+	}
+
+	level, rest := levels[0], levels[1:]
+	if child, ok := n.children[level]; ok {
+		if !child.match(rest, append(prefix, level), fn) {
+			return false
+		}
+	}
+	if child, ok := n.children[TopicWildcardOne]; ok {
+		if !child.match(rest, append(prefix, TopicWildcardOne), fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Range calls fn for every stored filter and value.
+// Iteration stops early if fn returns false.
+func (t *topicTrie[V]) Range(fn func(filter string, value V) bool) {
+	t.root.walk(nil, fn)
+}
+
+func (n *topicNode[V]) walk(prefix []string, fn func(string, V) bool) bool {
+	if n.has && !fn(strings.Join(prefix, TopicSeparator), n.value) {
+		return false
+	}
+	for level, child := range n.children {
+		if !child.walk(append(prefix, level), fn) {
+			return false
+		}
+	}
+	return true
+}
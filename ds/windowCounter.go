@@ -0,0 +1,117 @@
+package ds
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWindowBuckets is the number of buckets used when NewWindowCounter gets buckets <= 0.
+const DefaultWindowBuckets = 10
+
+type (
+	// WindowCounter counts events over a sliding time window.
+	//
+	// The window is split into N buckets; adding to the counter increments the
+	// bucket of the current time slot, and buckets older than the window are
+	// ignored and reused. The count is therefore approximate to one bucket width.
+	//
+	// WindowCounter is lock-free and safe for concurrent use.
+	WindowCounter struct {
+		width   int64 // bucket width in nanoseconds
+		buckets []windowBucket
+		start   time.Time
+		now     func() time.Time
+	}
+
+	// windowBucket holds the count of its latest time slot. Moving the bucket
+	// to a new slot swaps in a new windowSlot, so the slot and its count
+	// change together and no Add is lost to a reset.
+	windowBucket struct {
+		cur atomic.Pointer[windowSlot]
+	}
+
+	// windowSlot is the count of one time slot.
+	windowSlot struct {
+		slot  int64 // time slot index the count belongs to
+		count atomic.Int64
+	}
+)
+
+// NewWindowCounter creates a WindowCounter over window, split into buckets buckets.
+//
+// Example:
+//
+//	// requests in the last minute, with 1 second resolution
+//	c := NewWindowCounter(time.Minute, 60)
+//	c.Add(1)
+//	perSecond := c.Rate(time.Second)
+func NewWindowCounter(window time.Duration, buckets int) *WindowCounter {
+	if buckets <= 0 {
+		buckets = DefaultWindowBuckets
+	}
+	width := max(1, int64(window)/int64(buckets))
+	c := &WindowCounter{
+		width:   width,
+		buckets: make([]windowBucket, buckets),
+		start:   time.Now(),
+		now:     time.Now,
+	}
+	for i := range c.buckets {
+		c.buckets[i].cur.Store(&windowSlot{slot: -1})
+	}
+	return c
+}
+
+func (c *WindowCounter) slot() int64 {
+	return int64(c.now().Sub(c.start)) / c.width
+}
+
+// Add adds n to the current bucket.
+func (c *WindowCounter) Add(n int64) {
+	var (
+		slot = c.slot()
+		b    = &c.buckets[slot%int64(len(c.buckets))]
+	)
+	for {
+		cur := b.cur.Load()
+		if cur.slot == slot {
+			cur.count.Add(n)
+			return
+		}
+		if cur.slot > slot {
+			// A stale writer; the bucket already moved on to a newer slot.
+			return
+		}
+		// Claim the bucket for the new slot; losers retry on the winner's slot.
+		next := &windowSlot{slot: slot}
+		next.count.Store(n)
+		if b.cur.CompareAndSwap(cur, next) {
+			return
+		}
+	}
+}
+
+// Sum returns the total of the buckets inside the window.
+func (c *WindowCounter) Sum() int64 {
+	var (
+		slot = c.slot()
+		n    = int64(len(c.buckets))
+		sum  int64
+	)
+	for i := range c.buckets {
+		if cur := c.buckets[i].cur.Load(); cur.slot > slot-n && cur.slot <= slot {
+			sum += cur.count.Load()
+		}
+	}
+	return sum
+}
+
+// Rate returns the average count per interval over the window.
+func (c *WindowCounter) Rate(interval time.Duration) float64 {
+	return float64(c.Sum()) / (float64(c.Window()) / float64(interval))
+}
+
+// Window returns the covered time window.
+func (c *WindowCounter) Window() time.Duration {
+	return time.Duration(c.width * int64(len(c.buckets)))
+}
@@ -0,0 +1,88 @@
+package ds
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowCounter(t *testing.T) {
+	t.Run("WindowCounter 测试", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewWindowCounter(10*time.Second, 10)
+		c.start, c.now = clock.Now(), clock.Now
+
+		assert.Equal(t, 10*time.Second, c.Window())
+
+		t.Run("窗口内累加", func(t *testing.T) {
+			c.Add(3)
+			clock.Add(time.Second)
+			c.Add(2)
+			assert.Equal(t, int64(5), c.Sum())
+			assert.InDelta(t, 0.5, c.Rate(time.Second), 1e-9)
+		})
+
+		t.Run("滑出窗口的桶被忽略", func(t *testing.T) {
+			clock.Add(9 * time.Second)
+			assert.Equal(t, int64(2), c.Sum())
+			clock.Add(time.Second)
+			assert.Equal(t, int64(0), c.Sum())
+		})
+
+		t.Run("桶被复用时重置", func(t *testing.T) {
+			c.Add(1)
+			assert.Equal(t, int64(1), c.Sum())
+		})
+	})
+
+	t.Run("并发累加", func(t *testing.T) {
+		c := NewWindowCounter(time.Minute, 6)
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				for range 1000 {
+					c.Add(1)
+				}
+			})
+		}
+		wg.Wait()
+		assert.Equal(t, int64(8000), c.Sum())
+	})
+	t.Run("跨时间槽并发累加不丢失", func(t *testing.T) {
+		for range 200 {
+			var now atomic.Int64
+			c := NewWindowCounter(10*time.Second, 10)
+			c.start = time.Unix(0, 0)
+			c.now = func() time.Time { return time.Unix(0, now.Load()) }
+
+			var (
+				wg    sync.WaitGroup
+				ready sync.WaitGroup
+				start = make(chan struct{})
+			)
+			for range 8 {
+				ready.Add(1)
+				wg.Go(func() {
+					ready.Done()
+					<-start
+					for range 1000 {
+						c.Add(1)
+					}
+				})
+			}
+			ready.Wait()
+			close(start)
+			// Cross 9 slot boundaries while adding; all slots stay in the window.
+			for i := 1; i < 10; i++ {
+				runtime.Gosched()
+				now.Store(int64(i) * int64(time.Second))
+			}
+			wg.Wait()
+			assert.Equal(t, int64(8000), c.Sum())
+		}
+	})
+}
@@ -1,22 +1,138 @@
 package ds
 
 import (
+	"container/heap"
+	"context"
 	"errors"
 	"sync"
+	"time"
 )
 
-// WorkQueue is a simple pub-sub style queue where each topic
-// has exactly one subscriber (worker).
-// A published message goes to exactly one consumer.
+// WorkQueue is a multi-topic work queue with competing consumers.
+// A published message goes to exactly one consumer of its topic.
+//
+// Messages are delivered with at-least-once semantics:
+//   - Consume hands a message to one consumer and hides it from the others
+//     for the visibility timeout.
+//   - The consumer must Ack the Delivery once processed, or Nack it to request a retry.
+//   - Unacked messages become visible again after the visibility timeout.
+//   - Retries are bounded and delayed with exponential backoff; messages that
+//     exhaust their retries are moved to the dead-letter topic (if configured) or dropped.
+//
+// Subscribe is the simple channel form: it starts a consumer that acknowledges
+// every message as soon as it is received (at-most-once).
+//
+// Visibility timeouts and delayed messages are processed lazily by Consume,
+// Publish and Stats; no background goroutine is started.
+//
+// A topic may be backed by a Queue (e.g. DiskQueue) with AddTopicQueue so that
+// published messages survive a restart. If the Queue is an AckQueue, a
+// consumed message stays uncommitted in it until it is acked or
+// dead-lettered, so in-flight and retried messages are delivered again after
+// a restart, possibly along with later messages that were already acked.
+// Delayed messages are always kept in memory.
 //
 // Typical use case: task queues, job workers.
 //
 // T is the type of the message.
 type WorkQueue[T any] struct {
-	mutex       sync.Mutex        // protects subscribers map
-	subscribers map[string]chan T // topic name -> channel
+	mutex       sync.Mutex             // protects topics map
+	topics      map[string]*wqTopic[T] // topic name -> queue
+	conf        workQueueConfig        // delivery configuration
+	closed      bool                   // whether Close has been called
+	idGenerator                        // message IDs and delivery receipts
 }
 
+type (
+	// WorkQueueOption configures a WorkQueue.
+	WorkQueueOption func(*workQueueConfig)
+
+	// workQueueConfig holds the delivery configuration of a WorkQueue.
+	workQueueConfig struct {
+		visibility time.Duration // how long a delivered message stays hidden
+		maxRetries int           // redeliveries after the first attempt, < 0 for unlimited
+		backoff    time.Duration // base retry delay
+		maxBackoff time.Duration // upper bound of the retry delay
+		deadLetter string        // dead-letter topic, empty to drop
+	}
+
+	// WorkQueueStats is a snapshot of the messages held by a topic.
+	WorkQueueStats struct {
+		Ready    int    // messages waiting to be consumed
+		Delayed  int    // messages scheduled for later delivery (including retries)
+		InFlight int    // messages delivered but not yet acknowledged
+		Dead     uint64 // messages that exhausted their retries
+	}
+
+	// Delivery is a message handed to a consumer by WorkQueue.Consume.
+	// It must be settled with Ack or Nack.
+	Delivery[T any] struct {
+		ID      uint64 // message ID, stable across redeliveries
+		Topic   string // topic the message was consumed from
+		Value   T      // message payload
+		Attempt int    // delivery attempt, starting at 1
+
+		q       *WorkQueue[T]
+		t       *wqTopic[T]
+		msg     *wqMessage[T]
+		receipt uint64
+	}
+
+	// wqTopic is the queue state of a single topic.
+	wqTopic[T any] struct {
+		name     string
+		cap      int             // max pending (ready + delayed) messages, <= 0 for unbounded
+		ready    []*wqMessage[T] // FIFO of deliverable messages
+		store    Queue[T]        // optional backing queue for newly published messages
+		schedule wqSchedule[T]   // delayed and in-flight messages by deadline
+		delayed  int
+		inflight int
+		dead     uint64
+		wake     chan struct{} // closed and replaced whenever messages become ready
+		ctx      context.Context
+		cf       context.CancelFunc
+		closed   bool
+	}
+
+	// wqMessage is a queued message and its delivery state.
+	wqMessage[T any] struct {
+		id      uint64
+		value   T
+		attempt int
+		state   wqState
+		receipt uint64    // identifies the current delivery
+		at      time.Time // when a delayed message becomes ready or a delivery expires
+		index   int       // position in the schedule, -1 if not scheduled
+		token   uint64    // AckQueue token of a received message
+		stored  bool      // whether the message waits for a Commit to its topic's store
+	}
+
+	// wqSchedule is a min-heap of the delayed and in-flight messages ordered
+	// by their deadline. Settled messages are removed from it.
+	wqSchedule[T any] []*wqMessage[T]
+
+	wqState uint8
+)
+
+const (
+	wqReady wqState = iota
+	wqDelayed
+	wqInFlight
+	wqDone
+)
+
+const (
+	// DefaultVisibilityTimeout is how long a consumed message stays hidden
+	// from other consumers before it is redelivered.
+	DefaultVisibilityTimeout = 30 * time.Second
+	// DefaultMaxRetries is the default number of redeliveries after the first attempt.
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the default base delay between retries.
+	DefaultRetryBackoff = 100 * time.Millisecond
+	// DefaultMaxRetryBackoff is the default upper bound of the retry delay.
+	DefaultMaxRetryBackoff = 30 * time.Second
+)
+
 var (
 	// ErrTopicNotFound indicates the given topic does not exist.
 	ErrTopicNotFound = errors.New("topic not found")
@@ -26,79 +142,574 @@ var (
 
 	// ErrTopicQueueFull indicates the topic queue is full
 	ErrTopicQueueFull = errors.New("topic queue is full")
+
+	// ErrWorkQueueClosed is returned when operations are attempted on a closed WorkQueue or topic.
+	ErrWorkQueueClosed = errors.New("work queue is closed")
+
+	// ErrDeliveryExpired indicates the delivery was already settled or its
+	// visibility timeout elapsed and the message was handed out again.
+	ErrDeliveryExpired = errors.New("delivery expired")
 )
 
-// NewWorkQueue creates a new WorkQueue with a given channel buffer size.
-func NewWorkQueue[T any]() *WorkQueue[T] {
-	return &WorkQueue[T]{
-		subscribers: make(map[string]chan T),
+// WithVisibilityTimeout sets how long a consumed message stays invisible to other
+// consumers. If it is not acknowledged in time it is redelivered.
+func WithVisibilityTimeout(d time.Duration) WorkQueueOption {
+	return func(c *workQueueConfig) {
+		if d > 0 {
+			c.visibility = d
+		}
+	}
+}
+
+// WithMaxRetries sets how many times a message is redelivered after its first attempt.
+// A negative value retries forever.
+func WithMaxRetries(n int) WorkQueueOption {
+	return func(c *workQueueConfig) {
+		c.maxRetries = n
+	}
+}
+
+// WithRetryBackoff sets the exponential backoff between retries.
+// The n-th retry is delayed by base * 2^(n-1), capped at maxDelay.
+func WithRetryBackoff(base, maxDelay time.Duration) WorkQueueOption {
+	return func(c *workQueueConfig) {
+		c.backoff = max(0, base)
+		c.maxBackoff = max(c.backoff, maxDelay)
+	}
+}
+
+// WithDeadLetterTopic sets the topic that collects messages which exhausted
+// their retries. The topic is created on demand with unbounded capacity;
+// create it first with AddTopic to bound it. Dead letters that do not fit
+// in a full dead-letter topic are dropped.
+func WithDeadLetterTopic(topic string) WorkQueueOption {
+	return func(c *workQueueConfig) {
+		c.deadLetter = topic
+	}
+}
+
+// NewWorkQueue creates a new WorkQueue with the given options.
+func NewWorkQueue[T any](opts ...WorkQueueOption) *WorkQueue[T] {
+	q := &WorkQueue[T]{
+		topics: make(map[string]*wqTopic[T]),
+		conf: workQueueConfig{
+			visibility: DefaultVisibilityTimeout,
+			maxRetries: DefaultMaxRetries,
+			backoff:    DefaultRetryBackoff,
+			maxBackoff: DefaultMaxRetryBackoff,
+		},
+	}
+	for _, opt := range opts {
+		opt(&q.conf)
 	}
+	return q
 }
 
-// AddTopic creates a new topic with its own buffered channel.
+// AddTopic creates a new topic holding at most bufSize pending messages.
+// A bufSize <= 0 means unbounded.
 // Returns an error if the topic already exists.
 func (u *WorkQueue[T]) AddTopic(topic string, bufSize int) error {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
-	if _, ok := u.subscribers[topic]; ok {
+	if u.closed {
+		return ErrWorkQueueClosed
+	}
+	if _, ok := u.topics[topic]; ok {
 		return ErrTopicAlreadyExists
 	}
-	u.subscribers[topic] = make(chan T, bufSize)
+	u.addTopic(topic, bufSize)
 	return nil
 }
 
-// RemoveTopic closes and removes the topic's channel.
-// Messages in the buffer are dropped.
-func (u *WorkQueue[T]) RemoveTopic(topic string) error {
+// AddTopicQueue creates a new topic whose published messages are stored in store.
+// Messages are popped from store when they are consumed, so a durable store
+// such as DiskQueue keeps unconsumed messages across restarts. If store is an
+// AckQueue, messages are received when consumed and committed when acked or
+// dead-lettered, so unacked messages are kept too.
+// If store fails to decode a message, Consume returns the error and the
+// message is skipped.
+// Returns an error if the topic already exists.
+func (u *WorkQueue[T]) AddTopicQueue(topic string, store Queue[T]) error {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
-	if ch, ok := u.subscribers[topic]; ok {
-		close(ch)
-		delete(u.subscribers, topic)
+	if u.closed {
+		return ErrWorkQueueClosed
 	}
+	if _, ok := u.topics[topic]; ok {
+		return ErrTopicAlreadyExists
+	}
+	u.addTopic(topic, 0).store = store
 	return nil
 }
 
-// Publish sends a message to the topic's channel.
-// If the channel buffer is full, the message is dropped silently.
-// Returns an error if the topic does not exist.
+func (u *WorkQueue[T]) addTopic(topic string, bufSize int) *wqTopic[T] {
+	t := &wqTopic[T]{
+		name: topic,
+		cap:  bufSize,
+		wake: make(chan struct{}),
+	}
+	t.ctx, t.cf = context.WithCancel(context.Background())
+	u.topics[topic] = t
+	return t
+}
+
+// RemoveTopic closes and removes the topic.
+// Pending and in-flight messages are moved to the dead-letter topic if one is
+// configured, otherwise they are dropped. Messages still held by a backing
+// Queue, including uncommitted ones that were not dead-lettered, are left in it.
+func (u *WorkQueue[T]) RemoveTopic(topic string) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	t, ok := u.topics[topic]
+	if !ok {
+		return nil
+	}
+	delete(u.topics, topic)
+
+	var errs []error
+	if !t.closed && u.conf.deadLetter != "" && u.conf.deadLetter != topic {
+		for _, m := range t.ready {
+			errs = append(errs, u.deadLetter(t, m))
+		}
+		for _, m := range t.schedule {
+			errs = append(errs, u.deadLetter(t, m))
+		}
+	}
+	t.close()
+	return errors.Join(errs...)
+}
+
+// Publish enqueues a message for immediate delivery.
+// Returns ErrTopicQueueFull if the topic holds bufSize pending messages,
+// or an error if the topic does not exist.
 func (u *WorkQueue[T]) Publish(topic string, msg T) error {
+	return u.PublishAt(topic, msg, time.Time{})
+}
+
+// PublishDelayed enqueues a message that becomes deliverable after delay.
+func (u *WorkQueue[T]) PublishDelayed(topic string, msg T, delay time.Duration) error {
+	return u.PublishAt(topic, msg, time.Now().Add(delay))
+}
+
+// PublishAt enqueues a message that becomes deliverable at the given time.
+// A zero or past time delivers immediately.
+func (u *WorkQueue[T]) PublishAt(topic string, msg T, at time.Time) error {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
-	if ch, ok := u.subscribers[topic]; ok {
-		select {
-		case ch <- msg:
-		default:
-			return ErrTopicQueueFull
+	t, err := u.topic(topic)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	u.maintain(t, now)
+	if t.cap > 0 && t.pending() >= t.cap {
+		return ErrTopicQueueFull
+	}
+
+	if at.After(now) {
+		u.delay(t, u.newMessage(msg), at)
+		return nil
+	}
+	if t.store != nil {
+		if err := t.store.Push(msg); err != nil {
+			return err
 		}
+		t.signal()
 		return nil
 	}
-	return ErrTopicNotFound
+	t.push(u.newMessage(msg))
+	return nil
+}
+
+// Consume blocks until a message of the topic is available or ctx is done.
+// The returned Delivery is hidden from other consumers for the visibility
+// timeout and must be settled with Ack or Nack.
+func (u *WorkQueue[T]) Consume(ctx context.Context, topic string) (*Delivery[T], error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		u.mutex.Lock()
+		t, err := u.topic(topic)
+		if err != nil {
+			u.mutex.Unlock()
+			return nil, err
+		}
+
+		now := time.Now()
+		u.maintain(t, now)
+
+		m, err := u.pop(t)
+		if err != nil {
+			u.mutex.Unlock()
+			return nil, err
+		}
+		if m != nil {
+			m.attempt++
+			m.state = wqInFlight
+			m.receipt = u.Increment()
+			t.schedule.set(m, now.Add(u.conf.visibility))
+			t.inflight++
+			u.mutex.Unlock()
+
+			return &Delivery[T]{
+				ID:      m.id,
+				Topic:   t.name,
+				Value:   m.value,
+				Attempt: m.attempt,
+				q:       u,
+				t:       t,
+				msg:     m,
+				receipt: m.receipt,
+			}, nil
+		}
+
+		var (
+			wake = t.wake
+			next <-chan time.Time
+		)
+		if len(t.schedule) > 0 {
+			d := t.schedule[0].at.Sub(now)
+			if timer == nil {
+				timer = time.NewTimer(d)
+			} else {
+				timer.Reset(d)
+			}
+			next = timer.C
+		}
+		u.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wake:
+		case <-next:
+		}
+	}
 }
 
-// Subscribe returns the channel for the given topic.
-// Only one subscriber should consume from the channel.
+// Subscribe starts a consumer that forwards the topic's messages to the returned channel.
+// Messages are acknowledged as soon as they are received, so delivery is at-most-once;
+// use Consume for acknowledged delivery. Several subscribers compete for messages.
+//
+// The channel is closed when the topic is removed or the queue is closed.
+// If the topic is already closed, the returned channel is closed.
 // Returns an error if the topic does not exist.
 func (u *WorkQueue[T]) Subscribe(topic string) (<-chan T, error) {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
-	if ch, ok := u.subscribers[topic]; ok {
+	t, ok := u.topics[topic]
+	if !ok {
+		return nil, ErrTopicNotFound
+	}
+
+	ch := make(chan T)
+	if t.closed {
+		close(ch)
 		return ch, nil
 	}
-	return nil, ErrTopicNotFound
+
+	go func() {
+		defer close(ch)
+		for {
+			d, err := u.Consume(t.ctx, topic)
+			if err != nil {
+				return
+			}
+			_ = d.Ack()
+
+			select {
+			case ch <- d.Value:
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Stats returns a snapshot of the messages held by the topic.
+func (u *WorkQueue[T]) Stats(topic string) (WorkQueueStats, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	t, err := u.topic(topic)
+	if err != nil {
+		return WorkQueueStats{}, err
+	}
+	u.maintain(t, time.Now())
+
+	ready := len(t.ready)
+	if t.store != nil {
+		ready += t.store.Len()
+	}
+	return WorkQueueStats{
+		Ready:    ready,
+		Delayed:  t.delayed,
+		InFlight: t.inflight,
+		Dead:     t.dead,
+	}, nil
 }
 
-// Close closes all topic channels.
+// Close closes all topics and stops their subscribers.
 // Topics are not removed from the map.
 func (u *WorkQueue[T]) Close() {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 
-	for _, ch := range u.subscribers {
-		close(ch)
+	for _, t := range u.topics {
+		t.close()
+	}
+	u.closed = true
+}
+
+// Ack acknowledges the delivery and removes the message from the queue.
+// Returns ErrDeliveryExpired if the delivery was already settled or redelivered.
+func (d *Delivery[T]) Ack() error {
+	q := d.q
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := d.check(); err != nil {
+		return err
 	}
+	d.msg.state = wqDone
+	d.msg.receipt = 0
+	d.t.schedule.remove(d.msg)
+	d.t.inflight--
+	return d.t.commit(d.msg)
+}
+
+// Nack rejects the delivery. The message is retried after the backoff delay,
+// or dead-lettered if it exhausted its retries.
+// Returns ErrDeliveryExpired if the delivery was already settled or redelivered.
+func (d *Delivery[T]) Nack() error {
+	q := d.q
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := d.check(); err != nil {
+		return err
+	}
+	return q.retry(d.t, d.msg, time.Now())
+}
+
+func (d *Delivery[T]) check() error {
+	if d.t.closed {
+		return ErrWorkQueueClosed
+	}
+	if d.msg.state != wqInFlight || d.msg.receipt != d.receipt {
+		return ErrDeliveryExpired
+	}
+	return nil
+}
+
+// topic returns the open topic with the given name. Caller must hold the mutex.
+func (u *WorkQueue[T]) topic(name string) (*wqTopic[T], error) {
+	t, ok := u.topics[name]
+	if !ok {
+		return nil, ErrTopicNotFound
+	}
+	if t.closed {
+		return nil, ErrWorkQueueClosed
+	}
+	return t, nil
+}
+
+// pop removes the next deliverable message of t, preferring retried messages
+// over those in the backing Queue. Returns nil if none is available.
+func (u *WorkQueue[T]) pop(t *wqTopic[T]) (*wqMessage[T], error) {
+	if len(t.ready) > 0 {
+		m := t.ready[0]
+		t.ready[0] = nil
+		t.ready = t.ready[1:]
+		return m, nil
+	}
+	switch s := t.store.(type) {
+	case nil:
+		return nil, nil
+	case AckQueue[T]:
+		v, token, ok, err := s.Receive()
+		if err != nil || !ok {
+			return nil, err
+		}
+		m := u.newMessage(v)
+		m.token, m.stored = token, true
+		return m, nil
+	default:
+		v, ok, err := s.Pop()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return u.newMessage(v), nil
+	}
+}
+
+func (u *WorkQueue[T]) newMessage(v T) *wqMessage[T] {
+	return &wqMessage[T]{id: u.Increment(), value: v, index: -1}
+}
+
+// maintain applies all scheduled transitions that are due:
+// delayed messages become ready and expired deliveries are retried.
+func (u *WorkQueue[T]) maintain(t *wqTopic[T], now time.Time) {
+	for len(t.schedule) > 0 && !t.schedule[0].at.After(now) {
+		m := heap.Pop(&t.schedule).(*wqMessage[T])
+		switch m.state {
+		case wqDelayed:
+			t.delayed--
+			t.push(m)
+		case wqInFlight:
+			// A failed commit leaves the message in the store to be redelivered
+			// after a restart, which at-least-once delivery allows.
+			_ = u.retry(t, m, now)
+		}
+	}
+}
+
+// retry schedules the next attempt of an in-flight message, or dead-letters it.
+func (u *WorkQueue[T]) retry(t *wqTopic[T], m *wqMessage[T], now time.Time) error {
+	t.inflight--
+	if u.conf.maxRetries >= 0 && m.attempt > u.conf.maxRetries {
+		m.state = wqDone
+		m.receipt = 0
+		t.schedule.remove(m)
+		t.dead++
+		return u.deadLetter(t, m)
+	}
+	u.delay(t, m, now.Add(u.backoff(m.attempt)))
+	return nil
+}
+
+// delay schedules m to become ready at the given time.
+func (u *WorkQueue[T]) delay(t *wqTopic[T], m *wqMessage[T], at time.Time) {
+	m.state = wqDelayed
+	m.receipt = u.Increment()
+	t.schedule.set(m, at)
+	t.delayed++
+	t.signal()
+}
+
+// backoff returns the delay before retrying a message that was delivered attempt times.
+func (u *WorkQueue[T]) backoff(attempt int) time.Duration {
+	d := u.conf.backoff
+	for i := 1; i < attempt && d < u.conf.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, u.conf.maxBackoff)
+}
+
+// deadLetter moves a message from t to the dead-letter topic, if configured
+// and not full, and commits it to t's store.
+func (u *WorkQueue[T]) deadLetter(t *wqTopic[T], m *wqMessage[T]) error {
+	name := u.conf.deadLetter
+	if name == "" || name == t.name {
+		return t.commit(m)
+	}
+	dlq, ok := u.topics[name]
+	if !ok {
+		dlq = u.addTopic(name, 0)
+	}
+	if dlq.closed || (dlq.cap > 0 && dlq.pending() >= dlq.cap) {
+		return t.commit(m)
+	}
+	if dlq.store != nil {
+		// Keep the message in t's store if it could not be moved.
+		if err := dlq.store.Push(m.value); err != nil {
+			return err
+		}
+		dlq.signal()
+		return t.commit(m)
+	}
+	dlq.push(&wqMessage[T]{id: m.id, value: m.value, index: -1})
+	return t.commit(m)
+}
+
+// push appends m to the ready queue and wakes waiting consumers.
+func (t *wqTopic[T]) push(m *wqMessage[T]) {
+	m.state = wqReady
+	m.receipt = 0
+	t.ready = append(t.ready, m)
+	t.signal()
+}
+
+// commit removes a settled message from the store it was received from.
+func (t *wqTopic[T]) commit(m *wqMessage[T]) error {
+	if !m.stored {
+		return nil
+	}
+	m.stored = false
+	return t.store.(AckQueue[T]).Commit(m.token)
+}
+
+// pending returns the number of messages waiting for delivery.
+func (t *wqTopic[T]) pending() int {
+	n := len(t.ready) + t.delayed
+	if t.store != nil {
+		n += t.store.Len()
+	}
+	return n
+}
+
+// signal wakes all consumers waiting on the topic.
+func (t *wqTopic[T]) signal() {
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+func (t *wqTopic[T]) close() {
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.cf()
+	t.signal()
+}
+
+// set schedules m at the given time, moving it if it is already scheduled.
+func (s *wqSchedule[T]) set(m *wqMessage[T], at time.Time) {
+	m.at = at
+	if m.index >= 0 {
+		heap.Fix(s, m.index)
+		return
+	}
+	heap.Push(s, m)
+}
+
+// remove unschedules m if it is scheduled.
+func (s *wqSchedule[T]) remove(m *wqMessage[T]) {
+	if m.index >= 0 {
+		heap.Remove(s, m.index)
+	}
+}
+
+func (s wqSchedule[T]) Len() int           { return len(s) }
+func (s wqSchedule[T]) Less(i, j int) bool { return s[i].at.Before(s[j].at) }
+func (s wqSchedule[T]) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+func (s *wqSchedule[T]) Push(x any) {
+	m := x.(*wqMessage[T])
+	m.index = len(*s)
+	*s = append(*s, m)
+}
+func (s *wqSchedule[T]) Pop() any {
+	old := *s
+	n := len(old)
+	m := old[n-1]
+	old[n-1] = nil
+	m.index = -1
+	*s = old[:n-1]
+	return m
 }
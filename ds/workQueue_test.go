@@ -1,8 +1,10 @@
 package ds
 
 import (
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -143,3 +145,234 @@ func TestWorkQueueErrors(t *testing.T) {
 		assert.Equal(t, "topic queue is full", ErrTopicQueueFull.Error())
 	})
 }
+
+func TestWorkQueueConsume(t *testing.T) {
+	t.Run("WorkQueue Consume 测试", func(t *testing.T) {
+		t.Run("多个消费者竞争消费, 每条消息只投递一次", func(t *testing.T) {
+			wq := NewWorkQueue[int]()
+			defer wq.Close()
+			wq.AddTopic("jobs", 0)
+
+			var (
+				wg   sync.WaitGroup
+				mu   sync.Mutex
+				seen = make(map[int]int)
+				n    = 100
+			)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			for range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						mu.Lock()
+						done := len(seen) == n
+						mu.Unlock()
+						if done {
+							return
+						}
+						cctx, ccancel := context.WithTimeout(ctx, 50*time.Millisecond)
+						d, err := wq.Consume(cctx, "jobs")
+						ccancel()
+						if err != nil {
+							continue
+						}
+						assert.Nil(t, d.Ack())
+						mu.Lock()
+						seen[d.Value]++
+						mu.Unlock()
+					}
+				}()
+			}
+
+			for i := range n {
+				assert.Nil(t, wq.Publish("jobs", i))
+			}
+			wg.Wait()
+
+			assert.Len(t, seen, n)
+			for _, c := range seen {
+				assert.Equal(t, 1, c)
+			}
+		})
+
+		t.Run("消费不存在的topic返回错误", func(t *testing.T) {
+			wq := NewWorkQueue[int]()
+			d, err := wq.Consume(context.Background(), "nonexistent")
+			assert.Equal(t, ErrTopicNotFound, err)
+			assert.Nil(t, d)
+		})
+
+		t.Run("context取消后返回", func(t *testing.T) {
+			wq := NewWorkQueue[int]()
+			wq.AddTopic("jobs", 0)
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := wq.Consume(ctx, "jobs")
+			assert.Equal(t, context.DeadlineExceeded, err)
+		})
+	})
+}
+
+func TestWorkQueueAck(t *testing.T) {
+	t.Run("WorkQueue Ack/Nack 测试", func(t *testing.T) {
+		ctx := context.Background()
+
+		t.Run("Ack后消息被移除", func(t *testing.T) {
+			wq := NewWorkQueue[int]()
+			wq.AddTopic("jobs", 0)
+			wq.Publish("jobs", 1)
+
+			d, err := wq.Consume(ctx, "jobs")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, d.Attempt)
+
+			stats, _ := wq.Stats("jobs")
+			assert.Equal(t, WorkQueueStats{InFlight: 1}, stats)
+
+			assert.Nil(t, d.Ack())
+			assert.Equal(t, ErrDeliveryExpired, d.Ack())
+
+			stats, _ = wq.Stats("jobs")
+			assert.Equal(t, WorkQueueStats{}, stats)
+		})
+
+		t.Run("Ack后可见性超时条目被移除", func(t *testing.T) {
+			wq := NewWorkQueue[int](WithVisibilityTimeout(time.Hour))
+			wq.AddTopic("jobs", 0)
+			for i := range 100 {
+				wq.Publish("jobs", i)
+				d, err := wq.Consume(ctx, "jobs")
+				assert.Nil(t, err)
+				assert.Nil(t, d.Nack())
+			}
+			for range 100 {
+				d, err := wq.Consume(ctx, "jobs")
+				assert.Nil(t, err)
+				assert.Nil(t, d.Ack())
+			}
+			assert.Empty(t, wq.topics["jobs"].schedule)
+		})
+
+		t.Run("Nack后按退避重新投递", func(t *testing.T) {
+			wq := NewWorkQueue[string](WithRetryBackoff(10*time.Millisecond, time.Second))
+			wq.AddTopic("jobs", 0)
+			wq.Publish("jobs", "a")
+
+			d, _ := wq.Consume(ctx, "jobs")
+			assert.Nil(t, d.Nack())
+
+			stats, _ := wq.Stats("jobs")
+			assert.Equal(t, 1, stats.Delayed)
+
+			start := time.Now()
+			d2, err := wq.Consume(ctx, "jobs")
+			assert.Nil(t, err)
+			assert.GreaterOrEqual(t, time.Since(start), 5*time.Millisecond)
+			assert.Equal(t, d.ID, d2.ID)
+			assert.Equal(t, "a", d2.Value)
+			assert.Equal(t, 2, d2.Attempt)
+		})
+
+		t.Run("可见性超时后重新投递", func(t *testing.T) {
+			wq := NewWorkQueue[int](
+				WithVisibilityTimeout(20*time.Millisecond),
+				WithRetryBackoff(0, 0),
+			)
+			wq.AddTopic("jobs", 0)
+			wq.Publish("jobs", 7)
+
+			d, _ := wq.Consume(ctx, "jobs")
+			d2, err := wq.Consume(ctx, "jobs")
+			assert.Nil(t, err)
+			assert.Equal(t, 7, d2.Value)
+			assert.Equal(t, 2, d2.Attempt)
+
+			// The first delivery is stale once the message was handed out again.
+			assert.Equal(t, ErrDeliveryExpired, d.Ack())
+			assert.Nil(t, d2.Ack())
+		})
+
+		t.Run("重试耗尽后进入死信topic", func(t *testing.T) {
+			wq := NewWorkQueue[int](
+				WithMaxRetries(1),
+				WithRetryBackoff(0, 0),
+				WithDeadLetterTopic("jobs.dead"),
+			)
+			wq.AddTopic("jobs", 0)
+			wq.Publish("jobs", 9)
+
+			for range 2 {
+				d, err := wq.Consume(ctx, "jobs")
+				assert.Nil(t, err)
+				assert.Nil(t, d.Nack())
+			}
+
+			stats, _ := wq.Stats("jobs")
+			assert.Equal(t, WorkQueueStats{Dead: 1}, stats)
+
+			d, err := wq.Consume(ctx, "jobs.dead")
+			assert.Nil(t, err)
+			assert.Equal(t, 9, d.Value)
+			assert.Equal(t, 1, d.Attempt)
+		})
+	})
+}
+
+func TestWorkQueueDelayed(t *testing.T) {
+	t.Run("WorkQueue 延迟投递测试", func(t *testing.T) {
+		wq := NewWorkQueue[int]()
+		wq.AddTopic("jobs", 0)
+
+		assert.Nil(t, wq.PublishDelayed("jobs", 2, 40*time.Millisecond))
+		assert.Nil(t, wq.PublishAt("jobs", 1, time.Now().Add(20*time.Millisecond)))
+		assert.Nil(t, wq.Publish("jobs", 0))
+
+		stats, _ := wq.Stats("jobs")
+		assert.Equal(t, WorkQueueStats{Ready: 1, Delayed: 2}, stats)
+
+		start := time.Now()
+		for want := range 3 {
+			d, err := wq.Consume(context.Background(), "jobs")
+			assert.Nil(t, err)
+			assert.Equal(t, want, d.Value)
+			d.Ack()
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	})
+}
+
+func TestWorkQueueDeadLetterCap(t *testing.T) {
+	t.Run("死信topic容量已满时丢弃", func(t *testing.T) {
+		wq := NewWorkQueue[int](WithMaxRetries(0), WithDeadLetterTopic("dead"))
+		wq.AddTopic("jobs", 0)
+		wq.AddTopic("dead", 2)
+		for i := range 3 {
+			wq.Publish("jobs", i)
+			d, err := wq.Consume(context.Background(), "jobs")
+			assert.Nil(t, err)
+			assert.Nil(t, d.Nack())
+		}
+
+		stats, _ := wq.Stats("jobs")
+		assert.Equal(t, uint64(3), stats.Dead)
+		stats, _ = wq.Stats("dead")
+		assert.Equal(t, 2, stats.Ready)
+	})
+}
+
+func TestWorkQueueRemoveTopicDeadLetter(t *testing.T) {
+	t.Run("RemoveTopic 时未处理消息进入死信topic", func(t *testing.T) {
+		wq := NewWorkQueue[int](WithDeadLetterTopic("dead"))
+		wq.AddTopic("jobs", 0)
+		wq.Publish("jobs", 1)
+		wq.PublishDelayed("jobs", 2, time.Hour)
+
+		assert.Nil(t, wq.RemoveTopic("jobs"))
+
+		stats, _ := wq.Stats("dead")
+		assert.Equal(t, 2, stats.Ready)
+	})
+}
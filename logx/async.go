@@ -0,0 +1,34 @@
+package logx
+
+import (
+	"io"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+type OverflowPolicy = logcore.OverflowPolicy
+
+const (
+	OverflowDrop   = logcore.OverflowDrop
+	OverflowBlock  = logcore.OverflowBlock
+	OverflowSample = logcore.OverflowSample
+)
+
+// Close flushes the default logger and stops its async writers, see
+// WithAsync. Lines logged afterwards are written synchronously, so Close can
+// be deferred in main or called on shutdown without losing late lines.
+func Close() error {
+	if c, ok := defaultLogger.(io.Closer); ok {
+		return c.Close()
+	}
+	return defaultLogger.Sync()
+}
+
+// Dropped returns the number of lines the async writers of the default
+// logger dropped because their buffer was full.
+func Dropped() uint64 {
+	if d, ok := defaultLogger.(interface{ Dropped() uint64 }); ok {
+		return d.Dropped()
+	}
+	return 0
+}
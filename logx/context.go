@@ -0,0 +1,78 @@
+package logx
+
+import (
+	"context"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+type ContextExtractor = logcore.ContextExtractor
+
+type loggerKey struct{}
+
+// RegisterContextExtractor appends extractors whose fields are added by the *Ctx methods.
+// Importing contextx registers the trace, span, request, service, version and env values.
+func RegisterContextExtractor(e ...ContextExtractor) {
+	logcore.RegisterContextExtractor(e...)
+}
+
+// SetContextExtractors replaces the extractor list; without arguments it clears it.
+func SetContextExtractors(e ...ContextExtractor) {
+	logcore.SetContextExtractors(e...)
+}
+
+// ContextFields returns the fields extracted from ctx followed by kvs.
+func ContextFields(ctx context.Context, kvs ...Field) []Field {
+	return logcore.ContextFields(ctx, kvs...)
+}
+
+// WithContext returns a copy of ctx carrying logger, used by the package-level *Ctx functions.
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or nil.
+func FromContext(ctx context.Context) Logger {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value(loggerKey{}).(Logger)
+	return l
+}
+
+// ctxLogger returns the logger of ctx, adjusted for the extra frame of the
+// package-level function, or the default logger.
+func ctxLogger(ctx context.Context) Logger {
+	if l := FromContext(ctx); l != nil {
+		return l.AddCallerSkip(1)
+	}
+	return defaultLogger
+}
+
+func DebugCtx(ctx context.Context, msg string, kvs ...Field) {
+	ctxLogger(ctx).DebugCtx(ctx, msg, kvs...)
+}
+
+func InfoCtx(ctx context.Context, msg string, kvs ...Field) {
+	ctxLogger(ctx).InfoCtx(ctx, msg, kvs...)
+}
+
+func WarnCtx(ctx context.Context, msg string, kvs ...Field) {
+	ctxLogger(ctx).WarnCtx(ctx, msg, kvs...)
+}
+
+func ErrorCtx(ctx context.Context, msg string, kvs ...Field) {
+	ctxLogger(ctx).ErrorCtx(ctx, msg, kvs...)
+}
+
+func PanicCtx(ctx context.Context, msg string, kvs ...Field) {
+	ctxLogger(ctx).PanicCtx(ctx, msg, kvs...)
+}
+
+func FatalCtx(ctx context.Context, msg string, kvs ...Field) {
+	ctxLogger(ctx).FatalCtx(ctx, msg, kvs...)
+}
+
+func LogCtx(ctx context.Context, level string, msg string, kvs ...Field) {
+	ctxLogger(ctx).LogCtx(ctx, level, msg, kvs...)
+}
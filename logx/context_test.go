@@ -0,0 +1,73 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func TestContextLogging(t *testing.T) {
+	t.Run("Ctx 方法测试", func(t *testing.T) {
+		SetContextExtractors(func(ctx context.Context) []Field {
+			if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+				return []Field{String("request_id", id)}
+			}
+			return nil
+		})
+		defer SetContextExtractors()
+
+		old := defaultLogger
+		defer SetDefault(old)
+
+		var defBuf, ctxBuf bytes.Buffer
+		SetDefault(newJSONLogger(t, &defBuf))
+		ctxLog := newJSONLogger(t, &ctxBuf).AddCallerSkip(-1)
+		ctx := context.WithValue(context.Background(), requestIDKey{}, "r1")
+
+		t.Run("无 Logger 时使用默认 Logger", func(t *testing.T) {
+			InfoCtx(ctx, "default", Int("n", 1))
+			m := decodeLines(t, &defBuf)[0]
+			assert.Equal(t, "r1", m["request_id"])
+			assert.Equal(t, float64(1), m["n"])
+			assert.Contains(t, m["source"].(map[string]any)["file"], "context_test.go")
+			assert.Zero(t, ctxBuf.Len())
+		})
+
+		t.Run("使用 Context 中的 Logger", func(t *testing.T) {
+			defBuf.Reset()
+			lctx := WithContext(ctx, ctxLog)
+			assert.Equal(t, ctxLog, FromContext(lctx))
+			WarnCtx(lctx, "from ctx")
+			LogCtx(lctx, "error", "log ctx")
+
+			lines := decodeLines(t, &ctxBuf)
+			assert.Len(t, lines, 2)
+			assert.Equal(t, "WARN", lines[0]["level"])
+			assert.Equal(t, "r1", lines[0]["request_id"])
+			assert.Contains(t, lines[0]["source"].(map[string]any)["file"], "context_test.go")
+			assert.Equal(t, "ERROR", lines[1]["level"])
+			assert.Zero(t, defBuf.Len())
+		})
+
+		t.Run("Logger 方法", func(t *testing.T) {
+			ctxBuf.Reset()
+			ctxLog.ErrorCtx(ctx, "direct")
+			m := decodeLines(t, &ctxBuf)[0]
+			assert.Equal(t, "r1", m["request_id"])
+			assert.Contains(t, m["source"].(map[string]any)["file"], "context_test.go")
+		})
+
+		t.Run("slog 桥接携带 Context 字段", func(t *testing.T) {
+			defBuf.Reset()
+			slog.New(NewSlogHandler(Default(), nil)).InfoContext(ctx, "bridged")
+			assert.Equal(t, "r1", decodeLines(t, &defBuf)[0]["request_id"])
+		})
+
+		assert.Nil(t, FromContext(context.Background()))
+	})
+}
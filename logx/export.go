@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
+	"github.com/BYT0723/go-tools/logx/slogger"
 	"github.com/BYT0723/go-tools/logx/zaplogger"
 	"github.com/BYT0723/go-tools/logx/zerologger"
 )
@@ -21,6 +22,7 @@ func Init(opts ...Option) error {
 		return err
 	}
 	defaultLogger = logger
+	resetNamed()
 	return nil
 }
 
@@ -35,6 +37,11 @@ func NewLogger(opts ...Option) (logcore.Logger, error) {
 		return zerologger.NewInstance(cfg.LogCfg)
 	case TypeZap:
 		return zaplogger.NewInstance(cfg.LogCfg)
+	case TypeSlog:
+		if cfg.Handler != nil {
+			return slogger.NewWithHandler(cfg.Handler), nil
+		}
+		return slogger.NewInstance(cfg.LogCfg)
 	default:
 		return nil, fmt.Errorf("unknown logger type: %v", cfg.Type)
 	}
@@ -99,6 +106,20 @@ func Default() Logger {
 func SetDefault(logger Logger) {
 	if logger != nil {
 		defaultLogger = logger
+		resetNamed()
+	}
+}
+
+// ReplaceDefault sets the default logger like SetDefault and returns a
+// function restoring the previous one, e.g. in tests:
+//
+//	defer logx.ReplaceDefault(l)()
+func ReplaceDefault(logger Logger) (restore func()) {
+	old := defaultLogger
+	SetDefault(logger)
+	return func() {
+		defaultLogger = old
+		resetNamed()
 	}
 }
 
@@ -2,6 +2,7 @@ package logx
 
 import (
 	"testing"
+	"time"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
 )
@@ -53,3 +54,45 @@ func BenchmarkZeroLog(b *testing.B) {
 		l.Debugf("benchmark-fmt: %v", i)
 	}
 }
+
+func BenchmarkZapAsync(b *testing.B) {
+	benchmarkAsync(b, TypeZap)
+}
+
+func BenchmarkZeroLogAsync(b *testing.B) {
+	benchmarkAsync(b, TypeZeroLog)
+}
+
+// benchmarkAsync compares the synchronous and asynchronous file writers of a backend.
+func benchmarkAsync(b *testing.B, typ LoggerType) {
+	for _, async := range []bool{false, true} {
+		name := "sync"
+		if async {
+			name = "async"
+		}
+		b.Run(name, func(b *testing.B) {
+			l, err := NewLogger(
+				WithLoggerType(typ),
+				WithConf(&logcore.LoggerConf{
+					Dir:                b.TempDir(),
+					Level:              "debug",
+					Console:            false,
+					Async:              async,
+					AsyncOverflow:      logcore.OverflowBlock,
+					AsyncFlushInterval: 100 * time.Millisecond,
+				}),
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					l.Debug("benchmark", Int("index", i))
+					i++
+				}
+			})
+			_ = l.Sync()
+		})
+	}
+}
@@ -1,13 +1,21 @@
 package logx
 
 import (
+	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
 )
 
-type Field = logcore.Field
+type (
+	Field           = logcore.Field
+	ObjectMarshaler = logcore.ObjectMarshaler
+	ArrayMarshaler  = logcore.ArrayMarshaler
+	ObjectEncoder   = logcore.ObjectEncoder
+	ArrayEncoder    = logcore.ArrayEncoder
+	StackTracer     = logcore.StackTracer
+)
 
 func Any(key string, value any) Field {
 	return Field{
@@ -128,16 +136,113 @@ func Float64(key string, value float64) Field {
 	}
 }
 
+// Err returns the field "error" for value: its message, the errors of its
+// chain (errors.Unwrap and Join) with the fields of those that are
+// ObjectMarshalers, and the stack trace it records, see logcore.ErrorFields.
+// It is null if value is nil, and "<nil>" if value holds a nil pointer.
+//
+// Example:
+//
+//	func (e *APIError) MarshalLogObject(enc logx.ObjectEncoder) error {
+//		enc.AddString("code", e.Code)
+//		enc.AddString("kind", e.Kind)
+//		return nil
+//	}
+//
+//	logx.Error("request failed", logx.Err(fmt.Errorf("create user: %w", &APIError{...})))
 func Err(value error) Field {
+	if value == nil {
+		return Field{Key: "error"}
+	}
 	return Field{
 		Key:   "error",
-		Value: value.Error(),
+		Kind:  logcore.KindError,
+		Value: value,
 	}
 }
 
 func Duration(key string, value time.Duration) Field {
 	return Field{
 		Key:   key,
+		Kind:  logcore.KindDuration,
+		Value: value,
+	}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{
+		Key:   key,
+		Kind:  logcore.KindTime,
+		Value: value,
+	}
+}
+
+// Stringer returns a field whose value is value.String(), called only if the
+// entry is written. Like Object and Array, it is null if value is nil.
+func Stringer(key string, value fmt.Stringer) Field {
+	if value == nil {
+		return Field{Key: key}
+	}
+	return Field{
+		Key:   key,
+		Kind:  logcore.KindStringer,
+		Value: value,
+	}
+}
+
+// Binary returns a field whose value is written in base64.
+func Binary(key string, value []byte) Field {
+	return Field{
+		Key:   key,
+		Kind:  logcore.KindBinary,
+		Value: value,
+	}
+}
+
+// Namespace nests the fields after it under key. Passed to With, it stays
+// open in the derived logger: the fields of its later With calls and entries
+// are nested under key too.
+//
+// Example:
+//
+//	logx.Info("request", logx.String("id", id), logx.Namespace("user"), logx.String("name", name))
+//	// {"msg":"request","id":"...","user":{"name":"..."}}
+func Namespace(key string) Field {
+	return Field{
+		Key:  key,
+		Kind: logcore.KindNamespace,
+	}
+}
+
+// Stack returns a field with the stack trace of the caller.
+func Stack(key string) Field {
+	return Field{
+		Key:   key,
+		Kind:  logcore.KindStack,
+		Value: logcore.Stacktrace(1),
+	}
+}
+
+// Object returns a field encoded by value.MarshalLogObject.
+func Object(key string, value ObjectMarshaler) Field {
+	if value == nil {
+		return Field{Key: key}
+	}
+	return Field{
+		Key:   key,
+		Kind:  logcore.KindObject,
+		Value: value,
+	}
+}
+
+// Array returns a field encoded by value.MarshalLogArray.
+func Array(key string, value ArrayMarshaler) Field {
+	if value == nil {
+		return Field{Key: key}
+	}
+	return Field{
+		Key:   key,
+		Kind:  logcore.KindArray,
 		Value: value,
 	}
 }
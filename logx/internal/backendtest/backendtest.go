@@ -0,0 +1,627 @@
+// Package backendtest is the conformance suite shared by the logx backends.
+// Each backend runs it from its own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		backendtest.Run(t, backendtest.Backend{
+//			New:      func(cfg *logcore.LoggerConf) (backendtest.Instance, error) { return NewInstance(cfg) },
+//			Duration: 1.5,
+//		})
+//	}
+package backendtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Instance is a logger created by a backend.
+type Instance interface {
+	logcore.LevelLogger
+	Close() error
+	Dropped() uint64
+}
+
+// Backend describes the backend under test.
+type Backend struct {
+	// New creates a logger from cfg.
+	New func(cfg *logcore.LoggerConf) (Instance, error)
+	// Duration is the JSON value the backend encodes 1.5s as.
+	Duration any
+}
+
+// Run runs the conformance suite against b.
+func Run(t *testing.T, b Backend) {
+	for _, test := range []func(*testing.T, Backend){
+		testFileFormat,
+		testContext,
+		testRuntimeLevel,
+		testSampling,
+		testRedaction,
+		testAsync,
+		testSinks,
+		testNamed,
+		testFilePattern,
+		testFieldKinds,
+		testErrorFields,
+	} {
+		test(t, b)
+	}
+}
+
+func testFileFormat(t *testing.T, b Backend) {
+	t.Run("文件格式测试", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = dir, false
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+		ins.AddCallerSkip(-1).Warn("hello")
+		_ = ins.Sync()
+
+		data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+		assert.Nil(t, err)
+		var m map[string]any
+		assert.Nil(t, json.Unmarshal(data, &m))
+		assert.Equal(t, "warn", m["level"])
+		assert.Equal(t, "hello", m["msg"])
+		assert.Contains(t, m, "timestamp")
+		caller, _ := m["caller"].(string)
+		assert.True(t, strings.HasPrefix(caller, "backendtest/backendtest.go:"), caller)
+	})
+
+	t.Run("Multi 按级别分文件", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.Multi, cfg.Level = dir, false, true, "info"
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+		ins.Debug("d")
+		ins.Info("i")
+		ins.Error("e")
+		_ = ins.Sync()
+
+		_, err = os.Stat(filepath.Join(dir, "app-debug.log"))
+		assert.True(t, os.IsNotExist(err))
+		info, err := os.ReadFile(filepath.Join(dir, "app-info.log"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(info), `"msg":"i"`)
+		assert.NotContains(t, string(info), `"msg":"e"`)
+		e, err := os.ReadFile(filepath.Join(dir, "app-error.log"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(e), `"msg":"e"`)
+	})
+}
+
+func testContext(t *testing.T, b Backend) {
+	t.Run("Ctx 方法测试", func(t *testing.T) {
+		type key struct{}
+		logcore.SetContextExtractors(func(ctx context.Context) []logcore.Field {
+			if v, ok := ctx.Value(key{}).(string); ok {
+				return []logcore.Field{{Key: "request_id", Kind: reflect.String, Value: v}}
+			}
+			return nil
+		})
+		defer logcore.SetContextExtractors()
+
+		dir := t.TempDir()
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = dir, false
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		ctx := context.WithValue(context.Background(), key{}, "abc")
+		ins.DebugCtx(ctx, "d")
+		ins.InfoCtx(ctx, "i")
+		ins.WarnCtx(ctx, "w")
+		ins.ErrorCtx(ctx, "e")
+		ins.LogCtx(ctx, "info", "l", logcore.Field{Key: "k", Kind: reflect.String, Value: "v"})
+		ins.InfoCtx(context.Background(), "plain")
+		_ = ins.Sync()
+
+		data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(t, lines, 6)
+		for _, line := range lines[:5] {
+			assert.Contains(t, line, `"request_id":"abc"`)
+		}
+		assert.Contains(t, lines[4], `"k":"v"`)
+		assert.NotContains(t, lines[5], "request_id")
+	})
+}
+
+func testRuntimeLevel(t *testing.T, b Backend) {
+	t.Run("运行时级别测试", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.Level = dir, false, "info"
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+		db := ins.Named("db")
+		with := ins.With(logcore.Field{Key: "k", Value: "v"})
+
+		ins.Debug("root debug")
+		db.Debug("db debug 1")
+		assert.Nil(t, db.SetLevel("debug"))
+		db.Debug("db debug 2")
+		db.Named("sql").Debug("sql debug")
+		assert.Equal(t, "info", ins.Level())
+		assert.Equal(t, "debug", db.Level())
+
+		assert.Nil(t, ins.SetLevel("error"))
+		ins.Warn("root warn")
+		with.Warn("with warn")
+		ins.Error("root error")
+		assert.NotNil(t, ins.SetLevel("verbose"))
+		assert.Equal(t, "error", ins.Level())
+		_ = ins.Sync()
+
+		data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+		assert.Nil(t, err)
+		out := string(data)
+		assert.NotContains(t, out, "root debug")
+		assert.NotContains(t, out, "db debug 1")
+		assert.Contains(t, out, "db debug 2")
+		assert.Contains(t, out, `"logger":"db"`)
+		assert.Contains(t, out, `"logger":"db.sql"`)
+		assert.NotContains(t, out, "root warn")
+		assert.NotContains(t, out, "with warn")
+		assert.Contains(t, out, "root error")
+	})
+}
+
+func testSampling(t *testing.T, b Backend) {
+	t.Run("采样与去重测试", func(t *testing.T) {
+		newLogger := func(t *testing.T, modify func(*logcore.LoggerConf)) (logcore.Logger, string) {
+			cfg := logcore.DefaultLoggerConf()
+			cfg.Dir, cfg.Console = t.TempDir(), false
+			cfg.SampleInterval = time.Hour
+			modify(cfg)
+			ins, err := b.New(cfg)
+			assert.Nil(t, err)
+			return ins, filepath.Join(cfg.Dir, "app.log")
+		}
+
+		t.Run("先 N 条后每 M 条", func(t *testing.T) {
+			l, file := newLogger(t, func(c *logcore.LoggerConf) { c.SampleFirst, c.SampleThereafter = 2, 3 })
+			for range 10 {
+				l.Error("boom")
+			}
+			for i := range 3 {
+				l.Infof("n=%d", i)
+			}
+			_ = l.Sync()
+			data, err := os.ReadFile(file)
+			assert.Nil(t, err)
+			assert.Equal(t, 4, strings.Count(string(data), `"msg":"boom"`))
+			assert.Equal(t, 3, strings.Count(string(data), `"msg":"n=`))
+			assert.NotContains(t, string(data), "repeated")
+		})
+
+		t.Run("去重并输出汇总", func(t *testing.T) {
+			l, file := newLogger(t, func(c *logcore.LoggerConf) { c.Dedup = true })
+			for range 5 {
+				l.Warn("flap")
+			}
+			_ = l.Sync()
+			data, err := os.ReadFile(file)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, strings.Count(string(data), `"msg":"flap"`))
+			assert.Contains(t, string(data), `"msg":"flap (repeated 4 times)"`)
+			assert.Contains(t, string(data), `"repeated":4`)
+		})
+	})
+}
+
+func testRedaction(t *testing.T, b Backend) {
+	t.Run("字段脱敏测试", func(t *testing.T) {
+		logcore.SetRedactor(logcore.DefaultRedactor())
+		defer logcore.SetRedactor(nil)
+
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		ins.With(logcore.Field{Key: "token", Kind: reflect.String, Value: "t0ken"}).
+			Info("login", logcore.Field{Key: "Password", Kind: reflect.String, Value: "hunter2"}, logcore.Field{Key: "user", Kind: reflect.String, Value: "bob"})
+		_ = ins.Sync()
+
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, "app.log"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), `"Password":"[REDACTED]"`)
+		assert.Contains(t, string(data), `"token":"[REDACTED]"`)
+		assert.Contains(t, string(data), `"user":"bob"`)
+		assert.NotContains(t, string(data), "hunter2")
+		assert.NotContains(t, string(data), "t0ken")
+	})
+}
+
+func testAsync(t *testing.T, b Backend) {
+	t.Run("异步写入测试", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		cfg.Async, cfg.AsyncFlushInterval = true, time.Hour
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		file := filepath.Join(cfg.Dir, "app.log")
+		read := func() string {
+			data, _ := os.ReadFile(file)
+			return string(data)
+		}
+
+		ins.Info("buffered")
+		assert.NotContains(t, read(), "buffered")
+		assert.Nil(t, ins.Sync())
+		assert.Contains(t, read(), `"msg":"buffered"`)
+
+		ins.Warn("closing")
+		assert.Nil(t, ins.Close())
+		assert.Contains(t, read(), `"msg":"closing"`)
+		ins.Error("after close")
+		assert.Contains(t, read(), `"msg":"after close"`)
+		assert.Zero(t, ins.Dropped())
+	})
+
+	t.Run("未知溢出策略", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		cfg.Async, cfg.AsyncOverflow = true, "spill"
+		_, err := b.New(cfg)
+		assert.Error(t, err)
+	})
+}
+
+// memSink records the entries written to it and counts its syncs.
+type memSink struct {
+	mu      sync.Mutex
+	entries []string
+	synced  int
+}
+
+func (s *memSink) WriteEntry(level string, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var e struct {
+		Msg string `json:"msg"`
+	}
+	_ = json.Unmarshal(line, &e)
+	s.entries = append(s.entries, level+" "+e.Msg)
+	return nil
+}
+
+func (s *memSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.synced++
+	return nil
+}
+
+func (s *memSink) Close() error { return nil }
+
+func (s *memSink) syncs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced
+}
+
+func testSinks(t *testing.T, b Backend) {
+	t.Run("sink 级别过滤测试", func(t *testing.T) {
+		var (
+			all  = &memSink{}
+			warn = &memSink{}
+		)
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.Level = t.TempDir(), false, "info"
+		cfg.Sinks = []logcore.SinkConf{{Sink: all}, {Sink: warn, Level: "warn"}}
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		ins.Debug("d")
+		ins.Info("i")
+		ins.Warn("w")
+		ins.Errorf("e%d", 1)
+		assert.Nil(t, ins.Sync())
+		assert.Equal(t, []string{"info i", "warn w", "error e1"}, all.entries)
+		assert.Equal(t, []string{"warn w", "error e1"}, warn.entries)
+		assert.Nil(t, ins.Close())
+	})
+
+	t.Run("sink 配置错误", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		cfg.Sinks = []logcore.SinkConf{{Type: "kafka"}}
+		_, err := b.New(cfg)
+		assert.Error(t, err)
+	})
+}
+
+func testNamed(t *testing.T, b Backend) {
+	t.Run("命名日志器的 Sync", func(t *testing.T) {
+		sink := &memSink{}
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		cfg.Async, cfg.AsyncFlushInterval = true, time.Hour
+		cfg.Sinks = []logcore.SinkConf{{Sink: sink}}
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		named := ins.Named("db")
+		named.Info("named")
+		assert.Nil(t, named.Sync())
+
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, "app.log"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), `"msg":"named"`)
+		assert.Contains(t, string(data), `"db"`)
+		assert.Equal(t, []string{"info named"}, sink.entries)
+		assert.NotZero(t, sink.syncs())
+		assert.Nil(t, ins.Close())
+	})
+}
+
+func testFilePattern(t *testing.T, b Backend) {
+	t.Run("按日期命名的分级文件", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.Multi = t.TempDir(), false, true
+		cfg.FilePattern, cfg.Rotate, cfg.Compress = "app-%Y%m%d.log", "daily", "gzip"
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		ins.Info("info msg")
+		ins.Error("error msg")
+		assert.Nil(t, ins.Close())
+
+		day := time.Now().Format("20060102")
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, "app-"+day+"-info.log"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), "info msg")
+		data, err = os.ReadFile(filepath.Join(cfg.Dir, "app-"+day+"-error.log"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), "error msg")
+	})
+
+	t.Run("错误的轮转配置", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.Rotate = t.TempDir(), false, "weekly"
+		_, err := b.New(cfg)
+		assert.Error(t, err)
+	})
+}
+
+type testUser struct {
+	name string
+	tags testStrings
+	fail bool
+}
+
+func (u testUser) MarshalLogObject(enc logcore.ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	if err := enc.AddArray("tags", u.tags); err != nil {
+		return err
+	}
+	if u.fail {
+		return errors.New("marshal failed")
+	}
+	return nil
+}
+
+type testStrings []string
+
+func (s testStrings) MarshalLogArray(enc logcore.ArrayEncoder) error {
+	for _, v := range s {
+		enc.AppendString(v)
+	}
+	return nil
+}
+
+type testUsers []testUser
+
+func (us testUsers) MarshalLogArray(enc logcore.ArrayEncoder) error {
+	for _, u := range us {
+		if err := enc.AppendObject(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type testStringer struct{}
+
+func (testStringer) String() string { return "stringer" }
+
+func testFieldKinds(t *testing.T, b Backend) {
+	t.Run("扩展字段类型编码", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		ins.With(logcore.Field{Key: "owner", Kind: logcore.KindObject, Value: testUser{name: "ann", tags: testStrings{"a"}}}).Info("fields",
+			logcore.Field{Key: "elapsed", Kind: logcore.KindDuration, Value: 1500 * time.Millisecond},
+			logcore.Field{Key: "at", Kind: logcore.KindTime, Value: at},
+			logcore.Field{Key: "s", Kind: logcore.KindStringer, Value: testStringer{}},
+			logcore.Field{Key: "bin", Kind: logcore.KindBinary, Value: []byte("hi")},
+			logcore.Field{Key: "stack", Kind: logcore.KindStack, Value: "main.main\n\tmain.go:1"},
+			logcore.Field{Key: "users", Kind: logcore.KindArray, Value: testUsers{{name: "bob", tags: testStrings{"x", "y"}}}},
+			logcore.Field{Key: "bad", Kind: logcore.KindObject, Value: testUser{fail: true}},
+			logcore.Field{Key: "ns", Kind: logcore.KindNamespace},
+			logcore.Field{Key: "inner", Kind: reflect.String, Value: "v"},
+		)
+		assert.Nil(t, ins.Sync())
+
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, "app.log"))
+		assert.Nil(t, err)
+		var m map[string]any
+		assert.Nil(t, json.Unmarshal(data, &m))
+
+		assert.Equal(t, map[string]any{"name": "ann", "tags": []any{"a"}}, m["owner"])
+		assert.Equal(t, b.Duration, m["elapsed"])
+		parsed, err := time.Parse(time.RFC3339Nano, m["at"].(string))
+		assert.Nil(t, err)
+		assert.True(t, at.Equal(parsed))
+		assert.Equal(t, "stringer", m["s"])
+		assert.Equal(t, "aGk=", m["bin"])
+		assert.Equal(t, "main.main\n\tmain.go:1", m["stack"])
+		assert.Equal(t, []any{map[string]any{"name": "bob", "tags": []any{"x", "y"}}}, m["users"])
+		assert.Equal(t, "marshal failed", m["badError"])
+		assert.Equal(t, map[string]any{"inner": "v"}, m["ns"])
+		assert.NotContains(t, m, "inner")
+	})
+
+	t.Run("With 中的 Namespace 嵌套之后的字段", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		l := ins.With(
+			logcore.Field{Key: "id", Kind: reflect.Int, Value: 1},
+			logcore.Field{Key: "req", Kind: logcore.KindNamespace},
+			logcore.Field{Key: "path", Kind: reflect.String, Value: "/"},
+		)
+		l.Info("with", logcore.Field{Key: "status", Kind: reflect.Int, Value: 200})
+		l.With(logcore.Field{Key: "user", Kind: reflect.String, Value: "ann"}).Infof("with %d", 2)
+		assert.Nil(t, ins.Sync())
+
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, "app.log"))
+		assert.Nil(t, err)
+		var got []map[string]any
+		for line := range strings.Lines(string(data)) {
+			var m map[string]any
+			assert.Nil(t, json.Unmarshal([]byte(line), &m))
+			for _, key := range []string{"level", "msg", "timestamp", "caller"} {
+				delete(m, key)
+			}
+			got = append(got, m)
+		}
+		assert.Equal(t, []map[string]any{
+			{"id": 1.0, "req": map[string]any{"path": "/", "status": 200.0}},
+			{"id": 1.0, "req": map[string]any{"path": "/", "user": "ann"}},
+		}, got)
+	})
+}
+
+// testError is a coded error recording the stack of its creation.
+type testError struct {
+	code string
+	pcs  []uintptr
+}
+
+func newTestError(code string) *testError {
+	pcs := make([]uintptr, 32)
+	return &testError{code: code, pcs: pcs[:runtime.Callers(2, pcs)]}
+}
+
+func (e *testError) Error() string { return e.code + ": not found" }
+
+func (e *testError) Callers() []uintptr { return e.pcs }
+
+func (e *testError) MarshalLogObject(enc logcore.ObjectEncoder) error {
+	enc.AddString("code", e.code)
+	return nil
+}
+
+func testErrorFields(t *testing.T, b Backend) {
+	t.Run("错误链与堆栈", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.StackLevel = t.TempDir(), false, "error"
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		l := ins.AddCallerSkip(-1)
+		l.Error("failed", logcore.Field{Key: "error", Kind: logcore.KindError, Value: fmt.Errorf("load config: %w", newTestError("E404"))})
+		l.Info("plain", logcore.Field{Key: "error", Kind: logcore.KindError, Value: errors.New("boom")})
+		l.With(logcore.Field{Key: "cause", Kind: logcore.KindError, Value: errors.Join(errors.New("a"), errors.New("b"))}).Warn("joined")
+		assert.Nil(t, ins.Sync())
+
+		f, err := os.Open(filepath.Join(cfg.Dir, "app.log"))
+		assert.Nil(t, err)
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		var failed, plain, joined map[string]any
+		assert.Nil(t, dec.Decode(&failed))
+		assert.Nil(t, dec.Decode(&plain))
+		assert.Nil(t, dec.Decode(&joined))
+
+		fn := "github.com/BYT0723/go-tools/logx/internal/backendtest.testErrorFields.func1"
+		assert.Equal(t, "load config: E404: not found", failed["error"])
+		assert.Equal(t, []any{
+			map[string]any{"msg": "load config: E404: not found", "type": "*fmt.wrapError"},
+			map[string]any{"msg": "E404: not found", "type": "*backendtest.testError", "code": "E404"},
+		}, failed["errorChain"])
+		assert.True(t, strings.HasPrefix(failed["errorStack"].(string), fn+"\n"))
+		assert.True(t, strings.HasPrefix(failed["stacktrace"].(string), fn+"\n"))
+
+		assert.Equal(t, "boom", plain["error"])
+		for _, key := range []string{"errorChain", "errorStack", "stacktrace"} {
+			assert.NotContains(t, plain, key)
+		}
+
+		assert.Equal(t, "a\nb", joined["cause"])
+		assert.Equal(t, []any{
+			map[string]any{"msg": "a\nb", "type": "*errors.joinError"},
+			map[string]any{"msg": "a", "type": "*errors.errorString"},
+			map[string]any{"msg": "b", "type": "*errors.errorString"},
+		}, joined["causeChain"])
+		assert.NotContains(t, joined, "stacktrace")
+	})
+
+	t.Run("nil 指针错误", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console = t.TempDir(), false
+		ins, err := b.New(cfg)
+		assert.Nil(t, err)
+
+		// The methods of *testError dereference it, so they panic on nil.
+		var nilErr error = (*testError)(nil)
+		assert.NotPanics(t, func() {
+			ins.Error("nil", logcore.Field{Key: "error", Kind: logcore.KindError, Value: nilErr})
+			ins.With(logcore.Field{Key: "cause", Kind: logcore.KindError, Value: nilErr}).Error("with")
+			ins.Error("wrapped", logcore.Field{Key: "error", Kind: logcore.KindError, Value: fmt.Errorf("load: %w", nilErr)})
+		})
+		assert.Nil(t, ins.Sync())
+
+		f, err := os.Open(filepath.Join(cfg.Dir, "app.log"))
+		assert.Nil(t, err)
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		var direct, with, wrapped map[string]any
+		assert.Nil(t, dec.Decode(&direct))
+		assert.Nil(t, dec.Decode(&with))
+		assert.Nil(t, dec.Decode(&wrapped))
+
+		assert.Equal(t, "<nil>", direct["error"])
+		assert.NotContains(t, direct, "errorChain")
+		assert.Equal(t, "<nil>", with["cause"])
+		assert.Equal(t, "load: <nil>", wrapped["error"])
+		assert.Equal(t, []any{
+			map[string]any{"msg": "load: <nil>", "type": "*fmt.wrapError"},
+			map[string]any{"msg": "<nil>", "type": "*backendtest.testError"},
+		}, wrapped["errorChain"])
+		assert.NotContains(t, wrapped, "errorStack")
+	})
+
+	t.Run("错误的堆栈级别", func(t *testing.T) {
+		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir, cfg.Console, cfg.StackLevel = t.TempDir(), false, "loud"
+		_, err := b.New(cfg)
+		assert.Error(t, err)
+	})
+}
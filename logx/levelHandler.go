@@ -0,0 +1,73 @@
+package logx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// levelPayload is the JSON body read and written by LevelHandler.
+type levelPayload struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an http.Handler to read and change log levels at runtime.
+//
+//   - GET: returns all levels, as {"": "info", "db": "debug"}; the empty name is
+//     the default logger. With ?name=db, returns {"name": "db", "level": "debug"}.
+//   - PUT or POST: sets the level of a logger from a body like
+//     {"name": "db", "level": "debug"} and returns it like GET ?name=db.
+//
+// Errors are returned as {"error": "..."} with status 400, or 405 for other methods.
+//
+// Example:
+//
+//	mux.Handle("/debug/log/level", logx.LevelHandler())
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if !r.URL.Query().Has("name") {
+				writeLevelJSON(w, http.StatusOK, Levels())
+				return
+			}
+			name := r.URL.Query().Get("name")
+			level, err := GetLevel(name)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+			writeLevelJSON(w, http.StatusOK, levelPayload{Name: name, Level: level})
+		case http.MethodPut, http.MethodPost:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+			if req.Level == "" {
+				writeLevelError(w, http.StatusBadRequest, errors.New("logx: level is required"))
+				return
+			}
+			if err := SetLevel(req.Name, req.Level); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+			level, _ := GetLevel(req.Name)
+			writeLevelJSON(w, http.StatusOK, levelPayload{Name: req.Name, Level: level})
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelError(w, http.StatusMethodNotAllowed, errors.New("logx: method not allowed"))
+		}
+	})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	writeLevelJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,281 @@
+package logcore
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy is what an AsyncWriter does with a line when its buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDrop drops the line.
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowBlock waits until there is room for the line.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowSample keeps one in every OverflowSampleRate lines, waiting
+	// until there is room for it, and drops the others.
+	OverflowSample OverflowPolicy = "sample"
+)
+
+const (
+	// DefaultAsyncBufferSize is used when LoggerConf.AsyncBufferSize is not set.
+	DefaultAsyncBufferSize = 8192
+	// DefaultAsyncFlushInterval is used when LoggerConf.AsyncFlushInterval is not set.
+	DefaultAsyncFlushInterval = time.Second
+	// OverflowSampleRate is the rate at which OverflowSample keeps lines.
+	OverflowSampleRate = 10
+)
+
+// asyncBatch is the number of buffered lines that triggers a flush before the interval ends.
+const asyncBatch = 256
+
+// AsyncWriter buffers the lines written to it in a ring buffer and writes
+// them to the underlying writer from a background goroutine, in batches:
+// when asyncBatch lines are buffered, every flush interval, and on Sync and
+// Close. Each Write is expected to be one log line, as with zap, zerolog and
+// slog handlers.
+//
+// After Close, lines are written to the underlying writer synchronously.
+type AsyncWriter struct {
+	w        io.Writer
+	policy   OverflowPolicy
+	interval time.Duration
+	batch    int
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signaled when lines are flushed or the writer is closed
+	ring     [][]byte   // slots keep their backing arrays across uses
+	head     int
+	count    int
+	pushed   uint64 // lines buffered so far
+	flushed  uint64 // lines flushed so far
+	overflow uint64 // lines written while the buffer was full, for OverflowSample
+	closed   bool
+	err      error // first write error since the last Sync
+
+	dropped atomic.Uint64
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewAsyncWriter starts an AsyncWriter on w configured by the Async* fields of cfg.
+func NewAsyncWriter(w io.Writer, cfg *LoggerConf) (*AsyncWriter, error) {
+	aw := &AsyncWriter{
+		w:        w,
+		policy:   cfg.AsyncOverflow,
+		interval: cfg.AsyncFlushInterval,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	switch aw.policy {
+	case "":
+		aw.policy = OverflowDrop
+	case OverflowDrop, OverflowBlock, OverflowSample:
+	default:
+		return nil, fmt.Errorf("logcore: unknown overflow policy %q", aw.policy)
+	}
+	size := cfg.AsyncBufferSize
+	if size <= 0 {
+		size = DefaultAsyncBufferSize
+	}
+	if aw.interval <= 0 {
+		aw.interval = DefaultAsyncFlushInterval
+	}
+	aw.ring = make([][]byte, size)
+	aw.batch = max(min(asyncBatch, size/2), 1)
+	aw.cond = sync.NewCond(&aw.mu)
+
+	go aw.run()
+	return aw, nil
+}
+
+// Write buffers a copy of p. If the buffer is full, p is handled as set by
+// the overflow policy; a dropped line is reported as written.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.closed && w.count == len(w.ring) {
+		switch w.policy {
+		case OverflowDrop:
+			w.dropped.Add(1)
+			return len(p), nil
+		case OverflowSample:
+			w.overflow++
+			if w.overflow%OverflowSampleRate != 0 {
+				w.dropped.Add(1)
+				return len(p), nil
+			}
+		}
+		w.notify()
+		for !w.closed && w.count == len(w.ring) {
+			w.cond.Wait()
+		}
+	}
+	if w.closed {
+		// Keep the order of the lines: wait for the last flush to finish.
+		for w.count > 0 {
+			w.cond.Wait()
+		}
+		return w.w.Write(p)
+	}
+
+	i := (w.head + w.count) % len(w.ring)
+	w.ring[i] = append(w.ring[i][:0], p...)
+	w.count++
+	w.pushed++
+	if w.count >= w.batch {
+		w.notify()
+	}
+	return len(p), nil
+}
+
+// Sync waits until the lines buffered before the call are written, then
+// syncs the underlying writer if it has a Sync method. It returns the first
+// error the underlying writer returned since the last Sync.
+func (w *AsyncWriter) Sync() error {
+	w.mu.Lock()
+	target := w.pushed
+	w.notify()
+	for w.flushed < target {
+		w.cond.Wait()
+	}
+	err := w.err
+	w.err = nil
+	w.mu.Unlock()
+
+	if s, ok := w.w.(interface{ Sync() error }); ok {
+		if serr := s.Sync(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// Close flushes the buffered lines and stops the background goroutine.
+// It does not close the underlying writer.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+	return w.Sync()
+}
+
+// Dropped returns the number of lines dropped because the buffer was full.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+func (w *AsyncWriter) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var buf []byte
+	for {
+		select {
+		case <-w.wake:
+		case <-ticker.C:
+		case <-w.stop:
+			w.flush(buf)
+			return
+		}
+		buf = w.flush(buf)
+	}
+}
+
+// flush writes the buffered lines as one batch, reusing buf, and returns buf.
+func (w *AsyncWriter) flush(buf []byte) []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.count > 0 {
+		// Producers do not touch the slots being flushed until count is
+		// decremented, so they can be read without the lock.
+		head, n := w.head, w.count
+		w.mu.Unlock()
+		buf = buf[:0]
+		for i := range n {
+			buf = append(buf, w.ring[(head+i)%len(w.ring)]...)
+		}
+		_, err := w.w.Write(buf)
+		w.mu.Lock()
+
+		if err != nil && w.err == nil {
+			w.err = err
+		}
+		w.head = (head + n) % len(w.ring)
+		w.count -= n
+		w.flushed += uint64(n)
+		w.cond.Broadcast()
+	}
+	return buf
+}
+
+// AsyncWriters are the AsyncWriters of a backend instance.
+type AsyncWriters []*AsyncWriter
+
+// Wrap returns w wrapped in an AsyncWriter added to ws if cfg.Async is set,
+// and w otherwise.
+func (ws *AsyncWriters) Wrap(w io.Writer, cfg *LoggerConf) (io.Writer, error) {
+	if !cfg.Async {
+		return w, nil
+	}
+	aw, err := NewAsyncWriter(w, cfg)
+	if err != nil {
+		return nil, err
+	}
+	*ws = append(*ws, aw)
+	return aw, nil
+}
+
+// Sync syncs all writers and returns the first error.
+func (ws AsyncWriters) Sync() error {
+	var err error
+	for _, w := range ws {
+		if serr := w.Sync(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// Close closes all writers and returns the first error.
+func (ws AsyncWriters) Close() error {
+	var err error
+	for _, w := range ws {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Dropped returns the number of lines dropped by all writers.
+func (ws AsyncWriters) Dropped() uint64 {
+	var n uint64
+	for _, w := range ws {
+		n += w.Dropped()
+	}
+	return n
+}
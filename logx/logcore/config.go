@@ -1,5 +1,7 @@
 package logcore
 
+import "time"
+
 type LoggerConf struct {
 	// log folder.
 	// default: logs
@@ -25,9 +27,65 @@ type LoggerConf struct {
 	// The maximum storage duration of the file, in days.
 	// default: 7
 	MaxAge int
+	// Time-based rotation: "hourly", "daily" or a cron expression such as
+	// "0 */6 * * *", see rotate.ParseSchedule. Files are also rotated by MaxSize.
+	// default: "" (by size only)
+	Rotate string
+	// File name pattern in Dir with the date placeholders %Y %m %d %H %M %S,
+	// e.g. "app-%Y%m%d.log", used instead of Name and Ext. With Multi the
+	// level is inserted before the extension: "app-%Y%m%d-error.log".
+	// default: ""
+	FilePattern string
+	// Compression of rotated files: "gzip" or "zstd".
+	// default: "" (none)
+	Compress string
+	// The maximum total size of the current and rotated files of each output,
+	// in MB. The oldest rotated files are removed to stay within it.
+	// default: 0 (no limit)
+	MaxTotalSize int
+	// Called with the path of each rotated file once it is compressed, e.g.
+	// to upload it. It runs in a background goroutine.
+	// default: nil
+	OnRotate func(path string)
+	// Called with the errors of compressing and pruning rotated files. It
+	// runs in a background goroutine.
+	// default: nil (written to stderr)
+	OnRotateError func(err error)
 	// Whether the console outputs.
 	// default: false
 	Console bool
+	// Sampling, per level and message: in each SampleInterval, the first
+	// SampleFirst entries are logged, then every SampleThereafter-th one.
+	// default: 0 (disabled)
+	SampleFirst int
+	// default: 0 (drop all after SampleFirst)
+	SampleThereafter int
+	// default: 1s
+	SampleInterval time.Duration
+	// Log each message once per SampleInterval and write the number of
+	// dropped duplicates as "msg (repeated N times)" when the interval ends.
+	// With SampleFirst set, entries are dropped by the sampling rule instead.
+	// default: false
+	Dedup bool
+	// Write the log files from a background goroutine, through a buffer of
+	// AsyncBufferSize lines flushed in batches, so that slow disks do not
+	// stall the callers. Buffered lines are written on Sync and Close.
+	// default: false
+	Async bool
+	// default: 8192
+	AsyncBufferSize int
+	// default: 1s
+	AsyncFlushInterval time.Duration
+	// What to do with a line when the buffer is full, see OverflowPolicy.
+	// default: drop
+	AsyncOverflow OverflowPolicy
+	// Remote outputs, in addition to the files and the console.
+	// default: none
+	Sinks []SinkConf
+	// The lowest level whose entries carry the stack trace of the logging
+	// call in the field "stacktrace", e.g. "error".
+	// default: "" (none)
+	StackLevel string
 }
 
 // 合并LoggerConf
@@ -53,8 +111,52 @@ func (c *LoggerConf) Merge(cfg *LoggerConf) {
 	if cfg.MaxAge > 0 {
 		c.MaxAge = cfg.MaxAge
 	}
+	if cfg.Rotate != "" {
+		c.Rotate = cfg.Rotate
+	}
+	if cfg.FilePattern != "" {
+		c.FilePattern = cfg.FilePattern
+	}
+	if cfg.Compress != "" {
+		c.Compress = cfg.Compress
+	}
+	if cfg.MaxTotalSize > 0 {
+		c.MaxTotalSize = cfg.MaxTotalSize
+	}
+	if cfg.OnRotate != nil {
+		c.OnRotate = cfg.OnRotate
+	}
+	if cfg.OnRotateError != nil {
+		c.OnRotateError = cfg.OnRotateError
+	}
+	if cfg.SampleFirst > 0 {
+		c.SampleFirst = cfg.SampleFirst
+	}
+	if cfg.SampleThereafter > 0 {
+		c.SampleThereafter = cfg.SampleThereafter
+	}
+	if cfg.SampleInterval > 0 {
+		c.SampleInterval = cfg.SampleInterval
+	}
+	if cfg.AsyncBufferSize > 0 {
+		c.AsyncBufferSize = cfg.AsyncBufferSize
+	}
+	if cfg.AsyncFlushInterval > 0 {
+		c.AsyncFlushInterval = cfg.AsyncFlushInterval
+	}
+	if cfg.AsyncOverflow != "" {
+		c.AsyncOverflow = cfg.AsyncOverflow
+	}
+	if len(cfg.Sinks) > 0 {
+		c.Sinks = cfg.Sinks
+	}
+	if cfg.StackLevel != "" {
+		c.StackLevel = cfg.StackLevel
+	}
 	c.Multi = cfg.Multi
 	c.Console = cfg.Console
+	c.Dedup = cfg.Dedup
+	c.Async = cfg.Async
 }
 
 func DefaultLoggerConf() *LoggerConf {
@@ -0,0 +1,48 @@
+package logcore
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor returns the fields carried by ctx, or nil.
+type ContextExtractor func(ctx context.Context) []Field
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// RegisterContextExtractor appends extractors to the list consulted by the *Ctx methods.
+func RegisterContextExtractor(e ...ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors[:len(extractors):len(extractors)], e...)
+}
+
+// SetContextExtractors replaces the extractor list. Without arguments, it clears the list.
+func SetContextExtractors(e ...ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append([]ContextExtractor(nil), e...)
+}
+
+// ContextFields returns the fields extracted from ctx by the registered
+// extractors, in registration order, followed by kvs.
+func ContextFields(ctx context.Context, kvs ...Field) []Field {
+	if ctx == nil {
+		return kvs
+	}
+	extractorsMu.RLock()
+	list := extractors
+	extractorsMu.RUnlock()
+
+	var fields []Field
+	for _, e := range list {
+		fields = append(fields, e(ctx)...)
+	}
+	if len(fields) == 0 {
+		return kvs
+	}
+	return append(fields, kvs...)
+}
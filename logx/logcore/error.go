@@ -0,0 +1,141 @@
+package logcore
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// StackTracer is implemented by errors that record the stack where they were
+// created, as the program counters returned by runtime.Callers.
+type StackTracer interface {
+	Callers() []uintptr
+}
+
+// maxErrorChain bounds the number of errors logged from a chain.
+const maxErrorChain = 32
+
+// ErrorFields returns the fields logging err under key, the same in all
+// backends:
+//   - key: the message of err;
+//   - key+"Chain": if err wraps or joins other errors, or is an
+//     ObjectMarshaler, the errors reached by errors.Unwrap and Join, depth
+//     first, each an object with "msg", "type" and, for ObjectMarshalers,
+//     the fields added by MarshalLogObject (e.g. "code" or "kind");
+//   - key+"Stack": the stack trace of the innermost error that records one,
+//     either a StackTracer or an error with a StackTrace method returning
+//     program counters, as those of github.com/pkg/errors.
+//
+// An error holding a nil pointer, e.g. a nil *APIError returned as error, is
+// logged as "<nil>" without calling its methods, which would likely panic.
+//
+// Example:
+//
+//	{"error":"load config: not found","errorChain":[{"msg":"load config: not found","type":"*fmt.wrapError"},{"msg":"not found","type":"*app.Error","code":"E404"}]}
+func ErrorFields(key string, err error) []Field {
+	if isNilError(err) {
+		return []Field{{Key: key, Kind: reflect.String, Value: "<nil>"}}
+	}
+	chain := newErrorChain(err)
+	fields := []Field{{Key: key, Kind: reflect.String, Value: err.Error()}}
+	if _, ok := err.(ObjectMarshaler); ok || len(chain) > 1 {
+		fields = append(fields, Field{Key: key + "Chain", Kind: KindArray, Value: chain})
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if pcs := callers(chain[i]); len(pcs) > 0 {
+			fields = append(fields, Field{Key: key + "Stack", Kind: KindStack, Value: FormatStack(pcs)})
+			break
+		}
+	}
+	return fields
+}
+
+// errorChain lists the errors of the chain of err, depth first.
+type errorChain []error
+
+func newErrorChain(err error) errorChain {
+	var chain errorChain
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil || len(chain) == maxErrorChain {
+			return
+		}
+		chain = append(chain, err)
+		if isNilError(err) {
+			return
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(u.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				walk(e)
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+func (c errorChain) MarshalLogArray(enc ArrayEncoder) error {
+	for _, err := range c {
+		if err := enc.AppendObject(errorObject{err}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type errorObject struct {
+	err error
+}
+
+func (o errorObject) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("msg", errorMessage(o.err))
+	enc.AddString("type", fmt.Sprintf("%T", o.err))
+	if m, ok := o.err.(ObjectMarshaler); ok && !isNilError(o.err) {
+		return m.MarshalLogObject(enc)
+	}
+	return nil
+}
+
+// errorMessage returns the message of err, "<nil>" if it holds a nil pointer.
+func errorMessage(err error) string {
+	if isNilError(err) {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+// isNilError reports whether err, not nil itself, holds a nil pointer or
+// another nil value.
+func isNilError(err error) bool {
+	switch v := reflect.ValueOf(err); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// callers returns the program counters recorded by err, if any.
+func callers(err error) []uintptr {
+	if isNilError(err) {
+		return nil
+	}
+	if st, ok := err.(StackTracer); ok {
+		return st.Callers()
+	}
+	// github.com/pkg/errors: StackTrace() errors.StackTrace, a []Frame of uintptr.
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if t := m.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs
+}
@@ -1,9 +1,103 @@
 package logcore
 
-import "reflect"
+import (
+	"reflect"
+	"slices"
+	"time"
+)
 
 type Field struct {
 	Key   string
 	Kind  reflect.Kind
 	Value any
 }
+
+// Kinds of the fields whose values have no reflect.Kind of their own, numbered
+// after the reflect kinds.
+const (
+	// Value is a time.Duration.
+	KindDuration reflect.Kind = reflect.UnsafePointer + 1 + iota
+	// Value is a time.Time.
+	KindTime
+	// Value is a fmt.Stringer, called when the entry is written.
+	KindStringer
+	// Value is a []byte, written in base64.
+	KindBinary
+	// Value is nil; the fields after it are nested under Key.
+	KindNamespace
+	// Value is the stack trace, a string.
+	KindStack
+	// Value is an ObjectMarshaler.
+	KindObject
+	// Value is an ArrayMarshaler.
+	KindArray
+	// Value is an error, logged with the fields returned by ErrorFields.
+	KindError
+)
+
+// ObjectMarshaler is implemented by types that log themselves as objects
+// without reflection.
+//
+// Example:
+//
+//	func (u User) MarshalLogObject(enc logx.ObjectEncoder) error {
+//		enc.AddString("name", u.Name)
+//		enc.AddInt("age", u.Age)
+//		return nil
+//	}
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ArrayMarshaler is implemented by types that log themselves as arrays
+// without reflection.
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ObjectEncoder adds the fields of an ObjectMarshaler. The zap, zerolog and
+// slog backends implement it on top of their own encoders.
+type ObjectEncoder interface {
+	AddString(key, value string)
+	AddBool(key string, value bool)
+	AddInt(key string, value int)
+	AddInt64(key string, value int64)
+	AddUint64(key string, value uint64)
+	AddFloat64(key string, value float64)
+	AddDuration(key string, value time.Duration)
+	AddTime(key string, value time.Time)
+	AddObject(key string, value ObjectMarshaler) error
+	AddArray(key string, value ArrayMarshaler) error
+}
+
+// ArrayEncoder appends the elements of an ArrayMarshaler. Arrays cannot be
+// nested directly, but may hold objects that hold arrays.
+type ArrayEncoder interface {
+	AppendString(value string)
+	AppendBool(value bool)
+	AppendInt(value int)
+	AppendInt64(value int64)
+	AppendUint64(value uint64)
+	AppendFloat64(value float64)
+	AppendDuration(value time.Duration)
+	AppendTime(value time.Time)
+	AppendObject(value ObjectMarshaler) error
+}
+
+// WithNamespace splits the fields kvs of a With call, already redacted, for
+// the backends that cannot leave a namespace open in a derived logger. open
+// is the namespace left open by the earlier With calls: its Namespace field
+// and the fields after it. fields are added to the derived logger as they
+// are; the returned open is kept by it and written, followed by the fields
+// of each entry, so that they all nest under the namespace as with zap.
+func WithNamespace(open, kvs []Field) (fields, newOpen []Field) {
+	if len(open) > 0 {
+		return nil, append(slices.Clip(open), kvs...)
+	}
+	for i, kv := range kvs {
+		if kv.Kind == KindNamespace {
+			return kvs[:i], slices.Clone(kvs[i:])
+		}
+	}
+	return kvs, nil
+}
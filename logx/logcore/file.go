@@ -0,0 +1,66 @@
+package logcore
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/rotate"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileName returns the path of the log file of level, or of the file of all
+// levels if level is empty.
+func (c *LoggerConf) FileName(level string) string {
+	if c.FilePattern != "" {
+		name := c.FilePattern
+		if level != "" {
+			ext := filepath.Ext(name)
+			if strings.Contains(ext, "%") {
+				ext = ""
+			}
+			name = strings.TrimSuffix(name, ext) + "-" + level + ext
+		}
+		return filepath.Join(c.Dir, name)
+	}
+	name := filepath.Join(c.Dir, c.Name)
+	if level != "" {
+		name += "-" + level
+	}
+	return name + c.Ext
+}
+
+// NewFileWriter returns the writer of the log file filename, rotated as
+// configured by c. Files only rotated by size (none of Rotate, FilePattern,
+// Compress, MaxTotalSize and OnRotate set) are written by lumberjack, other
+// files by rotate.Writer.
+func NewFileWriter(c *LoggerConf, filename string) (io.Writer, error) {
+	if c.Rotate == "" && c.FilePattern == "" && c.Compress == "" && c.MaxTotalSize <= 0 && c.OnRotate == nil {
+		return &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    c.MaxSize,
+			MaxBackups: c.MaxBackups,
+			MaxAge:     c.MaxAge,
+		}, nil
+	}
+
+	var schedule rotate.Schedule
+	if c.Rotate != "" {
+		var err error
+		if schedule, err = rotate.ParseSchedule(c.Rotate); err != nil {
+			return nil, err
+		}
+	}
+	return rotate.New(rotate.Config{
+		Filename:     filename,
+		Schedule:     schedule,
+		MaxSize:      c.MaxSize,
+		MaxBackups:   c.MaxBackups,
+		MaxAge:       time.Duration(c.MaxAge) * 24 * time.Hour,
+		MaxTotalSize: c.MaxTotalSize,
+		Compress:     c.Compress,
+		OnRotate:     c.OnRotate,
+		OnError:      c.OnRotateError,
+	})
+}
@@ -0,0 +1,25 @@
+package logcore
+
+// LevelLogger is implemented by loggers whose level can be changed at runtime.
+// The zap, zerolog and slog backends implement it.
+type LevelLogger interface {
+	Logger
+	// Named returns a child logger that adds the field "logger" with name
+	// (joined to the receiver's name with a dot) and has its own level,
+	// initially the receiver's current level.
+	Named(name string) LevelLogger
+	// Level returns the current minimum level, e.g. "info".
+	Level() string
+	// SetLevel changes the minimum level. Loggers derived with With or
+	// AddCallerSkip share the level; Named children do not.
+	SetLevel(level string) error
+}
+
+// ValidLevel reports whether level is one of debug, info, warn, error, panic or fatal.
+func ValidLevel(level string) bool {
+	switch level {
+	case "debug", "info", "warn", "error", "panic", "fatal":
+		return true
+	}
+	return false
+}
@@ -1,9 +1,24 @@
 package logcore
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/BYT0723/go-tools/logx/rotate"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func TestDefaultLoggerConf(t *testing.T) {
@@ -56,10 +71,104 @@ func TestLoggerConfMerge(t *testing.T) {
 			base := DefaultLoggerConf()
 			base.Multi = false
 			base.Console = true
-			other := &LoggerConf{Multi: true, Console: false}
+			other := &LoggerConf{Multi: true, Console: false, Dedup: true}
 			base.Merge(other)
 			assert.True(t, base.Multi)
 			assert.False(t, base.Console)
+			assert.True(t, base.Dedup)
+		})
+
+		t.Run("Merge 采样配置", func(t *testing.T) {
+			base := DefaultLoggerConf()
+			base.Merge(&LoggerConf{SampleFirst: 5, SampleThereafter: 10, SampleInterval: time.Minute})
+			assert.Equal(t, 5, base.SampleFirst)
+			assert.Equal(t, 10, base.SampleThereafter)
+			assert.Equal(t, time.Minute, base.SampleInterval)
+		})
+
+		t.Run("Merge 异步配置", func(t *testing.T) {
+			base := DefaultLoggerConf()
+			base.Merge(&LoggerConf{Async: true, AsyncBufferSize: 16, AsyncFlushInterval: time.Minute, AsyncOverflow: OverflowBlock})
+			assert.True(t, base.Async)
+			assert.Equal(t, 16, base.AsyncBufferSize)
+			assert.Equal(t, time.Minute, base.AsyncFlushInterval)
+			assert.Equal(t, OverflowBlock, base.AsyncOverflow)
+		})
+
+		t.Run("Merge sinks", func(t *testing.T) {
+			base := DefaultLoggerConf()
+			base.Merge(&LoggerConf{Sinks: []SinkConf{{Type: SinkTCP}}})
+			base.Merge(&LoggerConf{})
+			assert.Equal(t, []SinkConf{{Type: SinkTCP}}, base.Sinks)
+		})
+
+		t.Run("Merge 轮转配置", func(t *testing.T) {
+			var (
+				rotated string
+				rotErr  error
+			)
+			base := DefaultLoggerConf()
+			base.Merge(&LoggerConf{
+				Rotate:        "daily",
+				FilePattern:   "app-%Y%m%d.log",
+				Compress:      "zstd",
+				MaxTotalSize:  100,
+				OnRotate:      func(path string) { rotated = path },
+				OnRotateError: func(err error) { rotErr = err },
+			})
+			assert.Equal(t, "daily", base.Rotate)
+			assert.Equal(t, "app-%Y%m%d.log", base.FilePattern)
+			assert.Equal(t, "zstd", base.Compress)
+			assert.Equal(t, 100, base.MaxTotalSize)
+			base.OnRotate("app-20240102.log")
+			assert.Equal(t, "app-20240102.log", rotated)
+			base.OnRotateError(io.ErrClosedPipe)
+			assert.Equal(t, io.ErrClosedPipe, rotErr)
+		})
+
+		t.Run("Merge 堆栈级别", func(t *testing.T) {
+			base := DefaultLoggerConf()
+			base.Merge(&LoggerConf{StackLevel: "error"})
+			base.Merge(&LoggerConf{})
+			assert.Equal(t, "error", base.StackLevel)
+		})
+	})
+}
+
+func TestFileWriter(t *testing.T) {
+	t.Run("FileName/NewFileWriter 测试", func(t *testing.T) {
+		t.Run("FileName", func(t *testing.T) {
+			c := &LoggerConf{Dir: "logs", Name: "app", Ext: ".log"}
+			assert.Equal(t, filepath.Join("logs", "app.log"), c.FileName(""))
+			assert.Equal(t, filepath.Join("logs", "app-error.log"), c.FileName("error"))
+
+			c.FilePattern = "app-%Y%m%d.log"
+			assert.Equal(t, filepath.Join("logs", "app-%Y%m%d.log"), c.FileName(""))
+			assert.Equal(t, filepath.Join("logs", "app-%Y%m%d-error.log"), c.FileName("error"))
+
+			c.FilePattern = "app.%Y%m%d"
+			assert.Equal(t, filepath.Join("logs", "app.%Y%m%d-error"), c.FileName("error"))
+		})
+
+		t.Run("按配置选择 writer", func(t *testing.T) {
+			c := DefaultLoggerConf()
+			w, err := NewFileWriter(c, filepath.Join(t.TempDir(), "app.log"))
+			assert.Nil(t, err)
+			assert.IsType(t, &lumberjack.Logger{}, w)
+
+			c.Rotate = "hourly"
+			w, err = NewFileWriter(c, filepath.Join(t.TempDir(), "app.log"))
+			assert.Nil(t, err)
+			assert.IsType(t, &rotate.Writer{}, w)
+			assert.Nil(t, w.(io.Closer).Close())
+
+			c.Rotate = "every day"
+			_, err = NewFileWriter(c, filepath.Join(t.TempDir(), "app.log"))
+			assert.Error(t, err)
+
+			c.Rotate, c.Compress = "", "lz4"
+			_, err = NewFileWriter(c, filepath.Join(t.TempDir(), "app.log"))
+			assert.Error(t, err)
 		})
 	})
 }
@@ -71,3 +180,509 @@ func TestField(t *testing.T) {
 		assert.Equal(t, 42, f.Value)
 	})
 }
+
+func TestContextFields(t *testing.T) {
+	t.Run("ContextFields 测试", func(t *testing.T) {
+		type key struct{}
+		defer SetContextExtractors()
+
+		SetContextExtractors()
+		kvs := []Field{{Key: "k", Value: 1}}
+		assert.Equal(t, kvs, ContextFields(context.Background(), kvs...))
+
+		RegisterContextExtractor(func(ctx context.Context) []Field {
+			if v, ok := ctx.Value(key{}).(string); ok {
+				return []Field{{Key: "id", Value: v}}
+			}
+			return nil
+		})
+		RegisterContextExtractor(func(context.Context) []Field {
+			return []Field{{Key: "static", Value: true}}
+		})
+
+		ctx := context.WithValue(context.Background(), key{}, "abc")
+		fields := ContextFields(ctx, kvs...)
+		assert.Equal(t, []Field{
+			{Key: "id", Value: "abc"},
+			{Key: "static", Value: true},
+			{Key: "k", Value: 1},
+		}, fields)
+		assert.Len(t, ContextFields(context.Background()), 1)
+		var nilCtx context.Context
+		assert.Equal(t, kvs, ContextFields(nilCtx, kvs...))
+
+		SetContextExtractors()
+		assert.Empty(t, ContextFields(ctx))
+	})
+}
+
+func TestSampler(t *testing.T) {
+	t.Run("Sampler 测试", func(t *testing.T) {
+		t.Run("未启用时为 nil", func(t *testing.T) {
+			s := NewSampler(DefaultLoggerConf(), nil)
+			assert.Nil(t, s)
+			assert.True(t, s.Allow("info", "msg"))
+			assert.NotPanics(t, s.Flush)
+		})
+
+		t.Run("先 N 条后每 M 条", func(t *testing.T) {
+			now := time.Unix(0, 0)
+			s := NewSampler(&LoggerConf{SampleFirst: 2, SampleThereafter: 3, SampleInterval: time.Minute}, nil)
+			s.now = func() time.Time { return now }
+
+			var allowed []int
+			for i := 1; i <= 10; i++ {
+				if s.Allow("error", "boom") {
+					allowed = append(allowed, i)
+				}
+			}
+			assert.Equal(t, []int{1, 2, 5, 8}, allowed)
+			assert.True(t, s.Allow("warn", "boom"), "不同级别分别计数")
+			assert.True(t, s.Allow("error", "other"), "不同消息分别计数")
+
+			now = now.Add(time.Minute)
+			assert.True(t, s.Allow("error", "boom"), "新的时间窗口重新计数")
+		})
+
+		t.Run("去重并汇总", func(t *testing.T) {
+			type summary struct {
+				level, msg string
+				repeated   int
+			}
+			var (
+				mu   sync.Mutex
+				sums []summary
+			)
+			s := NewSampler(&LoggerConf{Dedup: true, SampleInterval: time.Hour}, func(level, msg string, repeated int) {
+				mu.Lock()
+				defer mu.Unlock()
+				sums = append(sums, summary{level, msg, repeated})
+			})
+
+			assert.True(t, s.Allow("error", "down"))
+			for range 4 {
+				assert.False(t, s.Allow("error", "down"))
+			}
+			assert.True(t, s.Allow("info", "up"))
+			s.Flush()
+			assert.Equal(t, []summary{{"error", "down", 4}}, sums)
+			s.Flush()
+			assert.Len(t, sums, 1, "汇总只输出一次")
+		})
+
+		t.Run("时间窗口结束时输出汇总", func(t *testing.T) {
+			var repeated atomic.Int64
+			s := NewSampler(&LoggerConf{Dedup: true, SampleInterval: 20 * time.Millisecond}, func(_, _ string, n int) {
+				repeated.Add(int64(n))
+			})
+			s.Allow("warn", "flap")
+			s.Allow("warn", "flap")
+			s.Allow("warn", "flap")
+			assert.Eventually(t, func() bool { return repeated.Load() == 2 }, time.Second, 5*time.Millisecond)
+		})
+
+		t.Run("同一桶中的消息分别计数", func(t *testing.T) {
+			type summary struct {
+				level, msg string
+				repeated   int
+			}
+			var sums []summary
+			s := NewSampler(&LoggerConf{Dedup: true, SampleInterval: time.Hour}, func(level, msg string, repeated int) {
+				sums = append(sums, summary{level, msg, repeated})
+			})
+
+			// Find a message sharing the bucket of "a".
+			other := ""
+			for i := 0; other == ""; i++ {
+				if m := strconv.Itoa(i); s.bucket("error", m) == s.bucket("error", "a") {
+					other = m
+				}
+			}
+
+			for range 3 {
+				s.Allow("error", "a")
+				s.Allow("error", other)
+			}
+			s.Flush()
+			assert.Equal(t, []summary{{"error", "a", 2}, {"error", other, 2}}, sums)
+			assert.Len(t, s.buckets[s.bucket("error", "a")].entries, 2)
+		})
+
+		assert.Equal(t, "down (repeated 4 times)", SummaryMessage("down", 4))
+	})
+}
+
+func TestRedactor(t *testing.T) {
+	t.Run("Redactor 测试", func(t *testing.T) {
+		r := DefaultRedactor(RedactKeys(MaskHash, "user_id"))
+
+		t.Run("按字段名脱敏", func(t *testing.T) {
+			fields := r.Fields([]Field{
+				{Key: "Password", Kind: reflect.String, Value: "hunter2"},
+				{Key: "req.authorization", Value: "Bearer abc"},
+				{Key: "user_id", Kind: reflect.Int, Value: 42},
+				{Key: "name", Kind: reflect.String, Value: "bob"},
+			})
+			assert.Equal(t, "[REDACTED]", fields[0].Value)
+			assert.Equal(t, "[REDACTED]", fields[1].Value)
+			assert.Equal(t, reflect.String, fields[1].Kind)
+			assert.Equal(t, MaskHash("42"), fields[2].Value)
+			assert.Equal(t, "bob", fields[3].Value)
+		})
+
+		t.Run("按值的正则脱敏", func(t *testing.T) {
+			jwt := "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig-_x"
+			for in, want := range map[string]string{
+				"mail john.doe@example.com now": "mail john************.com now",
+				"card 4111 1111 1111 1111":      "card 4111***********1111",
+				"order 1234567890123":           "order 1234567890123", // 不满足 Luhn 校验
+				"token " + jwt:                  "token [REDACTED]",
+			} {
+				assert.Equal(t, want, r.String(in))
+				assert.Equal(t, want, r.Fields([]Field{{Key: "msg", Kind: reflect.String, Value: in}})[0].Value)
+			}
+		})
+
+		t.Run("未变化时不复制", func(t *testing.T) {
+			kvs := []Field{{Key: "n", Kind: reflect.Int, Value: 1}, {Key: "s", Value: "plain"}}
+			assert.Same(t, &kvs[0], &r.Fields(kvs)[0])
+
+			in := []Field{{Key: "password", Value: "x"}}
+			out := r.Fields(in)
+			assert.Equal(t, "x", in[0].Value, "输入不被修改")
+			assert.Equal(t, "[REDACTED]", out[0].Value)
+		})
+
+		t.Run("Mask", func(t *testing.T) {
+			assert.Equal(t, "***", MaskPartial("abc"))
+			assert.Equal(t, "ab****gh", MaskPartial("abcdefgh"))
+			assert.Equal(t, "密**2", MaskPartial("密码12"))
+			assert.Equal(t, MaskHash("a"), MaskHash("a"))
+			assert.NotEqual(t, MaskHash("a"), MaskHash("b"))
+			assert.Len(t, MaskHash("a"), len("sha256:")+16)
+		})
+
+		t.Run("错误链中的敏感信息", func(t *testing.T) {
+			err := fmt.Errorf("notify: %w", errors.New("send to john.doe@example.com"))
+			out := r.Fields([]Field{{Key: "error", Kind: KindError, Value: err}})
+			assert.Equal(t, Field{Key: "error", Kind: reflect.String, Value: "notify: send to john************.com"}, out[0])
+
+			plain := []Field{{Key: "error", Kind: KindError, Value: errors.New("timeout")}, {Key: "password", Kind: KindError, Value: errors.New("hunter2")}}
+			out = r.Fields(plain)
+			assert.Equal(t, plain[0], out[0])
+			assert.Equal(t, "[REDACTED]", out[1].Value)
+		})
+
+		t.Run("SetRedactor", func(t *testing.T) {
+			kvs := []Field{{Key: "password", Value: "x"}}
+			assert.Equal(t, kvs, Redact(kvs))
+			SetRedactor(r)
+			defer SetRedactor(nil)
+			assert.Equal(t, "[REDACTED]", Redact(kvs)[0].Value)
+		})
+	})
+}
+
+// codedError is an error with a code, recording the stack of its creation.
+type codedError struct {
+	code string
+	pcs  []uintptr
+}
+
+func (e *codedError) Error() string { return "not found" }
+
+func (e *codedError) Callers() []uintptr { return e.pcs }
+
+func (e *codedError) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("code", e.code)
+	return nil
+}
+
+// frame is a program counter, as github.com/pkg/errors.Frame.
+type frame uintptr
+
+// tracedError has a StackTrace method, as the errors of github.com/pkg/errors.
+type tracedError struct {
+	pcs []frame
+}
+
+func (e tracedError) Error() string { return "traced" }
+
+func (e tracedError) StackTrace() []frame { return e.pcs }
+
+// mapEncoder is an ObjectEncoder and ArrayEncoder recording the values.
+type mapEncoder struct {
+	m   map[string]any
+	arr []any
+}
+
+func (e *mapEncoder) AddString(key, value string)                 { e.m[key] = value }
+func (e *mapEncoder) AddBool(key string, value bool)              { e.m[key] = value }
+func (e *mapEncoder) AddInt(key string, value int)                { e.m[key] = value }
+func (e *mapEncoder) AddInt64(key string, value int64)            { e.m[key] = value }
+func (e *mapEncoder) AddUint64(key string, value uint64)          { e.m[key] = value }
+func (e *mapEncoder) AddFloat64(key string, value float64)        { e.m[key] = value }
+func (e *mapEncoder) AddDuration(key string, value time.Duration) { e.m[key] = value }
+func (e *mapEncoder) AddTime(key string, value time.Time)         { e.m[key] = value }
+func (e *mapEncoder) AddObject(key string, value ObjectMarshaler) error {
+	return errors.New("unsupported")
+}
+func (e *mapEncoder) AddArray(key string, value ArrayMarshaler) error {
+	return errors.New("unsupported")
+}
+func (e *mapEncoder) AppendString(value string)          { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendBool(value bool)              { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendInt(value int)                { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendInt64(value int64)            { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendUint64(value uint64)          { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendFloat64(value float64)        { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendDuration(value time.Duration) { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendTime(value time.Time)         { e.arr = append(e.arr, value) }
+func (e *mapEncoder) AppendObject(value ObjectMarshaler) error {
+	obj := &mapEncoder{m: map[string]any{}}
+	err := value.MarshalLogObject(obj)
+	e.arr = append(e.arr, obj.m)
+	return err
+}
+
+// chainOf returns the objects logged for the chain in fields[1].
+func chainOf(t *testing.T, fields []Field) []any {
+	assert.Equal(t, KindArray, fields[1].Kind)
+	enc := &mapEncoder{}
+	assert.Nil(t, fields[1].Value.(ArrayMarshaler).MarshalLogArray(enc))
+	return enc.arr
+}
+
+func TestErrorFields(t *testing.T) {
+	t.Run("ErrorFields 测试", func(t *testing.T) {
+		here := func() []uintptr {
+			pcs := make([]uintptr, 32)
+			return pcs[:runtime.Callers(1, pcs)]
+		}
+
+		t.Run("普通错误只记录消息", func(t *testing.T) {
+			assert.Equal(t, []Field{{Key: "error", Kind: reflect.String, Value: "boom"}}, ErrorFields("error", errors.New("boom")))
+		})
+
+		t.Run("包装与合并的错误链", func(t *testing.T) {
+			coded := &codedError{code: "E404", pcs: here()}
+			err := fmt.Errorf("load: %w", errors.Join(errors.New("a"), coded))
+			fields := ErrorFields("err", err)
+			assert.Len(t, fields, 3)
+			assert.Equal(t, Field{Key: "err", Kind: reflect.String, Value: "load: a\nnot found"}, fields[0])
+			assert.Equal(t, []any{
+				map[string]any{"msg": "load: a\nnot found", "type": "*fmt.wrapError"},
+				map[string]any{"msg": "a\nnot found", "type": "*errors.joinError"},
+				map[string]any{"msg": "a", "type": "*errors.errorString"},
+				map[string]any{"msg": "not found", "type": "*logcore.codedError", "code": "E404"},
+			}, chainOf(t, fields))
+			assert.Equal(t, Field{Key: "errStack", Kind: KindStack, Value: FormatStack(coded.pcs)}, fields[2])
+		})
+
+		t.Run("单个 ObjectMarshaler 错误", func(t *testing.T) {
+			fields := ErrorFields("error", &codedError{code: "E1"})
+			assert.Len(t, fields, 2)
+			assert.Equal(t, []any{map[string]any{"msg": "not found", "type": "*logcore.codedError", "code": "E1"}}, chainOf(t, fields))
+		})
+
+		t.Run("StackTrace 方法的堆栈", func(t *testing.T) {
+			var pcs []frame
+			for _, pc := range here() {
+				pcs = append(pcs, frame(pc))
+			}
+			fields := ErrorFields("error", tracedError{pcs: pcs})
+			assert.Len(t, fields, 2)
+			assert.Equal(t, "errorStack", fields[1].Key)
+			assert.True(t, strings.HasPrefix(fields[1].Value.(string), "github.com/BYT0723/go-tools/logx/logcore.TestErrorFields.func1."))
+		})
+
+		t.Run("Stacktrace 从调用者开始", func(t *testing.T) {
+			stack := Stacktrace(0)
+			assert.True(t, strings.HasPrefix(stack, "github.com/BYT0723/go-tools/logx/logcore.TestErrorFields.func1."), stack)
+			assert.Contains(t, stack, "logcore_test.go:")
+		})
+	})
+}
+
+// gateWriter blocks writes until its gate is opened.
+type gateWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	err  error
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	t.Run("AsyncWriter 测试", func(t *testing.T) {
+		newWriter := func(t *testing.T, w *gateWriter, size int, policy OverflowPolicy) *AsyncWriter {
+			aw, err := NewAsyncWriter(w, &LoggerConf{AsyncBufferSize: size, AsyncFlushInterval: time.Hour, AsyncOverflow: policy})
+			assert.Nil(t, err)
+			t.Cleanup(func() { _ = aw.Close() })
+			return aw
+		}
+		lines := func(from, to int) string {
+			var b strings.Builder
+			for i := from; i < to; i++ {
+				fmt.Fprintf(&b, "line %d\n", i)
+			}
+			return b.String()
+		}
+		write := func(aw *AsyncWriter, from, to int) {
+			for i := from; i < to; i++ {
+				_, _ = fmt.Fprintf(aw, "line %d\n", i)
+			}
+		}
+
+		t.Run("Sync 按顺序写出", func(t *testing.T) {
+			w := newGateWriter()
+			close(w.gate)
+			aw := newWriter(t, w, 8, OverflowBlock)
+			write(aw, 0, 100)
+			assert.Nil(t, aw.Sync())
+			assert.Equal(t, lines(0, 100), w.String())
+			assert.Zero(t, aw.Dropped())
+		})
+
+		t.Run("drop 策略", func(t *testing.T) {
+			w := newGateWriter()
+			aw := newWriter(t, w, 4, OverflowDrop)
+			write(aw, 0, 10)
+			assert.Equal(t, uint64(6), aw.Dropped())
+			close(w.gate)
+			assert.Nil(t, aw.Sync())
+			assert.Equal(t, lines(0, 4), w.String())
+		})
+
+		t.Run("sample 策略", func(t *testing.T) {
+			w := newGateWriter()
+			aw := newWriter(t, w, 4, OverflowSample)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				// line 13 is the 10th line that overflows
+				write(aw, 0, 14)
+			}()
+			assert.Eventually(t, func() bool { return aw.Dropped() == 9 }, time.Second, time.Millisecond)
+			close(w.gate)
+			<-done
+			assert.Nil(t, aw.Sync())
+			assert.Equal(t, uint64(9), aw.Dropped())
+			assert.Equal(t, lines(0, 4)+"line 13\n", w.String())
+		})
+
+		t.Run("block 策略", func(t *testing.T) {
+			w := newGateWriter()
+			aw := newWriter(t, w, 2, OverflowBlock)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				write(aw, 0, 5)
+			}()
+			assert.Never(t, func() bool {
+				select {
+				case <-done:
+					return true
+				default:
+					return false
+				}
+			}, 50*time.Millisecond, time.Millisecond)
+			close(w.gate)
+			<-done
+			assert.Nil(t, aw.Sync())
+			assert.Equal(t, lines(0, 5), w.String())
+			assert.Zero(t, aw.Dropped())
+		})
+
+		t.Run("Close 后同步写入", func(t *testing.T) {
+			w := newGateWriter()
+			close(w.gate)
+			aw := newWriter(t, w, 8, OverflowDrop)
+			write(aw, 0, 3)
+			assert.Nil(t, aw.Close())
+			assert.Equal(t, lines(0, 3), w.String())
+			write(aw, 3, 4)
+			assert.Equal(t, lines(0, 4), w.String())
+			assert.Nil(t, aw.Close())
+		})
+
+		t.Run("Sync 返回写入错误", func(t *testing.T) {
+			w := newGateWriter()
+			close(w.gate)
+			w.err = errors.New("disk full")
+			aw := newWriter(t, w, 8, OverflowDrop)
+			write(aw, 0, 1)
+			assert.EqualError(t, aw.Sync(), "disk full")
+			assert.Nil(t, aw.Sync())
+		})
+
+		t.Run("未知策略", func(t *testing.T) {
+			_, err := NewAsyncWriter(newGateWriter(), &LoggerConf{AsyncOverflow: "spill"})
+			assert.Error(t, err)
+		})
+
+		t.Run("AsyncWriters", func(t *testing.T) {
+			var ws AsyncWriters
+			w, err := ws.Wrap(newGateWriter(), &LoggerConf{})
+			assert.Nil(t, err)
+			assert.IsType(t, &gateWriter{}, w)
+			w, err = ws.Wrap(newGateWriter(), &LoggerConf{Async: true})
+			assert.Nil(t, err)
+			assert.IsType(t, &AsyncWriter{}, w)
+			assert.Len(t, ws, 1)
+			assert.Nil(t, ws.Close())
+		})
+	})
+}
+
+// slowWriter simulates a slow disk.
+type slowWriter struct{}
+
+func (slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(10 * time.Microsecond)
+	return len(p), nil
+}
+
+func BenchmarkAsyncWriter(b *testing.B) {
+	line := []byte(`{"level":"info","msg":"benchmark","index":1}` + "\n")
+
+	b.Run("sync", func(b *testing.B) {
+		var w slowWriter
+		for b.Loop() {
+			_, _ = w.Write(line)
+		}
+	})
+
+	for _, policy := range []OverflowPolicy{OverflowDrop, OverflowBlock} {
+		b.Run("async-"+string(policy), func(b *testing.B) {
+			aw, err := NewAsyncWriter(slowWriter{}, &LoggerConf{AsyncOverflow: policy})
+			if err != nil {
+				b.Fatal(err)
+			}
+			for b.Loop() {
+				_, _ = aw.Write(line)
+			}
+			_ = aw.Close()
+			b.ReportMetric(float64(aw.Dropped())/float64(b.N), "dropped/op")
+		})
+	}
+}
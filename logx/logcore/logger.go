@@ -1,5 +1,7 @@
 package logcore
 
+import "context"
+
 type Logger interface {
 	With(kvs ...Field) Logger
 	Debug(msg string, kvs ...Field)
@@ -16,6 +18,15 @@ type Logger interface {
 	Fatalf(format string, args ...any)
 	Log(level string, msg string, kvs ...Field)
 	Logf(level string, format string, args ...any)
+	// The *Ctx methods prepend the fields returned by the registered
+	// ContextExtractors for ctx, see ContextFields.
+	DebugCtx(ctx context.Context, msg string, kvs ...Field)
+	InfoCtx(ctx context.Context, msg string, kvs ...Field)
+	WarnCtx(ctx context.Context, msg string, kvs ...Field)
+	ErrorCtx(ctx context.Context, msg string, kvs ...Field)
+	PanicCtx(ctx context.Context, msg string, kvs ...Field)
+	FatalCtx(ctx context.Context, msg string, kvs ...Field)
+	LogCtx(ctx context.Context, level string, msg string, kvs ...Field)
 	Sync() error
 	AddCallerSkip(caller int) Logger
 }
@@ -0,0 +1,248 @@
+package logcore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+type (
+	// Mask replaces a sensitive value.
+	Mask func(value string) string
+
+	// RedactRule configures a Redactor.
+	RedactRule func(*Redactor)
+
+	// Redactor masks sensitive fields: fields whose key matches a key rule are
+	// masked as a whole, and in string values the matches of value rules are
+	// masked. Only the top-level value of a field is inspected; structs and
+	// maps logged with Any are not.
+	//
+	// A Redactor is immutable once created and safe for concurrent use.
+	Redactor struct {
+		keys   map[string]Mask
+		values []valueRule
+	}
+
+	valueRule struct {
+		re    *regexp.Regexp
+		mask  Mask
+		valid func(match string) bool
+	}
+)
+
+// Masking strategies.
+var (
+	// MaskFull replaces the value with "[REDACTED]", hiding its length too.
+	MaskFull Mask = func(string) string { return "[REDACTED]" }
+	// MaskPartial keeps up to 4 characters at each end of the value (a quarter
+	// of its length each) and replaces the others with '*'.
+	MaskPartial Mask = maskPartial
+	// MaskHash replaces the value with "sha256:" and the first 16 hex digits
+	// of its SHA-256, so equal values can still be correlated. Low-entropy
+	// values such as passwords can be recovered from an unsalted hash by
+	// guessing; use MaskFull for them.
+	MaskHash Mask = maskHash
+)
+
+// DefaultRedactKeys are the keys masked by DefaultRedactor.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "pwd", "secret", "token", "access_token",
+	"refresh_token", "id_token", "api_key", "apikey", "x-api-key",
+	"authorization", "proxy-authorization", "cookie", "set-cookie",
+	"private_key", "client_secret",
+}
+
+var (
+	emailRegexp = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Card numbers: 13 to 19 digits, optionally grouped by spaces or dashes.
+	panRegexp = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	jwtRegexp = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// NewRedactor creates a Redactor with rules. Later rules for the same key win.
+//
+// Example:
+//
+//	r := NewRedactor(
+//		RedactKeys(MaskFull, "password", "authorization"),
+//		RedactPattern(regexp.MustCompile(`\d{3}-\d{2}-\d{4}`), MaskPartial),
+//	)
+func NewRedactor(rules ...RedactRule) *Redactor {
+	r := &Redactor{keys: make(map[string]Mask)}
+	for _, rule := range rules {
+		rule(r)
+	}
+	return r
+}
+
+// DefaultRedactor masks DefaultRedactKeys fully, e-mail addresses and card
+// numbers (Luhn-valid) partially, and JWTs fully. Extra rules are added after
+// the defaults.
+func DefaultRedactor(rules ...RedactRule) *Redactor {
+	return NewRedactor(append([]RedactRule{
+		RedactKeys(MaskFull, DefaultRedactKeys...),
+		RedactPattern(emailRegexp, MaskPartial),
+		redactValues(panRegexp, MaskPartial, luhnValid),
+		RedactPattern(jwtRegexp, MaskFull),
+	}, rules...)...)
+}
+
+// RedactKeys masks the fields called one of keys with mask. Keys match case
+// insensitively, against the whole key or its last dot-separated part, so
+// "password" also matches "req.Password".
+func RedactKeys(mask Mask, keys ...string) RedactRule {
+	return func(r *Redactor) {
+		for _, k := range keys {
+			r.keys[strings.ToLower(k)] = mask
+		}
+	}
+}
+
+// RedactPattern masks the matches of re in string values with mask.
+func RedactPattern(re *regexp.Regexp, mask Mask) RedactRule {
+	return redactValues(re, mask, nil)
+}
+
+func redactValues(re *regexp.Regexp, mask Mask, valid func(string) bool) RedactRule {
+	return func(r *Redactor) {
+		r.values = append(r.values, valueRule{re: re, mask: mask, valid: valid})
+	}
+}
+
+// Fields returns kvs with sensitive values masked. kvs is returned as is if
+// nothing is masked, otherwise it is not modified.
+func (r *Redactor) Fields(kvs []Field) []Field {
+	if r == nil {
+		return kvs
+	}
+	var res []Field
+	for i, kv := range kvs {
+		masked, ok := r.field(kv)
+		if !ok {
+			continue
+		}
+		if res == nil {
+			res = append([]Field(nil), kvs...)
+		}
+		res[i] = masked
+	}
+	if res == nil {
+		return kvs
+	}
+	return res
+}
+
+// String returns s with the matches of the value rules masked.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, rule := range r.values {
+		s = rule.re.ReplaceAllStringFunc(s, func(m string) string {
+			if rule.valid != nil && !rule.valid(m) {
+				return m
+			}
+			return rule.mask(m)
+		})
+	}
+	return s
+}
+
+func (r *Redactor) field(kv Field) (Field, bool) {
+	if kv.Kind == KindNamespace {
+		return kv, false
+	}
+	key := strings.ToLower(kv.Key)
+	mask, ok := r.keys[key]
+	if !ok {
+		if i := strings.LastIndexByte(key, '.'); i >= 0 {
+			mask, ok = r.keys[key[i+1:]]
+		}
+	}
+	if ok {
+		return Field{Key: kv.Key, Kind: reflect.String, Value: mask(fmt.Sprint(kv.Value))}, true
+	}
+
+	if kv.Kind == KindError && len(r.values) > 0 {
+		// The messages of the chain are logged too: if one needs masking,
+		// only the masked message is logged.
+		err := kv.Value.(error)
+		for _, e := range newErrorChain(err) {
+			if msg := errorMessage(e); r.String(msg) != msg {
+				return Field{Key: kv.Key, Kind: reflect.String, Value: r.String(errorMessage(err))}, true
+			}
+		}
+		return kv, false
+	}
+
+	s, isString := kv.Value.(string)
+	if !isString || len(r.values) == 0 {
+		return kv, false
+	}
+	if masked := r.String(s); masked != s {
+		return Field{Key: kv.Key, Kind: reflect.String, Value: masked}, true
+	}
+	return kv, false
+}
+
+var redactor atomic.Pointer[Redactor]
+
+// SetRedactor sets the Redactor applied by the backends to all fields; nil disables redaction.
+func SetRedactor(r *Redactor) {
+	redactor.Store(r)
+}
+
+// Redact applies the Redactor set with SetRedactor to kvs.
+func Redact(kvs []Field) []Field {
+	if len(kvs) == 0 {
+		return kvs
+	}
+	return redactor.Load().Fields(kvs)
+}
+
+func maskPartial(s string) string {
+	n := utf8.RuneCountInString(s)
+	keep := min(n/4, 4)
+	var b strings.Builder
+	i := 0
+	for _, c := range s {
+		if i < keep || i >= n-keep {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('*')
+		}
+		i++
+	}
+	return b.String()
+}
+
+func maskHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// luhnValid reports whether the digits of s pass the Luhn check used by card numbers.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
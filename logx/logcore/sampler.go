@@ -0,0 +1,164 @@
+package logcore
+
+import (
+	"fmt"
+	"hash/maphash"
+	"slices"
+	"sync"
+	"time"
+)
+
+// samplerBuckets is the number of buckets of a Sampler. Each bucket keeps the
+// counters of the keys hashed to it, so keys that share a bucket are still
+// sampled and summarised on their own.
+const samplerBuckets = 4096
+
+// DefaultSampleInterval is used when LoggerConf.SampleInterval is not set.
+const DefaultSampleInterval = time.Second
+
+// SummaryFunc writes the summary of the entries a Sampler dropped.
+type SummaryFunc func(level, msg string, repeated int)
+
+// Sampler limits how often entries with the same level and message are
+// written, as configured by the Sample* and Dedup fields of LoggerConf.
+// It is safe for concurrent use and shared by all loggers of a backend instance.
+type Sampler struct {
+	first      int
+	thereafter int
+	interval   time.Duration
+	dedup      bool
+	summary    SummaryFunc
+	now        func() time.Time
+	seed       maphash.Seed
+	buckets    [samplerBuckets]samplerBucket
+}
+
+type samplerBucket struct {
+	mu      sync.Mutex
+	entries []*samplerEntry // keys in their window, usually at most one
+}
+
+// samplerEntry counts the entries of one level and message in a window.
+type samplerEntry struct {
+	level   string
+	msg     string
+	start   time.Time
+	count   int // entries seen in the window
+	dropped int // entries dropped in the window
+	timer   *time.Timer
+}
+
+// NewSampler returns the Sampler configured by cfg, or nil if cfg enables
+// neither sampling nor deduplication. If cfg.Dedup is set, summary is called
+// with the number of dropped entries at the end of each interval in which
+// entries were dropped, and by Flush.
+func NewSampler(cfg *LoggerConf, summary SummaryFunc) *Sampler {
+	if cfg.SampleFirst <= 0 && !cfg.Dedup {
+		return nil
+	}
+	s := &Sampler{
+		first:      cfg.SampleFirst,
+		thereafter: cfg.SampleThereafter,
+		interval:   cfg.SampleInterval,
+		dedup:      cfg.Dedup,
+		summary:    summary,
+		now:        time.Now,
+		seed:       maphash.MakeSeed(),
+	}
+	if s.interval <= 0 {
+		s.interval = DefaultSampleInterval
+	}
+	if s.first <= 0 {
+		// Dedup only: log each message once per interval.
+		s.first, s.thereafter = 1, 0
+	}
+	return s
+}
+
+// Allow reports whether an entry with level and msg should be written.
+// A nil Sampler allows everything.
+func (s *Sampler) Allow(level, msg string) bool {
+	if s == nil {
+		return true
+	}
+	b := &s.buckets[s.bucket(level, msg)]
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := s.now()
+	// Drop the keys whose window ended, so messages that are not logged
+	// again do not pile up.
+	var e *samplerEntry
+	b.entries = slices.DeleteFunc(b.entries, func(x *samplerEntry) bool {
+		if now.Sub(x.start) >= s.interval {
+			s.flushLocked(x)
+			return true
+		}
+		if x.level == level && x.msg == msg {
+			e = x
+		}
+		return false
+	})
+	if e == nil {
+		e = &samplerEntry{level: level, msg: msg, start: now}
+		b.entries = append(b.entries, e)
+	}
+
+	e.count++
+	if e.count <= s.first || (s.thereafter > 0 && (e.count-s.first)%s.thereafter == 0) {
+		return true
+	}
+	e.dropped++
+	if s.dedup && e.timer == nil {
+		e.timer = time.AfterFunc(s.interval-now.Sub(e.start), func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if i := slices.Index(b.entries, e); i >= 0 {
+				s.flushLocked(e)
+				b.entries = slices.Delete(b.entries, i, i+1)
+			}
+		})
+	}
+	return false
+}
+
+// bucket returns the index of the bucket of level and msg.
+func (s *Sampler) bucket(level, msg string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+	h.WriteString(level)
+	h.WriteByte(0)
+	h.WriteString(msg)
+	return h.Sum64() % samplerBuckets
+}
+
+// Flush writes the summaries of all pending dropped entries.
+func (s *Sampler) Flush() {
+	if s == nil {
+		return
+	}
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		b.mu.Lock()
+		for _, e := range b.entries {
+			s.flushLocked(e)
+		}
+		b.mu.Unlock()
+	}
+}
+
+func (s *Sampler) flushLocked(e *samplerEntry) {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if s.dedup && e.dropped > 0 && s.summary != nil {
+		s.summary(e.level, e.msg, e.dropped)
+	}
+	e.dropped = 0
+}
+
+// SummaryMessage returns the message of a summary entry, "msg (repeated N times)".
+func SummaryMessage(msg string, repeated int) string {
+	return fmt.Sprintf("%s (repeated %d times)", msg, repeated)
+}
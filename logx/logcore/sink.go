@@ -0,0 +1,396 @@
+package logcore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Sink receives the entries of a logger, encoded as JSON lines, in addition
+// to the local files and the console.
+//
+// A Sink must be safe for concurrent use. WriteEntry must not retain line.
+type Sink interface {
+	// WriteEntry writes one entry logged at level ("debug", "info", "warn",
+	// "error", "dpanic", "panic" or "fatal"). line ends with a newline.
+	WriteEntry(level string, line []byte) error
+	// Sync writes the buffered entries, if any.
+	Sync() error
+	// Close flushes the sink and releases its connections. A closed sink
+	// may still be written to; it reconnects or writes unbuffered.
+	Close() error
+}
+
+// Sink types.
+const (
+	SinkSyslog        = "syslog"
+	SinkJournald      = "journald"
+	SinkLoki          = "loki"
+	SinkElasticsearch = "elasticsearch"
+	SinkTCP           = "tcp"
+)
+
+// SinkConf configures a Sink of LoggerConf.Sinks.
+type SinkConf struct {
+	// One of the Sink* types, ignored if Sink is set.
+	Type string
+	// A custom sink, used instead of Type.
+	Sink Sink
+	// The lowest level written to the sink.
+	// default: debug
+	Level string
+	// syslog: "udp", "tcp" or "unix".
+	// default: udp
+	Network string
+	// syslog: host:port, or the socket path with "unix" (default /dev/log).
+	// journald: the socket path (default /run/systemd/journal/socket).
+	// loki: the push URL, e.g. http://loki:3100/loki/api/v1/push.
+	// elasticsearch: the bulk URL, e.g. http://es:9200/_bulk.
+	// tcp: host:port.
+	Addr string
+	// syslog APP-NAME and journald SYSLOG_IDENTIFIER.
+	// default: the program name
+	Tag string
+	// syslog facility, 0 to 23.
+	// default: 1 (user)
+	Facility int
+	// loki: labels of the streams, in addition to "level".
+	Labels map[string]string
+	// elasticsearch: the index the entries are written to.
+	// default: logs
+	Index string
+	// loki, elasticsearch: headers of the requests, e.g. Authorization.
+	Headers map[string]string
+	// loki, elasticsearch: entries sent per request.
+	// default: 100
+	BatchSize int
+	// loki, elasticsearch: how long entries are buffered at most.
+	// default: 1s
+	FlushInterval time.Duration
+	// Dial and request timeout.
+	// default: 5s
+	Timeout time.Duration
+}
+
+const (
+	defaultSinkTimeout   = 5 * time.Second
+	defaultSinkBatchSize = 100
+	// redialInterval is how long a network sink waits before dialing again
+	// after a failed dial, dropping the entries in between.
+	redialInterval = time.Second
+)
+
+// NewSink creates the sink configured by sc. Network sinks connect on the
+// first write, so that a logger can be created while the collector is down.
+func NewSink(sc *SinkConf) (Sink, error) {
+	if sc.Sink != nil {
+		return sc.Sink, nil
+	}
+	switch sc.Type {
+	case SinkSyslog:
+		return newSyslogSink(sc)
+	case SinkJournald:
+		return newJournaldSink(sc), nil
+	case SinkLoki:
+		return newHTTPSink(sc, lokiEncoder(sc.Labels))
+	case SinkElasticsearch:
+		return newHTTPSink(sc, elasticsearchEncoder(sc.Index))
+	case SinkTCP:
+		if sc.Addr == "" {
+			return nil, errors.New("logcore: tcp sink needs an address")
+		}
+		return &tcpSink{conn: newRedialConn("tcp", sc.Addr, sc.Timeout)}, nil
+	default:
+		return nil, fmt.Errorf("logcore: unknown sink type %q", sc.Type)
+	}
+}
+
+// Sinks are the sinks of a backend instance.
+type Sinks []Sink
+
+// Open creates the sinks of cfg, appending them to ss.
+func (ss *Sinks) Open(cfg *LoggerConf) error {
+	for i := range cfg.Sinks {
+		sc := &cfg.Sinks[i]
+		if sc.Level != "" && !ValidLevel(sc.Level) {
+			return fmt.Errorf("logcore: unknown sink level %q", sc.Level)
+		}
+		s, err := NewSink(sc)
+		if err != nil {
+			return err
+		}
+		*ss = append(*ss, s)
+	}
+	return nil
+}
+
+// Sync syncs all sinks and returns the first error.
+func (ss Sinks) Sync() error {
+	var err error
+	for _, s := range ss {
+		if serr := s.Sync(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// Close closes all sinks and returns the first error.
+func (ss Sinks) Close() error {
+	var err error
+	for _, s := range ss {
+		if cerr := s.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// SinkWriter returns a writer passing each line written to it to sink as
+// logged at level. Its Sync method syncs sink.
+func SinkWriter(sink Sink, level string) io.Writer {
+	return &sinkWriter{sink: sink, level: level}
+}
+
+type sinkWriter struct {
+	sink  Sink
+	level string
+}
+
+func (w *sinkWriter) Write(p []byte) (int, error) {
+	if err := w.sink.WriteEntry(w.level, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *sinkWriter) Sync() error {
+	return w.sink.Sync()
+}
+
+// netMaxPending is the number of messages a network sink buffers while its
+// peer is slow or down; messages beyond it are dropped.
+const netMaxPending = 1024
+
+// redialConn is a connection that is dialed on first use and redialed after
+// a write fails. Messages are buffered and written from a background
+// goroutine, so that a slow or unreachable peer does not block the logger.
+// After close, each message is written on its own.
+type redialConn struct {
+	network string
+	addr    string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending []func(net.Conn) []byte
+	closed  bool
+	err     error // first write error since the last sync
+
+	sendMu  sync.Mutex // guards conn and retryAt, keeps messages in order
+	conn    net.Conn
+	retryAt time.Time
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newRedialConn(network, addr string, timeout time.Duration) *redialConn {
+	if timeout <= 0 {
+		timeout = defaultSinkTimeout
+	}
+	c := &redialConn{
+		network: network,
+		addr:    addr,
+		timeout: timeout,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// write buffers the message built by msg. msg is called when the message is
+// sent, and again after a redial, since the framing may depend on the
+// connection; it must not refer to memory the caller reuses.
+func (c *redialConn) write(msg func(net.Conn) []byte) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		c.sendMu.Lock()
+		defer c.sendMu.Unlock()
+		return c.send(msg)
+	}
+	defer c.mu.Unlock()
+	if len(c.pending) >= netMaxPending {
+		return errSinkFull
+	}
+	c.pending = append(c.pending, msg)
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// sync sends the buffered messages and returns the first write error since
+// the last sync.
+func (c *redialConn) sync() error {
+	c.flush()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.err
+	c.err = nil
+	return err
+}
+
+// close sends the buffered messages, stops the background goroutine and
+// closes the connection.
+func (c *redialConn) close() error {
+	c.mu.Lock()
+	closed := c.closed
+	c.closed = true
+	c.mu.Unlock()
+	if !closed {
+		close(c.stop)
+		<-c.done
+	}
+
+	err := c.sync()
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.conn != nil {
+		if cerr := c.conn.Close(); err == nil {
+			err = cerr
+		}
+		c.conn = nil
+	}
+	return err
+}
+
+func (c *redialConn) run() {
+	defer close(c.done)
+	for {
+		select {
+		case <-c.wake:
+		case <-c.stop:
+			c.flush()
+			return
+		}
+		c.flush()
+	}
+}
+
+// flush sends the buffered messages.
+func (c *redialConn) flush() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, msg := range pending {
+		if err := c.send(msg); err != nil {
+			c.mu.Lock()
+			if c.err == nil {
+				c.err = err
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// send writes the message built by msg, dialing first if needed; the caller
+// holds sendMu. If none of the message was written, e.g. because the peer
+// closed the connection, it is sent again once after a redial. A message cut
+// off by a failed write is dropped with the connection instead: sending it
+// again, whole or not, would leave a fragment of a line in the stream.
+func (c *redialConn) send(msg func(net.Conn) []byte) error {
+	if c.conn != nil {
+		n, err := c.writeConn(msg(c.conn))
+		if err == nil {
+			return nil
+		}
+		c.conn.Close()
+		c.conn = nil
+		if n > 0 {
+			return err
+		}
+	}
+	if err := c.dial(); err != nil {
+		return err
+	}
+	if _, err := c.writeConn(msg(c.conn)); err != nil {
+		c.conn.Close()
+		c.conn = nil
+		return err
+	}
+	return nil
+}
+
+// writeConn writes b, as long as the peer keeps accepting part of it within
+// the timeout, and returns the number of bytes written.
+func (c *redialConn) writeConn(b []byte) (int, error) {
+	written := 0
+	for {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+		n, err := c.conn.Write(b[written:])
+		written += n
+		if err == nil || n == 0 {
+			return written, err
+		}
+	}
+}
+
+func (c *redialConn) dial() error {
+	if time.Now().Before(c.retryAt) {
+		return fmt.Errorf("logcore: %s %s is unreachable, retrying in %v", c.network, c.addr, time.Until(c.retryAt).Round(time.Millisecond))
+	}
+	var err error
+	if c.network == "unix" {
+		// Like log/syslog: datagram sockets such as /dev/log first.
+		c.conn, err = net.DialTimeout("unixgram", c.addr, c.timeout)
+		if err != nil {
+			c.conn, err = net.DialTimeout("unix", c.addr, c.timeout)
+		}
+	} else {
+		c.conn, err = net.DialTimeout(c.network, c.addr, c.timeout)
+	}
+	if err != nil {
+		c.retryAt = time.Now().Add(redialInterval)
+		return err
+	}
+	return nil
+}
+
+// tcpSink writes the entries as newline-delimited JSON over TCP.
+type tcpSink struct {
+	conn *redialConn
+}
+
+func (s *tcpSink) WriteEntry(_ string, line []byte) error {
+	line = bytes.Clone(line)
+	return s.conn.write(func(net.Conn) []byte { return line })
+}
+
+func (s *tcpSink) Sync() error {
+	return s.conn.sync()
+}
+
+func (s *tcpSink) Close() error {
+	return s.conn.close()
+}
+
+// programName is the default Tag.
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
@@ -0,0 +1,275 @@
+package logcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// httpMaxPending is the number of batches an HTTP sink buffers while its
+// endpoint is slow or down; entries beyond it are dropped.
+const httpMaxPending = 10
+
+var errSinkFull = errors.New("logcore: sink buffer full, entry dropped")
+
+type sinkEntry struct {
+	level string
+	time  time.Time
+	line  []byte // without the trailing newline
+}
+
+// httpFormat encodes batches for an HTTP endpoint.
+type httpFormat struct {
+	contentType string
+	encode      func([]sinkEntry) ([]byte, error)
+	// check inspects the body of a successful response, may be nil.
+	check func(body []byte) error
+}
+
+// httpSink buffers entries and pushes them in batches from a background
+// goroutine: when BatchSize entries are buffered, every FlushInterval, and on
+// Sync and Close. After Close, each entry is pushed on its own.
+type httpSink struct {
+	url      string
+	headers  map[string]string
+	client   *http.Client
+	format   httpFormat
+	batch    int
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending []sinkEntry
+	closed  bool
+	err     error // first push error since the last Sync
+
+	sendMu sync.Mutex // serializes pushes, keeping entries in order
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newHTTPSink(sc *SinkConf, format httpFormat) (*httpSink, error) {
+	if sc.Addr == "" {
+		return nil, fmt.Errorf("logcore: %s sink needs a URL", sc.Type)
+	}
+	s := &httpSink{
+		url:      sc.Addr,
+		headers:  sc.Headers,
+		client:   &http.Client{Timeout: sc.Timeout},
+		format:   format,
+		batch:    sc.BatchSize,
+		interval: sc.FlushInterval,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if s.client.Timeout <= 0 {
+		s.client.Timeout = defaultSinkTimeout
+	}
+	if s.batch <= 0 {
+		s.batch = defaultSinkBatchSize
+	}
+	if s.interval <= 0 {
+		s.interval = DefaultAsyncFlushInterval
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *httpSink) WriteEntry(level string, line []byte) error {
+	e := sinkEntry{level: level, time: time.Now(), line: bytes.Clone(bytes.TrimRight(line, "\n"))}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.sendMu.Lock()
+		defer s.sendMu.Unlock()
+		return s.push([]sinkEntry{e})
+	}
+	defer s.mu.Unlock()
+	if len(s.pending) >= s.batch*httpMaxPending {
+		return errSinkFull
+	}
+	s.pending = append(s.pending, e)
+	if len(s.pending) >= s.batch {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Sync pushes the buffered entries and returns the first push error since
+// the last Sync.
+func (s *httpSink) Sync() error {
+	s.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+func (s *httpSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	return s.Sync()
+}
+
+func (s *httpSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		case <-s.stop:
+			s.flush()
+			return
+		}
+		s.flush()
+	}
+}
+
+// flush pushes the buffered entries in batches.
+func (s *httpSink) flush() {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	for len(pending) > 0 {
+		n := min(len(pending), s.batch)
+		if err := s.push(pending[:n]); err != nil {
+			s.mu.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+		pending = pending[n:]
+	}
+}
+
+// push sends entries in one request; the caller holds sendMu.
+func (s *httpSink) push(entries []sinkEntry) error {
+	body, err := s.format.encode(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", s.format.contentType)
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("logcore: push to %s: %s: %s", s.url, resp.Status, bytes.TrimSpace(data))
+	}
+	if s.format.check != nil {
+		return s.format.check(data)
+	}
+	return nil
+}
+
+// lokiEncoder encodes batches for the Loki push API, one stream per level.
+func lokiEncoder(labels map[string]string) httpFormat {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	return httpFormat{
+		contentType: "application/json",
+		encode: func(entries []sinkEntry) ([]byte, error) {
+			var (
+				streams []*stream
+				byLevel = map[string]*stream{}
+			)
+			for _, e := range entries {
+				st, ok := byLevel[e.level]
+				if !ok {
+					st = &stream{Stream: map[string]string{"level": e.level}}
+					maps.Copy(st.Stream, labels)
+					byLevel[e.level] = st
+					streams = append(streams, st)
+				}
+				st.Values = append(st.Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), string(e.line)})
+			}
+			return json.Marshal(map[string]any{"streams": streams})
+		},
+	}
+}
+
+// elasticsearchEncoder encodes batches for the Elasticsearch bulk API.
+func elasticsearchEncoder(index string) httpFormat {
+	if index == "" {
+		index = "logs"
+	}
+	action, _ := json.Marshal(map[string]any{"index": map[string]string{"_index": index}})
+	action = append(action, '\n')
+	return httpFormat{
+		contentType: "application/x-ndjson",
+		encode: func(entries []sinkEntry) ([]byte, error) {
+			var b []byte
+			for _, e := range entries {
+				b = append(b, action...)
+				b = append(b, e.line...)
+				b = append(b, '\n')
+			}
+			return b, nil
+		},
+		check: func(body []byte) error {
+			// The bulk API reports rejected documents with status 200.
+			var resp struct {
+				Errors bool `json:"errors"`
+				Items  []map[string]struct {
+					Error json.RawMessage `json:"error"`
+				} `json:"items"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil || !resp.Errors {
+				return nil
+			}
+			failed, reason := 0, ""
+			for _, item := range resp.Items {
+				for _, res := range item {
+					if len(res.Error) == 0 {
+						continue
+					}
+					failed++
+					if reason == "" {
+						reason = string(res.Error)
+					}
+				}
+			}
+			return fmt.Errorf("logcore: elasticsearch rejected %d entries: %s", failed, reason)
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package logcore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// journaldSink writes entries with the native journal protocol: the message
+// becomes MESSAGE, the level PRIORITY, the caller CODE_FILE and CODE_LINE,
+// and the other fields upper-cased journal fields. Entries larger than the
+// socket's datagram limit are rejected.
+type journaldSink struct {
+	conn *redialConn
+	tag  string
+}
+
+func newJournaldSink(sc *SinkConf) *journaldSink {
+	addr, tag := sc.Addr, sc.Tag
+	if addr == "" {
+		addr = defaultJournaldSocket
+	}
+	if tag == "" {
+		tag = programName()
+	}
+	return &journaldSink{conn: newRedialConn("unixgram", addr, sc.Timeout), tag: tag}
+}
+
+func (s *journaldSink) WriteEntry(level string, line []byte) error {
+	msg := s.encode(level, line)
+	return s.conn.write(func(net.Conn) []byte { return msg })
+}
+
+func (s *journaldSink) encode(level string, line []byte) []byte {
+	var b []byte
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(severity(level)))
+	b = appendJournalField(b, "SYSLOG_IDENTIFIER", s.tag)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return appendJournalField(b, "MESSAGE", string(bytes.TrimRight(line, "\n")))
+	}
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		v := jsonString(fields[k])
+		switch k {
+		case "level", "timestamp", "time":
+			// journald records both.
+		case "msg":
+			b = appendJournalField(b, "MESSAGE", v)
+		case "caller":
+			if i := strings.LastIndexByte(v, ':'); i > 0 {
+				b = appendJournalField(b, "CODE_FILE", v[:i])
+				b = appendJournalField(b, "CODE_LINE", v[i+1:])
+			}
+		default:
+			if name := journalFieldName(k); name != "" {
+				b = appendJournalField(b, name, v)
+			}
+		}
+	}
+	return b
+}
+
+// jsonString returns a JSON string unquoted and any other value as JSON.
+func jsonString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// journalFieldName converts key to a valid journal field name: upper case
+// letters, digits and underscores, not starting with an underscore, or ""
+// if nothing is left.
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	res := strings.TrimLeft(string(name), "_0123456789")
+	if len(res) > 64 {
+		res = res[:64]
+	}
+	return res
+}
+
+// appendJournalField appends "NAME=value\n", or the binary form for values
+// containing a newline.
+func appendJournalField(b []byte, name, value string) []byte {
+	b = append(b, name...)
+	if !strings.Contains(value, "\n") {
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+func (s *journaldSink) Sync() error {
+	return s.conn.sync()
+}
+
+func (s *journaldSink) Close() error {
+	return s.conn.close()
+}
@@ -0,0 +1,127 @@
+package logcore
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// syslog severities, RFC 5424 section 6.2.1.
+const (
+	severityAlert   = 1
+	severityCrit    = 2
+	severityErr     = 3
+	severityWarning = 4
+	severityInfo    = 6
+	severityDebug   = 7
+)
+
+// severity returns the syslog severity of level.
+func severity(level string) int {
+	switch level {
+	case "debug":
+		return severityDebug
+	case "info":
+		return severityInfo
+	case "warn":
+		return severityWarning
+	case "error":
+		return severityErr
+	case "dpanic", "panic":
+		return severityCrit
+	case "fatal":
+		return severityAlert
+	default:
+		return severityInfo
+	}
+}
+
+// syslogSink writes RFC 5424 messages whose MSG is the JSON line. Over
+// stream connections the messages are framed by octet counting (RFC 6587).
+type syslogSink struct {
+	conn     *redialConn
+	facility int
+	hostname string
+	tag      string
+	pid      string
+}
+
+func newSyslogSink(sc *SinkConf) (*syslogSink, error) {
+	network, addr := sc.Network, sc.Addr
+	switch network {
+	case "":
+		network = "udp"
+	case "udp", "tcp":
+	case "unix":
+		if addr == "" {
+			addr = "/dev/log"
+		}
+	default:
+		return nil, fmt.Errorf("logcore: unknown syslog network %q", network)
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("logcore: syslog sink over %s needs an address", network)
+	}
+	if sc.Facility < 0 || sc.Facility > 23 {
+		return nil, fmt.Errorf("logcore: syslog facility %d out of range", sc.Facility)
+	}
+
+	s := &syslogSink{
+		conn:     newRedialConn(network, addr, sc.Timeout),
+		facility: sc.Facility,
+		hostname: "-",
+		tag:      sc.Tag,
+		pid:      strconv.Itoa(os.Getpid()),
+	}
+	if s.facility == 0 {
+		s.facility = 1
+	}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		s.hostname = h
+	}
+	if s.tag == "" {
+		s.tag = programName()
+	}
+	return s, nil
+}
+
+func (s *syslogSink) WriteEntry(level string, line []byte) error {
+	msg := s.format(level, time.Now(), bytes.TrimRight(line, "\n"))
+	return s.conn.write(func(c net.Conn) []byte {
+		if isStream(c) {
+			return append(strconv.AppendInt(nil, int64(len(msg)), 10), append([]byte{' '}, msg...)...)
+		}
+		return msg
+	})
+}
+
+// format returns "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID - - MSG".
+func (s *syslogSink) format(level string, t time.Time, msg []byte) []byte {
+	b := make([]byte, 0, 64+len(s.hostname)+len(s.tag)+len(msg))
+	b = fmt.Appendf(b, "<%d>1 ", s.facility*8+severity(level))
+	b = t.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	b = fmt.Appendf(b, " %s %s %s - - ", s.hostname, s.tag, s.pid)
+	return append(b, msg...)
+}
+
+func (s *syslogSink) Sync() error {
+	return s.conn.sync()
+}
+
+func (s *syslogSink) Close() error {
+	return s.conn.close()
+}
+
+// isStream reports whether c is a stream connection.
+func isStream(c net.Conn) bool {
+	if addr := c.RemoteAddr(); addr != nil {
+		switch addr.Network() {
+		case "tcp", "tcp4", "tcp6", "unix":
+			return true
+		}
+	}
+	return false
+}
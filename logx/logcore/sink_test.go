@@ -0,0 +1,456 @@
+package logcore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLine = `{"level":"warn","timestamp":"2024-01-02T03:04:05Z","caller":"svc/main.go:12","msg":"disk almost full","free":0.1}` + "\n"
+
+func TestNewSink(t *testing.T) {
+	t.Run("NewSink 参数校验", func(t *testing.T) {
+		for _, sc := range []SinkConf{
+			{Type: "kafka"},
+			{Type: SinkTCP},
+			{Type: SinkSyslog},
+			{Type: SinkSyslog, Network: "sctp", Addr: "localhost:514"},
+			{Type: SinkSyslog, Addr: "localhost:514", Facility: 24},
+			{Type: SinkLoki},
+			{Type: SinkElasticsearch},
+		} {
+			_, err := NewSink(&sc)
+			assert.Error(t, err, "%+v", sc)
+		}
+
+		var ss Sinks
+		assert.Error(t, ss.Open(&LoggerConf{Sinks: []SinkConf{{Type: SinkTCP, Addr: "localhost:1", Level: "trace"}}}))
+
+		custom := &memSink{}
+		assert.Nil(t, ss.Open(&LoggerConf{Sinks: []SinkConf{{Sink: custom}}}))
+		assert.Equal(t, Sinks{custom}, ss)
+		assert.Nil(t, ss.Sync())
+		assert.Nil(t, ss.Close())
+	})
+}
+
+func TestSyslogSink(t *testing.T) {
+	t.Run("syslog sink 测试", func(t *testing.T) {
+		t.Run("UDP", func(t *testing.T) {
+			pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+			require.Nil(t, err)
+			defer pc.Close()
+
+			s, err := NewSink(&SinkConf{Type: SinkSyslog, Addr: pc.LocalAddr().String(), Tag: "svc"})
+			require.Nil(t, err)
+			defer s.Close()
+			assert.Nil(t, s.WriteEntry("warn", []byte(testLine)))
+
+			buf := make([]byte, 4096)
+			_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := pc.ReadFrom(buf)
+			require.Nil(t, err)
+			msg := string(buf[:n])
+			// facility user (1) * 8 + warning (4)
+			assert.True(t, strings.HasPrefix(msg, "<12>1 "), msg)
+			assert.Contains(t, msg, " svc "+strconv.Itoa(os.Getpid())+" - - {")
+			assert.True(t, strings.HasSuffix(msg, strings.TrimSuffix(testLine, "\n")), msg)
+		})
+
+		t.Run("TCP 使用 octet counting", func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.Nil(t, err)
+			defer ln.Close()
+
+			s, err := NewSink(&SinkConf{Type: SinkSyslog, Network: "tcp", Addr: ln.Addr().String(), Facility: 16})
+			require.Nil(t, err)
+			defer s.Close()
+			assert.Nil(t, s.WriteEntry("error", []byte(testLine)))
+			assert.Nil(t, s.WriteEntry("info", []byte(testLine)))
+
+			conn, err := ln.Accept()
+			require.Nil(t, err)
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for _, pri := range []string{"<131>1 ", "<134>1 "} {
+				size, err := r.ReadString(' ')
+				require.Nil(t, err)
+				n, err := strconv.Atoi(strings.TrimSpace(size))
+				require.Nil(t, err)
+				msg := make([]byte, n)
+				_, err = io.ReadFull(r, msg)
+				require.Nil(t, err)
+				assert.True(t, strings.HasPrefix(string(msg), pri), string(msg))
+				assert.True(t, strings.HasSuffix(string(msg), `"free":0.1}`), string(msg))
+			}
+		})
+
+		t.Run("unix datagram", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "log.sock")
+			conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+			require.Nil(t, err)
+			defer conn.Close()
+
+			s, err := NewSink(&SinkConf{Type: SinkSyslog, Network: "unix", Addr: path})
+			require.Nil(t, err)
+			defer s.Close()
+			assert.Nil(t, s.WriteEntry("fatal", []byte(testLine)))
+
+			buf := make([]byte, 4096)
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, err := conn.Read(buf)
+			require.Nil(t, err)
+			assert.True(t, strings.HasPrefix(string(buf[:n]), "<9>1 "), string(buf[:n]))
+		})
+	})
+}
+
+func TestJournaldSink(t *testing.T) {
+	t.Run("journald sink 测试", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		require.Nil(t, err)
+		defer conn.Close()
+
+		s, err := NewSink(&SinkConf{Type: SinkJournald, Addr: path, Tag: "svc"})
+		require.Nil(t, err)
+		defer s.Close()
+		line := `{"level":"error","timestamp":"2024-01-02T03:04:05Z","caller":"svc/main.go:12","msg":"boom\nstack","user_id":7,"req.path":"/x","_secret":"s"}` + "\n"
+		assert.Nil(t, s.WriteEntry("error", []byte(line)))
+
+		buf := make([]byte, 4096)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		require.Nil(t, err)
+		fields := parseJournal(t, buf[:n])
+		assert.Equal(t, map[string]string{
+			"PRIORITY":          "3",
+			"SYSLOG_IDENTIFIER": "svc",
+			"CODE_FILE":         "svc/main.go",
+			"CODE_LINE":         "12",
+			"MESSAGE":           "boom\nstack",
+			"USER_ID":           "7",
+			"REQ_PATH":          "/x",
+			"SECRET":            "s",
+		}, fields)
+
+		t.Run("非 JSON 行", func(t *testing.T) {
+			assert.Nil(t, s.WriteEntry("info", []byte("plain text\n")))
+			n, err := conn.Read(buf)
+			require.Nil(t, err)
+			assert.Equal(t, "plain text", parseJournal(t, buf[:n])["MESSAGE"])
+		})
+	})
+}
+
+// parseJournal decodes the native journal protocol.
+func parseJournal(t *testing.T, b []byte) map[string]string {
+	fields := map[string]string{}
+	for len(b) > 0 {
+		i := strings.IndexAny(string(b), "=\n")
+		require.True(t, i > 0)
+		name := string(b[:i])
+		if b[i] == '=' {
+			end := strings.IndexByte(string(b[i+1:]), '\n')
+			fields[name] = string(b[i+1 : i+1+end])
+			b = b[i+2+end:]
+			continue
+		}
+		size := int(binary.LittleEndian.Uint64(b[i+1:]))
+		fields[name] = string(b[i+9 : i+9+size])
+		b = b[i+10+size:]
+	}
+	return fields
+}
+
+// pushServer records the requests sent to it.
+type pushServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	bodies  []string
+	headers []http.Header
+}
+
+func newPushServer(t *testing.T, status int, resp string) *pushServer {
+	s := &pushServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.headers = append(s.headers, r.Header.Clone())
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, resp)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *pushServer) requests() ([]string, []http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...), append([]http.Header(nil), s.headers...)
+}
+
+func TestLokiSink(t *testing.T) {
+	t.Run("Loki sink 测试", func(t *testing.T) {
+		srv := newPushServer(t, http.StatusNoContent, "")
+		s, err := NewSink(&SinkConf{
+			Type:          SinkLoki,
+			Addr:          srv.URL + "/loki/api/v1/push",
+			Labels:        map[string]string{"app": "svc"},
+			Headers:       map[string]string{"X-Scope-OrgID": "tenant"},
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+		})
+		require.Nil(t, err)
+		defer s.Close()
+
+		assert.Nil(t, s.WriteEntry("info", []byte(`{"msg":"a"}`+"\n")))
+		assert.Nil(t, s.WriteEntry("info", []byte(`{"msg":"b"}`+"\n")))
+		assert.Nil(t, s.WriteEntry("error", []byte(`{"msg":"c"}`+"\n")))
+		assert.Nil(t, s.Sync())
+
+		bodies, headers := srv.requests()
+		require.Len(t, bodies, 2, "按 BatchSize 分批")
+		assert.Equal(t, "tenant", headers[0].Get("X-Scope-OrgID"))
+		assert.Equal(t, "application/json", headers[0].Get("Content-Type"))
+
+		values := map[string][]string{}
+		for _, body := range bodies {
+			var req struct {
+				Streams []struct {
+					Stream map[string]string `json:"stream"`
+					Values [][2]string       `json:"values"`
+				} `json:"streams"`
+			}
+			require.Nil(t, json.Unmarshal([]byte(body), &req))
+			for _, st := range req.Streams {
+				assert.Equal(t, "svc", st.Stream["app"])
+				for _, v := range st.Values {
+					_, err := strconv.ParseInt(v[0], 10, 64)
+					assert.Nil(t, err)
+					values[st.Stream["level"]] = append(values[st.Stream["level"]], v[1])
+				}
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			"info":  {`{"msg":"a"}`, `{"msg":"b"}`},
+			"error": {`{"msg":"c"}`},
+		}, values)
+
+		t.Run("Close 后直接推送", func(t *testing.T) {
+			assert.Nil(t, s.Close())
+			assert.Nil(t, s.WriteEntry("warn", []byte(`{"msg":"d"}`+"\n")))
+			bodies, _ := srv.requests()
+			assert.Len(t, bodies, 3)
+			assert.Contains(t, bodies[2], `{\"msg\":\"d\"}`)
+		})
+	})
+}
+
+func TestElasticsearchSink(t *testing.T) {
+	t.Run("Elasticsearch sink 测试", func(t *testing.T) {
+		t.Run("bulk 格式", func(t *testing.T) {
+			srv := newPushServer(t, http.StatusOK, `{"errors":false,"items":[]}`)
+			s, err := NewSink(&SinkConf{Type: SinkElasticsearch, Addr: srv.URL + "/_bulk", Index: "app-logs", FlushInterval: time.Hour})
+			require.Nil(t, err)
+			defer s.Close()
+
+			assert.Nil(t, s.WriteEntry("info", []byte(`{"msg":"a"}`+"\n")))
+			assert.Nil(t, s.WriteEntry("warn", []byte(`{"msg":"b"}`+"\n")))
+			assert.Nil(t, s.Sync())
+
+			bodies, headers := srv.requests()
+			require.Len(t, bodies, 1)
+			assert.Equal(t, "application/x-ndjson", headers[0].Get("Content-Type"))
+			action := `{"index":{"_index":"app-logs"}}`
+			assert.Equal(t, action+"\n"+`{"msg":"a"}`+"\n"+action+"\n"+`{"msg":"b"}`+"\n", bodies[0])
+		})
+
+		t.Run("部分文档被拒绝", func(t *testing.T) {
+			srv := newPushServer(t, http.StatusOK, `{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`)
+			s, err := NewSink(&SinkConf{Type: SinkElasticsearch, Addr: srv.URL, FlushInterval: time.Hour})
+			require.Nil(t, err)
+			defer s.Close()
+
+			assert.Nil(t, s.WriteEntry("info", []byte(`{"msg":"a"}`+"\n")))
+			err = s.Sync()
+			assert.ErrorContains(t, err, "rejected 1 entries")
+			assert.ErrorContains(t, err, "mapper_parsing_exception")
+			assert.Nil(t, s.Sync())
+		})
+
+		t.Run("HTTP 错误", func(t *testing.T) {
+			srv := newPushServer(t, http.StatusServiceUnavailable, "busy")
+			s, err := NewSink(&SinkConf{Type: SinkElasticsearch, Addr: srv.URL, FlushInterval: time.Hour})
+			require.Nil(t, err)
+			defer s.Close()
+
+			assert.Nil(t, s.WriteEntry("info", []byte(`{"msg":"a"}`+"\n")))
+			assert.ErrorContains(t, s.Sync(), "503")
+		})
+	})
+}
+
+func TestTCPSink(t *testing.T) {
+	t.Run("TCP NDJSON sink 测试", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer ln.Close()
+
+		conns := make(chan net.Conn, 4)
+		go func() {
+			for {
+				c, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				conns <- c
+			}
+		}()
+
+		s, err := NewSink(&SinkConf{Type: SinkTCP, Addr: ln.Addr().String()})
+		require.Nil(t, err)
+		defer s.Close()
+
+		assert.Nil(t, s.WriteEntry("info", []byte(`{"msg":"first"}`+"\n")))
+		conn := <-conns
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.Nil(t, err)
+		assert.Equal(t, `{"msg":"first"}`+"\n", line)
+
+		t.Run("断开后重连", func(t *testing.T) {
+			conn.Close()
+			// Writes may still succeed until the sink notices the closed connection.
+			var next net.Conn
+			assert.Eventually(t, func() bool {
+				_ = s.WriteEntry("info", []byte(`{"msg":"again"}`+"\n"))
+				select {
+				case next = <-conns:
+					return true
+				default:
+					return false
+				}
+			}, 5*time.Second, 10*time.Millisecond)
+			require.NotNil(t, next)
+			defer next.Close()
+			line, err := bufio.NewReader(next).ReadString('\n')
+			require.Nil(t, err)
+			assert.Equal(t, `{"msg":"again"}`+"\n", line)
+		})
+
+		t.Run("不可达时限制重拨频率", func(t *testing.T) {
+			addr := ln.Addr().String()
+			ln.Close()
+			s, err := NewSink(&SinkConf{Type: SinkTCP, Addr: addr, Timeout: time.Second})
+			require.Nil(t, err)
+			assert.Nil(t, s.WriteEntry("info", []byte("{}\n")))
+			assert.Error(t, s.Sync())
+			assert.Nil(t, s.WriteEntry("info", []byte("{}\n")))
+			assert.ErrorContains(t, s.Sync(), "retrying")
+		})
+	})
+
+	t.Run("对端不读取时不阻塞", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer ln.Close()
+
+		s, err := NewSink(&SinkConf{Type: SinkTCP, Addr: ln.Addr().String(), Timeout: 100 * time.Millisecond})
+		require.Nil(t, err)
+		defer s.Close()
+
+		line := []byte(`{"msg":"` + strings.Repeat("x", 16<<10) + `"}` + "\n")
+		start, full := time.Now(), 0
+		for range 3000 {
+			if err := s.WriteEntry("info", line); err != nil {
+				assert.ErrorIs(t, err, errSinkFull)
+				full++
+			}
+		}
+		assert.Less(t, time.Since(start), 2*time.Second)
+		assert.Positive(t, full)
+		// Let Close fail fast instead of waiting on the peer.
+		ln.Close()
+	})
+
+	t.Run("部分写入后丢弃该行", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer ln.Close()
+
+		c := newRedialConn("tcp", ln.Addr().String(), time.Second)
+		defer c.close()
+		broken := &partialConn{}
+		c.conn = broken
+		assert.Nil(t, c.write(func(net.Conn) []byte { return []byte(`{"msg":"cut"}` + "\n") }))
+		assert.Nil(t, c.write(func(net.Conn) []byte { return []byte(`{"msg":"next"}` + "\n") }))
+		assert.ErrorIs(t, c.sync(), io.ErrClosedPipe)
+		assert.True(t, broken.closed)
+
+		conn, err := ln.Accept()
+		require.Nil(t, err)
+		defer conn.Close()
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.Nil(t, err)
+		assert.Equal(t, `{"msg":"next"}`+"\n", line)
+	})
+}
+
+// partialConn writes 3 bytes of the first message, then fails.
+type partialConn struct {
+	net.Conn
+	closed bool
+}
+
+func (c *partialConn) Write(p []byte) (int, error) {
+	return min(len(p), 3), io.ErrClosedPipe
+}
+
+func (c *partialConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *partialConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+// memSink records the entries written to it.
+type memSink struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (s *memSink) WriteEntry(level string, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, level+" "+string(line))
+	return nil
+}
+
+func (s *memSink) Sync() error  { return nil }
+func (s *memSink) Close() error { return nil }
+
+func TestSinkWriter(t *testing.T) {
+	t.Run("SinkWriter 测试", func(t *testing.T) {
+		s := &memSink{}
+		n, err := SinkWriter(s, "error").Write([]byte("{}\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []string{"error {}\n"}, s.entries)
+	})
+}
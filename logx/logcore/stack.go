@@ -0,0 +1,36 @@
+package logcore
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Stacktrace returns the stack trace of its caller, skipping skip more
+// frames, formatted by FormatStack.
+func Stacktrace(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	return FormatStack(pcs[:n])
+}
+
+// FormatStack formats the frames of pcs as "function\n\tfile:line" lines,
+// as zap does.
+func FormatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.PC == 0 && f.Function == "" {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
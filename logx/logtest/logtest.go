@@ -0,0 +1,287 @@
+// Package logtest provides a logger that records its entries in memory, so
+// that tests can assert what was logged.
+//
+// Example:
+//
+//	func TestRetry(t *testing.T) {
+//		obs := logtest.Observe(t) // the logx default logger until t ends
+//		retry()
+//		obs.AssertLogged(t, "warn", "retrying", logx.Int("attempt", 2))
+//	}
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BYT0723/go-tools/logx"
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+// Entry is a recorded log entry.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	// Fields holds the fields of With followed by those of the call, masked by
+	// the redactor set with logx.SetRedactor.
+	Fields []logcore.Field
+	// Caller is the "file:line" of the logging call.
+	Caller string
+}
+
+// Field returns the value of the field called key and whether there is one.
+func (e Entry) Field(key string) (any, bool) {
+	for _, kv := range e.Fields {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (e Entry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q", e.Level, e.Message)
+	for _, kv := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", kv.Key, kv.Value)
+	}
+	return b.String()
+}
+
+// recorder holds the entries of a Logger and of the loggers derived from it.
+type recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Logger is a logcore.Logger recording every entry, whatever its level.
+// Panic records the entry and panics with the message; Fatal records it but
+// does not exit. Loggers derived with With and AddCallerSkip record to the
+// same entries.
+type Logger struct {
+	rec    *recorder
+	fields []logcore.Field
+	skip   int
+}
+
+var _ logcore.Logger = (*Logger)(nil)
+
+// New returns an empty Logger. Entries logged through the logx package
+// functions report the caller of logx only after AddCallerSkip(1), as done
+// by Observe.
+func New() *Logger {
+	return &Logger{rec: &recorder{}}
+}
+
+// Observe makes a new Logger the logx default logger until the end of t,
+// restoring the previous one afterwards, and returns it.
+func Observe(t testing.TB) *Logger {
+	l := New()
+	t.Cleanup(logx.ReplaceDefault(l.AddCallerSkip(1)))
+	return l
+}
+
+// Entries returns a copy of the recorded entries, oldest first.
+func (l *Logger) Entries() []Entry {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	return append([]Entry(nil), l.rec.entries...)
+}
+
+// Find returns the entries at level (any level if empty) whose message
+// contains msgSubstr and which have all of fields, compared by key and value.
+func (l *Logger) Find(level, msgSubstr string, fields ...logcore.Field) []Entry {
+	var res []Entry
+	for _, e := range l.Entries() {
+		if match(e, level, msgSubstr, fields) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Reset drops the recorded entries.
+func (l *Logger) Reset() {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	l.rec.entries = nil
+}
+
+// AssertLogged reports an error to t unless an entry matches, see Find.
+func (l *Logger) AssertLogged(t testing.TB, level, msgSubstr string, fields ...logcore.Field) bool {
+	t.Helper()
+	if len(l.Find(level, msgSubstr, fields...)) > 0 {
+		return true
+	}
+	t.Errorf("logtest: no entry %s\nrecorded:%s", describe(level, msgSubstr, fields), l.dump())
+	return false
+}
+
+// AssertNotLogged reports an error to t if an entry matches, see Find.
+func (l *Logger) AssertNotLogged(t testing.TB, level, msgSubstr string, fields ...logcore.Field) bool {
+	t.Helper()
+	found := l.Find(level, msgSubstr, fields...)
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("logtest: unexpected entry %s\nfound: %s", describe(level, msgSubstr, fields), found[0])
+	return false
+}
+
+func match(e Entry, level, msgSubstr string, fields []logcore.Field) bool {
+	if (level != "" && e.Level != level) || !strings.Contains(e.Message, msgSubstr) {
+		return false
+	}
+	for _, f := range fields {
+		v, ok := e.Field(f.Key)
+		if !ok || !reflect.DeepEqual(v, f.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(level, msgSubstr string, fields []logcore.Field) string {
+	if level == "" {
+		level = "*"
+	}
+	return Entry{Level: level, Message: msgSubstr, Fields: fields}.String()
+}
+
+func (l *Logger) dump() string {
+	entries := l.Entries()
+	if len(entries) == 0 {
+		return " none"
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString("\n\t")
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
+
+func (l *Logger) log(level, msg string, kvs []logcore.Field) {
+	e := Entry{Time: time.Now(), Level: level, Message: msg}
+	// runtime.Caller, log and the Logger method.
+	if _, file, line, ok := runtime.Caller(2 + l.skip); ok {
+		e.Caller = fmt.Sprintf("%s:%d", file, line)
+	}
+	fields := make([]logcore.Field, 0, len(l.fields)+len(kvs))
+	e.Fields = logcore.Redact(append(append(fields, l.fields...), kvs...))
+
+	l.rec.mu.Lock()
+	l.rec.entries = append(l.rec.entries, e)
+	l.rec.mu.Unlock()
+
+	if level == "panic" {
+		panic(msg)
+	}
+}
+
+func (l *Logger) With(kvs ...logcore.Field) logcore.Logger {
+	c := *l
+	c.fields = append(l.fields[:len(l.fields):len(l.fields)], kvs...)
+	return &c
+}
+
+func (l *Logger) Debug(msg string, kvs ...logcore.Field) {
+	l.log("debug", msg, kvs)
+}
+
+func (l *Logger) Debugf(format string, args ...any) {
+	l.log("debug", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Info(msg string, kvs ...logcore.Field) {
+	l.log("info", msg, kvs)
+}
+
+func (l *Logger) Infof(format string, args ...any) {
+	l.log("info", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Warn(msg string, kvs ...logcore.Field) {
+	l.log("warn", msg, kvs)
+}
+
+func (l *Logger) Warnf(format string, args ...any) {
+	l.log("warn", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Error(msg string, kvs ...logcore.Field) {
+	l.log("error", msg, kvs)
+}
+
+func (l *Logger) Errorf(format string, args ...any) {
+	l.log("error", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Panic(msg string, kvs ...logcore.Field) {
+	l.log("panic", msg, kvs)
+}
+
+func (l *Logger) Panicf(format string, args ...any) {
+	l.log("panic", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Fatal(msg string, kvs ...logcore.Field) {
+	l.log("fatal", msg, kvs)
+}
+
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.log("fatal", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Log(level string, msg string, kvs ...logcore.Field) {
+	l.log(level, msg, kvs)
+}
+
+func (l *Logger) Logf(level string, format string, args ...any) {
+	l.log(level, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log("debug", msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log("info", msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log("warn", msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log("error", msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) PanicCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log("panic", msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log("fatal", msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) LogCtx(ctx context.Context, level string, msg string, kvs ...logcore.Field) {
+	l.log(level, msg, logcore.ContextFields(ctx, kvs...))
+}
+
+func (l *Logger) Sync() error {
+	return nil
+}
+
+func (l *Logger) AddCallerSkip(skip int) logcore.Logger {
+	c := *l
+	c.skip += skip
+	return &c
+}
@@ -0,0 +1,145 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/BYT0723/go-tools/logx"
+	"github.com/BYT0723/go-tools/logx/logcore"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeT records the errors reported by the assertions.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// line returns the "file:line" of the line after its caller.
+func line() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Logger 测试", func(t *testing.T) {
+		t.Run("记录级别消息字段与调用位置", func(t *testing.T) {
+			l := New()
+			caller := line()
+			l.With(logx.String("k", "v")).Warn("retrying", logx.Int("attempt", 2))
+			l.Errorf("failed %d times", 3)
+			l.Log("debug", "plain")
+
+			entries := l.Entries()
+			assert.Len(t, entries, 3)
+			assert.Equal(t, "warn", entries[0].Level)
+			assert.Equal(t, "retrying", entries[0].Message)
+			assert.Equal(t, []logcore.Field{logx.String("k", "v"), logx.Int("attempt", 2)}, entries[0].Fields)
+			assert.Equal(t, caller, entries[0].Caller)
+			assert.False(t, entries[0].Time.IsZero())
+			assert.Equal(t, "failed 3 times", entries[1].Message)
+			assert.Equal(t, "debug", entries[2].Level)
+
+			l.Reset()
+			assert.Empty(t, l.Entries())
+		})
+
+		t.Run("With 不影响原 logger", func(t *testing.T) {
+			l := New()
+			a := l.With(logx.String("a", "1"))
+			_ = a.With(logx.String("b", "2"))
+			c := a.With(logx.String("c", "3"))
+			c.Info("msg")
+			l.Info("root")
+
+			entries := l.Entries()
+			assert.Equal(t, []logcore.Field{logx.String("a", "1"), logx.String("c", "3")}, entries[0].Fields)
+			assert.Empty(t, entries[1].Fields)
+		})
+
+		t.Run("Ctx 方法与脱敏", func(t *testing.T) {
+			type key struct{}
+			logcore.SetContextExtractors(func(ctx context.Context) []logcore.Field {
+				if id, ok := ctx.Value(key{}).(string); ok {
+					return []logcore.Field{logx.String("trace_id", id)}
+				}
+				return nil
+			})
+			defer logcore.SetContextExtractors()
+			logx.SetRedactor(logx.DefaultRedactor())
+			defer logx.SetRedactor(nil)
+
+			l := New()
+			l.InfoCtx(context.WithValue(context.Background(), key{}, "t-1"), "login", logx.String("password", "hunter2"))
+			l.AssertLogged(t, "info", "login", logx.String("trace_id", "t-1"), logx.String("password", "[REDACTED]"))
+		})
+
+		t.Run("Panic 记录后 panic, Fatal 不退出", func(t *testing.T) {
+			l := New()
+			assert.PanicsWithValue(t, "boom", func() { l.Panic("boom") })
+			l.Fatalf("fatal %s", "error")
+			l.AssertLogged(t, "panic", "boom")
+			l.AssertLogged(t, "fatal", "fatal error")
+		})
+	})
+}
+
+func TestAssert(t *testing.T) {
+	t.Run("AssertLogged/AssertNotLogged 测试", func(t *testing.T) {
+		l := New()
+		l.Warn("retrying request", logx.Int("attempt", 2), logx.Err(errors.New("timeout")))
+
+		ft := &fakeT{TB: t}
+		assert.True(t, l.AssertLogged(ft, "warn", "retrying"))
+		assert.True(t, l.AssertLogged(ft, "", "request", logx.Int("attempt", 2)))
+		assert.True(t, l.AssertLogged(ft, "warn", "", logx.Err(errors.New("timeout"))))
+		assert.True(t, l.AssertNotLogged(ft, "error", "retrying"))
+		assert.Empty(t, ft.errors)
+
+		assert.False(t, l.AssertLogged(ft, "warn", "retrying", logx.Int("attempt", 3)))
+		assert.False(t, l.AssertNotLogged(ft, "", "retrying"))
+		assert.Len(t, ft.errors, 2)
+		assert.Contains(t, ft.errors[0], `no entry warn "retrying" attempt=3`)
+		assert.Contains(t, ft.errors[0], `warn "retrying request" attempt=2 error=timeout`)
+		assert.Contains(t, ft.errors[1], `unexpected entry * "retrying"`)
+
+		assert.Len(t, l.Find("warn", ""), 1)
+		assert.Empty(t, l.Find("info", ""))
+	})
+}
+
+func TestObserve(t *testing.T) {
+	t.Run("Observe 测试", func(t *testing.T) {
+		prev := Observe(t)
+
+		t.Run("替换默认 logger", func(t *testing.T) {
+			obs := Observe(t)
+
+			_, file, ln, _ := runtime.Caller(0)
+			logx.Info("package", logx.String("k", "v"))
+			logx.Default().Warn("default")
+			logx.Named("db").Error("named")
+
+			entries := obs.Entries()
+			assert.Len(t, entries, 3)
+			for i, e := range entries {
+				assert.Equal(t, fmt.Sprintf("%s:%d", file, ln+1+i), e.Caller, e.Message)
+			}
+			obs.AssertLogged(t, "warn", "default")
+			obs.AssertLogged(t, "error", "named", logx.String("logger", "db"))
+			assert.Empty(t, prev.Entries())
+		})
+
+		logx.Info("after")
+		prev.AssertLogged(t, "info", "after")
+	})
+}
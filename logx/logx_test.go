@@ -2,7 +2,10 @@ package logx
 
 import (
 	"errors"
+	"io"
+	"log/slog"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -108,21 +111,82 @@ func TestFieldBuilders(t *testing.T) {
 			e := errors.New("test error")
 			f := Err(e)
 			assert.Equal(t, "error", f.Key)
-			assert.Equal(t, "test error", f.Value)
+			assert.Equal(t, logcore.KindError, f.Kind)
+			assert.Equal(t, e, f.Value)
+		})
+
+		t.Run("Err nil", func(t *testing.T) {
+			var f Field
+			assert.NotPanics(t, func() { f = Err(nil) })
+			assert.Equal(t, "error", f.Key)
+			assert.Nil(t, f.Value)
 		})
 
 		t.Run("Duration", func(t *testing.T) {
 			f := Duration("elapsed", time.Second)
 			assert.Equal(t, "elapsed", f.Key)
+			assert.Equal(t, logcore.KindDuration, f.Kind)
 			assert.Equal(t, time.Second, f.Value)
 		})
+
+		t.Run("Time", func(t *testing.T) {
+			now := time.Now()
+			f := Time("at", now)
+			assert.Equal(t, logcore.KindTime, f.Kind)
+			assert.Equal(t, now, f.Value)
+		})
+
+		t.Run("Stringer", func(t *testing.T) {
+			f := Stringer("timeout", time.Second)
+			assert.Equal(t, logcore.KindStringer, f.Kind)
+			assert.Equal(t, time.Second, f.Value)
+			assert.Equal(t, Field{Key: "timeout"}, Stringer("timeout", nil))
+		})
+
+		t.Run("Binary", func(t *testing.T) {
+			f := Binary("payload", []byte{1, 2})
+			assert.Equal(t, logcore.KindBinary, f.Kind)
+			assert.Equal(t, []byte{1, 2}, f.Value)
+		})
+
+		t.Run("Namespace", func(t *testing.T) {
+			assert.Equal(t, Field{Key: "req", Kind: logcore.KindNamespace}, Namespace("req"))
+		})
+
+		t.Run("Stack", func(t *testing.T) {
+			f := Stack("stack")
+			assert.Equal(t, logcore.KindStack, f.Kind)
+			stack := f.Value.(string)
+			assert.True(t, strings.HasPrefix(stack, "github.com/BYT0723/go-tools/logx.TestFieldBuilders"), stack)
+			assert.NotContains(t, stack, "logx.Stack")
+		})
+
+		t.Run("Object/Array", func(t *testing.T) {
+			var (
+				o ObjectMarshaler = testObject{}
+				a ArrayMarshaler  = testArray{}
+			)
+			assert.Equal(t, Field{Key: "o", Kind: logcore.KindObject, Value: o}, Object("o", o))
+			assert.Equal(t, Field{Key: "a", Kind: logcore.KindArray, Value: a}, Array("a", a))
+			assert.Equal(t, Field{Key: "o"}, Object("o", nil))
+			assert.Equal(t, Field{Key: "a"}, Array("a", nil))
+		})
 	})
 }
 
+type (
+	testObject struct{}
+	testArray  struct{}
+)
+
+func (testObject) MarshalLogObject(enc ObjectEncoder) error { return nil }
+func (testArray) MarshalLogArray(enc ArrayEncoder) error    { return nil }
+
 func TestLoggerType(t *testing.T) {
 	t.Run("LoggerType 测试", func(t *testing.T) {
 		assert.Equal(t, LoggerType(0), TypeZap)
 		assert.Equal(t, LoggerType(1), TypeZeroLog)
+		assert.Equal(t, LoggerType(2), TypeSlog)
 		assert.Equal(t, LoggerType(15), TypeInvalid)
 	})
 }
@@ -148,6 +212,14 @@ func TestOptionFuncs(t *testing.T) {
 			assert.Equal(t, TypeZeroLog, cfg.Type)
 		})
 
+		t.Run("WithSlogHandler", func(t *testing.T) {
+			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
+			h := slog.NewTextHandler(io.Discard, nil)
+			WithSlogHandler(h)(cfg)
+			assert.Equal(t, TypeSlog, cfg.Type)
+			assert.Equal(t, h, cfg.Handler)
+		})
+
 		t.Run("WithLevel", func(t *testing.T) {
 			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
 			WithLevel("error")(cfg)
@@ -184,6 +256,91 @@ func TestOptionFuncs(t *testing.T) {
 			assert.Equal(t, 30, cfg.LogCfg.MaxAge)
 		})
 
+		t.Run("WithSampling/WithDedup", func(t *testing.T) {
+			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
+			WithSampling(10, 100, time.Minute)(cfg)
+			WithDedup(true)(cfg)
+			assert.Equal(t, 10, cfg.LogCfg.SampleFirst)
+			assert.Equal(t, 100, cfg.LogCfg.SampleThereafter)
+			assert.Equal(t, time.Minute, cfg.LogCfg.SampleInterval)
+			assert.True(t, cfg.LogCfg.Dedup)
+		})
+
+		t.Run("WithAsync", func(t *testing.T) {
+			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
+			WithAsync(1024, time.Minute, OverflowBlock)(cfg)
+			assert.True(t, cfg.LogCfg.Async)
+			assert.Equal(t, 1024, cfg.LogCfg.AsyncBufferSize)
+			assert.Equal(t, time.Minute, cfg.LogCfg.AsyncFlushInterval)
+			assert.Equal(t, OverflowBlock, cfg.LogCfg.AsyncOverflow)
+		})
+
+		t.Run("WithRotate/WithFilePattern/WithCompress/WithMaxTotalSize/WithOnRotate/WithOnRotateError", func(t *testing.T) {
+			var (
+				rotated string
+				rotErr  error
+			)
+			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
+			WithRotate("0 */6 * * *")(cfg)
+			WithFilePattern("app-%Y%m%d%H.log")(cfg)
+			WithCompress("zstd")(cfg)
+			WithMaxTotalSize(512)(cfg)
+			WithOnRotate(func(path string) { rotated = path })(cfg)
+			WithOnRotateError(func(err error) { rotErr = err })(cfg)
+			assert.Equal(t, "0 */6 * * *", cfg.LogCfg.Rotate)
+			assert.Equal(t, "app-%Y%m%d%H.log", cfg.LogCfg.FilePattern)
+			assert.Equal(t, "zstd", cfg.LogCfg.Compress)
+			assert.Equal(t, 512, cfg.LogCfg.MaxTotalSize)
+			cfg.LogCfg.OnRotate("app-2024010206.log.zst")
+			assert.Equal(t, "app-2024010206.log.zst", rotated)
+			cfg.LogCfg.OnRotateError(io.ErrShortWrite)
+			assert.Equal(t, io.ErrShortWrite, rotErr)
+		})
+
+		t.Run("WithStackLevel", func(t *testing.T) {
+			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
+			WithStackLevel("error")(cfg)
+			assert.Equal(t, "error", cfg.LogCfg.StackLevel)
+		})
+
+		t.Run("WithSinks", func(t *testing.T) {
+			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
+			WithSinks(SinkConf{Type: SinkTCP, Addr: "localhost:5170"})(cfg)
+			WithSinks(SinkConf{Type: SinkSyslog, Level: "warn"})(cfg)
+			assert.Equal(t, []SinkConf{
+				{Type: SinkTCP, Addr: "localhost:5170"},
+				{Type: SinkSyslog, Level: "warn"},
+			}, cfg.LogCfg.Sinks)
+		})
+
+		t.Run("Close/Dropped", func(t *testing.T) {
+			l, err := NewLogger(WithConf(&logcore.LoggerConf{Dir: t.TempDir()}), WithAsync(0, time.Hour, OverflowDrop))
+			assert.Nil(t, err)
+			useDefault(t, l)
+			Info("async")
+			assert.Nil(t, Close())
+			assert.Zero(t, Dropped())
+
+			l, err = NewLogger(WithSlogHandler(slog.DiscardHandler))
+			assert.Nil(t, err)
+			useDefault(t, l)
+			assert.Nil(t, Close())
+			assert.Zero(t, Dropped())
+		})
+
+		t.Run("ReplaceDefault", func(t *testing.T) {
+			a, err := NewLogger(WithSlogHandler(slog.DiscardHandler))
+			assert.Nil(t, err)
+			b, err := NewLogger(WithSlogHandler(slog.DiscardHandler))
+			assert.Nil(t, err)
+			useDefault(t, a)
+
+			restore := ReplaceDefault(b)
+			assert.Same(t, b, defaultLogger)
+			restore()
+			assert.Same(t, a, defaultLogger)
+		})
+
 		t.Run("WithConf 合并配置并清理Ext", func(t *testing.T) {
 			cfg := &InitConf{LogCfg: logcore.DefaultLoggerConf()}
 			WithConf(&logcore.LoggerConf{Name: "merged", Ext: ".log"})(cfg)
@@ -0,0 +1,264 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+type LevelLogger = logcore.LevelLogger
+
+// ErrLevelUnsupported is returned when the default logger cannot change its level at runtime.
+var ErrLevelUnsupported = errors.New("logx: logger does not support runtime levels")
+
+var (
+	// namedMu serializes the creation of named loggers and level changes.
+	namedMu sync.Mutex
+	// namedLevels holds the levels set with SetLevel, by name.
+	namedLevels = map[string]string{}
+	// namedCache holds the named loggers derived from the current default
+	// logger, by name. It is cleared when the default logger changes.
+	namedCache sync.Map
+)
+
+// namedLogger resolves its logger on every call, so that loggers returned by
+// Named before Init, or before SetDefault, log through the current default logger.
+type namedLogger struct {
+	name string
+}
+
+// Named returns the logger called name, derived from the default logger. Its
+// lines carry the field "logger" with name and its level can be changed with
+// SetLevel independently of the default logger; it starts at the default
+// logger's level, or at the level last set for name.
+//
+// Named may be called before Init.
+//
+// Example:
+//
+//	var log = logx.Named("db")
+//
+//	logx.SetLevel("db", "debug")
+//	log.Debug("query", logx.String("sql", q))
+func Named(name string) LevelLogger {
+	return namedLogger{name: name}
+}
+
+// SetLevel changes the level of the logger called name, or of the default
+// logger if name is empty. Levels set for names that are not used yet apply
+// once they are.
+func SetLevel(name, level string) error {
+	if !logcore.ValidLevel(level) {
+		return fmt.Errorf("logx: unknown level %q", level)
+	}
+	if name == "" {
+		l, ok := defaultLogger.(LevelLogger)
+		if !ok {
+			return ErrLevelUnsupported
+		}
+		return l.SetLevel(level)
+	}
+
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	namedLevels[name] = level
+	if l, ok := namedCache.Load(name); ok {
+		if ll, ok := l.(LevelLogger); ok {
+			return ll.SetLevel(level)
+		}
+	}
+	return nil
+}
+
+// GetLevel returns the level of the logger called name, or of the default
+// logger if name is empty. A named logger not used yet reports the level it
+// would start at.
+func GetLevel(name string) (string, error) {
+	if name != "" {
+		if l, ok := namedCache.Load(name); ok {
+			if ll, ok := l.(LevelLogger); ok {
+				return ll.Level(), nil
+			}
+			return "", ErrLevelUnsupported
+		}
+		namedMu.Lock()
+		level, ok := namedLevels[name]
+		namedMu.Unlock()
+		if ok {
+			return level, nil
+		}
+	}
+	if l, ok := defaultLogger.(LevelLogger); ok {
+		return l.Level(), nil
+	}
+	return "", ErrLevelUnsupported
+}
+
+// Levels returns the levels of the default logger (under the empty name) and
+// of all named loggers that are in use or have a level set.
+func Levels() map[string]string {
+	res := make(map[string]string)
+	if l, ok := defaultLogger.(LevelLogger); ok {
+		res[""] = l.Level()
+	}
+
+	namedMu.Lock()
+	maps.Copy(res, namedLevels)
+	namedMu.Unlock()
+
+	namedCache.Range(func(k, v any) bool {
+		if l, ok := v.(LevelLogger); ok {
+			res[k.(string)] = l.Level()
+		}
+		return true
+	})
+	return res
+}
+
+// resolveNamed returns the logger called name, creating it from the default logger if needed.
+func resolveNamed(name string) Logger {
+	if l, ok := namedCache.Load(name); ok {
+		return l.(Logger)
+	}
+
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	if l, ok := namedCache.Load(name); ok {
+		return l.(Logger)
+	}
+	var l Logger
+	if dl, ok := defaultLogger.(LevelLogger); ok {
+		child := dl.Named(name)
+		if level, ok := namedLevels[name]; ok {
+			_ = child.SetLevel(level)
+		}
+		l = child
+	} else {
+		l = defaultLogger.With(String("logger", name))
+	}
+	namedCache.Store(name, l)
+	return l
+}
+
+// resetNamed drops the named loggers derived from the previous default logger.
+func resetNamed() {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	namedCache.Clear()
+}
+
+func (n namedLogger) With(kvs ...Field) Logger {
+	return resolveNamed(n.name).AddCallerSkip(-1).With(kvs...)
+}
+
+func (n namedLogger) Debug(msg string, kvs ...Field) {
+	resolveNamed(n.name).Debug(msg, kvs...)
+}
+
+func (n namedLogger) Debugf(format string, args ...any) {
+	resolveNamed(n.name).Debugf(format, args...)
+}
+
+func (n namedLogger) Info(msg string, kvs ...Field) {
+	resolveNamed(n.name).Info(msg, kvs...)
+}
+
+func (n namedLogger) Infof(format string, args ...any) {
+	resolveNamed(n.name).Infof(format, args...)
+}
+
+func (n namedLogger) Warn(msg string, kvs ...Field) {
+	resolveNamed(n.name).Warn(msg, kvs...)
+}
+
+func (n namedLogger) Warnf(format string, args ...any) {
+	resolveNamed(n.name).Warnf(format, args...)
+}
+
+func (n namedLogger) Error(msg string, kvs ...Field) {
+	resolveNamed(n.name).Error(msg, kvs...)
+}
+
+func (n namedLogger) Errorf(format string, args ...any) {
+	resolveNamed(n.name).Errorf(format, args...)
+}
+
+func (n namedLogger) Panic(msg string, kvs ...Field) {
+	resolveNamed(n.name).Panic(msg, kvs...)
+}
+
+func (n namedLogger) Panicf(format string, args ...any) {
+	resolveNamed(n.name).Panicf(format, args...)
+}
+
+func (n namedLogger) Fatal(msg string, kvs ...Field) {
+	resolveNamed(n.name).Fatal(msg, kvs...)
+}
+
+func (n namedLogger) Fatalf(format string, args ...any) {
+	resolveNamed(n.name).Fatalf(format, args...)
+}
+
+func (n namedLogger) Log(level string, msg string, kvs ...Field) {
+	resolveNamed(n.name).Log(level, msg, kvs...)
+}
+
+func (n namedLogger) Logf(level string, format string, args ...any) {
+	resolveNamed(n.name).Logf(level, format, args...)
+}
+
+func (n namedLogger) DebugCtx(ctx context.Context, msg string, kvs ...Field) {
+	resolveNamed(n.name).DebugCtx(ctx, msg, kvs...)
+}
+
+func (n namedLogger) InfoCtx(ctx context.Context, msg string, kvs ...Field) {
+	resolveNamed(n.name).InfoCtx(ctx, msg, kvs...)
+}
+
+func (n namedLogger) WarnCtx(ctx context.Context, msg string, kvs ...Field) {
+	resolveNamed(n.name).WarnCtx(ctx, msg, kvs...)
+}
+
+func (n namedLogger) ErrorCtx(ctx context.Context, msg string, kvs ...Field) {
+	resolveNamed(n.name).ErrorCtx(ctx, msg, kvs...)
+}
+
+func (n namedLogger) PanicCtx(ctx context.Context, msg string, kvs ...Field) {
+	resolveNamed(n.name).PanicCtx(ctx, msg, kvs...)
+}
+
+func (n namedLogger) FatalCtx(ctx context.Context, msg string, kvs ...Field) {
+	resolveNamed(n.name).FatalCtx(ctx, msg, kvs...)
+}
+
+func (n namedLogger) LogCtx(ctx context.Context, level string, msg string, kvs ...Field) {
+	resolveNamed(n.name).LogCtx(ctx, level, msg, kvs...)
+}
+
+func (n namedLogger) Sync() error {
+	return resolveNamed(n.name).Sync()
+}
+
+func (n namedLogger) AddCallerSkip(skip int) Logger {
+	// The returned logger is called directly, without namedLogger in between.
+	return resolveNamed(n.name).AddCallerSkip(skip - 1)
+}
+
+func (n namedLogger) Named(name string) LevelLogger {
+	return namedLogger{name: n.name + "." + name}
+}
+
+func (n namedLogger) Level() string {
+	level, _ := GetLevel(n.name)
+	return level
+}
+
+func (n namedLogger) SetLevel(level string) error {
+	return SetLevel(n.name, level)
+}
+
+var _ LevelLogger = namedLogger{}
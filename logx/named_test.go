@@ -0,0 +1,146 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useDefault makes l the default logger for the duration of the test.
+func useDefault(t *testing.T, l Logger) {
+	old := defaultLogger
+	SetDefault(l)
+	t.Cleanup(func() {
+		defaultLogger = old
+		resetNamed()
+		namedMu.Lock()
+		clear(namedLevels)
+		namedMu.Unlock()
+	})
+}
+
+func TestNamed(t *testing.T) {
+	t.Run("Named 测试", func(t *testing.T) {
+		// Named works before the default logger is set.
+		db := Named("db")
+
+		var buf bytes.Buffer
+		useDefault(t, newJSONLogger(t, &buf))
+		require.NoError(t, SetLevel("", "info"))
+
+		t.Run("继承默认 Logger 的级别", func(t *testing.T) {
+			buf.Reset()
+			db.Debug("dropped")
+			db.Info("kept")
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 1)
+			assert.Equal(t, "db", lines[0]["logger"])
+			assert.Contains(t, lines[0]["source"].(map[string]any)["file"], "named_test.go")
+		})
+
+		t.Run("级别相互独立", func(t *testing.T) {
+			buf.Reset()
+			require.NoError(t, SetLevel("db", "debug"))
+			db.Debug("db debug")
+			Debug("root debug")
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 1)
+			assert.Equal(t, "db debug", lines[0]["msg"])
+
+			level, err := GetLevel("db")
+			assert.NoError(t, err)
+			assert.Equal(t, "debug", level)
+			assert.Equal(t, "debug", db.Level())
+		})
+
+		t.Run("先设置级别后使用", func(t *testing.T) {
+			require.NoError(t, SetLevel("cache", "error"))
+			level, _ := GetLevel("cache")
+			assert.Equal(t, "error", level)
+
+			buf.Reset()
+			cache := Named("cache")
+			cache.Warn("dropped")
+			cache.Error("kept")
+			assert.Len(t, decodeLines(t, &buf), 1)
+		})
+
+		t.Run("With 与子名称", func(t *testing.T) {
+			buf.Reset()
+			db.With(String("table", "users")).Info("with")
+			db.Named("sql").Info("sub")
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 2)
+			assert.Equal(t, "users", lines[0]["table"])
+			assert.Equal(t, "db", lines[0]["logger"])
+			assert.Contains(t, lines[0]["source"].(map[string]any)["file"], "named_test.go")
+			assert.Equal(t, "db.sql", lines[1]["logger"])
+		})
+
+		t.Run("Levels", func(t *testing.T) {
+			assert.Equal(t, map[string]string{
+				"":       "info",
+				"db":     "debug",
+				"cache":  "error",
+				"db.sql": "info",
+			}, Levels())
+		})
+
+		t.Run("无效级别", func(t *testing.T) {
+			assert.Error(t, SetLevel("db", "verbose"))
+			assert.Equal(t, "debug", db.Level())
+		})
+
+		t.Run("替换默认 Logger 后重新派生", func(t *testing.T) {
+			var other bytes.Buffer
+			SetDefault(newJSONLogger(t, &other))
+			db.Debug("to other")
+			assert.Contains(t, other.String(), "to other")
+			assert.Equal(t, "debug", db.Level(), "已设置的级别保留")
+		})
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Run("LevelHandler 测试", func(t *testing.T) {
+		var buf bytes.Buffer
+		useDefault(t, newJSONLogger(t, &buf))
+		h := LevelHandler()
+
+		do := func(method, target, body string) (int, map[string]string) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+			var res map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			return rec.Code, res
+		}
+
+		code, res := do(http.MethodPut, "/", `{"name":"api","level":"warn"}`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]string{"name": "api", "level": "warn"}, res)
+
+		code, res = do(http.MethodGet, "/?name=api", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "warn", res["level"])
+
+		code, res = do(http.MethodGet, "/", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]string{"": "debug", "api": "warn"}, res)
+
+		code, res = do(http.MethodPost, "/", `{"name":"api","level":"verbose"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, res["error"], "verbose")
+
+		code, _ = do(http.MethodPost, "/", `{"name":"api"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = do(http.MethodDelete, "/", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, code)
+	})
+}
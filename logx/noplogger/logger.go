@@ -1,6 +1,10 @@
 package noplogger
 
-import "github.com/BYT0723/go-tools/logx/logcore"
+import (
+	"context"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
 
 type NopLogger struct{}
 
@@ -50,6 +54,27 @@ func (NopLogger) Log(level string, msg string, kvs ...logcore.Field) {
 func (NopLogger) Logf(level string, format string, args ...any) {
 }
 
+func (NopLogger) DebugCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+}
+
+func (NopLogger) InfoCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+}
+
+func (NopLogger) WarnCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+}
+
+func (NopLogger) ErrorCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+}
+
+func (NopLogger) PanicCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+}
+
+func (NopLogger) FatalCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+}
+
+func (NopLogger) LogCtx(ctx context.Context, level string, msg string, kvs ...logcore.Field) {
+}
+
 func (NopLogger) Sync() error {
 	return nil
 }
@@ -1,6 +1,7 @@
 package noplogger
 
 import (
+	"context"
 	"testing"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
@@ -23,6 +24,8 @@ func TestNopLogger(t *testing.T) {
 			assert.NotPanics(t, func() { l.Errorf("test %s", "arg") })
 			assert.NotPanics(t, func() { l.Log("info", "test") })
 			assert.NotPanics(t, func() { l.Logf("info", "test %s", "arg") })
+			assert.NotPanics(t, func() { l.InfoCtx(context.Background(), "test") })
+			assert.NotPanics(t, func() { l.LogCtx(context.Background(), "info", "test") })
 		})
 
 		t.Run("Sync 返回nil", func(t *testing.T) {
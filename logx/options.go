@@ -1,8 +1,10 @@
 package logx
 
 import (
+	"log/slog"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
 )
@@ -12,6 +14,9 @@ type Option func(cfg *InitConf)
 type InitConf struct {
 	Type   LoggerType
 	LogCfg *logcore.LoggerConf
+	// Handler receives the records of a TypeSlog logger.
+	// If nil, a handler is built from LogCfg.
+	Handler slog.Handler
 }
 
 func WithLoggerType(_t LoggerType) Option {
@@ -23,6 +28,14 @@ func WithLoggerType(_t LoggerType) Option {
 	}
 }
 
+// WithSlogHandler selects TypeSlog and makes it write to h instead of the files in LogCfg.
+func WithSlogHandler(h slog.Handler) Option {
+	return func(cfg *InitConf) {
+		cfg.Type = TypeSlog
+		cfg.Handler = h
+	}
+}
+
 func WithLevel(level string) Option {
 	return func(cfg *InitConf) {
 		cfg.LogCfg.Level = level
@@ -61,6 +74,90 @@ func WithMaxAge(age int) Option {
 	}
 }
 
+// WithRotate rotates the log files on a schedule: "hourly", "daily" or a
+// cron expression such as "0 */6 * * *".
+func WithRotate(schedule string) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.Rotate = schedule
+	}
+}
+
+// WithFilePattern names the log files after pattern, e.g. "app-%Y%m%d.log",
+// see LoggerConf.FilePattern.
+func WithFilePattern(pattern string) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.FilePattern = pattern
+	}
+}
+
+// WithCompress compresses rotated files with "gzip" or "zstd".
+func WithCompress(algo string) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.Compress = algo
+	}
+}
+
+// size: mb, of the current and rotated files of each output
+func WithMaxTotalSize(size int) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.MaxTotalSize = size
+	}
+}
+
+// WithOnRotate calls fn with the path of each rotated (and compressed) file.
+func WithOnRotate(fn func(path string)) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.OnRotate = fn
+	}
+}
+
+// WithOnRotateError calls fn with the errors of compressing and pruning
+// rotated files, instead of writing them to stderr.
+func WithOnRotateError(fn func(err error)) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.OnRotateError = fn
+	}
+}
+
+// WithStackLevel adds the stack trace of the logging call to the entries at
+// level and above, e.g. "error", in the field "stacktrace".
+func WithStackLevel(level string) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.StackLevel = level
+	}
+}
+
+// WithSampling logs, per level and message, the first `first` entries of each
+// interval, then every `thereafter`-th one. interval <= 0 means 1s.
+func WithSampling(first, thereafter int, interval time.Duration) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.SampleFirst = first
+		cfg.LogCfg.SampleThereafter = thereafter
+		cfg.LogCfg.SampleInterval = interval
+	}
+}
+
+// WithDedup suppresses duplicate entries and logs "msg (repeated N times)"
+// summaries instead, see LoggerConf.Dedup.
+func WithDedup(dedup bool) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.Dedup = dedup
+	}
+}
+
+// WithAsync writes the log files from a background goroutine through a
+// buffer of size lines, flushed at least every interval; overflow decides
+// what happens when the buffer is full. Zero values mean the defaults, see
+// LoggerConf.Async. Call Sync or Close before the program exits.
+func WithAsync(size int, interval time.Duration, overflow OverflowPolicy) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.Async = true
+		cfg.LogCfg.AsyncBufferSize = size
+		cfg.LogCfg.AsyncFlushInterval = interval
+		cfg.LogCfg.AsyncOverflow = overflow
+	}
+}
+
 var extRegex = regexp.MustCompile(`^\.[a-zA-Z0-9]+$`)
 
 func WithConf(cfg *logcore.LoggerConf) Option {
@@ -0,0 +1,53 @@
+package logx
+
+import (
+	"regexp"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+type (
+	Redactor   = logcore.Redactor
+	RedactRule = logcore.RedactRule
+	Mask       = logcore.Mask
+)
+
+var (
+	MaskFull    = logcore.MaskFull
+	MaskPartial = logcore.MaskPartial
+	MaskHash    = logcore.MaskHash
+)
+
+// NewRedactor creates a Redactor with rules, see logcore.NewRedactor.
+func NewRedactor(rules ...RedactRule) *Redactor {
+	return logcore.NewRedactor(rules...)
+}
+
+// DefaultRedactor returns a Redactor for common secrets (passwords, tokens,
+// authorization headers, e-mail addresses, card numbers and JWTs) extended by rules.
+func DefaultRedactor(rules ...RedactRule) *Redactor {
+	return logcore.DefaultRedactor(rules...)
+}
+
+// RedactKeys masks the fields called one of keys with mask.
+func RedactKeys(mask Mask, keys ...string) RedactRule {
+	return logcore.RedactKeys(mask, keys...)
+}
+
+// RedactPattern masks the matches of re in string values with mask.
+func RedactPattern(re *regexp.Regexp, mask Mask) RedactRule {
+	return logcore.RedactPattern(re, mask)
+}
+
+// SetRedactor makes the zap, zerolog and slog backends mask the fields of all
+// loggers, including those written by the ginx and echox middleware, with r.
+// nil disables redaction. Messages are not redacted.
+//
+// Example:
+//
+//	logx.SetRedactor(logx.DefaultRedactor(
+//		logx.RedactKeys(logx.MaskHash, "user_id"),
+//	))
+func SetRedactor(r *Redactor) {
+	logcore.SetRedactor(r)
+}
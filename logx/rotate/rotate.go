@@ -0,0 +1,471 @@
+// Package rotate provides a log file writer with size and time based
+// rotation, file names with date placeholders, compression of rotated files
+// and retention by count, age and total size.
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms of rotated files.
+const (
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+// backupTimeFormat is inserted before the extension of a file renamed by a
+// rotation, as in lumberjack.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// megabyte is a variable so that tests can use small sizes.
+var megabyte int64 = 1 << 20
+
+// Config configures a Writer.
+type Config struct {
+	// Filename is the path of the log file. Its base name may contain the
+	// placeholders %Y, %m, %d, %H, %M and %S, replaced by the time the file
+	// is opened, and %% for a percent sign.
+	Filename string
+	// Schedule rotates the file at the times it returns, see ParseSchedule.
+	// nil rotates by size only.
+	Schedule Schedule
+	// MaxSize is the size in megabytes at which the file is rotated.
+	// 0 rotates by time only.
+	MaxSize int
+	// MaxBackups is the number of rotated files kept. 0 keeps all.
+	MaxBackups int
+	// MaxAge is how long rotated files are kept. 0 keeps them forever.
+	MaxAge time.Duration
+	// MaxTotalSize is the budget in megabytes of the current and rotated
+	// files; the oldest rotated files are removed to stay within it.
+	// 0 means no budget.
+	MaxTotalSize int
+	// Compress is the compression of rotated files, CompressGzip or
+	// CompressZstd; "" leaves them as they are.
+	Compress string
+	// OnRotate is called with the path of each rotated file once it is
+	// compressed, e.g. to upload it. It runs in a background goroutine, one
+	// file at a time, before the retention limits are applied.
+	OnRotate func(path string)
+	// OnError is called with the errors of the background compression and
+	// pruning, in the background goroutine. nil writes them to os.Stderr.
+	OnError func(err error)
+}
+
+// Writer is an io.WriteCloser writing to a rotated log file. The file is
+// opened on the first write, appending to an existing file of the same
+// name. Rotation happens on the write that exceeds MaxSize or that comes at
+// or after the next scheduled time, so an idle file is rotated by the next
+// write. Rotated files are named after the placeholders of Filename; if that
+// does not give a new name, the file is renamed with the rotation time
+// inserted before its extension, e.g. app-2024-01-02T15-04-05.000.log.
+//
+// Rotated files are compressed and pruned in a background goroutine.
+type Writer struct {
+	cfg     Config
+	dir     string
+	pattern string         // base name of Filename
+	backups *regexp.Regexp // matches the base names of rotated files
+	now     func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	name string // path of the current file
+	size int64
+	next time.Time // next scheduled rotation, zero if none
+
+	millJobs []millJob     // rotated files waiting for the background goroutine
+	millWake chan struct{} // nil until the first rotation
+	millDone chan struct{}
+}
+
+// millJob is a rotated file for the background goroutine.
+type millJob struct {
+	rotated string
+	current string // path of the file opened by the rotation
+}
+
+// New returns a Writer configured by cfg.
+func New(cfg Config) (*Writer, error) {
+	switch cfg.Compress {
+	case "", CompressGzip, CompressZstd:
+	default:
+		return nil, fmt.Errorf("rotate: unknown compression %q", cfg.Compress)
+	}
+	w := &Writer{
+		cfg:     cfg,
+		dir:     filepath.Dir(cfg.Filename),
+		pattern: filepath.Base(cfg.Filename),
+		now:     time.Now,
+	}
+	re, err := backupRegexp(w.pattern)
+	if err != nil {
+		return nil, err
+	}
+	w.backups = re
+	return w, nil
+}
+
+// Write writes p to the current file, rotating it first if needed.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	if w.file == nil {
+		if err := w.openLocked(now); err != nil {
+			return 0, err
+		}
+	} else if !w.next.IsZero() && !now.Before(w.next) {
+		if err := w.rotateLocked(now); err != nil {
+			return 0, err
+		}
+	}
+	if limit := int64(w.cfg.MaxSize) * megabyte; limit > 0 && w.size > 0 && w.size+int64(len(p)) > limit {
+		if err := w.rotateLocked(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the current file now.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	if w.file == nil {
+		return w.openLocked(now)
+	}
+	return w.rotateLocked(now)
+}
+
+// Sync commits the current file to stable storage.
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file and waits for the background compression
+// and pruning to finish. Writing after Close opens the file again.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	millWake, millDone := w.millWake, w.millDone
+	w.millWake, w.millDone = nil, nil
+	w.mu.Unlock()
+
+	if millWake != nil {
+		close(millWake)
+		<-millDone
+	}
+	return err
+}
+
+// Filename returns the path of the current file, or "" before the first write.
+func (w *Writer) Filename() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.name
+}
+
+func (w *Writer) openLocked(now time.Time) error {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return err
+	}
+	name := filepath.Join(w.dir, formatName(w.pattern, now))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.name, w.size = f, name, info.Size()
+	w.next = time.Time{}
+	if w.cfg.Schedule != nil {
+		w.next = w.cfg.Schedule.Next(now)
+	}
+	return nil
+}
+
+func (w *Writer) rotateLocked(now time.Time) error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	rotated := w.name
+	if filepath.Join(w.dir, formatName(w.pattern, now)) == w.name {
+		ext := filepath.Ext(w.name)
+		rotated = strings.TrimSuffix(w.name, ext) + "-" + now.Format(backupTimeFormat) + ext
+		if err := os.Rename(w.name, rotated); err != nil {
+			return err
+		}
+	}
+	if err := w.openLocked(now); err != nil {
+		return err
+	}
+
+	// The job is queued without blocking, so that a slow OnRotate or
+	// compression never holds up the writes; one wake-up covers all jobs.
+	w.millJobs = append(w.millJobs, millJob{rotated: rotated, current: w.name})
+	if w.millWake == nil {
+		w.millWake, w.millDone = make(chan struct{}, 1), make(chan struct{})
+		go w.mill(w.millWake, w.millDone)
+	}
+	select {
+	case w.millWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// mill compresses the rotated files, calls OnRotate and applies the retention
+// limits, until wake is closed.
+func (w *Writer) mill(wake <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for range wake {
+		w.mu.Lock()
+		jobs := w.millJobs
+		w.millJobs = nil
+		w.mu.Unlock()
+
+		for _, job := range jobs {
+			path := job.rotated
+			if w.cfg.Compress != "" {
+				if compressed, err := compress(path, w.cfg.Compress); err == nil {
+					path = compressed
+				} else {
+					w.error(fmt.Errorf("rotate: compress %s: %w", path, err))
+				}
+			}
+			if w.cfg.OnRotate != nil {
+				w.cfg.OnRotate(path)
+			}
+			if err := w.prune(job.current); err != nil {
+				w.error(fmt.Errorf("rotate: prune %s: %w", w.dir, err))
+			}
+		}
+	}
+}
+
+// error reports an error of the background goroutine.
+func (w *Writer) error(err error) {
+	if w.cfg.OnError != nil {
+		w.cfg.OnError(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// prune removes the rotated files beyond MaxBackups, MaxAge and MaxTotalSize.
+func (w *Writer) prune(current string) error {
+	if w.cfg.MaxBackups <= 0 && w.cfg.MaxAge <= 0 && w.cfg.MaxTotalSize <= 0 {
+		return nil
+	}
+	files, currentSize, err := w.rotatedFiles(filepath.Base(current))
+	if err != nil {
+		return err
+	}
+
+	var (
+		cutoff = w.now().Add(-w.cfg.MaxAge)
+		budget = int64(w.cfg.MaxTotalSize) * megabyte
+		total  = currentSize
+		errs   []error
+	)
+	for i, f := range files { // newest first
+		total += f.Size()
+		remove := (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) ||
+			(w.cfg.MaxAge > 0 && f.ModTime().Before(cutoff)) ||
+			(budget > 0 && total > budget)
+		if remove {
+			total -= f.Size()
+			if err := os.Remove(filepath.Join(w.dir, f.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rotatedFiles returns the rotated files, newest first, and the size of the
+// current file, called current.
+func (w *Writer) rotatedFiles(current string) ([]fs.FileInfo, int64, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		files       []fs.FileInfo
+		currentSize int64
+	)
+	for _, e := range entries {
+		if e.IsDir() || !w.backups.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if e.Name() == current {
+			currentSize = info.Size()
+			continue
+		}
+		files = append(files, info)
+	}
+	slices.SortFunc(files, func(a, b fs.FileInfo) int {
+		return b.ModTime().Compare(a.ModTime())
+	})
+	return files, currentSize, nil
+}
+
+// placeholders are the supported placeholders and the number of digits they expand to.
+var placeholders = map[byte]int{'Y': 4, 'm': 2, 'd': 2, 'H': 2, 'M': 2, 'S': 2}
+
+// formatName replaces the placeholders of pattern with t.
+func formatName(pattern string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
+
+// backupRegexp returns the regexp matching the files a Writer with pattern
+// creates: the names pattern expands to, optionally followed by a rotation
+// time, and optionally compressed. For patterns without placeholders the
+// rotation time is required, except for the current file itself.
+func backupRegexp(pattern string) (*regexp.Regexp, error) {
+	ext := filepath.Ext(pattern)
+	if strings.Contains(ext, "%") {
+		ext = ""
+	}
+	var (
+		stem    = pattern[:len(pattern)-len(ext)]
+		re      strings.Builder
+		dynamic bool
+	)
+	re.WriteByte('^')
+	for i := 0; i < len(stem); i++ {
+		if stem[i] != '%' {
+			re.WriteString(regexp.QuoteMeta(stem[i : i+1]))
+			continue
+		}
+		if i+1 == len(stem) {
+			return nil, fmt.Errorf("rotate: %q ends with %%", pattern)
+		}
+		i++
+		if stem[i] == '%' {
+			re.WriteString("%")
+			continue
+		}
+		n, ok := placeholders[stem[i]]
+		if !ok {
+			return nil, fmt.Errorf("rotate: unknown placeholder %%%c in %q", stem[i], pattern)
+		}
+		fmt.Fprintf(&re, `\d{%d}`, n)
+		dynamic = true
+	}
+	re.WriteString(`(-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3})`)
+	if dynamic {
+		re.WriteByte('?')
+	}
+	re.WriteString(regexp.QuoteMeta(ext))
+	re.WriteString(`(\.gz|\.zst)?$`)
+	if !dynamic {
+		// The current file has no rotation time.
+		return regexp.Compile(fmt.Sprintf("%s|^%s$", re.String(), regexp.QuoteMeta(pattern)))
+	}
+	return regexp.Compile(re.String())
+}
+
+// compress compresses path with algo into a new file and removes path.
+func compress(path, algo string) (string, error) {
+	dst := path + ".gz"
+	if algo == CompressZstd {
+		dst = path + ".zst"
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+	var zw io.WriteCloser
+	if algo == CompressZstd {
+		if zw, err = zstd.NewWriter(out); err != nil {
+			out.Close()
+			os.Remove(dst)
+			return "", err
+		}
+	} else {
+		zw = gzip.NewWriter(out)
+	}
+
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return "", err
+	}
+	src.Close()
+	return dst, os.Remove(path)
+}
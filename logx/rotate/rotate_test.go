@@ -0,0 +1,328 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Sizes in the tests are in bytes.
+	megabyte = 1
+}
+
+func TestParseSchedule(t *testing.T) {
+	t.Run("ParseSchedule 测试", func(t *testing.T) {
+		base := time.Date(2024, 1, 31, 22, 30, 15, 0, time.UTC) // Wednesday
+		for spec, want := range map[string]time.Time{
+			"hourly":         time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC),
+			"@daily":         time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			"*/20 * * * *":   time.Date(2024, 1, 31, 22, 40, 0, 0, time.UTC),
+			"0 */6 * * *":    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			"15 8-9 * * *":   time.Date(2024, 2, 1, 8, 15, 0, 0, time.UTC),
+			"0 0 * * 1":      time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+			"0 0 * * 7":      time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC),
+			"0 0 1 * *":      time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			"0 0 29 2 *":     time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			"0 0 15 * 5":     time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), // day 15 or Friday
+			"30,45 22 * * *": time.Date(2024, 1, 31, 22, 45, 0, 0, time.UTC),
+		} {
+			s, err := ParseSchedule(spec)
+			require.Nil(t, err, spec)
+			assert.Equal(t, want, s.Next(base), spec)
+		}
+
+		s, err := ParseSchedule("0 0 30 2 *")
+		require.Nil(t, err)
+		assert.True(t, s.Next(base).IsZero())
+
+		for _, spec := range []string{"", "weekly", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+			_, err := ParseSchedule(spec)
+			assert.Error(t, err, spec)
+		}
+	})
+}
+
+// clock is a settable time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func newWriter(t *testing.T, cfg Config, c *clock) *Writer {
+	w, err := New(cfg)
+	require.Nil(t, err)
+	w.now = c.Now
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func names(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	var res []string
+	for _, e := range entries {
+		res = append(res, e.Name())
+	}
+	slices.Sort(res)
+	return res
+}
+
+func read(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(data)
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("Writer 测试", func(t *testing.T) {
+		t.Run("按大小轮转并重命名", func(t *testing.T) {
+			dir := t.TempDir()
+			c := &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+			w := newWriter(t, Config{Filename: filepath.Join(dir, "app.log"), MaxSize: 10}, c)
+
+			_, err := w.Write([]byte("12345678\n"))
+			require.Nil(t, err)
+			_, err = w.Write([]byte("abc\n"))
+			require.Nil(t, err)
+			require.Nil(t, w.Close())
+
+			assert.Equal(t, []string{"app-2024-01-02T03-04-05.000.log", "app.log"}, names(t, dir))
+			assert.Equal(t, "12345678\n", read(t, filepath.Join(dir, "app-2024-01-02T03-04-05.000.log")))
+			assert.Equal(t, "abc\n", read(t, filepath.Join(dir, "app.log")))
+		})
+
+		t.Run("按时间轮转并使用日期文件名", func(t *testing.T) {
+			dir := t.TempDir()
+			daily, _ := ParseSchedule("daily")
+			c := &clock{now: time.Date(2024, 1, 2, 23, 59, 0, 0, time.UTC)}
+			var rotated []string
+			w := newWriter(t, Config{
+				Filename: filepath.Join(dir, "app-%Y%m%d.log"),
+				Schedule: daily,
+				OnRotate: func(path string) { rotated = append(rotated, path) },
+			}, c)
+
+			_, _ = w.Write([]byte("day 1\n"))
+			assert.Equal(t, filepath.Join(dir, "app-20240102.log"), w.Filename())
+			c.Set(time.Date(2024, 1, 3, 0, 0, 1, 0, time.UTC))
+			_, _ = w.Write([]byte("day 2\n"))
+			assert.Equal(t, filepath.Join(dir, "app-20240103.log"), w.Filename())
+			require.Nil(t, w.Close())
+
+			assert.Equal(t, []string{"app-20240102.log", "app-20240103.log"}, names(t, dir))
+			assert.Equal(t, "day 1\n", read(t, filepath.Join(dir, "app-20240102.log")))
+			assert.Equal(t, []string{filepath.Join(dir, "app-20240102.log")}, rotated)
+		})
+
+		t.Run("重启后追加到已有文件", func(t *testing.T) {
+			dir := t.TempDir()
+			c := &clock{now: time.Now()}
+			for _, line := range []string{"a\n", "b\n"} {
+				w := newWriter(t, Config{Filename: filepath.Join(dir, "app.log")}, c)
+				_, _ = w.Write([]byte(line))
+				require.Nil(t, w.Close())
+			}
+			assert.Equal(t, "a\nb\n", read(t, filepath.Join(dir, "app.log")))
+		})
+
+		for algo, decode := range map[string]func(io.Reader) (io.Reader, error){
+			CompressGzip: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+			CompressZstd: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+		} {
+			t.Run("压缩 "+algo, func(t *testing.T) {
+				dir := t.TempDir()
+				c := &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+				var rotated []string
+				w := newWriter(t, Config{
+					Filename: filepath.Join(dir, "app.log"),
+					Compress: algo,
+					OnRotate: func(path string) { rotated = append(rotated, path) },
+				}, c)
+				_, _ = w.Write([]byte("old\n"))
+				require.Nil(t, w.Rotate())
+				_, _ = w.Write([]byte("new\n"))
+				require.Nil(t, w.Close())
+
+				ext := map[string]string{CompressGzip: ".gz", CompressZstd: ".zst"}[algo]
+				backup := filepath.Join(dir, "app-2024-01-02T03-04-05.000.log"+ext)
+				assert.Equal(t, []string{filepath.Base(backup), "app.log"}, names(t, dir))
+				assert.Equal(t, []string{backup}, rotated)
+
+				f, err := os.Open(backup)
+				require.Nil(t, err)
+				defer f.Close()
+				r, err := decode(f)
+				require.Nil(t, err)
+				data, err := io.ReadAll(r)
+				require.Nil(t, err)
+				assert.Equal(t, "old\n", string(data))
+			})
+		}
+
+		t.Run("保留数量与总大小上限", func(t *testing.T) {
+			dir := t.TempDir()
+			start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			c := &clock{now: start}
+			w := newWriter(t, Config{Filename: filepath.Join(dir, "app.log"), MaxBackups: 3, MaxTotalSize: 25}, c)
+			// unrelated files are kept
+			require.Nil(t, os.WriteFile(filepath.Join(dir, "app-debug-2020-01-01T00-00-00.000.log"), nil, 0o644))
+			require.Nil(t, os.WriteFile(filepath.Join(dir, "other.log"), nil, 0o644))
+
+			for i := range 5 {
+				c.Set(start.Add(time.Duration(i) * time.Second))
+				_, _ = w.Write([]byte("0123456789"))
+				require.Nil(t, w.Rotate())
+				// rotated files are ordered by modification time
+				_ = os.Chtimes(filepath.Join(dir, "app-"+c.Now().Format(backupTimeFormat)+".log"), c.Now(), c.Now())
+			}
+			_, _ = w.Write([]byte("0123456789"))
+			require.Nil(t, w.Close())
+
+			// 3 backups would be 40 bytes with the current file: the budget keeps 1.
+			assert.Equal(t, []string{
+				"app-2024-01-02T03-04-09.000.log",
+				"app-debug-2020-01-01T00-00-00.000.log",
+				"app.log",
+				"other.log",
+			}, names(t, dir))
+		})
+
+		t.Run("按时间清理", func(t *testing.T) {
+			dir := t.TempDir()
+			c := &clock{now: time.Now()}
+			w := newWriter(t, Config{Filename: filepath.Join(dir, "app-%Y%m%d%H%M%S.log"), MaxAge: time.Hour}, c)
+			stale := filepath.Join(dir, "app-20200101000000.log.gz")
+			require.Nil(t, os.WriteFile(stale, nil, 0o644))
+			_ = os.Chtimes(stale, c.Now().Add(-2*time.Hour), c.Now().Add(-2*time.Hour))
+
+			_, _ = w.Write([]byte("a\n"))
+			c.Set(c.Now().Add(time.Second))
+			require.Nil(t, w.Rotate())
+			require.Nil(t, w.Close())
+
+			_, err := os.Stat(stale)
+			assert.True(t, os.IsNotExist(err))
+			assert.Len(t, names(t, dir), 2)
+		})
+
+		t.Run("慢 OnRotate 不阻塞写入", func(t *testing.T) {
+			dir := t.TempDir()
+			start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			c := &clock{now: start}
+			var (
+				release = make(chan struct{})
+				mu      sync.Mutex
+				rotated int
+			)
+			w := newWriter(t, Config{
+				Filename: filepath.Join(dir, "app.log"),
+				OnRotate: func(string) {
+					<-release
+					mu.Lock()
+					rotated++
+					mu.Unlock()
+				},
+			}, c)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := range 200 {
+					c.Set(start.Add(time.Duration(i) * time.Millisecond))
+					_, _ = w.Write([]byte("a\n"))
+					_ = w.Rotate()
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Rotate blocked on the background goroutine")
+			}
+
+			close(release)
+			require.Nil(t, w.Close())
+			assert.Equal(t, 200, rotated)
+		})
+
+		t.Run("后台错误交给 OnError", func(t *testing.T) {
+			dir := t.TempDir()
+			c := &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+			var (
+				errs    []error
+				rotated []string
+			)
+			w := newWriter(t, Config{
+				Filename: filepath.Join(dir, "app.log"),
+				Compress: CompressGzip,
+				OnRotate: func(path string) { rotated = append(rotated, path) },
+				OnError:  func(err error) { errs = append(errs, err) },
+			}, c)
+			backup := filepath.Join(dir, "app-2024-01-02T03-04-05.000.log")
+			// A directory in the way of the compressed file.
+			require.Nil(t, os.Mkdir(backup+".gz", 0o755))
+
+			_, _ = w.Write([]byte("old\n"))
+			require.Nil(t, w.Rotate())
+			require.Nil(t, w.Close())
+
+			require.Len(t, errs, 1)
+			assert.ErrorContains(t, errs[0], "rotate: compress "+backup)
+			assert.Equal(t, []string{backup}, rotated)
+		})
+
+		t.Run("配置错误", func(t *testing.T) {
+			_, err := New(Config{Filename: "app.log", Compress: "lz4"})
+			assert.Error(t, err)
+			_, err = New(Config{Filename: "app-%q.log"})
+			assert.Error(t, err)
+			_, err = New(Config{Filename: "app-%"})
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestBackupRegexp(t *testing.T) {
+	t.Run("backupRegexp 测试", func(t *testing.T) {
+		re, err := backupRegexp("app-%Y%m%d.log")
+		require.Nil(t, err)
+		for name, want := range map[string]bool{
+			"app-20240102.log":                               true,
+			"app-20240102.log.gz":                            true,
+			"app-20240102-2024-01-02T03-04-05.000.log.zst":   true,
+			"app-20240102-error.log":                         false,
+			"app-2024010.log":                                false,
+			"app-20240102-error-2024-01-02T03-04-05.000.log": false,
+			"app-20240102.log.bak":                           false,
+		} {
+			assert.Equal(t, want, re.MatchString(name), name)
+		}
+
+		re, err = backupRegexp("app.log")
+		require.Nil(t, err)
+		assert.True(t, re.MatchString("app.log"))
+		assert.True(t, re.MatchString("app-2024-01-02T03-04-05.000.log.gz"))
+		assert.False(t, re.MatchString("app-error.log"))
+	})
+}
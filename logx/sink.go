@@ -0,0 +1,31 @@
+package logx
+
+import "github.com/BYT0723/go-tools/logx/logcore"
+
+type (
+	Sink     = logcore.Sink
+	SinkConf = logcore.SinkConf
+)
+
+const (
+	SinkSyslog        = logcore.SinkSyslog
+	SinkJournald      = logcore.SinkJournald
+	SinkLoki          = logcore.SinkLoki
+	SinkElasticsearch = logcore.SinkElasticsearch
+	SinkTCP           = logcore.SinkTCP
+)
+
+// WithSinks adds remote outputs to the files and the console, each with its
+// own level.
+//
+// Example:
+//
+//	logx.Init(logx.WithSinks(
+//		logx.SinkConf{Type: logx.SinkLoki, Addr: "http://loki:3100/loki/api/v1/push", Labels: map[string]string{"app": "api"}},
+//		logx.SinkConf{Type: logx.SinkSyslog, Network: "unix", Level: "warn"},
+//	))
+func WithSinks(sinks ...SinkConf) Option {
+	return func(cfg *InitConf) {
+		cfg.LogCfg.Sinks = append(cfg.LogCfg.Sinks, sinks...)
+	}
+}
@@ -0,0 +1,157 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+// slogHandler is a slog.Handler that writes records into a Logger.
+type slogHandler struct {
+	logger Logger
+	opts   slog.HandlerOptions
+	// fields collects WithAttrs, already prefixed with their groups.
+	fields []Field
+	// groups are the names passed to WithGroup.
+	groups []string
+}
+
+// NewSlogHandler returns a slog.Handler that writes into l, so that
+// libraries logging through log/slog end up in the same output as logx.
+//
+// Attributes become Fields; groups are flattened into dotted keys
+// ("req.method"). The caller recorded by slog is kept: l reports the code that
+// called the slog.Logger, not the handler. slog levels are rounded down to
+// debug, info, warn or error. Fields extracted from the context passed to
+// the *Context methods of slog.Logger are added as with LogCtx. Of opts,
+// Level and ReplaceAttr are honored; opts may be nil.
+//
+// Example:
+//
+//	slog.SetDefault(slog.New(logx.NewSlogHandler(logx.Default(), nil)))
+func NewSlogHandler(l Logger, opts *slog.HandlerOptions) slog.Handler {
+	h := &slogHandler{logger: l}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *slogHandler) Enabled(_ context.Context, lv slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return lv >= minLevel
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := slices.Clip(h.fields)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = h.appendAttr(fields, h.groups, a)
+		return true
+	})
+
+	level := "debug"
+	switch {
+	case r.Level >= slog.LevelError:
+		level = "error"
+	case r.Level >= slog.LevelWarn:
+		level = "warn"
+	case r.Level >= slog.LevelInfo:
+		level = "info"
+	}
+	h.logger.AddCallerSkip(callerDepth(r.PC)).LogCtx(ctx, level, r.Message, fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	c := *h
+	c.fields = slices.Clip(h.fields)
+	for _, a := range attrs {
+		c.fields = h.appendAttr(c.fields, h.groups, a)
+	}
+	return &c
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.groups = append(slices.Clip(h.groups), name)
+	return &c
+}
+
+// appendAttr appends a as fields, flattening groups into dotted keys.
+func (h *slogHandler) appendAttr(fields []Field, groups []string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			fields = h.appendAttr(fields, groups, ga)
+		}
+		return fields
+	}
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	switch a.Value.Kind() {
+	case slog.KindBool:
+		return append(fields, Bool(key, a.Value.Bool()))
+	case slog.KindInt64:
+		return append(fields, Int64(key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, Uint64(key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, Float64(key, a.Value.Float64()))
+	case slog.KindString:
+		return append(fields, String(key, a.Value.String()))
+	case slog.KindDuration:
+		return append(fields, Duration(key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, Time(key, a.Value.Time()))
+	default:
+		if err, ok := a.Value.Any().(error); ok {
+			return append(fields, Field{Key: key, Kind: logcore.KindError, Value: err})
+		}
+		return append(fields, Any(key, a.Value.Any()))
+	}
+}
+
+// callerDepth returns the number of frames between the caller of Handle and
+// the frame slog recorded as pc, or 0 if pc is not on the stack.
+func callerDepth(pc uintptr) int {
+	if pc == 0 {
+		return 0
+	}
+	var pcs [32]uintptr
+	// Skip runtime.Callers, callerDepth and Handle.
+	n := runtime.Callers(3, pcs[:])
+	for i, p := range pcs[:n] {
+		if p == pc {
+			return i
+		}
+	}
+	return 0
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
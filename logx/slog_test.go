@@ -0,0 +1,143 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newJSONLogger returns a TypeSlog Logger writing JSON with source info into buf.
+func newJSONLogger(t *testing.T, buf *bytes.Buffer) Logger {
+	l, err := NewLogger(WithSlogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelDebug,
+	})))
+	require.NoError(t, err)
+	return l
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var res []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		res = append(res, m)
+	}
+	return res
+}
+
+func TestSlogBackend(t *testing.T) {
+	t.Run("TypeSlog 后端测试", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := newJSONLogger(t, &buf).AddCallerSkip(-1)
+
+		l.With(String("svc", "api")).Info("hello", Int("n", 1), Bool("ok", true))
+		_, _, line, _ := runtime.Caller(0)
+		l.Warnf("x=%d", 2)
+
+		lines := decodeLines(t, &buf)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "INFO", lines[0]["level"])
+		assert.Equal(t, "hello", lines[0]["msg"])
+		assert.Equal(t, "api", lines[0]["svc"])
+		assert.Equal(t, float64(1), lines[0]["n"])
+		assert.Equal(t, true, lines[0]["ok"])
+		src := lines[0]["source"].(map[string]any)
+		assert.True(t, strings.HasSuffix(src["file"].(string), "slog_test.go"))
+		assert.Equal(t, float64(line-1), src["line"])
+		assert.Equal(t, "x=2", lines[1]["msg"])
+	})
+
+	t.Run("默认 handler 写入文件", func(t *testing.T) {
+		l, err := NewLogger(WithLoggerType(TypeSlog), WithPath(t.TempDir()), WithLevel("info"))
+		require.NoError(t, err)
+		assert.NotPanics(t, func() { l.Info("file") })
+		assert.Panics(t, func() { l.Panic("boom") })
+	})
+
+	t.Run("无效 level 返回错误", func(t *testing.T) {
+		_, err := NewLogger(WithLoggerType(TypeSlog), WithLevel("verbose"))
+		assert.Error(t, err)
+	})
+}
+
+func TestSlogHandler(t *testing.T) {
+	t.Run("slog.Handler 桥接测试", func(t *testing.T) {
+		t.Run("属性, 分组与调用者", func(t *testing.T) {
+			var buf bytes.Buffer
+			sl := slog.New(NewSlogHandler(newJSONLogger(t, &buf), &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			sl.With("app", "demo").WithGroup("req").Debug("served",
+				"method", "GET",
+				slog.Int("status", 200),
+				slog.Group("user", "id", uint64(7)),
+			)
+			_, _, line, _ := runtime.Caller(0)
+
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 1)
+			m := lines[0]
+			assert.Equal(t, "DEBUG", m["level"])
+			assert.Equal(t, "served", m["msg"])
+			assert.Equal(t, "demo", m["app"])
+			assert.Equal(t, "GET", m["req.method"])
+			assert.Equal(t, float64(200), m["req.status"])
+			assert.Equal(t, float64(7), m["req.user.id"])
+			src := m["source"].(map[string]any)
+			assert.True(t, strings.HasSuffix(src["file"].(string), "slog_test.go"))
+			assert.Equal(t, float64(line-5), src["line"])
+		})
+
+		t.Run("级别过滤与映射", func(t *testing.T) {
+			var buf bytes.Buffer
+			sl := slog.New(NewSlogHandler(newJSONLogger(t, &buf), nil))
+			sl.Debug("dropped")
+			sl.Log(t.Context(), slog.LevelWarn+1, "warn+1")
+			sl.Log(t.Context(), slog.LevelError+4, "error+4")
+
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 2)
+			assert.Equal(t, "WARN", lines[0]["level"])
+			assert.Equal(t, "ERROR", lines[1]["level"])
+		})
+
+		t.Run("ReplaceAttr", func(t *testing.T) {
+			var buf bytes.Buffer
+			sl := slog.New(NewSlogHandler(newJSONLogger(t, &buf), &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == "password" {
+						return slog.Attr{}
+					}
+					if len(groups) == 1 && groups[0] == "g" {
+						a.Key = strings.ToUpper(a.Key)
+					}
+					return a
+				},
+			}))
+			sl.Info("login", "user", "bob", "password", "secret", slog.Group("g", "k", "v"))
+
+			m := decodeLines(t, &buf)[0]
+			assert.Equal(t, "bob", m["user"])
+			assert.NotContains(t, m, "password")
+			assert.Equal(t, "v", m["g.K"])
+		})
+
+		t.Run("错误属性", func(t *testing.T) {
+			var buf bytes.Buffer
+			sl := slog.New(NewSlogHandler(newJSONLogger(t, &buf), nil))
+			sl.Error("failed", "err", fmt.Errorf("load: %w", errors.New("not found")))
+
+			m := decodeLines(t, &buf)[0]
+			assert.Equal(t, "load: not found", m["err"])
+			assert.Len(t, m["errChain"], 2)
+		})
+	})
+}
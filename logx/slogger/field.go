@@ -0,0 +1,88 @@
+package slogger
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+// stringerValue calls String only when the record is handled.
+type stringerValue struct {
+	s fmt.Stringer
+}
+
+func (v stringerValue) LogValue() slog.Value {
+	return slog.StringValue(v.s.String())
+}
+
+// objectAttrs returns the attributes of m as a group.
+func objectAttrs(key string, m logcore.ObjectMarshaler) ([]slog.Attr, error) {
+	var enc objectEncoder
+	err := m.MarshalLogObject(&enc)
+	return []slog.Attr{{Key: key, Value: slog.GroupValue(enc...)}}, err
+}
+
+// arrayAttrs returns the elements of m as a slice.
+func arrayAttrs(key string, m logcore.ArrayMarshaler) ([]slog.Attr, error) {
+	var enc arrayEncoder
+	err := m.MarshalLogArray(&enc)
+	return []slog.Attr{slog.Any(key, []any(enc))}, err
+}
+
+type objectEncoder []slog.Attr
+
+func (e *objectEncoder) AddString(key, value string)        { *e = append(*e, slog.String(key, value)) }
+func (e *objectEncoder) AddBool(key string, value bool)     { *e = append(*e, slog.Bool(key, value)) }
+func (e *objectEncoder) AddInt(key string, value int)       { *e = append(*e, slog.Int(key, value)) }
+func (e *objectEncoder) AddInt64(key string, value int64)   { *e = append(*e, slog.Int64(key, value)) }
+func (e *objectEncoder) AddUint64(key string, value uint64) { *e = append(*e, slog.Uint64(key, value)) }
+func (e *objectEncoder) AddFloat64(key string, value float64) {
+	*e = append(*e, slog.Float64(key, value))
+}
+func (e *objectEncoder) AddDuration(key string, value time.Duration) {
+	*e = append(*e, slog.Duration(key, value))
+}
+func (e *objectEncoder) AddTime(key string, value time.Time) { *e = append(*e, slog.Time(key, value)) }
+func (e *objectEncoder) AddObject(key string, value logcore.ObjectMarshaler) error {
+	attrs, err := objectAttrs(key, value)
+	*e = append(*e, attrs...)
+	return err
+}
+func (e *objectEncoder) AddArray(key string, value logcore.ArrayMarshaler) error {
+	attrs, err := arrayAttrs(key, value)
+	*e = append(*e, attrs...)
+	return err
+}
+
+// arrayEncoder collects the elements; objects become maps, as slog has no
+// array of groups.
+type arrayEncoder []any
+
+func (e *arrayEncoder) AppendString(value string)          { *e = append(*e, value) }
+func (e *arrayEncoder) AppendBool(value bool)              { *e = append(*e, value) }
+func (e *arrayEncoder) AppendInt(value int)                { *e = append(*e, value) }
+func (e *arrayEncoder) AppendInt64(value int64)            { *e = append(*e, value) }
+func (e *arrayEncoder) AppendUint64(value uint64)          { *e = append(*e, value) }
+func (e *arrayEncoder) AppendFloat64(value float64)        { *e = append(*e, value) }
+func (e *arrayEncoder) AppendDuration(value time.Duration) { *e = append(*e, value) }
+func (e *arrayEncoder) AppendTime(value time.Time)         { *e = append(*e, value) }
+func (e *arrayEncoder) AppendObject(value logcore.ObjectMarshaler) error {
+	var enc objectEncoder
+	err := value.MarshalLogObject(&enc)
+	*e = append(*e, groupMap(enc))
+	return err
+}
+
+func groupMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			m[a.Key] = groupMap(a.Value.Group())
+		} else {
+			m[a.Key] = a.Value.Any()
+		}
+	}
+	return m
+}
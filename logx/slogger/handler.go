@@ -0,0 +1,144 @@
+package slogger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Levels beyond slog.LevelError, used by Panic and Fatal.
+const (
+	LevelPanic slog.Level = slog.LevelError + 4
+	LevelFatal slog.Level = slog.LevelError + 8
+)
+
+// ParseLevel parses a logx level name (debug, info, warn, error, panic, fatal).
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "panic", "dpanic":
+		return LevelPanic, nil
+	case "fatal":
+		return LevelFatal, nil
+	default:
+		return 0, errors.New("unknown level: " + level)
+	}
+}
+
+// levelName returns the lower-case name of lv, as used by the zap and zerolog backends.
+func levelName(lv slog.Level) string {
+	switch {
+	case lv >= LevelFatal:
+		return "fatal"
+	case lv >= LevelPanic:
+		return "panic"
+	case lv >= slog.LevelError:
+		return "error"
+	case lv >= slog.LevelWarn:
+		return "warn"
+	case lv >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// replaceAttr renames the built-in attributes so that files written by the
+// slog backend share their layout with the zap and zerolog backends.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String("timestamp", a.Value.Time().Format(time.RFC3339Nano))
+	case slog.LevelKey:
+		lv, _ := a.Value.Any().(slog.Level)
+		return slog.String(slog.LevelKey, levelName(lv))
+	case slog.SourceKey:
+		src, ok := a.Value.Any().(*slog.Source)
+		if !ok {
+			return a
+		}
+		return slog.String("caller", shortCaller(src.File, src.Line))
+	}
+	return a
+}
+
+// shortCaller trims file to its last directory and base name, like "logx/export.go:42".
+func shortCaller(file string, line int) string {
+	if idx := strings.LastIndexByte(file, '/'); idx != -1 {
+		if idx = strings.LastIndexByte(file[:idx], '/'); idx != -1 {
+			file = file[idx+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(line)
+}
+
+// levelHandler passes on only the records whose level is accepted by filter.
+type levelHandler struct {
+	slog.Handler
+	filter func(slog.Level) bool
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, lv slog.Level) bool {
+	return h.filter(lv) && h.Handler.Enabled(ctx, lv)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), filter: h.filter}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), filter: h.filter}
+}
+
+// multiHandler sends each record to every handler that is enabled for it.
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, lv slog.Level) bool {
+	for _, sub := range h {
+		if sub.Enabled(ctx, lv) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, sub := range h {
+		if sub.Enabled(ctx, r.Level) {
+			if err := sub.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make(multiHandler, len(h))
+	for i, sub := range h {
+		res[i] = sub.WithAttrs(attrs)
+	}
+	return res
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	res := make(multiHandler, len(h))
+	for i, sub := range h {
+		res[i] = sub.WithGroup(name)
+	}
+	return res
+}
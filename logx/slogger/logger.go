@@ -0,0 +1,488 @@
+package slogger
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+)
+
+type slogLogger struct {
+	handler slog.Handler
+	skip    int
+	level   *slog.LevelVar
+	name    string
+	sampler *logcore.Sampler
+	async   logcore.AsyncWriters
+	sinks   logcore.Sinks
+	// stackLevel is the lowest level logged with a stack trace, noStack for none.
+	stackLevel slog.Level
+	// ns is the namespace left open by With, written before the attributes of each record.
+	ns []logcore.Field
+}
+
+// frames between runtime.Callers and the caller of the package-level logx functions:
+// runtime.Callers, log, the Logger method and the logx wrapper.
+var defaultCallerSkip = 4
+
+// levels are the levels of the per-level outputs.
+var levels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, LevelPanic, LevelFatal}
+
+// minLevel lets every record through a handler.
+const minLevel = slog.Level(-1 << 10)
+
+// noStack is above every level, disabling stack traces.
+const noStack = slog.Level(1 << 10)
+
+// exit is replaced in tests.
+var exit = os.Exit
+
+func NewInstance(cfg *logcore.LoggerConf) (ins *slogLogger, err error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	stackLevel := noStack
+	if cfg.StackLevel != "" {
+		if stackLevel, err = ParseLevel(cfg.StackLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		handlers multiHandler
+		async    logcore.AsyncWriters
+		sinks    logcore.Sinks
+	)
+
+	// The handlers accept every level; the level of each logger is checked in log.
+	if !cfg.Multi {
+		h, err := newFileHandler(cfg, cfg.FileName(""), func(slog.Level) bool { return true }, &async)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, h)
+	} else {
+		for _, lv := range levels {
+			targetLevel := lv
+			h, err := newFileHandler(cfg, cfg.FileName(levelName(targetLevel)), func(l slog.Level) bool { return levelName(l) == levelName(targetLevel) }, &async)
+			if err != nil {
+				_ = async.Close()
+				return nil, err
+			}
+			handlers = append(handlers, h)
+		}
+	}
+
+	if cfg.Console {
+		handlers = append(handlers, slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+			AddSource:   true,
+			Level:       minLevel,
+			ReplaceAttr: replaceAttr,
+		}))
+	}
+
+	// Each sink filters by its own level, on top of the level of the logger.
+	if err := sinks.Open(cfg); err != nil {
+		_ = async.Close()
+		_ = sinks.Close()
+		return nil, err
+	}
+	for i, sink := range sinks {
+		sinkHandlers, err := newSinkHandlers(sink, cfg.Sinks[i].Level)
+		if err != nil {
+			_ = async.Close()
+			_ = sinks.Close()
+			return nil, err
+		}
+		handlers = append(handlers, sinkHandlers...)
+	}
+
+	ins = NewWithHandler(handlers)
+	ins.level.Set(level)
+	ins.async = async
+	ins.sinks = sinks
+	ins.stackLevel = stackLevel
+	ins.sampler = logcore.NewSampler(cfg, func(level, msg string, repeated int) {
+		lv, _ := ParseLevel(level)
+		r := slog.NewRecord(time.Now(), lv, logcore.SummaryMessage(msg, repeated), 0)
+		r.AddAttrs(slog.Int("repeated", repeated))
+		_ = handlers.Handle(context.Background(), r)
+	})
+	return ins, nil
+}
+
+// NewWithHandler returns a Logger that writes records to h.
+// Source information is recorded for handlers created with AddSource.
+// The level of the Logger starts at debug; h may filter further.
+func NewWithHandler(h slog.Handler) *slogLogger {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelDebug)
+	return &slogLogger{handler: h, level: level, stackLevel: noStack}
+}
+
+func newFileHandler(cfg *logcore.LoggerConf, filename string, filter func(slog.Level) bool, async *logcore.AsyncWriters) (slog.Handler, error) {
+	fw, err := logcore.NewFileWriter(cfg, filename)
+	if err != nil {
+		return nil, err
+	}
+	w, err := async.Wrap(fw, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newJSONHandler(w, filter), nil
+}
+
+// newSinkHandlers returns the handlers passing the records of level min and
+// above to sink, one per level so that the sink knows the level of each record.
+func newSinkHandlers(sink logcore.Sink, min string) ([]slog.Handler, error) {
+	minLevel := slog.LevelDebug
+	if min != "" {
+		var err error
+		if minLevel, err = ParseLevel(min); err != nil {
+			return nil, err
+		}
+	}
+	var handlers []slog.Handler
+	for _, lv := range levels {
+		if lv < minLevel {
+			continue
+		}
+		targetLevel := lv
+		handlers = append(handlers, newJSONHandler(
+			logcore.SinkWriter(sink, levelName(targetLevel)),
+			func(l slog.Level) bool { return levelName(l) == levelName(targetLevel) },
+		))
+	}
+	return handlers, nil
+}
+
+func newJSONHandler(w io.Writer, filter func(slog.Level) bool) slog.Handler {
+	return &levelHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
+			AddSource:   true,
+			Level:       minLevel, // filtering is done by filter
+			ReplaceAttr: replaceAttr,
+		}),
+		filter: filter,
+	}
+}
+
+func (l *slogLogger) With(kvs ...logcore.Field) logcore.Logger {
+	c := *l
+	kvs, c.ns = logcore.WithNamespace(l.ns, logcore.Redact(kvs))
+	c.handler = l.handler.WithAttrs(attrs(kvs))
+	return &c
+}
+
+func (l *slogLogger) Debug(msg string, kvs ...logcore.Field) {
+	l.log(context.Background(), slog.LevelDebug, msg, kvs...)
+}
+
+func (l *slogLogger) Debugf(format string, args ...any) {
+	l.logf(slog.LevelDebug, format, args...)
+}
+
+func (l *slogLogger) Info(msg string, kvs ...logcore.Field) {
+	l.log(context.Background(), slog.LevelInfo, msg, kvs...)
+}
+
+func (l *slogLogger) Infof(format string, args ...any) {
+	l.logf(slog.LevelInfo, format, args...)
+}
+
+func (l *slogLogger) Warn(msg string, kvs ...logcore.Field) {
+	l.log(context.Background(), slog.LevelWarn, msg, kvs...)
+}
+
+func (l *slogLogger) Warnf(format string, args ...any) {
+	l.logf(slog.LevelWarn, format, args...)
+}
+
+func (l *slogLogger) Error(msg string, kvs ...logcore.Field) {
+	l.log(context.Background(), slog.LevelError, msg, kvs...)
+}
+
+func (l *slogLogger) Errorf(format string, args ...any) {
+	l.logf(slog.LevelError, format, args...)
+}
+
+func (l *slogLogger) Panic(msg string, kvs ...logcore.Field) {
+	l.log(context.Background(), LevelPanic, msg, kvs...)
+	panic(msg)
+}
+
+func (l *slogLogger) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(context.Background(), LevelPanic, msg)
+	panic(msg)
+}
+
+func (l *slogLogger) Fatal(msg string, kvs ...logcore.Field) {
+	l.log(context.Background(), LevelFatal, msg, kvs...)
+	exit(1)
+}
+
+func (l *slogLogger) Fatalf(format string, args ...any) {
+	l.logf(LevelFatal, format, args...)
+	exit(1)
+}
+
+func (l *slogLogger) Log(level string, msg string, kvs ...logcore.Field) {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		lv = slog.LevelDebug
+	}
+	l.log(context.Background(), lv, msg, kvs...)
+}
+
+func (l *slogLogger) Logf(level, format string, args ...any) {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		lv = slog.LevelDebug
+	}
+	l.logf(lv, format, args...)
+}
+
+func (l *slogLogger) DebugCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(ctx, slog.LevelDebug, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *slogLogger) InfoCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(ctx, slog.LevelInfo, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *slogLogger) WarnCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(ctx, slog.LevelWarn, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *slogLogger) ErrorCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(ctx, slog.LevelError, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *slogLogger) PanicCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(ctx, LevelPanic, msg, logcore.ContextFields(ctx, kvs...)...)
+	panic(msg)
+}
+
+func (l *slogLogger) FatalCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(ctx, LevelFatal, msg, logcore.ContextFields(ctx, kvs...)...)
+	exit(1)
+}
+
+func (l *slogLogger) LogCtx(ctx context.Context, level string, msg string, kvs ...logcore.Field) {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		lv = slog.LevelDebug
+	}
+	l.log(ctx, lv, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *slogLogger) Sync() error {
+	l.sampler.Flush()
+	err := l.async.Sync()
+	if serr := l.sinks.Sync(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Close writes the lines buffered by the async writers and the sinks of the
+// instance and stops them; the instance keeps logging synchronously.
+func (l *slogLogger) Close() error {
+	l.sampler.Flush()
+	err := l.async.Close()
+	if serr := l.sinks.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Dropped returns the number of lines the async writers of the instance dropped.
+func (l *slogLogger) Dropped() uint64 {
+	return l.async.Dropped()
+}
+
+func (l *slogLogger) AddCallerSkip(skip int) logcore.Logger {
+	c := *l
+	c.skip += skip
+	return &c
+}
+
+func (l *slogLogger) Named(name string) logcore.LevelLogger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	c := *l
+	c.handler = l.handler.WithAttrs([]slog.Attr{slog.String("logger", name)})
+	c.level = new(slog.LevelVar)
+	c.level.Set(l.level.Level())
+	c.name = name
+	return &c
+}
+
+func (l *slogLogger) Level() string {
+	return levelName(l.level.Level())
+}
+
+func (l *slogLogger) SetLevel(level string) error {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.Set(lv)
+	return nil
+}
+
+func (l *slogLogger) enabled(ctx context.Context, lv slog.Level) bool {
+	return lv >= l.level.Level() && l.handler.Enabled(ctx, lv)
+}
+
+func (l *slogLogger) log(ctx context.Context, lv slog.Level, msg string, kvs ...logcore.Field) {
+	if !l.enabled(ctx, lv) || !l.sampler.Allow(levelName(lv), msg) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(defaultCallerSkip+l.skip, pcs[:])
+	r := slog.NewRecord(time.Now(), lv, msg, pcs[0])
+	kvs = logcore.Redact(kvs)
+	if len(l.ns) > 0 {
+		kvs = append(slices.Clip(l.ns), kvs...)
+	}
+	r.AddAttrs(attrs(kvs)...)
+	l.addStack(&r)
+	l.handle(ctx, r)
+}
+
+func (l *slogLogger) logf(lv slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	if !l.enabled(ctx, lv) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if !l.sampler.Allow(levelName(lv), msg) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(defaultCallerSkip+l.skip, pcs[:])
+	r := slog.NewRecord(time.Now(), lv, msg, pcs[0])
+	r.AddAttrs(attrs(l.ns)...)
+	l.addStack(&r)
+	l.handle(ctx, r)
+}
+
+// addStack adds the stack trace of the logging call to the records at
+// stackLevel and above. It is called by log and logf, in place of
+// runtime.Callers.
+func (l *slogLogger) addStack(r *slog.Record) {
+	if r.Level >= l.stackLevel {
+		r.AddAttrs(slog.String("stacktrace", logcore.Stacktrace(defaultCallerSkip+l.skip)))
+	}
+}
+
+func (l *slogLogger) handle(ctx context.Context, r slog.Record) {
+	_ = l.handler.Handle(ctx, r)
+	if r.Level >= LevelPanic {
+		// The entry may be the last one before the process exits.
+		_ = l.async.Sync()
+		_ = l.sinks.Sync()
+	}
+}
+
+// Attrs converts fields to slog attributes, applying the logcore redactor.
+// The fields after a namespace become a group.
+func Attrs(kvs []logcore.Field) []slog.Attr {
+	return attrs(logcore.Redact(kvs))
+}
+
+func attrs(kvs []logcore.Field) []slog.Attr {
+	res := make([]slog.Attr, 0, len(kvs))
+	for i, kv := range kvs {
+		var (
+			group []slog.Attr
+			err   error
+		)
+		switch kv.Kind {
+		case logcore.KindNamespace:
+			return append(res, slog.Attr{Key: kv.Key, Value: slog.GroupValue(attrs(kvs[i+1:])...)})
+		case logcore.KindObject:
+			group, err = objectAttrs(kv.Key, kv.Value.(logcore.ObjectMarshaler))
+		case logcore.KindArray:
+			group, err = arrayAttrs(kv.Key, kv.Value.(logcore.ArrayMarshaler))
+		case logcore.KindError:
+			group = attrs(logcore.ErrorFields(kv.Key, kv.Value.(error)))
+		default:
+			res = append(res, attr(kv))
+			continue
+		}
+		res = append(res, group...)
+		if err != nil {
+			res = append(res, slog.String(kv.Key+"Error", err.Error()))
+		}
+	}
+	return res
+}
+
+func attr(kv logcore.Field) slog.Attr {
+	switch kv.Kind {
+	case reflect.Bool:
+		return slog.Bool(kv.Key, kv.Value.(bool))
+	case reflect.Int:
+		return slog.Int(kv.Key, kv.Value.(int))
+	case reflect.Int8:
+		return slog.Int64(kv.Key, int64(kv.Value.(int8)))
+	case reflect.Int16:
+		return slog.Int64(kv.Key, int64(kv.Value.(int16)))
+	case reflect.Int32:
+		return slog.Int64(kv.Key, int64(kv.Value.(int32)))
+	case reflect.Int64:
+		return slog.Int64(kv.Key, kv.Value.(int64))
+	case reflect.Uint:
+		return slog.Uint64(kv.Key, uint64(kv.Value.(uint)))
+	case reflect.Uint8:
+		return slog.Uint64(kv.Key, uint64(kv.Value.(uint8)))
+	case reflect.Uint16:
+		return slog.Uint64(kv.Key, uint64(kv.Value.(uint16)))
+	case reflect.Uint32:
+		return slog.Uint64(kv.Key, uint64(kv.Value.(uint32)))
+	case reflect.Uint64:
+		return slog.Uint64(kv.Key, kv.Value.(uint64))
+	case reflect.Float32:
+		return slog.Float64(kv.Key, float64(kv.Value.(float32)))
+	case reflect.Float64:
+		return slog.Float64(kv.Key, kv.Value.(float64))
+	case reflect.String:
+		return slog.String(kv.Key, kv.Value.(string))
+	case logcore.KindDuration:
+		return slog.Duration(kv.Key, kv.Value.(time.Duration))
+	case logcore.KindTime:
+		return slog.Time(kv.Key, kv.Value.(time.Time))
+	case logcore.KindStringer:
+		return slog.Any(kv.Key, stringerValue{kv.Value.(fmt.Stringer)})
+	case logcore.KindBinary:
+		return slog.String(kv.Key, base64.StdEncoding.EncodeToString(kv.Value.([]byte)))
+	case logcore.KindStack:
+		return slog.String(kv.Key, kv.Value.(string))
+	default:
+		switch v := kv.Value.(type) {
+		case error:
+			return slog.String(kv.Key, v.Error())
+		case time.Duration:
+			return slog.Duration(kv.Key, v)
+		case time.Time:
+			return slog.Time(kv.Key, v)
+		case fmt.Stringer:
+			return slog.String(kv.Key, v.String())
+		default:
+			return slog.Any(kv.Key, v)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package slogger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/internal/backendtest"
+	"github.com/BYT0723/go-tools/logx/logcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Run("ParseLevel 测试", func(t *testing.T) {
+		for name, want := range map[string]slog.Level{
+			"debug": slog.LevelDebug,
+			"INFO":  slog.LevelInfo,
+			"warn":  slog.LevelWarn,
+			"error": slog.LevelError,
+			"panic": LevelPanic,
+			"fatal": LevelFatal,
+		} {
+			lv, err := ParseLevel(name)
+			assert.NoError(t, err)
+			assert.Equal(t, want, lv)
+		}
+		_, err := ParseLevel("verbose")
+		assert.Error(t, err)
+	})
+}
+
+func TestAttrs(t *testing.T) {
+	t.Run("Attrs 测试", func(t *testing.T) {
+		attrs := Attrs([]logcore.Field{
+			{Key: "b", Kind: reflect.Bool, Value: true},
+			{Key: "i8", Kind: reflect.Int8, Value: int8(-8)},
+			{Key: "u16", Kind: reflect.Uint16, Value: uint16(16)},
+			{Key: "f32", Kind: reflect.Float32, Value: float32(0.5)},
+			{Key: "s", Kind: reflect.String, Value: "x"},
+			{Key: "err", Value: errors.New("bad")},
+			{Key: "d", Value: time.Second},
+			{Key: "any", Value: []int{1}},
+		})
+		require.Len(t, attrs, 8)
+		assert.Equal(t, slog.KindBool, attrs[0].Value.Kind())
+		assert.Equal(t, int64(-8), attrs[1].Value.Int64())
+		assert.Equal(t, uint64(16), attrs[2].Value.Uint64())
+		assert.Equal(t, 0.5, attrs[3].Value.Float64())
+		assert.Equal(t, "x", attrs[4].Value.String())
+		assert.Equal(t, "bad", attrs[5].Value.String())
+		assert.Equal(t, time.Second, attrs[6].Value.Duration())
+		assert.Equal(t, []int{1}, attrs[7].Value.Any())
+	})
+}
+
+func TestSlogLogger(t *testing.T) {
+	t.Run("slogLogger 方法测试", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := NewWithHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+		t.Run("级别过滤", func(t *testing.T) {
+			buf.Reset()
+			l.Debug("dropped")
+			l.Logf("info", "n=%d", 1)
+			l.Log("unknown", "dropped too")
+			assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+			assert.Contains(t, buf.String(), `"msg":"n=1"`)
+		})
+
+		t.Run("With 不影响原 Logger", func(t *testing.T) {
+			buf.Reset()
+			l.With(logcore.Field{Key: "k", Kind: reflect.String, Value: "v"}).Info("a")
+			l.Info("b")
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Contains(t, lines[0], `"k":"v"`)
+			assert.NotContains(t, lines[1], `"k"`)
+		})
+
+		t.Run("Panic 与 Fatal", func(t *testing.T) {
+			buf.Reset()
+			assert.PanicsWithValue(t, "p1", func() { l.Panic("p1") })
+			assert.PanicsWithValue(t, "p2", func() { l.Panicf("p%d", 2) })
+
+			code := 0
+			exit = func(c int) { code = c }
+			defer func() { exit = os.Exit }()
+			l.Fatal("f")
+			assert.Equal(t, 1, code)
+			assert.Contains(t, buf.String(), `"level":"ERROR+8"`)
+		})
+
+		t.Run("Sync", func(t *testing.T) {
+			assert.Nil(t, l.Sync())
+		})
+	})
+}
+
+func TestNewInstance(t *testing.T) {
+	t.Run("NewInstance 测试", func(t *testing.T) {
+		t.Run("无效level返回错误", func(t *testing.T) {
+			_, err := NewInstance(&logcore.LoggerConf{Level: "invalid-level"})
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestConformance(t *testing.T) {
+	backendtest.Run(t, backendtest.Backend{
+		New:      func(cfg *logcore.LoggerConf) (backendtest.Instance, error) { return NewInstance(cfg) },
+		Duration: 1.5e+09,
+	})
+}
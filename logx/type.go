@@ -7,6 +7,7 @@ type (
 const (
 	TypeZap LoggerType = iota // default
 	TypeZeroLog
+	TypeSlog
 
 	TypeInvalid LoggerType = 1<<4 - 1
 )
@@ -7,7 +7,6 @@ import (
 	"github.com/BYT0723/go-tools/logx/logcore"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func newConsoleCore(level zap.AtomicLevel) zapcore.Core {
@@ -21,19 +20,91 @@ func newConsoleCore(level zap.AtomicLevel) zapcore.Core {
 	)
 }
 
-func newCore(cfg *logcore.LoggerConf, filter zap.LevelEnablerFunc, filename string) zapcore.Core {
+func newJSONEncoder() zapcore.Encoder {
 	productionCfg := zap.NewProductionEncoderConfig()
 	productionCfg.TimeKey = "timestamp"
 	productionCfg.EncodeTime = zapcore.TimeEncoderOfLayout(time.RFC3339Nano)
+	return zapcore.NewJSONEncoder(productionCfg)
+}
+
+func newCore(cfg *logcore.LoggerConf, filter zap.LevelEnablerFunc, filename string, async *logcore.AsyncWriters) (zapcore.Core, error) {
+	fw, err := logcore.NewFileWriter(cfg, filename)
+	if err != nil {
+		return nil, err
+	}
+	w, err := async.Wrap(fw, cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return zapcore.NewCore(
-		zapcore.NewJSONEncoder(productionCfg),
-		zapcore.AddSync(&lumberjack.Logger{
-			Filename:   filename,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-		}),
+		newJSONEncoder(),
+		zapcore.AddSync(w),
 		filter,
-	)
+	), nil
+}
+
+// newSinkCores returns the cores writing the entries of level min and above
+// to sink, one per level so that the sink knows the level of each entry.
+func newSinkCores(sink logcore.Sink, min string) ([]zapcore.Core, error) {
+	minLevel := zap.DebugLevel
+	if min != "" {
+		var err error
+		if minLevel, err = zapcore.ParseLevel(min); err != nil {
+			return nil, err
+		}
+	}
+	var cores []zapcore.Core
+	for i := minLevel; i <= zap.FatalLevel; i++ {
+		targetLevel := i
+		cores = append(cores, zapcore.NewCore(
+			newJSONEncoder(),
+			zapcore.AddSync(logcore.SinkWriter(sink, targetLevel.String())),
+			zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l == targetLevel }),
+		))
+	}
+	return cores, nil
+}
+
+// levelCore drops the entries below level. All loggers of an instance share
+// the same outputs; levelCore lets each of them have its own level.
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.level.Enabled(l)
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	return c.level.Level()
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+// samplerCore drops the entries rejected by sampler.
+type samplerCore struct {
+	zapcore.Core
+	sampler *logcore.Sampler
+}
+
+func (c *samplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplerCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+func (c *samplerCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Core.Enabled(e.Level) || !c.sampler.Allow(e.Level.String(), e.Message) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
 }
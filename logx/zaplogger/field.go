@@ -0,0 +1,63 @@
+package zaplogger
+
+import (
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapObject encodes a logcore.ObjectMarshaler with a zap encoder.
+type zapObject struct {
+	m logcore.ObjectMarshaler
+}
+
+func (o zapObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.m.MarshalLogObject(objectEncoder{enc})
+}
+
+// zapArray encodes a logcore.ArrayMarshaler with a zap encoder.
+type zapArray struct {
+	m logcore.ArrayMarshaler
+}
+
+func (a zapArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.m.MarshalLogArray(arrayEncoder{enc})
+}
+
+type objectEncoder struct {
+	enc zapcore.ObjectEncoder
+}
+
+func (e objectEncoder) AddString(key, value string)          { e.enc.AddString(key, value) }
+func (e objectEncoder) AddBool(key string, value bool)       { e.enc.AddBool(key, value) }
+func (e objectEncoder) AddInt(key string, value int)         { e.enc.AddInt(key, value) }
+func (e objectEncoder) AddInt64(key string, value int64)     { e.enc.AddInt64(key, value) }
+func (e objectEncoder) AddUint64(key string, value uint64)   { e.enc.AddUint64(key, value) }
+func (e objectEncoder) AddFloat64(key string, value float64) { e.enc.AddFloat64(key, value) }
+func (e objectEncoder) AddDuration(key string, value time.Duration) {
+	e.enc.AddDuration(key, value)
+}
+func (e objectEncoder) AddTime(key string, value time.Time) { e.enc.AddTime(key, value) }
+func (e objectEncoder) AddObject(key string, value logcore.ObjectMarshaler) error {
+	return e.enc.AddObject(key, zapObject{value})
+}
+func (e objectEncoder) AddArray(key string, value logcore.ArrayMarshaler) error {
+	return e.enc.AddArray(key, zapArray{value})
+}
+
+type arrayEncoder struct {
+	enc zapcore.ArrayEncoder
+}
+
+func (e arrayEncoder) AppendString(value string)          { e.enc.AppendString(value) }
+func (e arrayEncoder) AppendBool(value bool)              { e.enc.AppendBool(value) }
+func (e arrayEncoder) AppendInt(value int)                { e.enc.AppendInt(value) }
+func (e arrayEncoder) AppendInt64(value int64)            { e.enc.AppendInt64(value) }
+func (e arrayEncoder) AppendUint64(value uint64)          { e.enc.AppendUint64(value) }
+func (e arrayEncoder) AppendFloat64(value float64)        { e.enc.AppendFloat64(value) }
+func (e arrayEncoder) AppendDuration(value time.Duration) { e.enc.AppendDuration(value) }
+func (e arrayEncoder) AppendTime(value time.Time)         { e.enc.AppendTime(value) }
+func (e arrayEncoder) AppendObject(value logcore.ObjectMarshaler) error {
+	return e.enc.AppendObject(zapObject{value})
+}
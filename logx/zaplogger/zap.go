@@ -1,9 +1,10 @@
 package zaplogger
 
 import (
+	"context"
 	"fmt"
-	"path/filepath"
 	"reflect"
+	"time"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
 	"go.uber.org/zap"
@@ -11,7 +12,11 @@ import (
 )
 
 type zapLogger struct {
-	zap *zap.Logger
+	zap     *zap.Logger
+	level   zap.AtomicLevel
+	sampler *logcore.Sampler
+	async   logcore.AsyncWriters
+	sinks   logcore.Sinks
 }
 
 func NewInstance(cfg *logcore.LoggerConf) (ins *zapLogger, err error) {
@@ -21,46 +26,95 @@ func NewInstance(cfg *logcore.LoggerConf) (ins *zapLogger, err error) {
 	}
 
 	var (
-		cores    = []zapcore.Core{}
-		basename = filepath.Join(cfg.Dir, cfg.Name)
+		cores = []zapcore.Core{}
+		async logcore.AsyncWriters
+		sinks logcore.Sinks
 	)
+	// The cores accept every level; the level of each logger is checked by levelCore.
 	if !cfg.Multi {
-		targetLevel := level.Level()
-		cores = append(cores, newCore(
+		core, err := newCore(
 			cfg,
-			func(l zapcore.Level) bool { return l >= targetLevel },
-			basename+cfg.Ext,
-		))
+			func(zapcore.Level) bool { return true },
+			cfg.FileName(""),
+			&async,
+		)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
 	} else {
-		for i := level.Level(); i <= zap.FatalLevel; i++ {
-			var (
-				targetLevel = i
-				filename    = basename + "-" + targetLevel.String() + cfg.Ext
-			)
-			cores = append(cores, newCore(
+		for i := zap.DebugLevel; i <= zap.FatalLevel; i++ {
+			targetLevel := i
+			core, err := newCore(
 				cfg,
 				func(l zapcore.Level) bool { return l == targetLevel },
-				filename,
-			))
+				cfg.FileName(targetLevel.String()),
+				&async,
+			)
+			if err != nil {
+				_ = async.Close()
+				return nil, err
+			}
+			cores = append(cores, core)
 		}
 	}
 
 	if cfg.Console {
-		cores = append(cores, newConsoleCore(level))
+		cores = append(cores, newConsoleCore(zap.NewAtomicLevelAt(zap.DebugLevel)))
+	}
+
+	// Each sink filters by its own level, on top of the level of the logger.
+	if err := sinks.Open(cfg); err != nil {
+		_ = async.Close()
+		_ = sinks.Close()
+		return nil, err
+	}
+	for i, sink := range sinks {
+		sinkCores, err := newSinkCores(sink, cfg.Sinks[i].Level)
+		if err != nil {
+			_ = async.Close()
+			_ = sinks.Close()
+			return nil, err
+		}
+		cores = append(cores, sinkCores...)
+	}
+
+	var (
+		tee     = zapcore.NewTee(cores...)
+		sampler = logcore.NewSampler(cfg, func(level, msg string, repeated int) {
+			lv, _ := zapcore.ParseLevel(level)
+			ent := zapcore.Entry{Level: lv, Time: time.Now(), Message: logcore.SummaryMessage(msg, repeated)}
+			if ce := tee.Check(ent, nil); ce != nil {
+				ce.Write(zap.Int("repeated", repeated))
+			}
+		})
+		core zapcore.Core = tee
+	)
+	if sampler != nil {
+		core = &samplerCore{Core: core, sampler: sampler}
 	}
 
-	core := zapcore.NewTee(cores...)
+	opts := []zap.Option{zap.AddCaller(), zap.AddCallerSkip(2)}
+	if cfg.StackLevel != "" {
+		stackLevel, err := zapcore.ParseLevel(cfg.StackLevel)
+		if err != nil {
+			_ = async.Close()
+			_ = sinks.Close()
+			return nil, err
+		}
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
 
-	zl := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2))
+	zl := zap.New(&levelCore{Core: core, level: level}, opts...)
 
-	ins = &zapLogger{zap: zl}
+	ins = &zapLogger{zap: zl, level: level, sampler: sampler, async: async, sinks: sinks}
 
 	return
 }
 
 func (l *zapLogger) With(kvs ...logcore.Field) logcore.Logger {
 	zl := l.zap.With(transFields(kvs)...)
-	return &zapLogger{zap: zl}
+	return &zapLogger{zap: zl, level: l.level, sampler: l.sampler, async: l.async, sinks: l.sinks}
 }
 
 func (l *zapLogger) Debug(msg string, kvs ...logcore.Field) {
@@ -135,24 +189,107 @@ func (l *zapLogger) Logf(level, format string, args ...any) {
 	}
 }
 
+func (l *zapLogger) DebugCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.zap.Debug(msg, transFields(logcore.ContextFields(ctx, kvs...))...)
+}
+
+func (l *zapLogger) InfoCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.zap.Info(msg, transFields(logcore.ContextFields(ctx, kvs...))...)
+}
+
+func (l *zapLogger) WarnCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.zap.Warn(msg, transFields(logcore.ContextFields(ctx, kvs...))...)
+}
+
+func (l *zapLogger) ErrorCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.zap.Error(msg, transFields(logcore.ContextFields(ctx, kvs...))...)
+}
+
+func (l *zapLogger) PanicCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.zap.Panic(msg, transFields(logcore.ContextFields(ctx, kvs...))...)
+}
+
+func (l *zapLogger) FatalCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.zap.Fatal(msg, transFields(logcore.ContextFields(ctx, kvs...))...)
+}
+
+func (l *zapLogger) LogCtx(ctx context.Context, level string, msg string, kvs ...logcore.Field) {
+	var lv zapcore.Level
+	if v, err := zap.ParseAtomicLevel(level); err != nil {
+		lv = zap.DebugLevel
+	} else {
+		lv = v.Level()
+	}
+	if ce := l.zap.WithOptions(zap.AddCallerSkip(1)).Check(lv, msg); ce != nil {
+		ce.Write(transFields(logcore.ContextFields(ctx, kvs...))...)
+	}
+}
+
 func (l *zapLogger) Sync() error {
+	l.sampler.Flush()
 	return l.zap.Sync()
 }
 
+// Close writes the lines buffered by the async writers and the sinks of the
+// instance and stops them; the instance keeps logging synchronously.
+func (l *zapLogger) Close() error {
+	l.sampler.Flush()
+	_ = l.zap.Sync()
+	err := l.async.Close()
+	if serr := l.sinks.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Dropped returns the number of lines the async writers of the instance dropped.
+func (l *zapLogger) Dropped() uint64 {
+	return l.async.Dropped()
+}
+
 func (l *zapLogger) Logger() logcore.Logger {
 	l.zap = l.zap.WithOptions(zap.AddCallerSkip(-1))
 	return l
 }
 
 func (l *zapLogger) AddCallerSkip(skip int) logcore.Logger {
-	zl := &zapLogger{zap: l.zap}
+	zl := &zapLogger{zap: l.zap, level: l.level, sampler: l.sampler, async: l.async, sinks: l.sinks}
 	zl.zap = zl.zap.WithOptions(zap.AddCallerSkip(skip))
 
 	return zl
 }
 
-func transFields(fields []logcore.Field) (result []zapcore.Field) {
-	for _, kv := range fields {
+func (l *zapLogger) Named(name string) logcore.LevelLogger {
+	level := zap.NewAtomicLevelAt(l.level.Level())
+	zl := l.zap.Named(name).WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		if lc, ok := c.(*levelCore); ok {
+			c = lc.Core
+		}
+		return &levelCore{Core: c, level: level}
+	}))
+	return &zapLogger{zap: zl, level: level, sampler: l.sampler, async: l.async, sinks: l.sinks}
+}
+
+func (l *zapLogger) Level() string {
+	return l.level.String()
+}
+
+func (l *zapLogger) SetLevel(level string) error {
+	lv, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.SetLevel(lv)
+	return nil
+}
+
+func transFields(fields []logcore.Field) []zapcore.Field {
+	return appendFields(nil, logcore.Redact(fields))
+}
+
+// appendFields appends kvs, already redacted, to result.
+func appendFields(result []zapcore.Field, kvs []logcore.Field) []zapcore.Field {
+	for _, kv := range kvs {
 		switch kv.Kind {
 		case reflect.Bool:
 			result = append(result, zap.Bool(kv.Key, kv.Value.(bool)))
@@ -182,6 +319,24 @@ func transFields(fields []logcore.Field) (result []zapcore.Field) {
 			result = append(result, zap.Float64(kv.Key, kv.Value.(float64)))
 		case reflect.String:
 			result = append(result, zap.String(kv.Key, kv.Value.(string)))
+		case logcore.KindDuration:
+			result = append(result, zap.Duration(kv.Key, kv.Value.(time.Duration)))
+		case logcore.KindTime:
+			result = append(result, zap.Time(kv.Key, kv.Value.(time.Time)))
+		case logcore.KindStringer:
+			result = append(result, zap.Stringer(kv.Key, kv.Value.(fmt.Stringer)))
+		case logcore.KindBinary:
+			result = append(result, zap.Binary(kv.Key, kv.Value.([]byte)))
+		case logcore.KindNamespace:
+			result = append(result, zap.Namespace(kv.Key))
+		case logcore.KindStack:
+			result = append(result, zap.String(kv.Key, kv.Value.(string)))
+		case logcore.KindObject:
+			result = append(result, zap.Object(kv.Key, zapObject{kv.Value.(logcore.ObjectMarshaler)}))
+		case logcore.KindArray:
+			result = append(result, zap.Array(kv.Key, zapArray{kv.Value.(logcore.ArrayMarshaler)}))
+		case logcore.KindError:
+			result = appendFields(result, logcore.ErrorFields(kv.Key, kv.Value.(error)))
 		default:
 			switch v := kv.Value.(type) {
 			case error:
@@ -193,5 +348,5 @@ func transFields(fields []logcore.Field) (result []zapcore.Field) {
 			}
 		}
 	}
-	return
+	return result
 }
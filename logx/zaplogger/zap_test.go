@@ -1,9 +1,11 @@
 package zaplogger
 
 import (
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/BYT0723/go-tools/logx/internal/backendtest"
 	"github.com/BYT0723/go-tools/logx/logcore"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -57,6 +59,7 @@ func TestNewInstance(t *testing.T) {
 	t.Run("NewInstance 测试", func(t *testing.T) {
 		t.Run("有效配置创建实例", func(t *testing.T) {
 			cfg := logcore.DefaultLoggerConf()
+			cfg.Dir = t.TempDir()
 			ins, err := NewInstance(cfg)
 			assert.Nil(t, err)
 			assert.NotNil(t, ins)
@@ -74,6 +77,7 @@ func TestNewInstance(t *testing.T) {
 func TestZapLoggerMethods(t *testing.T) {
 	t.Run("zapLogger 方法测试", func(t *testing.T) {
 		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir = t.TempDir()
 		zl, err := NewInstance(cfg)
 		assert.Nil(t, err)
 
@@ -115,6 +119,7 @@ func TestZapLoggerMethods(t *testing.T) {
 func TestZapLoggerInterface(t *testing.T) {
 	t.Run("zapLogger 实现 Logger 接口", func(t *testing.T) {
 		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir = t.TempDir()
 		zl, err := NewInstance(cfg)
 		assert.Nil(t, err)
 
@@ -153,7 +158,17 @@ func TestNewCore(t *testing.T) {
 	t.Run("newCore 测试", func(t *testing.T) {
 		cfg := logcore.DefaultLoggerConf()
 		level := zap.NewAtomicLevel()
-		core := newCore(cfg, func(l zapcore.Level) bool { return l >= level.Level() }, "/tmp/test.log")
+		var async logcore.AsyncWriters
+		core, err := newCore(cfg, func(l zapcore.Level) bool { return l >= level.Level() }, filepath.Join(t.TempDir(), "test.log"), &async)
+		assert.Nil(t, err)
 		assert.NotNil(t, core)
+		assert.Empty(t, async)
+	})
+}
+
+func TestConformance(t *testing.T) {
+	backendtest.Run(t, backendtest.Backend{
+		New:      func(cfg *logcore.LoggerConf) (backendtest.Instance, error) { return NewInstance(cfg) },
+		Duration: 1.5,
 	})
 }
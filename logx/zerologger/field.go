@@ -0,0 +1,72 @@
+package zerologger
+
+import (
+	"time"
+
+	"github.com/BYT0723/go-tools/logx/logcore"
+	"github.com/rs/zerolog"
+)
+
+// zeroObject encodes a logcore.ObjectMarshaler with zerolog. zerolog
+// marshals synchronously, so err holds the error once Object returns.
+type zeroObject struct {
+	m   logcore.ObjectMarshaler
+	err error
+}
+
+func (o *zeroObject) MarshalZerologObject(e *zerolog.Event) {
+	o.err = o.m.MarshalLogObject(objectEncoder{e})
+}
+
+// zeroArray encodes a logcore.ArrayMarshaler with zerolog, see zeroObject.
+type zeroArray struct {
+	m   logcore.ArrayMarshaler
+	err error
+}
+
+func (a *zeroArray) MarshalZerologArray(arr *zerolog.Array) {
+	a.err = a.m.MarshalLogArray(arrayEncoder{arr})
+}
+
+type objectEncoder struct {
+	e *zerolog.Event
+}
+
+func (e objectEncoder) AddString(key, value string)          { e.e.Str(key, value) }
+func (e objectEncoder) AddBool(key string, value bool)       { e.e.Bool(key, value) }
+func (e objectEncoder) AddInt(key string, value int)         { e.e.Int(key, value) }
+func (e objectEncoder) AddInt64(key string, value int64)     { e.e.Int64(key, value) }
+func (e objectEncoder) AddUint64(key string, value uint64)   { e.e.Uint64(key, value) }
+func (e objectEncoder) AddFloat64(key string, value float64) { e.e.Float64(key, value) }
+func (e objectEncoder) AddDuration(key string, value time.Duration) {
+	e.e.Dur(key, value)
+}
+func (e objectEncoder) AddTime(key string, value time.Time) { e.e.Time(key, value) }
+func (e objectEncoder) AddObject(key string, value logcore.ObjectMarshaler) error {
+	o := &zeroObject{m: value}
+	e.e.Object(key, o)
+	return o.err
+}
+func (e objectEncoder) AddArray(key string, value logcore.ArrayMarshaler) error {
+	a := &zeroArray{m: value}
+	e.e.Array(key, a)
+	return a.err
+}
+
+type arrayEncoder struct {
+	a *zerolog.Array
+}
+
+func (e arrayEncoder) AppendString(value string)          { e.a.Str(value) }
+func (e arrayEncoder) AppendBool(value bool)              { e.a.Bool(value) }
+func (e arrayEncoder) AppendInt(value int)                { e.a.Int(value) }
+func (e arrayEncoder) AppendInt64(value int64)            { e.a.Int64(value) }
+func (e arrayEncoder) AppendUint64(value uint64)          { e.a.Uint64(value) }
+func (e arrayEncoder) AppendFloat64(value float64)        { e.a.Float64(value) }
+func (e arrayEncoder) AppendDuration(value time.Duration) { e.a.Dur(value) }
+func (e arrayEncoder) AppendTime(value time.Time)         { e.a.Time(value) }
+func (e arrayEncoder) AppendObject(value logcore.ObjectMarshaler) error {
+	o := &zeroObject{m: value}
+	e.a.Object(o)
+	return o.err
+}
@@ -1,21 +1,34 @@
 package zerologger
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
-	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/BYT0723/go-tools/logx/logcore"
 	"github.com/rs/zerolog"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type zeroLogger struct {
-	zero zerolog.Logger
+	zero    zerolog.Logger
+	level   *atomic.Int32 // zerolog.Level
+	name    string
+	sampler *logcore.Sampler
+	async   logcore.AsyncWriters
+	sinks   logcore.Sinks
+	// callerSkip is the frame count passed to CallerWithSkipFrameCount.
+	callerSkip int
+	// stackLevel is the lowest level logged with a stack trace, Disabled for none.
+	stackLevel zerolog.Level
+	// ns is the namespace left open by With, written before the fields of each entry.
+	ns []logcore.Field
 }
 
 var defaultCallerSkip = 5
@@ -25,6 +38,12 @@ func NewInstance(cfg *logcore.LoggerConf) (ins *zeroLogger, err error) {
 	if err != nil {
 		return nil, err
 	}
+	stackLevel := zerolog.Disabled
+	if cfg.StackLevel != "" {
+		if stackLevel, err = zerolog.ParseLevel(cfg.StackLevel); err != nil {
+			return nil, err
+		}
+	}
 
 	zerolog.TimeFieldFormat = time.RFC3339Nano
 	zerolog.TimestampFieldName = "timestamp"
@@ -43,29 +62,27 @@ func NewInstance(cfg *logcore.LoggerConf) (ins *zeroLogger, err error) {
 	}
 
 	var (
-		writers  []io.Writer
-		basename = filepath.Join(cfg.Dir, cfg.Name)
+		writers []io.Writer
+		async   logcore.AsyncWriters
+		sinks   logcore.Sinks
 	)
 
+	// The writers accept every level; the level of each logger is checked in log.
 	if !cfg.Multi {
-		writers = append(writers, NewLevelWriter(zerolog.SyncWriter(&lumberjack.Logger{
-			Filename:   basename + cfg.Ext,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-		}), func(l zerolog.Level) bool { return l >= level }))
+		w, err := newFileWriter(cfg, cfg.FileName(""), &async)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, NewLevelWriter(w, func(zerolog.Level) bool { return true }))
 	} else {
-		for i := level; i < zerolog.Disabled; i++ {
-			var (
-				targetLevel = i
-				filename    = basename + "-" + targetLevel.String() + cfg.Ext
-			)
-			writers = append(writers, NewLevelWriter(zerolog.SyncWriter(&lumberjack.Logger{
-				Filename:   filename,
-				MaxSize:    cfg.MaxSize,
-				MaxBackups: cfg.MaxBackups,
-				MaxAge:     cfg.MaxAge,
-			}), func(l zerolog.Level) bool { return l == targetLevel }))
+		for i := zerolog.DebugLevel; i < zerolog.Disabled; i++ {
+			targetLevel := i
+			w, err := newFileWriter(cfg, cfg.FileName(targetLevel.String()), &async)
+			if err != nil {
+				_ = async.Close()
+				return nil, err
+			}
+			writers = append(writers, NewLevelWriter(w, func(l zerolog.Level) bool { return l == targetLevel }))
 		}
 	}
 
@@ -81,20 +98,56 @@ func NewInstance(cfg *logcore.LoggerConf) (ins *zeroLogger, err error) {
 		}))
 	}
 
+	// Each sink filters by its own level, on top of the level of the logger.
+	if err := sinks.Open(cfg); err != nil {
+		_ = async.Close()
+		_ = sinks.Close()
+		return nil, err
+	}
+	for i, sink := range sinks {
+		sinkWriters, err := newSinkWriters(sink, cfg.Sinks[i].Level)
+		if err != nil {
+			_ = async.Close()
+			_ = sinks.Close()
+			return nil, err
+		}
+		writers = append(writers, sinkWriters...)
+	}
+
+	writer := zerolog.MultiLevelWriter(writers...)
+	summary := zerolog.New(writer).With().Timestamp().Logger()
+
 	ins = &zeroLogger{
-		zero: zerolog.New(zerolog.MultiLevelWriter(writers...)).
+		level:      new(atomic.Int32),
+		async:      async,
+		sinks:      sinks,
+		callerSkip: defaultCallerSkip,
+		stackLevel: stackLevel,
+		sampler: logcore.NewSampler(cfg, func(level, msg string, repeated int) {
+			lv, _ := zerolog.ParseLevel(level)
+			summary.WithLevel(lv).Int("repeated", repeated).Msg(logcore.SummaryMessage(msg, repeated))
+		}),
+		zero: zerolog.New(writer).
 			With().
 			Timestamp().
 			CallerWithSkipFrameCount(defaultCallerSkip).
 			Logger(),
 	}
+	ins.level.Store(int32(level))
 
 	return
 }
 
 func (l *zeroLogger) With(kvs ...logcore.Field) logcore.Logger {
 	copy := l.clone()
-	ctx := copy.zero.With()
+	copy.callerSkip = defaultCallerSkip - 1
+	kvs, copy.ns = logcore.WithNamespace(l.ns, logcore.Redact(kvs))
+	copy.zero = contextFields(copy.zero.With(), kvs).CallerWithSkipFrameCount(copy.callerSkip).Logger()
+	return copy
+}
+
+// contextFields adds kvs, already redacted and without a namespace, to ctx.
+func contextFields(ctx zerolog.Context, kvs []logcore.Field) zerolog.Context {
 	for _, kv := range kvs {
 		switch kv.Kind {
 		case reflect.Bool:
@@ -125,6 +178,28 @@ func (l *zeroLogger) With(kvs ...logcore.Field) logcore.Logger {
 			ctx = ctx.Float64(kv.Key, kv.Value.(float64))
 		case reflect.String:
 			ctx = ctx.Str(kv.Key, kv.Value.(string))
+		case logcore.KindDuration:
+			ctx = ctx.Dur(kv.Key, kv.Value.(time.Duration))
+		case logcore.KindTime:
+			ctx = ctx.Time(kv.Key, kv.Value.(time.Time))
+		case logcore.KindStringer:
+			ctx = ctx.Stringer(kv.Key, kv.Value.(fmt.Stringer))
+		case logcore.KindBinary:
+			ctx = ctx.Str(kv.Key, base64.StdEncoding.EncodeToString(kv.Value.([]byte)))
+		case logcore.KindStack:
+			ctx = ctx.Str(kv.Key, kv.Value.(string))
+		case logcore.KindObject:
+			o := &zeroObject{m: kv.Value.(logcore.ObjectMarshaler)}
+			if ctx = ctx.Object(kv.Key, o); o.err != nil {
+				ctx = ctx.Str(kv.Key+"Error", o.err.Error())
+			}
+		case logcore.KindArray:
+			a := &zeroArray{m: kv.Value.(logcore.ArrayMarshaler)}
+			if ctx = ctx.Array(kv.Key, a); a.err != nil {
+				ctx = ctx.Str(kv.Key+"Error", a.err.Error())
+			}
+		case logcore.KindError:
+			ctx = contextFields(ctx, logcore.ErrorFields(kv.Key, kv.Value.(error)))
 		default:
 			switch v := kv.Value.(type) {
 			case error:
@@ -136,8 +211,7 @@ func (l *zeroLogger) With(kvs ...logcore.Field) logcore.Logger {
 			}
 		}
 	}
-	copy.zero = ctx.CallerWithSkipFrameCount(defaultCallerSkip - 1).Logger()
-	return copy
+	return ctx
 }
 
 func (l *zeroLogger) Debug(msg string, kvs ...logcore.Field) {
@@ -201,11 +275,64 @@ func (l *zeroLogger) Logf(level, format string, args ...any) {
 	if err != nil {
 		lv = zerolog.DebugLevel
 	}
-	l.zero.WithLevel(lv).Msgf(format, args...)
+	l.logf(lv, format, args...)
+}
+
+func (l *zeroLogger) DebugCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(zerolog.DebugLevel, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *zeroLogger) InfoCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(zerolog.InfoLevel, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *zeroLogger) WarnCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(zerolog.WarnLevel, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *zeroLogger) ErrorCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(zerolog.ErrorLevel, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *zeroLogger) PanicCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(zerolog.PanicLevel, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *zeroLogger) FatalCtx(ctx context.Context, msg string, kvs ...logcore.Field) {
+	l.log(zerolog.FatalLevel, msg, logcore.ContextFields(ctx, kvs...)...)
+}
+
+func (l *zeroLogger) LogCtx(ctx context.Context, level string, msg string, kvs ...logcore.Field) {
+	lv, err := zerolog.ParseLevel(level)
+	if err != nil {
+		lv = zerolog.DebugLevel
+	}
+	l.log(lv, msg, logcore.ContextFields(ctx, kvs...)...)
 }
 
 func (l *zeroLogger) Sync() error {
-	return nil
+	l.sampler.Flush()
+	err := l.async.Sync()
+	if serr := l.sinks.Sync(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Close writes the lines buffered by the async writers and the sinks of the
+// instance and stops them; the instance keeps logging synchronously.
+func (l *zeroLogger) Close() error {
+	l.sampler.Flush()
+	err := l.async.Close()
+	if serr := l.sinks.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Dropped returns the number of lines the async writers of the instance dropped.
+func (l *zeroLogger) Dropped() uint64 {
+	return l.async.Dropped()
 }
 
 func (l *zeroLogger) Logger() logcore.Logger {
@@ -220,22 +347,131 @@ func (l *zeroLogger) clone() *zeroLogger {
 
 func (l *zeroLogger) AddCallerSkip(skip int) logcore.Logger {
 	zl := l.clone()
-	zl.zero = zl.zero.With().CallerWithSkipFrameCount(defaultCallerSkip + skip).Logger()
+	zl.callerSkip = defaultCallerSkip + skip
+	zl.zero = zl.zero.With().CallerWithSkipFrameCount(zl.callerSkip).Logger()
 	return zl
 }
 
+func (l *zeroLogger) Named(name string) logcore.LevelLogger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child := l.clone()
+	child.zero = l.zero.With().Str("logger", name).Logger()
+	child.level = new(atomic.Int32)
+	child.level.Store(l.level.Load())
+	child.name = name
+	return child
+}
+
+func (l *zeroLogger) Level() string {
+	return zerolog.Level(l.level.Load()).String()
+}
+
+func (l *zeroLogger) SetLevel(level string) error {
+	lv, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.Store(int32(lv))
+	return nil
+}
+
+func (l *zeroLogger) enabled(lv zerolog.Level) bool {
+	return lv >= zerolog.Level(l.level.Load())
+}
+
 func (l *zeroLogger) log(lv zerolog.Level, msg string, kvs ...logcore.Field) {
+	if !l.enabled(lv) || !l.sampler.Allow(lv.String(), msg) {
+		return
+	}
 	e := l.zero.WithLevel(lv)
-	addFields(e, kvs...)
+	appendFields(e, l.fields(kvs))
+	l.addStack(e, lv)
 	e.Msg(msg)
+	l.syncFatal(lv)
 }
 
 func (l *zeroLogger) logf(lv zerolog.Level, format string, args ...any) {
-	l.zero.WithLevel(lv).Msgf(format, args...)
+	if !l.enabled(lv) {
+		return
+	}
+	if l.sampler != nil {
+		// Sampling is keyed by the formatted message.
+		msg := fmt.Sprintf(format, args...)
+		if l.sampler.Allow(lv.String(), msg) {
+			e := l.zero.WithLevel(lv)
+			appendFields(e, l.ns)
+			l.addStack(e, lv)
+			e.Msg(msg)
+			l.syncFatal(lv)
+		}
+		return
+	}
+	e := l.zero.WithLevel(lv)
+	appendFields(e, l.ns)
+	l.addStack(e, lv)
+	e.Msgf(format, args...)
+	l.syncFatal(lv)
 }
 
-func addFields(e *zerolog.Event, kvs ...logcore.Field) {
-	for _, kv := range kvs {
+// addStack adds the stack trace of the logging call to the entries at
+// stackLevel and above. It is called by log and logf.
+func (l *zeroLogger) addStack(e *zerolog.Event, lv zerolog.Level) {
+	if lv >= l.stackLevel && lv <= zerolog.PanicLevel {
+		e.Str("stacktrace", logcore.Stacktrace(l.callerSkip-1))
+	}
+}
+
+// syncFatal writes the buffered lines after a panic or fatal entry, which may be the last one.
+func (l *zeroLogger) syncFatal(lv zerolog.Level) {
+	if lv == zerolog.FatalLevel || lv == zerolog.PanicLevel {
+		_ = l.async.Sync()
+		_ = l.sinks.Sync()
+	}
+}
+
+// newSinkWriters returns the writers passing the entries of level min and
+// above to sink, one per level so that the sink knows the level of each entry.
+func newSinkWriters(sink logcore.Sink, min string) ([]io.Writer, error) {
+	minLevel := zerolog.DebugLevel
+	if min != "" {
+		var err error
+		if minLevel, err = zerolog.ParseLevel(min); err != nil {
+			return nil, err
+		}
+	}
+	var writers []io.Writer
+	for i := minLevel; i <= zerolog.PanicLevel; i++ {
+		targetLevel := i
+		writers = append(writers, NewLevelWriter(
+			logcore.SinkWriter(sink, targetLevel.String()),
+			func(l zerolog.Level) bool { return l == targetLevel },
+		))
+	}
+	return writers, nil
+}
+
+func newFileWriter(cfg *logcore.LoggerConf, filename string, async *logcore.AsyncWriters) (io.Writer, error) {
+	w, err := logcore.NewFileWriter(cfg, filename)
+	if err != nil {
+		return nil, err
+	}
+	return async.Wrap(zerolog.SyncWriter(w), cfg)
+}
+
+// fields returns kvs redacted, after the namespace left open by With.
+func (l *zeroLogger) fields(kvs []logcore.Field) []logcore.Field {
+	kvs = logcore.Redact(kvs)
+	if len(l.ns) == 0 {
+		return kvs
+	}
+	return append(slices.Clip(l.ns), kvs...)
+}
+
+// appendFields adds kvs, already redacted, to e.
+func appendFields(e *zerolog.Event, kvs []logcore.Field) {
+	for i, kv := range kvs {
 		switch kv.Kind {
 		case reflect.Bool:
 			e.Bool(kv.Key, kv.Value.(bool))
@@ -265,6 +501,33 @@ func addFields(e *zerolog.Event, kvs ...logcore.Field) {
 			e.Float64(kv.Key, kv.Value.(float64))
 		case reflect.String:
 			e.Str(kv.Key, kv.Value.(string))
+		case logcore.KindDuration:
+			e.Dur(kv.Key, kv.Value.(time.Duration))
+		case logcore.KindTime:
+			e.Time(kv.Key, kv.Value.(time.Time))
+		case logcore.KindStringer:
+			e.Stringer(kv.Key, kv.Value.(fmt.Stringer))
+		case logcore.KindBinary:
+			e.Str(kv.Key, base64.StdEncoding.EncodeToString(kv.Value.([]byte)))
+		case logcore.KindNamespace:
+			dict := zerolog.Dict()
+			appendFields(dict, kvs[i+1:])
+			e.Dict(kv.Key, dict)
+			return
+		case logcore.KindStack:
+			e.Str(kv.Key, kv.Value.(string))
+		case logcore.KindObject:
+			o := &zeroObject{m: kv.Value.(logcore.ObjectMarshaler)}
+			if e.Object(kv.Key, o); o.err != nil {
+				e.Str(kv.Key+"Error", o.err.Error())
+			}
+		case logcore.KindArray:
+			a := &zeroArray{m: kv.Value.(logcore.ArrayMarshaler)}
+			if e.Array(kv.Key, a); a.err != nil {
+				e.Str(kv.Key+"Error", a.err.Error())
+			}
+		case logcore.KindError:
+			appendFields(e, logcore.ErrorFields(kv.Key, kv.Value.(error)))
 		default:
 			switch v := kv.Value.(type) {
 			case error:
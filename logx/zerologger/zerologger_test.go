@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/BYT0723/go-tools/logx/internal/backendtest"
 	"github.com/BYT0723/go-tools/logx/logcore"
 	"github.com/rs/zerolog"
 
@@ -42,7 +43,7 @@ func TestNewInstance(t *testing.T) {
 	t.Run("NewInstance 测试", func(t *testing.T) {
 		t.Run("有效配置创建实例", func(t *testing.T) {
 			cfg := &logcore.LoggerConf{
-				Dir:        ".",
+				Dir:        t.TempDir(),
 				Name:       "test",
 				Ext:        ".log",
 				Level:      "debug",
@@ -59,7 +60,7 @@ func TestNewInstance(t *testing.T) {
 		t.Run("无效level返回错误", func(t *testing.T) {
 			cfg := &logcore.LoggerConf{
 				Level:      "invalid-level",
-				Dir:        ".",
+				Dir:        t.TempDir(),
 				Name:       "test",
 				Ext:        ".log",
 				MaxBackups: 1,
@@ -75,7 +76,7 @@ func TestNewInstance(t *testing.T) {
 func TestZeroLoggerMethods(t *testing.T) {
 	t.Run("zeroLogger 方法测试", func(t *testing.T) {
 		cfg := &logcore.LoggerConf{
-			Dir:        ".",
+			Dir:        t.TempDir(),
 			Name:       "test",
 			Ext:        ".log",
 			Level:      "debug",
@@ -132,6 +133,7 @@ func TestZeroLoggerMethods(t *testing.T) {
 func TestZeroLoggerInterface(t *testing.T) {
 	t.Run("zeroLogger 实现 Logger 接口", func(t *testing.T) {
 		cfg := logcore.DefaultLoggerConf()
+		cfg.Dir = t.TempDir()
 		zl, err := NewInstance(cfg)
 		assert.Nil(t, err)
 
@@ -139,3 +141,10 @@ func TestZeroLoggerInterface(t *testing.T) {
 		assert.NotNil(t, l)
 	})
 }
+
+func TestConformance(t *testing.T) {
+	backendtest.Run(t, backendtest.Backend{
+		New:      func(cfg *logcore.LoggerConf) (backendtest.Instance, error) { return NewInstance(cfg) },
+		Duration: 1500.0,
+	})
+}
@@ -2,9 +2,11 @@ package component
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/BYT0723/go-tools/ds"
 	"github.com/BYT0723/go-tools/monitor"
 
 	"github.com/stretchr/testify/assert"
@@ -156,6 +158,37 @@ func TestMonitorComponentNotify(t *testing.T) {
 			default:
 			}
 		})
+
+		t.Run("PublishTo 按告警主题发布", func(t *testing.T) {
+			bus := ds.NewBroadcastBus[*monitor.Alert]()
+			defer bus.Close()
+			web, _ := bus.Subscribe("alerts/web/+")
+			all, _ := bus.Subscribe("alerts/#")
+
+			mc := NewMonitorComponent()
+			mc.SetAlertTopic(monitor.AlertTopic("web", "api"))
+			mc.PublishTo(bus)
+			alert := monitor.NewAlert(monitor.SeverityWarning, monitor.SourceAlertRule, "slow", nil)
+			mc.Notify(alert)
+
+			assert.Same(t, alert, <-web.Channel())
+			assert.Same(t, alert, <-all.Channel())
+			assert.Same(t, alert, <-mc.Subscribe())
+		})
+
+		t.Run("空名称的告警主题可被通配订阅", func(t *testing.T) {
+			bus := ds.NewBroadcastBus[*monitor.Alert]()
+			defer bus.Close()
+			web, _ := bus.Subscribe("alerts/web/+")
+
+			mc := NewMonitorComponent()
+			mc.SetAlertTopic(monitor.AlertTopic("web", ""))
+			mc.PublishTo(bus)
+			alert := monitor.NewAlert(monitor.SeverityWarning, monitor.SourceAlertRule, "down", nil)
+			mc.Notify(alert)
+
+			assert.Same(t, alert, <-web.Channel())
+		})
 	})
 }
 
@@ -208,3 +241,59 @@ func TestMonitorComponentTicker(t *testing.T) {
 		})
 	})
 }
+
+func TestMonitorComponentTimingWheel(t *testing.T) {
+	t.Run("MonitorComponent 共享TimingWheel 测试", func(t *testing.T) {
+		w := ds.NewTimingWheel(time.Millisecond, 16)
+		defer w.Stop()
+
+		mc := NewMonitorComponent()
+		mc.SetCycle(5 * time.Millisecond)
+		mc.SetTimingWheel(w)
+
+		tick := mc.Tick()
+		assert.Equal(t, tick, mc.Tick())
+		for range 2 {
+			select {
+			case <-tick:
+			case <-time.After(time.Second):
+				t.Fatal("cycle did not fire")
+			}
+		}
+		assert.Equal(t, 1, w.Len())
+
+		mc.StopTick()
+		assert.Equal(t, 0, w.Len())
+	})
+}
+
+func TestMonitorComponentConcurrent(t *testing.T) {
+	t.Run("MonitorComponent 并发配置与Tick", func(t *testing.T) {
+		w := ds.NewTimingWheel(time.Millisecond, 16)
+		defer w.Stop()
+
+		mc := NewMonitorComponent()
+		mc.SetTimingWheel(w)
+		bus := ds.NewBroadcastBus[*monitor.Alert]()
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Go(func() {
+				switch i % 4 {
+				case 0:
+					mc.Tick()
+				case 1:
+					mc.SetCycle(time.Duration(i+1) * time.Millisecond)
+				case 2:
+					mc.PublishTo(bus)
+					mc.SetAlertTopic(monitor.AlertTopic("web", "a"))
+				case 3:
+					mc.Notify(&monitor.Alert{})
+					_ = mc.AlertTopic()
+				}
+			})
+		}
+		wg.Wait()
+		mc.StopTick()
+	})
+}
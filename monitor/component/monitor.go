@@ -4,9 +4,11 @@ package component
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/BYT0723/go-tools/channelx"
+	"github.com/BYT0723/go-tools/ds"
 	"github.com/BYT0723/go-tools/monitor"
 )
 
@@ -14,12 +16,18 @@ type (
 	// MonitorComponent is a struct that encapsulates monitoring functionality.
 	// It manages the monitoring cycle, timeout, and alert notifications.
 	MonitorComponent struct {
+		mutex       sync.Mutex          // Protects the cycle, tickers, wheel, bus and topic
 		ctx         context.Context     // The context for cancellation
 		cf          context.CancelFunc  // The cancel function for the context
 		cycle       time.Duration       // The monitoring cycle duration
 		cycleTicker *time.Ticker        // The ticker for the monitoring cycle
+		wheel       *ds.TimingWheel     // Optional shared timing wheel driving the cycle
+		wheelTicker *ds.WheelTicker     // The cycle ticker when a timing wheel is set
 		Timeout     time.Duration       // The timeout duration for monitoring
 		ch          chan *monitor.Alert // Channel for alerts
+
+		bus   *ds.BroadcastBus[*monitor.Alert] // Optional bus the alerts are also published on
+		topic string                           // The topic of the alerts on the bus
 	}
 )
 
@@ -35,6 +43,9 @@ func NewMonitorComponent() *MonitorComponent {
 // SetCycle sets the monitoring cycle duration. If the cycle is 0 or unchanged, it will not update.
 // If the timeout is greater than the new cycle, the timeout will be adjusted to match the new cycle.
 func (m *MonitorComponent) SetCycle(cycle time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	if m.cycle == cycle || cycle == 0 {
 		return
 	}
@@ -45,6 +56,17 @@ func (m *MonitorComponent) SetCycle(cycle time.Duration) {
 	if m.cycleTicker != nil {
 		m.cycleTicker.Reset(cycle) // Reset the ticker with the new cycle duration
 	}
+	if m.wheelTicker != nil {
+		m.wheelTicker.Reset(cycle)
+	}
+}
+
+// SetTimingWheel drives the monitoring cycle from a shared ds.TimingWheel
+// instead of a dedicated time.Ticker. It must be called before Start.
+func (m *MonitorComponent) SetTimingWheel(w *ds.TimingWheel) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.wheel = w
 }
 
 // SetTimeout sets the timeout duration for monitoring. If the timeout is 0 or unchanged, it will not update.
@@ -55,9 +77,41 @@ func (m *MonitorComponent) SetTimeout(timeout time.Duration) {
 	m.Timeout = timeout
 }
 
+// SetAlertTopic sets the topic the alerts are published on, see monitor.AlertTopic.
+// The monitors set "alerts/<type>/<name>" when they are created.
+func (m *MonitorComponent) SetAlertTopic(topic string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.topic = topic
+}
+
+// AlertTopic returns the topic the alerts are published on.
+func (m *MonitorComponent) AlertTopic() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.topic
+}
+
+// PublishTo also publishes the alerts on bus, under the alert topic, so that
+// consumers can subscribe by pattern (e.g. "alerts/web/+"). Alerts without
+// a matching subscriber are dropped. It must be called before Start.
+func (m *MonitorComponent) PublishTo(bus *ds.BroadcastBus[*monitor.Alert]) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.bus = bus
+}
+
 // Notify sends one or more alerts to the alert channel. It uses a timeout when sending the alert.
+// The alerts are also published on the bus set by PublishTo, if any.
 func (m *MonitorComponent) Notify(as ...*monitor.Alert) {
+	m.mutex.Lock()
+	bus, topic := m.bus, m.topic
+	m.mutex.Unlock()
+
 	for _, a := range as {
+		if bus != nil && topic != "" {
+			_ = bus.Publish(topic, a)
+		}
 		// Send the alert to the channel with a timeout of 1 second
 		_ = channelx.InTimeout(m.ch, a, time.Second)
 	}
@@ -91,8 +145,42 @@ func (m *MonitorComponent) Context() context.Context {
 
 // Ticker returns the current ticker for the monitoring cycle. If the ticker is nil, it initializes it.
 func (m *MonitorComponent) Ticker() *time.Ticker {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.ticker()
+}
+
+func (m *MonitorComponent) ticker() *time.Ticker {
 	if m.cycleTicker == nil {
 		m.cycleTicker = time.NewTicker(m.cycle) // Create a new ticker if not already initialized
 	}
 	return m.cycleTicker
 }
+
+// Tick returns the channel that fires once per monitoring cycle.
+// It uses the timing wheel if one is set, otherwise the Ticker.
+func (m *MonitorComponent) Tick() <-chan time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.wheel == nil {
+		return m.ticker().C
+	}
+	if m.wheelTicker == nil {
+		m.wheelTicker = m.wheel.NewTicker(m.cycle)
+	}
+	return m.wheelTicker.C
+}
+
+// StopTick stops the cycle ticker started by Tick.
+func (m *MonitorComponent) StopTick() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.wheelTicker != nil {
+		m.wheelTicker.Stop()
+	}
+	if m.cycleTicker != nil {
+		m.cycleTicker.Stop()
+	}
+}
@@ -2,9 +2,13 @@ package monitor
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
+// AlertTopicPrefix is the root level of hierarchical alert topics.
+const AlertTopicPrefix = "alerts"
+
 // Monitor defines the behavior of a monitor.
 type Monitor interface {
 	// Start initializes and begins the monitoring process using the provided context.
@@ -58,3 +62,26 @@ func NewAlert(severity Severity, source Source, descr string, payload any) *Aler
 		Payload:  payload,
 	}
 }
+
+// AlertTopic returns the hierarchical topic "alerts/<kind>/<name>" used to
+// publish alerts of a monitor on a topic bus such as ds.BroadcastBus.
+// Consumers can subscribe by pattern, e.g. "alerts/web/+" or "alerts/#".
+// The web, ping and prometheus monitors publish on it once given a bus with
+// PublishTo.
+//
+// Separator characters in kind and name are replaced by "_" so each stays one
+// level, and an empty kind or name becomes "_": an empty level would be a
+// topic of its own, which "alerts/web/+" does not match.
+func AlertTopic(kind, name string) string {
+	return AlertTopicPrefix + "/" + topicLevel(kind) + "/" + topicLevel(name)
+}
+
+var topicLevelReplacer = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// topicLevel returns s as a single non-empty topic level.
+func topicLevel(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return topicLevelReplacer.Replace(s)
+}
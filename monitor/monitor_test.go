@@ -117,3 +117,14 @@ func TestAlertTimestamp(t *testing.T) {
 		assert.True(t, alert.Ts.Before(after) || alert.Ts.Equal(after))
 	})
 }
+
+func TestAlertTopic(t *testing.T) {
+	t.Run("AlertTopic 测试", func(t *testing.T) {
+		assert.Equal(t, "alerts/web/api", AlertTopic("web", "api"))
+		assert.Equal(t, "alerts/ping/10.0.0.1", AlertTopic("ping", "10.0.0.1"))
+		assert.Equal(t, "alerts/web/_", AlertTopic("web", ""))
+		assert.Equal(t, "alerts/_/_", AlertTopic("", ""))
+		assert.Equal(t, "alerts/a_b/x_y", AlertTopic("a/b", "x#y"))
+		assert.Equal(t, "alerts/web/http:__x_y", AlertTopic("web", "http://x/y"))
+	})
+}
@@ -46,6 +46,7 @@ func NewMonitor(addr string, opts ...Option) *Monitor {
 		count:            count,                           // Set the default ping attempt count.
 	}
 	p.SetCycle(cycle) // Set the monitoring cycle duration.
+	p.SetAlertTopic(monitor.AlertTopic("ping", addr))
 
 	// Apply any provided options to configure the monitor.
 	for _, opt := range opts {
@@ -66,8 +67,8 @@ func (m *Monitor) Start(ctx context.Context) {
 
 	// Start the monitoring process in a separate goroutine.
 	go func() {
-		t := m.Ticker() // Get the ticker for the monitoring cycle.
-		defer t.Stop()
+		t := m.Tick() // Get the ticker for the monitoring cycle.
+		defer m.StopTick()
 
 		// Perform the first ping operation.
 		s, err := m.do()
@@ -83,7 +84,7 @@ func (m *Monitor) Start(ctx context.Context) {
 			case <-m.Context().Done():
 				m.Stop(ctx) // Stop monitoring if the context is canceled.
 				return
-			case <-t.C:
+			case <-t:
 				// Perform subsequent pings at regular intervals.
 				s, err := m.do()
 				if err != nil {
@@ -14,6 +14,7 @@ func TestNewMonitor(t *testing.T) {
 		m := NewMonitor("127.0.0.1")
 		assert.NotNil(t, m)
 		assert.Equal(t, "127.0.0.1", m.addr)
+		assert.Equal(t, "alerts/ping/127.0.0.1", m.AlertTopic())
 		assert.GreaterOrEqual(t, m.count, minCount)
 	})
 
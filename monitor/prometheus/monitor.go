@@ -55,6 +55,7 @@ func NewMonitor(addr string, opts ...Option) *Monitor {
 		addr:             addr,                                                  // Set the target address for the web service.
 	}
 	p.SetCycle(defautlCycle) // Set the default monitoring cycle.
+	p.SetAlertTopic(monitor.AlertTopic("prometheus", addr))
 	p.Timeout = defautlCycle // Set the default timeout.
 
 	// Apply any provided options to configure the monitor.
@@ -69,15 +70,15 @@ func (m *Monitor) Start(ctx context.Context) {
 	m.SetContext(ctx)
 
 	go func() {
-		t := m.Ticker()
-		defer t.Stop()
+		t := m.Tick()
+		defer m.StopTick()
 
 		for {
 			select {
 			case <-m.Context().Done():
 				m.Stop(ctx)
 				return
-			case <-t.C:
+			case <-t:
 				d, err := m.do()
 				if err != nil {
 					m.Notify(monitor.InternalAlert(err))
@@ -16,6 +16,7 @@ func TestNewMonitor(t *testing.T) {
 		m := NewMonitor("http://localhost:9100/metrics")
 		assert.NotNil(t, m)
 		assert.Equal(t, "http://localhost:9100/metrics", m.addr)
+		assert.Equal(t, "alerts/prometheus/http:__localhost:9100_metrics", m.AlertTopic())
 	})
 
 	t.Run("WithCycle", func(t *testing.T) {
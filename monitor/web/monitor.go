@@ -60,6 +60,7 @@ func NewMonitor(method string, addr string, opts ...Option) *Monitor {
 		addr:             addr,                                                  // Set the target address for the web service.
 	}
 	p.SetCycle(defautlCycle) // Set the default monitoring cycle.
+	p.SetAlertTopic(monitor.AlertTopic("web", addr))
 	p.Timeout = defautlCycle // Set the default timeout.
 
 	// Apply any provided options to configure the monitor.
@@ -76,8 +77,8 @@ func (m *Monitor) Start(ctx context.Context) {
 
 	// Start the monitoring process in a separate goroutine.
 	go func() {
-		t := m.Ticker() // Get the ticker for the monitoring cycle.
-		defer t.Stop()
+		t := m.Tick() // Get the ticker for the monitoring cycle.
+		defer m.StopTick()
 
 		// Perform the first HTTP request and evaluate the response.
 		s, err := m.do()
@@ -93,7 +94,7 @@ func (m *Monitor) Start(ctx context.Context) {
 			case <-m.Context().Done():
 				m.Stop(ctx) // Stop monitoring if the context is canceled.
 				return
-			case <-t.C:
+			case <-t:
 				// Perform subsequent HTTP requests at regular intervals.
 				s, err := m.do()
 				if err != nil {
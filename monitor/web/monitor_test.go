@@ -17,6 +17,7 @@ func TestNewMonitor(t *testing.T) {
 		m := NewMonitor(http.MethodGet, "http://example.com")
 		assert.NotNil(t, m)
 		assert.Equal(t, "http://example.com", m.addr)
+		assert.Equal(t, "alerts/web/http:__example.com", m.AlertTopic())
 	})
 
 	t.Run("WithTimeout", func(t *testing.T) {
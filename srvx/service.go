@@ -2,8 +2,11 @@ package srvx
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 
+	"github.com/BYT0723/go-tools/ds"
 	"github.com/BYT0723/go-tools/logx"
 	"github.com/BYT0723/go-tools/logx/noplogger"
 )
@@ -31,40 +34,101 @@ type Services struct {
 
 	wg       sync.WaitGroup
 	services []Service
+	deps     [][]string
 }
 
-func (ss *Services) Register(s Service) {
+// Register 注册服务
+// deps 为依赖服务的名称, 服务在所有依赖 Init 成功后才会 Init
+// 依赖未注册时, Run 会跳过该服务及依赖它的服务, 其余服务照常启动
+func (ss *Services) Register(s Service, deps ...string) {
 	ss.services = append(ss.services, s)
+	ss.deps = append(ss.deps, deps)
 }
 
-// Run start all service and wait for all service exit
+// Run start all service and wait for all service exit.
+// Services are initialized in dependency order; independent services start in
+// parallel. A service whose dependency fails to init or is not registered is
+// skipped, and so are the services depending on it.
 func (ss *Services) Run(ctx context.Context) {
 	if ss.Log == nil {
 		ss.Log = noplogger.NopLogger{}
 	}
-	for _, s := range ss.services {
-		ss.wg.Go(func() {
-			name := s.Name()
-
-			ss.Log.Info("service init", logx.String("name", name))
-			if err := s.Init(ctx); err != nil {
-				ss.Log.Error("service init error", logx.String("name", name), logx.Err(err))
-				return
+
+	byName := make(map[string]int, len(ss.services))
+	for i, s := range ss.services {
+		if _, ok := byName[s.Name()]; !ok {
+			byName[s.Name()] = i
+		}
+	}
+
+	var (
+		e       = ds.NewDAGExecutor[int](ds.WithContinueOnError())
+		mu      sync.Mutex
+		started = make([]bool, len(ss.services))
+	)
+	for i, s := range ss.services {
+		var (
+			deps    = make([]int, 0, len(ss.deps[i]))
+			missing []string
+		)
+		for _, name := range ss.deps[i] {
+			d, ok := byName[name]
+			if !ok {
+				ss.Log.Error("service dependency not registered", logx.String("name", s.Name()), logx.String("dependency", name))
+				missing = append(missing, name)
+				continue
 			}
+			deps = append(deps, d)
+		}
+		if len(missing) > 0 {
+			// Fail the node so that the executor skips its dependents.
+			e.Add(i, func(context.Context) error {
+				return fmt.Errorf("%w: %v", ds.ErrUnknownDependency, missing)
+			}, deps...)
+			continue
+		}
+		e.Add(i, func(context.Context) error {
+			mu.Lock()
+			started[i] = true
+			mu.Unlock()
+			return ss.start(ctx, s)
+		}, deps...)
+	}
 
-			defer func() {
-				ss.Log.Info("service exit", logx.String("name", name))
-				if err := s.Destroy(ctx); err != nil {
-					ss.Log.Error("service destroy error", logx.String("name", name), logx.Err(err))
-				}
-			}()
-
-			ss.Log.Info("service run", logx.String("name", name))
-			if err := s.Run(ctx); err != nil {
-				ss.Log.Error("service run error", logx.String("name", name), logx.Err(err))
-				return
+	if err := e.Run(ctx); errors.Is(err, ds.ErrCycle) {
+		ss.Log.Error("service dependency cycle", logx.Err(err))
+	} else {
+		for i, s := range ss.services {
+			if !started[i] {
+				ss.Log.Error("service skipped", logx.String("name", s.Name()))
 			}
-		})
+		}
 	}
 	ss.wg.Wait()
 }
+
+// start initializes s and, on success, runs it in the background until it exits.
+func (ss *Services) start(ctx context.Context, s Service) error {
+	name := s.Name()
+	ss.Log.Info("service init", logx.String("name", name))
+	if err := s.Init(ctx); err != nil {
+		ss.Log.Error("service init error", logx.String("name", name), logx.Err(err))
+		return err
+	}
+
+	ss.wg.Go(func() {
+		defer func() {
+			ss.Log.Info("service exit", logx.String("name", name))
+			if err := s.Destroy(ctx); err != nil {
+				ss.Log.Error("service destroy error", logx.String("name", name), logx.Err(err))
+			}
+		}()
+
+		ss.Log.Info("service run", logx.String("name", name))
+		if err := s.Run(ctx); err != nil {
+			ss.Log.Error("service run error", logx.String("name", name), logx.Err(err))
+			return
+		}
+	})
+	return nil
+}
@@ -2,11 +2,16 @@ package srvx
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/BYT0723/go-tools/logx"
 	"github.com/BYT0723/go-tools/logx/logcore"
+	"github.com/BYT0723/go-tools/logx/logtest"
 	"github.com/BYT0723/go-tools/logx/noplogger"
+	"github.com/stretchr/testify/assert"
 )
 
 type (
@@ -32,7 +37,7 @@ func (s *sshService) Destroy(ctx context.Context) error  { return nil }
 func (s *frpService) Destroy(ctx context.Context) error  { return nil }
 
 func TestServices_Run(t *testing.T) {
-	if err := logx.Init(logx.WithConf(&logcore.LoggerConf{Console: true})); err != nil {
+	if err := logx.Init(logx.WithConf(&logcore.LoggerConf{Dir: t.TempDir(), Console: true})); err != nil {
 		t.Fatal(err)
 	}
 
@@ -70,3 +75,97 @@ func TestServiceInterface(t *testing.T) {
 	// 编译期验证 NopLogger 可作为 Logger
 	var _ logx.Logger = noplogger.NopLogger{}
 }
+
+type recordService struct {
+	name    string
+	initErr error
+	mu      *sync.Mutex
+	events  *[]string
+}
+
+func (s *recordService) record(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.events = append(*s.events, s.name+":"+event)
+}
+
+func (s *recordService) Name() string { return s.name }
+func (s *recordService) Init(ctx context.Context) error {
+	s.record("init")
+	return s.initErr
+}
+func (s *recordService) Run(ctx context.Context) error     { return nil }
+func (s *recordService) Destroy(ctx context.Context) error { s.record("destroy"); return nil }
+
+func TestServices_Dependencies(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	newService := func(name string, initErr error) *recordService {
+		return &recordService{name: name, initErr: initErr, mu: &mu, events: &events}
+	}
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		events = nil
+	}
+
+	t.Run("依赖先初始化", func(t *testing.T) {
+		reset()
+		var srv Services
+		srv.Register(newService("http", nil), "db", "cache")
+		srv.Register(newService("db", nil), "config")
+		srv.Register(newService("cache", nil))
+		srv.Register(newService("config", nil))
+		srv.Run(context.Background())
+
+		index := func(e string) int { return slices.Index(events, e) }
+		assert.Less(t, index("config:init"), index("db:init"))
+		assert.Less(t, index("db:init"), index("http:init"))
+		assert.Less(t, index("cache:init"), index("http:init"))
+		assert.Len(t, events, 8)
+	})
+
+	t.Run("依赖初始化失败时跳过依赖方", func(t *testing.T) {
+		reset()
+		var srv Services
+		log := logtest.New()
+		srv.Log = log
+		srv.Register(newService("db", errors.New("connect refused")))
+		srv.Register(newService("http", nil), "db")
+		srv.Register(newService("cron", nil))
+		srv.Run(context.Background())
+
+		assert.ElementsMatch(t, []string{"db:init", "cron:init", "cron:destroy"}, events)
+		log.AssertLogged(t, "error", "service init error", logx.String("name", "db"))
+		log.AssertLogged(t, "error", "service skipped", logx.String("name", "http"))
+		log.AssertNotLogged(t, "info", "service init", logx.String("name", "http"))
+	})
+
+	t.Run("未注册的依赖只跳过该服务及其依赖方", func(t *testing.T) {
+		reset()
+		log := logtest.New()
+		srv := Services{Log: log}
+		srv.Register(newService("http", nil), "missing")
+		srv.Register(newService("gateway", nil), "http")
+		srv.Register(newService("cron", nil))
+		srv.Run(context.Background())
+
+		assert.ElementsMatch(t, []string{"cron:init", "cron:destroy"}, events)
+		log.AssertLogged(t, "error", "service dependency not registered", logx.String("name", "http"), logx.String("dependency", "missing"))
+		log.AssertLogged(t, "error", "service skipped", logx.String("name", "http"))
+		log.AssertLogged(t, "error", "service skipped", logx.String("name", "gateway"))
+	})
+
+	t.Run("循环依赖不启动任何服务", func(t *testing.T) {
+		reset()
+		log := logtest.New()
+		srv := Services{Log: log}
+		srv.Register(newService("a", nil), "b")
+		srv.Register(newService("b", nil), "a")
+		srv.Run(context.Background())
+		assert.Empty(t, events)
+		log.AssertLogged(t, "error", "service dependency cycle")
+	})
+}
@@ -1,11 +1,15 @@
 package echox
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/BYT0723/go-tools/ds"
 	"github.com/BYT0723/go-tools/logx"
+	"github.com/BYT0723/go-tools/logx/logtest"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,6 +34,31 @@ func TestWithTraceLogger(t *testing.T) {
 			mw := WithTraceLogger(nil)
 			assert.NotNil(t, mw)
 		})
+
+		t.Run("敏感字段脱敏", func(t *testing.T) {
+			logx.SetRedactor(logx.DefaultRedactor())
+			defer logx.SetRedactor(nil)
+
+			var buf bytes.Buffer
+			l, err := logx.NewLogger(logx.WithSlogHandler(slog.NewJSONHandler(&buf, nil)))
+			assert.Nil(t, err)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer secret-token")
+			c := e.NewContext(req, httptest.NewRecorder())
+			h := WithTraceLogger(l)(WithApiLog("info", func(c echo.Context) []logx.Field {
+				return []logx.Field{
+					logx.String("authorization", c.Request().Header.Get("Authorization")),
+					logx.String("body", "email=john.doe@example.com"),
+				}
+			})(func(c echo.Context) error { return c.String(200, "ok") }))
+			assert.Nil(t, h(c))
+
+			assert.Contains(t, buf.String(), `"authorization":"[REDACTED]"`)
+			assert.Contains(t, buf.String(), `"body":"email=john************.com"`)
+			assert.NotContains(t, buf.String(), "secret-token")
+		})
 	})
 }
 
@@ -46,6 +75,23 @@ func TestWithApiLog(t *testing.T) {
 			})
 			assert.NotNil(t, mw)
 		})
+
+		t.Run("记录请求日志", func(t *testing.T) {
+			log := logtest.New()
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/users", nil), httptest.NewRecorder())
+			h := WithTraceLogger(log)(WithApiLog("warn", func(c echo.Context) []logx.Field {
+				return []logx.Field{logx.String("custom", "value")}
+			})(func(c echo.Context) error { return c.String(404, "not found") }))
+			assert.Nil(t, h(c))
+
+			log.AssertLogged(t, "warn", "API REQUEST",
+				logx.String("custom", "value"),
+				logx.String("method", http.MethodGet),
+				logx.String("path", "/users"),
+				logx.Int("status", 404),
+			)
+		})
 	})
 }
 
@@ -124,3 +170,59 @@ func TestWithValue(t *testing.T) {
 		})
 	})
 }
+
+func TestWithRateLimit(t *testing.T) {
+	t.Run("WithRateLimit 测试", func(t *testing.T) {
+		limiter := ds.NewKeyedLimiter(func(string) ds.RateLimiter {
+			return ds.NewGCRALimiter(1, 1)
+		}, 0)
+		defer limiter.Release()
+
+		e := echo.New()
+		h := WithRateLimit(limiter, func(c echo.Context) string {
+			return c.Request().Header.Get("X-Api-Key")
+		})(func(c echo.Context) error {
+			return c.String(200, "ok")
+		})
+
+		do := func(key string) (*httptest.ResponseRecorder, error) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Api-Key", key)
+			rec := httptest.NewRecorder()
+			return rec, h(e.NewContext(req, rec))
+		}
+
+		rec, err := do("a")
+		assert.Nil(t, err)
+		assert.Equal(t, 200, rec.Code)
+
+		rec, err = do("a")
+		var he *echo.HTTPError
+		assert.ErrorAs(t, err, &he)
+		assert.Equal(t, http.StatusTooManyRequests, he.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+		_, err = do("b")
+		assert.Nil(t, err)
+	})
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("WithMetrics 测试", func(t *testing.T) {
+		var (
+			requests = ds.NewMeter()
+			latency  = ds.NewHistogram()
+			e        = echo.New()
+		)
+		h := WithMetrics(requests, latency)(func(c echo.Context) error {
+			return c.String(200, "ok")
+		})
+
+		for range 3 {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			assert.Nil(t, h(e.NewContext(req, httptest.NewRecorder())))
+		}
+		assert.Equal(t, int64(3), requests.Count())
+		assert.Equal(t, int64(3), latency.Count())
+	})
+}
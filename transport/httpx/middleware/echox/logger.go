@@ -13,6 +13,9 @@ var (
 	_ echo.MiddlewareFunc = WithApiLog("info")
 )
 
+// must be after WithTraceLogger
+// else do nothing
+// sensitive fields are masked by the redactor set with logx.SetRedactor
 func WithApiLog(level string, fields ...func(echo.Context) []logx.Field) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -33,25 +36,6 @@ func WithApiLog(level string, fields ...func(echo.Context) []logx.Field) echo.Mi
 				latency = time.Since(start)
 			)
 
-			if traceID, err := ctxx.TraceID(c.Request().Context()); err == nil {
-				fs = append(fs, logx.String("trace_id", traceID))
-			}
-			if spanID, err := ctxx.SpanID(c.Request().Context()); err == nil {
-				fs = append(fs, logx.String("span_id", spanID))
-			}
-			if reqID, err := ctxx.RequestID(c.Request().Context()); err == nil {
-				fs = append(fs, logx.String("request_id", reqID))
-			}
-			if service, err := ctxx.Service(c.Request().Context()); err == nil {
-				fs = append(fs, logx.String("service", service))
-			}
-			if version, err := ctxx.Version(c.Request().Context()); err == nil {
-				fs = append(fs, logx.String("version", version))
-			}
-			if env, err := ctxx.Env(c.Request().Context()); err == nil {
-				fs = append(fs, logx.String("env", env))
-			}
-
 			for _, f := range fields {
 				fs = append(fs, f(c)...)
 			}
@@ -62,7 +46,7 @@ func WithApiLog(level string, fields ...func(echo.Context) []logx.Field) echo.Mi
 				logx.Int("status", c.Response().Status),
 				logx.Duration("latency", latency),
 			)
-			l.Log(level, "API REQUEST", fs...)
+			l.LogCtx(c.Request().Context(), level, "API REQUEST", fs...)
 
 			return err
 		}
@@ -0,0 +1,30 @@
+package echox
+
+import (
+	"time"
+
+	"github.com/BYT0723/go-tools/ds"
+	"github.com/labstack/echo/v4"
+)
+
+var _ echo.MiddlewareFunc = WithMetrics(nil, nil)
+
+// WithMetrics marks every request on requests and records its latency in
+// nanoseconds on latency. Either may be nil to skip it.
+func WithMetrics(requests *ds.Meter, latency *ds.Histogram) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+
+			if requests != nil {
+				requests.Mark(1)
+			}
+			if latency != nil {
+				latency.ObserveDuration(time.Since(start))
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package echox
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/BYT0723/go-tools/ds"
+	"github.com/labstack/echo/v4"
+)
+
+var _ echo.MiddlewareFunc = WithRateLimit(nil, nil)
+
+// WithRateLimit rejects requests exceeding the per-key limit with 429 Too Many Requests
+// and a Retry-After header.
+// key extracts the limiter key from the request; nil uses the client IP.
+// A nil limiter disables rate limiting.
+func WithRateLimit(limiter *ds.KeyedLimiter[string], key func(echo.Context) string) echo.MiddlewareFunc {
+	if key == nil {
+		key = func(c echo.Context) string { return c.RealIP() }
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if limiter == nil {
+				return next(c)
+			}
+
+			r := limiter.Reserve(key(c))
+			if !r.OK() || r.Delay() > 0 {
+				r.Cancel()
+				if r.OK() {
+					c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(r.Delay().Seconds()))))
+				}
+				return echo.NewHTTPError(http.StatusTooManyRequests)
+			}
+			return next(c)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package ginx
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/BYT0723/go-tools/ds"
 	"github.com/BYT0723/go-tools/logx"
+	"github.com/BYT0723/go-tools/logx/logtest"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -33,6 +37,33 @@ func TestWithTraceLogger(t *testing.T) {
 			h := WithTraceLogger(nil)
 			assert.NotNil(t, h)
 		})
+
+		t.Run("敏感字段脱敏", func(t *testing.T) {
+			logx.SetRedactor(logx.DefaultRedactor())
+			defer logx.SetRedactor(nil)
+
+			var buf bytes.Buffer
+			l, err := logx.NewLogger(logx.WithSlogHandler(slog.NewJSONHandler(&buf, nil)))
+			assert.Nil(t, err)
+
+			router := gin.New()
+			router.Use(WithTraceLogger(l), WithApiLog("info", func(ctx *gin.Context) []logx.Field {
+				return []logx.Field{
+					logx.String("authorization", ctx.GetHeader("Authorization")),
+					logx.String("body", "email=john.doe@example.com"),
+				}
+			}))
+			router.GET("/", func(c *gin.Context) {
+				c.String(200, "ok")
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer secret-token")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Contains(t, buf.String(), `"authorization":"[REDACTED]"`)
+			assert.Contains(t, buf.String(), `"body":"email=john************.com"`)
+			assert.NotContains(t, buf.String(), "secret-token")
+		})
 	})
 }
 
@@ -49,6 +80,25 @@ func TestWithApiLog(t *testing.T) {
 			})
 			assert.NotNil(t, h)
 		})
+
+		t.Run("记录请求日志", func(t *testing.T) {
+			log := logtest.New()
+			router := gin.New()
+			router.Use(WithTraceLogger(log), WithApiLog("warn", func(ctx *gin.Context) []logx.Field {
+				return []logx.Field{logx.String("custom", "value")}
+			}))
+			router.GET("/users", func(c *gin.Context) {
+				c.String(404, "not found")
+			})
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+			log.AssertLogged(t, "warn", "API REQUEST",
+				logx.String("custom", "value"),
+				logx.String("method", http.MethodGet),
+				logx.String("path", "/users"),
+				logx.Int("status", 404),
+			)
+		})
 	})
 }
 
@@ -119,3 +169,52 @@ func TestWithValue(t *testing.T) {
 		})
 	})
 }
+
+func TestWithRateLimit(t *testing.T) {
+	t.Run("WithRateLimit 测试", func(t *testing.T) {
+		limiter := ds.NewKeyedLimiter(func(string) ds.RateLimiter {
+			return ds.NewTokenBucket(1, 2)
+		}, 0)
+		defer limiter.Release()
+
+		router := gin.New()
+		router.Use(WithRateLimit(limiter, nil))
+		router.GET("/", func(c *gin.Context) { c.String(200, "ok") })
+
+		do := func(ip string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = ip + ":1234"
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		assert.Equal(t, 200, do("10.0.0.1").Code)
+		assert.Equal(t, 200, do("10.0.0.1").Code)
+		rec := do("10.0.0.1")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+		t.Run("不同key互不影响", func(t *testing.T) {
+			assert.Equal(t, 200, do("10.0.0.2").Code)
+		})
+	})
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("WithMetrics 测试", func(t *testing.T) {
+		var (
+			requests = ds.NewMeter()
+			latency  = ds.NewHistogram()
+			router   = gin.New()
+		)
+		router.Use(WithMetrics(requests, latency))
+		router.GET("/", func(c *gin.Context) { c.String(200, "ok") })
+
+		for range 3 {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}
+		assert.Equal(t, int64(3), requests.Count())
+		assert.Equal(t, int64(3), latency.Count())
+	})
+}
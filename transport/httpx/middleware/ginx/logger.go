@@ -15,6 +15,7 @@ var (
 
 // must be after WithTraceLogger
 // else do nothing
+// sensitive fields are masked by the redactor set with logx.SetRedactor
 func WithApiLog(level string, fields ...func(*gin.Context) []logx.Field) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		// 开始时间
@@ -35,25 +36,6 @@ func WithApiLog(level string, fields ...func(*gin.Context) []logx.Field) func(*g
 			latency = time.Since(start)
 		)
 
-		if traceID, err := ctxx.TraceID(ctx.Request.Context()); err == nil {
-			fs = append(fs, logx.String("trace_id", traceID))
-		}
-		if spanID, err := ctxx.SpanID(ctx.Request.Context()); err == nil {
-			fs = append(fs, logx.String("span_id", spanID))
-		}
-		if reqID, err := ctxx.RequestID(ctx.Request.Context()); err == nil {
-			fs = append(fs, logx.String("request_id", reqID))
-		}
-		if service, err := ctxx.Service(ctx.Request.Context()); err == nil {
-			fs = append(fs, logx.String("service", service))
-		}
-		if version, err := ctxx.Version(ctx.Request.Context()); err == nil {
-			fs = append(fs, logx.String("version", version))
-		}
-		if env, err := ctxx.Env(ctx.Request.Context()); err == nil {
-			fs = append(fs, logx.String("env", env))
-		}
-
 		for _, f := range fields {
 			fs = append(fs, f(ctx)...)
 		}
@@ -64,7 +46,7 @@ func WithApiLog(level string, fields ...func(*gin.Context) []logx.Field) func(*g
 			logx.Int("status", ctx.Writer.Status()),
 			logx.Duration("latency", latency),
 		)
-		l.Log(level, "API REQUEST", fs...)
+		l.LogCtx(ctx.Request.Context(), level, "API REQUEST", fs...)
 	}
 }
 
@@ -0,0 +1,27 @@
+package ginx
+
+import (
+	"time"
+
+	"github.com/BYT0723/go-tools/ds"
+	"github.com/gin-gonic/gin"
+)
+
+var _ gin.HandlerFunc = WithMetrics(nil, nil)
+
+// WithMetrics marks every request on requests and records its latency in
+// nanoseconds on latency. Either may be nil to skip it.
+func WithMetrics(requests *ds.Meter, latency *ds.Histogram) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		if requests != nil {
+			requests.Mark(1)
+		}
+		if latency != nil {
+			latency.ObserveDuration(time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ginx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/BYT0723/go-tools/ds"
+	"github.com/gin-gonic/gin"
+)
+
+var _ gin.HandlerFunc = WithRateLimit(nil, nil)
+
+// WithRateLimit rejects requests exceeding the per-key limit with 429 Too Many Requests
+// and a Retry-After header.
+// key extracts the limiter key from the request; nil uses the client IP.
+// A nil limiter disables rate limiting.
+func WithRateLimit(limiter *ds.KeyedLimiter[string], key func(*gin.Context) string) gin.HandlerFunc {
+	if key == nil {
+		key = func(ctx *gin.Context) string { return ctx.ClientIP() }
+	}
+	return func(ctx *gin.Context) {
+		if limiter == nil {
+			ctx.Next()
+			return
+		}
+
+		r := limiter.Reserve(key(ctx))
+		if !r.OK() || r.Delay() > 0 {
+			r.Cancel()
+			if r.OK() {
+				ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(r.Delay().Seconds()))))
+			}
+			ctx.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		ctx.Next()
+	}
+}